@@ -3,18 +3,16 @@ package main
 import (
 	"Fenrir-CodeAuditTool/configs"
 	"Fenrir-CodeAuditTool/internal/utils"
-	"archive/zip"
 	"context"
 	"crypto/md5"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -53,6 +51,41 @@ code_audit:
     # 是否在启动时重新构建AST（当enabled为true时，此选项生效）
     rebuild_on_startup: false
 
+# 自定义规则配置（污点源、危险汇聚点与净化方法）
+rules:
+  # 规则文件路径列表，支持通配符，例如 "./rules/*.yaml"
+  files: []
+  # 是否禁用内置规则，只使用规则文件中的规则
+  disable_builtin: false
+
+# Java 归档（JAR/WAR/EAR）反编译配置，代码仓库路径为归档文件或归档所在目录时生效
+decompiler:
+  # 内置预设：cfr、procyon、vineflower
+  type: "cfr"
+  # 反编译器 jar 包路径
+  jar_path: "./lib/cfr.jar"
+  # java 可执行文件
+  java: "java"
+  # 自定义命令参数列表，{input} 替换为 jar 路径，{output} 替换为源码输出目录，设置后优先于 type
+  command: []
+  # 解包与反编译的工作目录
+  work_dir: "./work"
+  # 不反编译的嵌套依赖包（WEB-INF/lib 等目录中的 jar），按文件名通配符匹配，例如 "spring-*.jar"
+  exclude_libs: []
+
+# Maven 依赖索引配置：解析 pom.xml（包括父 POM）中声明的依赖，从本地仓库中找到对应的 jar，
+# 优先使用 -sources.jar，否则使用 decompiler 段配置的反编译器反编译后作为 library 层加入索引
+maven:
+  enabled: false
+  # 本地仓库路径，为空时使用 ~/.m2/repository
+  local_repository: ""
+  # 参与索引的依赖 scope，为空时为 compile、provided、runtime
+  scopes: []
+  # 只索引匹配的依赖，按 groupId:artifactId 通配符匹配，例如 "org.apache.sling:*"，为空时索引全部依赖
+  include: []
+  # 不索引的依赖，匹配方式同 include
+  exclude: []
+
 # 远程仓库配置
 remote_repository:
   enabled: false
@@ -79,179 +112,6 @@ remote_repository:
 	return nil
 }
 
-// RemoteRepositoryManager 远程仓库管理器
-type RemoteRepositoryManager struct {
-	config *configs.Config
-}
-
-// NewRemoteRepositoryManager 创建远程仓库管理器
-func NewRemoteRepositoryManager(config *configs.Config) *RemoteRepositoryManager {
-	return &RemoteRepositoryManager{
-		config: config,
-	}
-}
-
-// DownloadAndPrepare 下载并准备远程仓库
-func (m *RemoteRepositoryManager) DownloadAndPrepare() (string, error) {
-	repoConfig := m.config.RemoteRepository
-
-	switch repoConfig.Type {
-	case "zip":
-		return m.downloadAndExtractZip(repoConfig.URL, repoConfig.TargetPath)
-	case "git":
-		return m.cloneGitRepository(repoConfig.URL, repoConfig.Branch, repoConfig.TargetPath)
-	case "local":
-		return repoConfig.TargetPath, nil
-	default:
-		return "", fmt.Errorf("不支持的仓库类型: %s", repoConfig.Type)
-	}
-}
-
-// downloadAndExtractZip 下载并解压ZIP文件
-func (m *RemoteRepositoryManager) downloadAndExtractZip(url, targetPath string) (string, error) {
-	// 清理目标目录
-	if err := os.RemoveAll(targetPath); err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("清理目录失败: %v", err)
-	}
-
-	// 创建临时目录
-	if err := os.MkdirAll(targetPath, 0755); err != nil {
-		return "", fmt.Errorf("创建目录失败: %v", err)
-	}
-
-	// 下载文件
-	zipPath := filepath.Join(targetPath, "download.zip")
-	if err := m.downloadFile(url, zipPath); err != nil {
-		return "", err
-	}
-
-	// 解压文件
-	if err := m.extractZip(zipPath, targetPath); err != nil {
-		return "", err
-	}
-
-	// 删除zip文件
-	os.Remove(zipPath)
-
-	return targetPath, nil
-}
-
-// downloadFile 下载文件
-func (m *RemoteRepositoryManager) downloadFile(url, filepath string) error {
-	fmt.Printf("开始下载: %s\n", url)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("下载失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载失败，状态码: %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("创建文件失败: %v", err)
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("写入文件失败: %v", err)
-	}
-
-	fmt.Printf("下载完成: %s\n", filepath)
-	return nil
-}
-
-// extractZip 解压ZIP文件
-func (m *RemoteRepositoryManager) extractZip(zipPath, targetPath string) error {
-	fmt.Printf("开始解压: %s\n", zipPath)
-
-	r, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return fmt.Errorf("打开zip文件失败: %v", err)
-	}
-	defer r.Close()
-
-	for _, f := range r.File {
-		// 防止路径遍历攻击
-		fpath := filepath.Join(targetPath, f.Name)
-		if !strings.HasPrefix(fpath, filepath.Clean(targetPath)+string(os.PathSeparator)) {
-			return fmt.Errorf("非法文件路径: %s", fpath)
-		}
-
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(fpath, 0755); err != nil {
-				return fmt.Errorf("创建目录失败: %v", err)
-			}
-			continue
-		}
-
-		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
-			return fmt.Errorf("创建父目录失败: %v", err)
-		}
-
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return fmt.Errorf("创建文件失败: %v", err)
-		}
-
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
-			return fmt.Errorf("打开zip内文件失败: %v", err)
-		}
-
-		_, err = io.Copy(outFile, rc)
-
-		outFile.Close()
-		rc.Close()
-
-		if err != nil {
-			return fmt.Errorf("解压文件失败: %v", err)
-		}
-	}
-
-	fmt.Printf("解压完成: %s\n", targetPath)
-	return nil
-}
-
-// cloneGitRepository 克隆Git仓库
-func (m *RemoteRepositoryManager) cloneGitRepository(url, branch, targetPath string) (string, error) {
-	fmt.Printf("开始克隆Git仓库: %s (分支: %s)\n", url, branch)
-
-	// 清理目标目录
-	if err := os.RemoveAll(targetPath); err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("清理目录失败: %v", err)
-	}
-
-	// 构建git命令
-	args := []string{"clone"}
-	if branch != "" {
-		args = append(args, "--branch", branch)
-	}
-	args = append(args, url, targetPath)
-
-	cmd := exec.Command("git", args...)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return "", fmt.Errorf("git克隆失败: %v, 输出: %s", err, string(output))
-	}
-
-	fmt.Printf("Git克隆完成: %s\n", targetPath)
-	return targetPath, nil
-}
-
-// Cleanup 清理临时文件
-func (m *RemoteRepositoryManager) Cleanup() error {
-	if m.config.RemoteRepository.AutoClean {
-		fmt.Printf("清理临时目录: %s\n", m.config.RemoteRepository.TargetPath)
-		return os.RemoveAll(m.config.RemoteRepository.TargetPath)
-	}
-	return nil
-}
-
 // handleRemoteRepository 处理远程仓库下载和准备
 func handleRemoteRepository(config *configs.Config, remoteSpec, branch string) (string, error) {
 	parts := strings.SplitN(remoteSpec, ":", 2)
@@ -278,7 +138,7 @@ func handleRemoteRepository(config *configs.Config, remoteSpec, branch string) (
 	config.RemoteRepository.AutoClean = true
 
 	// 下载和处理仓库
-	repoManager := NewRemoteRepositoryManager(config)
+	repoManager := utils.NewRemoteRepositoryManager(config)
 	repoPath, err := repoManager.DownloadAndPrepare()
 	if err != nil {
 		return "", err
@@ -368,7 +228,7 @@ func (s *ServerState) IsReady() bool {
 
 func main() {
 	// 解析 -i 参数（仓库路径）
-	repoPathFlag := flag.String("i", "", "代码仓库路径（优先于 resources/config.yaml 中的配置）")
+	repoPathFlag := flag.String("i", "", "代码仓库路径，也可以是 .jar/.war/.ear 文件或包含它们的目录（优先于 resources/config.yaml 中的配置）")
 	remoteRepoFlag := flag.String("remote", "", "远程仓库URL (格式: type:url, 如 zip:https://example.com/repo.zip)")
 	branchFlag := flag.String("branch", "main", "Git分支名 (仅用于git类型)")
 	flag.Parse()
@@ -400,6 +260,15 @@ func main() {
 		config.CodeAudit.RepositoryPath = *repoPathFlag
 	}
 
+	// 代码仓库为 Java 归档（或归档目录）时，先解包并反编译为源码再建立索引
+	if utils.IsArchiveInput(config.CodeAudit.RepositoryPath) {
+		sourcePath, err := utils.NewArchiveIngester(config).Prepare(config.CodeAudit.RepositoryPath)
+		if err != nil {
+			log.Fatalf("处理 Java 归档失败: %v", err)
+		}
+		config.CodeAudit.RepositoryPath = sourcePath
+	}
+
 	// 创建服务器状态
 	serverState := NewServerState(config)
 
@@ -447,7 +316,7 @@ func main() {
 		mcp.WithDescription("从远程仓库下载代码并进行自动化代码审计。如果服务器未初始化，此工具将设置代码仓库路径并初始化AST索引"),
 		mcp.WithString("repository_url",
 			mcp.Required(),
-			mcp.Description("远程仓库URL，支持格式: zip:https://example.com/repo.zip 或 git:https://github.com/user/repo.git"),
+			mcp.Description("远程仓库URL，支持格式: zip:https://example.com/repo.zip、git:https://github.com/user/repo.git 或 local:/path/to/app.war；.jar/.war/.ear 会先解包并反编译"),
 		),
 		mcp.WithString("branch",
 			mcp.Description("Git分支名 (仅用于git仓库)"),
@@ -505,6 +374,28 @@ func main() {
 		}
 		stats["by_language"] = languageCount
 
+		// 反编译归档时按来源归档统计
+		artifactCount := make(map[string]int)
+		for _, node := range nodes {
+			if artifact := node.Metadata["artifact"]; artifact != "" {
+				artifactCount[artifact]++
+			}
+		}
+		if len(artifactCount) > 0 {
+			stats["by_artifact"] = artifactCount
+		}
+
+		// 按所属模块统计
+		moduleCount := make(map[string]int)
+		for _, node := range nodes {
+			if node.Module != "" {
+				moduleCount[node.Module]++
+			}
+		}
+		if len(moduleCount) > 0 {
+			stats["by_module"] = moduleCount
+		}
+
 		resultJSON, _ := json.MarshalIndent(stats, "", "  ")
 
 		return &mcp.CallToolResult{
@@ -524,26 +415,49 @@ func main() {
 			"你需要先使用 remote_code_audit 工具设置代码仓库。"+
 			"你可以通过三个参数传入想要搜索的类、方法或字段。如果你只想搜索类，只需要传入 className 参数，methodName 和 fieldName 置为空字符串。"+
 			"如果你想搜索类的方法或字段，需要同时传入（className 和 methodName）或（className 和 fieldName），另一个参数置为空字符串。"+
-			"该方法会将结果代码段以字符串形式返回。"),
+			"该方法会将结果代码段以字符串形式返回。"+
+			"传入 annotation 或 modifiers 参数时按注解或修饰符筛选，返回匹配的类、方法或字段及其注解和修饰符，"+
+			"例如查找所有带 @PreAuthorize 的方法、所有带 @Component 的类或所有 native 方法。Python 的装饰器同样按注解处理，例如 annotation 为 route 可以找到所有 @app.route 视图函数。"),
 		mcp.WithString("className",
 			mcp.Required(),
 			mcp.Description("本参数 className 用于指定要搜索的类名，为必需选项。例如 com.example.myClass ，"+
 				"对于内部类，则用 $ 表示，例如 com.example.myClass$insideClass 。"+
 				"在不知道包名的情况下，你也可以不传入全类名，只传入类名，例如 myClass 。这会返回所有匹配到的类。"+
-				"如果你只搜索类的全部代码，可以只传入 className ，methodName 和 fieldName 置为空字符串。"),
+				"如果你只搜索类的全部代码，可以只传入 className ，methodName 和 fieldName 置为空字符串。"+
+				"对于 Python 项目，类名为 模块路径.类名（如 app.views.UserView）；搜索模块级函数（如 Flask 视图函数）时 className 传入模块路径，例如 app.views 或 views 。"+
+				"JavaScript / TypeScript 项目的模块路径为不带扩展名的文件路径，例如 src/routes/user.controller 。"+
+				"PHP 项目的类名为 命名空间.类名（\\ 替换为 .，如 App.Http.UserController），全局命名空间中的函数 className 传入 global 。"),
 		),
 		mcp.WithString("methodName",
 			mcp.Required(),
 			mcp.Description("本参数 methodName 用于指定要搜索的方法，可以选择带上参数类型，为可选选项。下面是调用方法："+
 				"1、myMethod 表示仅根据方法名搜索，仅根据方法名搜索时会返回所有重构方法。"+
 				"2、myMethod() 表示根据方法名和参数类型搜索，此时为无参。"+
-				"3、myMethod(ArgTpye1 arg1, ArgType2[] arg2, String arg3) 表示根据方法名和参数类型搜索，此时为有参。在没有具体的参数名称时，你需要用 arg0, arg1，arg2... 表示参数名称",
+				"3、myMethod(ArgType1, ArgType2[], String) 表示根据方法名和参数类型搜索，此时为有参，参数类型可以带包名也可以不带。"+
+				"4、myMethod(ArgType1 name1, String name2) 表示同时按参数类型和形参名搜索，也可以只写形参名，例如 myMethod(name1, name2)。"+
+				"在配合 annotation 或 modifiers 参数筛选时，methodName 可以为 * 表示全部方法，或以 * 结尾表示名称前缀，例如 set* 。",
 			),
 		),
 		mcp.WithString("fieldName",
 			mcp.Required(),
 			mcp.Description("本参数 fieldName 用于指定要搜索的字段名，为可选选项。例如 myField 。没有指定时候必须为空字符串 "),
 		),
+		mcp.WithString("annotation",
+			mcp.Description("本参数 annotation 用于按注解筛选，为可选选项，可以是简单名或全限定名，例如 PreAuthorize 或 org.springframework.stereotype.Component 。"+
+				"筛选的层级由其他参数决定：methodName 和 fieldName 都为空时筛选类，methodName 非空时筛选方法，fieldName 非空时筛选字段，"+
+				"methodName 或 fieldName 为 * 表示全部方法或字段，className 为空字符串表示全部类。"+
+				"以 ! 开头表示筛选没有该注解的元素，例如 !PreAuthorize 配合 methodName 为 * 可查找缺少鉴权注解的方法（类上有该注解的方法不会返回）。"),
+		),
+		mcp.WithString("modifiers",
+			mcp.Description("本参数 modifiers 用于按修饰符筛选，为可选选项，多个修饰符用逗号分隔，以 ! 开头表示不含该修饰符。"+
+				"筛选层级与 annotation 参数相同，两者可以同时使用。例如 methodName 为 set* 且 modifiers 为 public,!final 可查找所有公开的非 final setter，"+
+				"methodName 为 * 且 modifiers 为 native 可查找所有 native 方法。"),
+		),
+		mcp.WithString("module",
+			mcp.Description("本参数 module 用于只在指定模块中搜索，为可选选项。模块取文件向上最近的构建清单："+
+				"pom.xml 的 artifactId、Gradle 项目路径（如 :app，可省略开头的冒号）、go.mod 的模块路径或 package.json 的 name，"+
+				"没有构建清单的反编译代码以来源归档名作为模块。同一全限定类名出现在多个模块时，结果中会标注各自所属的模块。"),
+		),
 	)
 
 	s.AddTool(codeSearchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -553,6 +467,8 @@ func main() {
 		var className string
 		var methodName string
 		var fieldName string
+		var module string
+		var filter utils.SearchFilter
 		if request.Params.Arguments != nil {
 			if args, ok := request.Params.Arguments.(map[string]any); ok {
 				// className
@@ -587,6 +503,19 @@ func main() {
 				} else {
 					fieldName = ""
 				}
+
+				// annotation / modifiers
+				if v, exists := args["annotation"]; exists && v != nil {
+					filter.Annotation = fmt.Sprint(v)
+				}
+				if v, exists := args["modifiers"]; exists && v != nil {
+					filter.Modifiers = fmt.Sprint(v)
+				}
+
+				// module
+				if v, exists := args["module"]; exists && v != nil {
+					module = fmt.Sprint(v)
+				}
 			} else {
 				// Arguments 不是 map[string]any 时也赋默认空串，防止后续使用 panic
 				className = ""
@@ -600,8 +529,15 @@ func main() {
 			fieldName = ""
 		}
 
-		// 调用统一搜索函数
-		results, err := utils.UnifiedSearch(serverState.query, className, methodName, fieldName)
+		// 调用统一搜索函数，指定注解或修饰符时按条件筛选，指定模块时只在该模块的节点中搜索
+		query := serverState.query.InModule(module)
+		var results []string
+		var err error
+		if filter.Annotation != "" || filter.Modifiers != "" {
+			results, err = utils.SearchWithFilters(query, className, methodName, fieldName, filter)
+		} else {
+			results, err = utils.UnifiedSearch(query, className, methodName, fieldName)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -621,7 +557,8 @@ func main() {
 
 	// 注册类父类/子类查找工具（只有在AST初始化后才可用）
 	classHierarchyTool := mcp.NewTool("class_hierarchy",
-		mcp.WithDescription("查找指定类的所有父类或所有子类。注意，位于依赖包中的类的子类是无法找到的，但是你可以在项目类中找到依赖包中的父类。"+
+		mcp.WithDescription("查找指定类的所有父类或所有子类。注意，默认只索引项目代码，依赖包中的类的子类是无法找到的，但是你可以在项目类中找到依赖包中的父类；"+
+			"配置中开启 maven 后，pom.xml 中声明的依赖会作为 library 层加入索引，父类与子类的查找可以跨越项目与依赖的边界。"+
 			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
 		mcp.WithString("className",
 			mcp.Required(),
@@ -631,6 +568,10 @@ func main() {
 			mcp.Required(),
 			mcp.Description("本参数 type 指定要查找的是父类还是子类，只有两个可选项：super 表示查找所有父类，sub 表示查找所有子类。"),
 		),
+		mcp.WithString("module",
+			mcp.Description("本参数 module 为可选选项，指定后只查找该模块中的 className，取值与 code_search 的 module 参数相同。"+
+				"没有指定且同名类存在于多个模块时，结果按模块分组列出。"),
+		),
 	)
 
 	s.AddTool(classHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -639,6 +580,7 @@ func main() {
 
 		var className string
 		var typ string
+		var module string
 
 		if request.Params.Arguments != nil {
 			if args, ok := request.Params.Arguments.(map[string]any); ok {
@@ -663,6 +605,11 @@ func main() {
 				} else {
 					typ = ""
 				}
+
+				// module
+				if v, exists := args["module"]; exists && v != nil {
+					module = fmt.Sprint(v)
+				}
 			} else {
 				// Arguments 不是 map[string]any 时也赋默认空串，防止后续使用 panic
 				className = ""
@@ -674,40 +621,537 @@ func main() {
 			typ = ""
 		}
 
-		var resultStr string
+		var groups map[string][]utils.ClassRef
+		var title, empty string
 		if typ == "super" {
-			allSupers := utils.GetAllSuperClasses(serverState.query, className)
-			resultStr += fmt.Sprintf("类 %s 的所有父类：\n", className)
-			found := false
-			for _, superList := range allSupers {
-				for _, sup := range superList {
-					resultStr += fmt.Sprintf("  %s.%s\n", sup.Package, sup.Name)
-					found = true
+			groups = utils.GetAllSuperClasses(serverState.query, className, module)
+			title, empty = "所有父类", "(无父类)"
+		} else if typ == "sub" {
+			groups = utils.GetAllSubClasses(serverState.query, className, module)
+			title, empty = "所有子类", "(无子类)"
+		} else {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Type: "text", Text: "type 参数只能为 super 或 sub"},
+				},
+			}, nil
+		}
+
+		// 同名类存在于多个模块时按模块分组输出
+		modules := make([]string, 0, len(groups))
+		for m := range groups {
+			modules = append(modules, m)
+		}
+		sort.Strings(modules)
+		resultStr := fmt.Sprintf("类 %s 的%s：\n", className, title)
+		if len(modules) == 0 {
+			resultStr += "  " + empty + "\n"
+		}
+		for _, m := range modules {
+			indent := "  "
+			if len(modules) > 1 {
+				name := m
+				if name == "" {
+					name = "(未知模块)"
 				}
+				resultStr += fmt.Sprintf("  [模块 %s]\n", name)
+				indent = "    "
 			}
-			if !found {
-				resultStr += "  (无父类)\n"
+			if len(groups[m]) == 0 {
+				resultStr += indent + empty + "\n"
 			}
-		} else if typ == "sub" {
-			allSubs := utils.GetAllSubClasses(serverState.query, className)
-			resultStr += fmt.Sprintf("类 %s 的所有子类：\n", className)
-			found := false
-			for _, subList := range allSubs {
-				for _, sub := range subList {
-					resultStr += fmt.Sprintf("  %s.%s\n", sub.Package, sub.Name)
-					found = true
+			for _, ref := range groups[m] {
+				resultStr += fmt.Sprintf("%s%s.%s\n", indent, ref.Package, ref.Name)
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: resultStr},
+			},
+		}, nil
+	})
+
+	// 注册调用方查找工具（只有在AST初始化后才可用）
+	findCallersTool := mcp.NewTool("find_callers",
+		mcp.WithDescription("查找调用了指定类中指定方法的所有方法，返回调用方方法、调用所在的文件与行号以及调用方方法的代码片段。"+
+			"可用于从危险方法出发反向追踪哪些代码能够到达它。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("className",
+			mcp.Required(),
+			mcp.Description("本参数 className 指定被调用方法所在的类名，可以是全类名（如 com.example.Foo）或简单类名（如 Foo）。"),
+		),
+		mcp.WithString("methodName",
+			mcp.Required(),
+			mcp.Description("本参数 methodName 指定被调用的方法，写法与 code_search 工具的 methodName 参数相同："+
+				"myMethod 表示匹配所有同名重载方法；myMethod(ArgTpye1 arg0, String arg1) 表示按参数类型精确匹配。"),
+		),
+	)
+
+	s.AddTool(findCallersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		var className string
+		var methodName string
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["className"]; exists && v != nil {
+					className = fmt.Sprint(v)
+				}
+				if v, exists := args["methodName"]; exists && v != nil {
+					methodName = fmt.Sprint(v)
 				}
 			}
-			if !found {
-				resultStr += "  (无子类)\n"
+		}
+
+		results, err := utils.FindCallers(serverState.query, className, methodName)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: utils.FormatSearchResults(results)},
+			},
+		}, nil
+	})
+
+	// 注册调用图生成工具（只有在AST初始化后才可用）
+	callGraphTool := mcp.NewTool("call_graph",
+		mcp.WithDescription("从指定方法出发，沿索引中真实的调用关系展开指定层数，生成调用关系图。"+
+			"审计总结中需要画流程示意图时，应当使用本工具的输出，而不是自行编写。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("className",
+			mcp.Required(),
+			mcp.Description("本参数 className 指定起始方法所在的类名，可以是全类名（如 com.example.Foo）或简单类名（如 Foo）。"),
+		),
+		mcp.WithString("methodName",
+			mcp.Required(),
+			mcp.Description("本参数 methodName 指定起始方法，写法与 code_search 工具的 methodName 参数相同。"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("本参数 depth 指定调用关系展开的层数，默认为 3，最大为 10。"),
+		),
+		mcp.WithString("format",
+			mcp.Description("本参数 format 指定输出格式，可选 mermaid（默认）、dot（Graphviz）或 text（缩进文本）。"),
+		),
+	)
+
+	s.AddTool(callGraphTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		var className string
+		var methodName string
+		depth := 3
+		format := "mermaid"
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["className"]; exists && v != nil {
+					className = fmt.Sprint(v)
+				}
+				if v, exists := args["methodName"]; exists && v != nil {
+					methodName = fmt.Sprint(v)
+				}
+				if v, exists := args["depth"]; exists && v != nil {
+					if d, err := strconv.Atoi(fmt.Sprint(v)); err == nil && d > 0 {
+						depth = d
+					}
+				}
+				if v, exists := args["format"]; exists && v != nil && fmt.Sprint(v) != "" {
+					format = fmt.Sprint(v)
+				}
 			}
-		} else {
-			resultStr = "type 参数只能为 super 或 sub"
+		}
+		if depth > 10 {
+			depth = 10
+		}
+
+		results, err := utils.VisualizeMethodCallGraph(serverState.query, className, methodName, depth, format)
+		if err != nil {
+			return nil, err
 		}
 
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Type: "text", Text: resultStr},
+				&mcp.TextContent{Type: "text", Text: utils.FormatSearchResults(results)},
+			},
+		}, nil
+	})
+
+	// 注册污点路径分析工具（只有在AST初始化后才可用）
+	taintPathsTool := mcp.NewTool("taint_paths",
+		mcp.WithDescription("基于调用图和方法内的数据流查找从外部输入（污点源）到危险方法（汇聚点）的跨方法路径，并列出每一跳所在的类、方法、行号和被污染的实参。"+
+			"污点源包括 HttpServletRequest.getParameter 等请求读取方法以及带有 @RequestParam、@PathVariable 等注解的参数；"+
+			"汇聚点与 find_sinks 工具使用相同的高危 API 目录，配置文件 rules 段指定的自定义规则（含净化方法）也会生效。"+
+			"污点只经由实参传入被调用方法，汇聚点调用的实参或接收者被污染时才报告；字段和返回值不参与跨方法传递，仍需要结合代码确认。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("category",
+			mcp.Description("本参数 category 用于只查看某一类汇聚点，可选 command-injection、sql-injection、deserialization、jndi-injection、"+
+				"xxe、expression-injection、reflection、path-traversal、buffer-overflow 或自定义规则中的分类，为空表示全部。"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("本参数 maxDepth 指定源方法与汇聚点方法之间最多经过的调用层数，默认为 8。"),
+		),
+	)
+
+	s.AddTool(taintPathsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		// 每次调用重新加载规则文件，修改规则后无需重启
+		taintConfig, err := utils.LoadTaintConfig(serverState.config)
+		if err != nil {
+			return nil, err
+		}
+
+		category := ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["category"]; exists && v != nil {
+					category = fmt.Sprint(v)
+				}
+				if v, exists := args["maxDepth"]; exists && v != nil {
+					if d, err := strconv.Atoi(fmt.Sprint(v)); err == nil && d > 0 {
+						taintConfig.MaxDepth = d
+					}
+				}
+			}
+		}
+
+		if category != "" {
+			var sinks []utils.TaintRule
+			for _, rule := range taintConfig.Sinks {
+				if rule.Category == category {
+					sinks = append(sinks, rule)
+				}
+			}
+			taintConfig.Sinks = sinks
+		}
+
+		paths := utils.FindTaintPaths(serverState.query, taintConfig)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: utils.FormatTaintPaths(paths, 100)},
+			},
+		}, nil
+	})
+
+	// 注册高危 API 清点工具（只有在AST初始化后才可用）
+	findSinksTool := mcp.NewTool("find_sinks",
+		mcp.WithDescription("扫描索引中的所有方法调用，列出命中内置高危 Java API 目录的调用位置，按漏洞类型分组，"+
+			"包括反射、反序列化、JNDI 查找、XML 解析、SpEL/OGNL 等表达式执行、命令执行、SQL 执行和文件读写，"+
+			"以及 C/C++ 代码中 system、popen、exec 系列等命令执行函数和 strcpy、sprintf、memcpy 等缓冲区溢出高危函数。"+
+			"配置文件 rules 段指定的自定义汇聚点规则也会一并扫描。每条结果包含调用所在的方法、文件、行号和调用语句，可作为逐个深入审计的起点。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("category",
+			mcp.Description("本参数 category 用于只查看某一类高危 API，可选 command-injection、sql-injection、deserialization、jndi-injection、"+
+				"xxe、expression-injection、reflection、path-traversal、buffer-overflow 或自定义规则中的分类，为空表示全部。"),
+		),
+	)
+
+	s.AddTool(findSinksTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		category := ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["category"]; exists && v != nil {
+					category = fmt.Sprint(v)
+				}
+			}
+		}
+
+		// 每次调用重新加载规则文件，修改规则后无需重启
+		taintConfig, err := utils.LoadTaintConfig(serverState.config)
+		if err != nil {
+			return nil, err
+		}
+
+		var rules []utils.TaintRule
+		for _, rule := range taintConfig.Sinks {
+			if category == "" || rule.Category == category {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == 0 {
+			return nil, fmt.Errorf("未知的 category: %s", category)
+		}
+
+		findings := utils.FindSinks(serverState.query, rules)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: utils.FormatSinkFindings(findings)},
+			},
+		}, nil
+	})
+
+	listEndpointsTool := mcp.NewTool("list_endpoints",
+		mcp.WithDescription("根据 Spring MVC（@RestController、@RequestMapping、@GetMapping 等）和 JAX-RS（@Path、@GET、@POST 等）注解，"+
+			"组合类级别与方法级别的路径，列出代码仓库对外暴露的全部 HTTP 路由。每条结果包含 HTTP 方法、完整路径、处理类和处理方法，"+
+			"以及参数绑定方式（如 @RequestParam、@PathVariable、@RequestBody），可用于梳理攻击面。"+
+			"JAX-RS 中只有 @Path 没有 HTTP 方法注解的方法为子资源定位器，HTTP 方法显示为 LOCATOR。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("keyword",
+			mcp.Description("本参数 keyword 用于过滤路由，只返回路径或处理类名中包含该关键字的路由，为空表示全部。"),
+		),
+	)
+
+	s.AddTool(listEndpointsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		keyword := ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["keyword"]; exists && v != nil {
+					keyword = fmt.Sprint(v)
+				}
+			}
+		}
+
+		endpoints := utils.ListEndpoints(serverState.query)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: utils.FormatEndpoints(endpoints, keyword)},
+			},
+		}, nil
+	})
+
+	listEntryPointsTool := mcp.NewTool("list_entry_points",
+		mcp.WithDescription("列出代码仓库中全部 Servlet 入口，包括 WEB-INF/web.xml（及 web-fragment.xml）中声明的 servlet、filter、listener，"+
+			"@WebServlet、@WebFilter、@WebListener 注解注册的类，以及 OSGi/Sling 方式注册的 Servlet"+
+			"（@Component 的 sling.servlet.paths、sling.servlet.resourceTypes 等属性，@SlingServlet、@SlingServletPaths、@SlingServletResourceTypes 注解）。"+
+			"每条结果包含入口类型、实现类、映射路径或资源类型、限定的 HTTP 方法以及实现类中的 doGet/doPost/doFilter 等处理方法，"+
+			"可在不知道具体类名的情况下找到审计起点。Spring MVC / JAX-RS 路由请使用 list_endpoints 工具。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("keyword",
+			mcp.Description("本参数 keyword 用于过滤入口，只返回类名、名称、路径或资源类型中包含该关键字的入口，为空表示全部。"),
+		),
+	)
+
+	s.AddTool(listEntryPointsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		keyword := ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["keyword"]; exists && v != nil {
+					keyword = fmt.Sprint(v)
+				}
+			}
+		}
+
+		entries := utils.ListEntryPoints(serverState.query)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: utils.FormatEntryPoints(entries, keyword)},
+			},
+		}, nil
+	})
+
+	gradleProjectsTool := mcp.NewTool("gradle_projects",
+		mcp.WithDescription("列出代码仓库中 Gradle 构建的项目结构：settings.gradle(.kts) 中 include 的子项目及其目录，"+
+			"以及每个项目 build.gradle(.kts) 中的插件、源码集（sourceSets 配置的目录和 src/main/java 等约定目录）和 dependencies 块声明的依赖，"+
+			"依赖版本会按 ext、gradle.properties 中的属性和 gradle/libs.versions.toml 版本目录补全，project(':core') 表示项目之间的依赖。"+
+			"可用于判断某个目录下的类属于哪个子项目、子项目之间的依赖关系以及引入了哪些第三方组件。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("project",
+			mcp.Description("本参数 project 用于只查看某个项目，可以是项目路径（如 :app、:libs:core）或项目名（如 core），为空表示全部。"),
+		),
+	)
+
+	s.AddTool(gradleProjectsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		project := ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["project"]; exists && v != nil {
+					project = fmt.Sprint(v)
+				}
+			}
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: utils.FormatGradleProjects(serverState.query.GradleProjects(), project)},
+			},
+		}, nil
+	})
+
+	vulnerableDependenciesTool := mcp.NewTool("vulnerable_dependencies",
+		mcp.WithDescription("收集 pom.xml（包括父 POM）、build.gradle(.kts)、package.json（优先使用 package-lock.json 中的锁定版本）和 go.mod 中声明的依赖，"+
+			"与缓存目录下 osv 目录中的离线 OSV 漏洞库（.json 文件或 OSV 按生态导出的 all.zip）比对，列出存在已知漏洞的组件、漏洞编号、严重程度和修复版本，"+
+			"并给出项目代码中实际导入了该组件的类，优先审计被导入的组件，从这些类出发确认漏洞是否可达。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("imported_only",
+			mcp.Description("本参数 imported_only 为 true 时只列出被项目代码导入的组件，默认列出全部存在漏洞的组件。"),
+		),
+	)
+
+	s.AddTool(vulnerableDependenciesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		importedOnly := false
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["imported_only"]; exists && v != nil {
+					importedOnly, _ = strconv.ParseBool(fmt.Sprint(v))
+				}
+			}
+		}
+
+		// 每次调用重新加载漏洞库，更新漏洞库后无需重启
+		db, err := utils.LoadOSVDatabase(serverState.config.CodeAudit.ASTCache.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+
+		dependencies := utils.CollectDependencies(serverState.config.CodeAudit.RepositoryPath,
+			serverState.query.GradleProjects(), serverState.config.Maven.LocalRepository)
+		results := utils.FindVulnerableDependencies(serverState.query, db, dependencies, serverState.config.Maven.LocalRepository)
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: fmt.Sprintf("共检查 %d 个依赖\n\n%s",
+					len(dependencies), utils.FormatVulnerableDependencies(results, importedOnly))},
+			},
+		}, nil
+	})
+
+	fieldReferencesTool := mcp.NewTool("field_references",
+		mcp.WithDescription("列出字段的声明以及所有读取和写入位置（this.field、obj.field、直接使用字段名、赋值和自增自减），"+
+			"每处访问包含所在方法、文件、行号和代码行，可用于追踪 password、secretKey 等敏感字段在哪里被赋值、在哪里被使用。"+
+			"接收者类型无法推断的同名字段访问会单独列出。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("className",
+			mcp.Description("本参数 className 指定字段所在的类，可以是全类名（如 com.example.Foo）或简单类名（如 Foo），为空表示在所有类中查找。"),
+		),
+		mcp.WithString("fieldName",
+			mcp.Required(),
+			mcp.Description("本参数 fieldName 指定字段名，例如 password ；以 * 结尾表示按前缀匹配，例如 secret* 。"),
+		),
+	)
+
+	s.AddTool(fieldReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		className, fieldName := "", ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["className"]; exists && v != nil {
+					className = fmt.Sprint(v)
+				}
+				if v, exists := args["fieldName"]; exists && v != nil {
+					fieldName = fmt.Sprint(v)
+				}
+			}
+		}
+
+		result, err := utils.FindFieldReferences(serverState.query, className, fieldName)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: result},
+			},
+		}, nil
+	})
+
+	findCallsTool := mcp.NewTool("find_calls",
+		mcp.WithDescription("按接收者的静态类型精确查找方法调用，例如查询 javax.servlet.ServletRequest#getParameter 只会列出接收者为 ServletRequest "+
+			"及其子类型（HttpServletRequest、SlingHttpServletRequest、项目中的包装类）的调用，不会包含 map.getParameter 这类同名调用。"+
+			"接收者类型从局部变量、方法参数、字段（含父类字段）、import 和链式调用的返回类型推断，无法推断类型的同名调用会单独列出。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("本参数 target 指定被调用的方法，格式为 类名#方法名，类名可以是全类名（如 java.sql.Statement#executeQuery）或简单类名（如 Statement#executeQuery），方法名以 * 结尾表示按前缀匹配。"),
+		),
+	)
+
+	s.AddTool(findCallsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		target := ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["target"]; exists && v != nil {
+					target = fmt.Sprint(v)
+				}
+			}
+		}
+
+		result, err := utils.FindCalls(serverState.query, target)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: result},
+			},
+		}, nil
+	})
+
+	dataFlowTool := mcp.NewTool("data_flow",
+		mcp.WithDescription("分析单个方法内部的数据流：列出方法中的方法调用及每个实参表达式，"+
+			"并沿局部变量的声明和赋值向上追溯实参的来源，最终说明实参是否来自方法参数或字段。"+
+			"例如可以回答“这里传给 executeQuery 的 SQL 是否拼接了方法参数”。结果来自方法参数时，可继续用 find_callers 工具查看调用方传入的值。"+
+			"你需要先使用 remote_code_audit 工具设置代码仓库。"),
+		mcp.WithString("className",
+			mcp.Required(),
+			mcp.Description("本参数 className 指定方法所在的类，可以是全类名（如 com.example.Foo）或简单类名（如 Foo）。"),
+		),
+		mcp.WithString("methodName",
+			mcp.Required(),
+			mcp.Description("本参数 methodName 指定要分析的方法，写法与 code_search 工具的 methodName 参数相同。"),
+		),
+		mcp.WithString("callName",
+			mcp.Description("本参数 callName 指定只分析对某个方法的调用，例如 executeQuery ，构造器调用写作 <init> ，为空表示方法中的全部调用。"),
+		),
+	)
+
+	s.AddTool(dataFlowTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// 等待直到AST就绪
+		serverState.WaitUntilReady()
+
+		className, methodName, callName := "", "", ""
+		if request.Params.Arguments != nil {
+			if args, ok := request.Params.Arguments.(map[string]any); ok {
+				if v, exists := args["className"]; exists && v != nil {
+					className = fmt.Sprint(v)
+				}
+				if v, exists := args["methodName"]; exists && v != nil {
+					methodName = fmt.Sprint(v)
+				}
+				if v, exists := args["callName"]; exists && v != nil {
+					callName = fmt.Sprint(v)
+				}
+			}
+		}
+
+		result, err := utils.TraceCallArguments(serverState.query, className, methodName, callName)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Type: "text", Text: result},
 			},
 		}, nil
 	})
@@ -24,6 +24,31 @@ type Config struct {
 		} `yaml:"ast_cache"`
 	} `yaml:"code_audit"`
 
+	// 自定义污点源、危险汇聚点与净化方法规则
+	Rules struct {
+		Files          []string `yaml:"files"`           // 规则文件路径，支持通配符
+		DisableBuiltin bool     `yaml:"disable_builtin"` // 是否禁用内置规则，只使用规则文件
+	} `yaml:"rules"`
+
+	// Java 归档（JAR/WAR/EAR）的解包与反编译配置
+	Decompiler struct {
+		Type        string   `yaml:"type"`         // 内置预设：cfr、procyon、vineflower
+		JarPath     string   `yaml:"jar_path"`     // 反编译器 jar 包路径
+		Java        string   `yaml:"java"`         // java 可执行文件，默认为 java
+		Command     []string `yaml:"command"`      // 自定义命令参数，{input} 为 jar 路径，{output} 为源码输出目录，设置后优先于 type
+		WorkDir     string   `yaml:"work_dir"`     // 解包与反编译输出目录
+		ExcludeLibs []string `yaml:"exclude_libs"` // 不反编译的嵌套依赖包，按文件名通配符匹配，如 spring-*.jar
+	} `yaml:"decompiler"`
+
+	// Maven 依赖索引配置，将 pom.xml 中声明的依赖作为 library 层加入索引
+	Maven struct {
+		Enabled         bool     `yaml:"enabled"`          // 是否索引依赖
+		LocalRepository string   `yaml:"local_repository"` // 本地仓库路径，默认为 ~/.m2/repository
+		Scopes          []string `yaml:"scopes"`           // 参与索引的依赖 scope，默认为 compile、provided、runtime
+		Include         []string `yaml:"include"`          // 只索引匹配的依赖，按 groupId:artifactId 通配符匹配，如 org.apache.sling:*
+		Exclude         []string `yaml:"exclude"`          // 不索引的依赖，匹配方式同 include
+	} `yaml:"maven"`
+
 	// 新增远程仓库配置
 	RemoteRepository struct {
 		Enabled    bool   `yaml:"enabled"`
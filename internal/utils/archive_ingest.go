@@ -0,0 +1,394 @@
+package utils
+
+import (
+	"Fenrir-CodeAuditTool/configs"
+	"archive/zip"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArtifactMarkerFile 归档解包目录中记录归档名的标记文件，建立索引时据此为节点标注来源归档
+const ArtifactMarkerFile = ".fenrir-artifact"
+
+// maxArchiveDepth 嵌套归档的最大解包层数（EAR 中的 WAR 中的 JAR 为三层）
+const maxArchiveDepth = 3
+
+// decompilerPresets 内置反编译器的命令参数，{java}、{jar}、{input}、{output} 在运行时替换
+var decompilerPresets = map[string][]string{
+	"cfr":        {"{java}", "-jar", "{jar}", "{input}", "--outputdir", "{output}", "--silent", "true"},
+	"procyon":    {"{java}", "-jar", "{jar}", "-jar", "{input}", "-o", "{output}"},
+	"vineflower": {"{java}", "-jar", "{jar}", "{input}", "{output}"},
+}
+
+// classRoots 归档中 class 文件所在的目录：WAR 的 WEB-INF/classes 与 Spring Boot 的 BOOT-INF/classes，普通 JAR 为根目录
+var classRoots = []string{"WEB-INF/classes", "BOOT-INF/classes"}
+
+// IsJavaArchive 判断文件（或下载地址）是否为 Java 归档（.jar、.war、.ear）
+func IsJavaArchive(path string) bool {
+	path, _, _ = strings.Cut(path, "?")
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jar", ".war", ".ear":
+		return true
+	}
+	return false
+}
+
+// IsArchiveInput 判断代码仓库路径是否需要先解包反编译：Java 归档文件，或直接包含归档文件且本身不是源码项目的目录
+func IsArchiveInput(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return IsJavaArchive(path)
+	}
+	return len(listArchives(path)) > 0 && !NewProjectDetector(path).isValidProject(path)
+}
+
+// listArchives 列出目录下（不含子目录）的 Java 归档
+func listArchives(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var archives []string
+	for _, entry := range entries {
+		if !entry.IsDir() && IsJavaArchive(entry.Name()) {
+			archives = append(archives, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(archives)
+	return archives
+}
+
+// ArchiveIngester 将 Java 归档解包并反编译为源码目录，供建立 AST 索引
+type ArchiveIngester struct {
+	config *configs.Config
+}
+
+// NewArchiveIngester 创建归档处理器
+func NewArchiveIngester(config *configs.Config) *ArchiveIngester {
+	return &ArchiveIngester{
+		config: config,
+	}
+}
+
+// Prepare 解包并反编译归档文件或归档目录，返回反编译后的源码根目录。
+// 每个归档（包括 WEB-INF/lib、BOOT-INF/lib 和 EAR 中嵌套的归档）对应一个子目录，
+// 其中 sources 为反编译的源码，resources 为 web.xml 等非 class 文件，nested 为嵌套的归档；
+// 归档没有变化时沿用上次的反编译结果
+func (a *ArchiveIngester) Prepare(input string) (string, error) {
+	command, err := a.decompilerCommand()
+	if err != nil {
+		return "", err
+	}
+
+	absInput, err := filepath.Abs(input)
+	if err != nil {
+		return "", fmt.Errorf("无法获取绝对路径: %v", err)
+	}
+	info, err := os.Stat(absInput)
+	if err != nil {
+		return "", fmt.Errorf("归档路径不存在: %s", absInput)
+	}
+	archives := []string{absInput}
+	if info.IsDir() {
+		archives = listArchives(absInput)
+	}
+
+	workDir := a.config.Decompiler.WorkDir
+	if workDir == "" {
+		workDir = "./work"
+	}
+	// 不同位置的同名归档使用不同的目录
+	hash := fmt.Sprintf("%x", md5.Sum([]byte(absInput)))[:8]
+	root, err := filepath.Abs(filepath.Join(workDir, filepath.Base(absInput)+"_"+hash))
+	if err != nil {
+		return "", fmt.Errorf("无法获取绝对路径: %v", err)
+	}
+
+	for _, archive := range archives {
+		name := filepath.Base(archive)
+		if err := a.ingest(archive, name, filepath.Join(root, name), command, 1); err != nil {
+			return "", err
+		}
+	}
+	log.Printf("Java 归档已反编译到: %s", root)
+	return root, nil
+}
+
+// decompilerCommand 返回反编译命令参数，自定义命令优先于内置预设
+func (a *ArchiveIngester) decompilerCommand() ([]string, error) {
+	decompiler := a.config.Decompiler
+	if len(decompiler.Command) > 0 {
+		return decompiler.Command, nil
+	}
+
+	decompilerType := strings.ToLower(decompiler.Type)
+	if decompilerType == "" {
+		decompilerType = "cfr"
+	}
+	preset, ok := decompilerPresets[decompilerType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的反编译器类型: %s，可选 cfr、procyon、vineflower，或通过 decompiler.command 自定义命令", decompiler.Type)
+	}
+	if decompiler.JarPath == "" {
+		return nil, fmt.Errorf("未配置反编译器 jar 包路径 decompiler.jar_path")
+	}
+	jarPath, err := filepath.Abs(decompiler.JarPath)
+	if err != nil {
+		return nil, fmt.Errorf("无法获取绝对路径: %v", err)
+	}
+	if _, err := os.Stat(jarPath); err != nil {
+		return nil, fmt.Errorf("反编译器 jar 包不存在: %s", jarPath)
+	}
+	java := decompiler.Java
+	if java == "" {
+		java = "java"
+	}
+
+	command := make([]string, len(preset))
+	for i, arg := range preset {
+		command[i] = strings.NewReplacer("{java}", java, "{jar}", jarPath).Replace(arg)
+	}
+	return command, nil
+}
+
+// ingest 解包并反编译单个归档到 outDir，再递归处理其中嵌套的归档。
+// 顶层归档失败时返回错误，嵌套的依赖包失败时只记录日志
+func (a *ArchiveIngester) ingest(archive, artifact, outDir string, command []string, depth int) error {
+	marker := filepath.Join(outDir, ArtifactMarkerFile)
+	if markerInfo, err := os.Stat(marker); err == nil {
+		if archiveInfo, err := os.Stat(archive); err == nil && markerInfo.ModTime().After(archiveInfo.ModTime()) {
+			log.Printf("归档未变化，沿用已有的反编译结果: %s", artifact)
+			return nil
+		}
+	}
+
+	log.Printf("正在解包并反编译: %s", artifact)
+	if err := os.RemoveAll(outDir); err != nil {
+		return fmt.Errorf("清理目录失败: %v", err)
+	}
+	unpacked := filepath.Join(outDir, ".unpacked")
+	if err := unzipArchive(archive, unpacked); err != nil {
+		return fmt.Errorf("解包 %s 失败: %v", artifact, err)
+	}
+	defer os.RemoveAll(unpacked)
+
+	// WAR 和 Spring Boot 包中的 class 文件重新打包后再交给反编译器
+	classJar := ""
+	for _, classRoot := range classRoots {
+		if dir := filepath.Join(unpacked, filepath.FromSlash(classRoot)); isDirectory(dir) {
+			classJar = filepath.Join(outDir, ".classes.jar")
+			if err := zipDirectory(dir, classJar); err != nil {
+				return fmt.Errorf("打包 %s 中的 class 文件失败: %v", artifact, err)
+			}
+			defer os.Remove(classJar)
+			break
+		}
+	}
+
+	// 其余文件：嵌套归档递归处理，class 文件由反编译器处理，其他文件作为资源保留
+	hasClasses := false
+	var nested []string
+	err := filepath.Walk(unpacked, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(unpacked, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case IsJavaArchive(path):
+			nested = append(nested, rel)
+		case strings.HasSuffix(path, ".class"):
+			hasClasses = true
+		default:
+			return copyFile(path, filepath.Join(outDir, "resources", rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("处理 %s 中的文件失败: %v", artifact, err)
+	}
+
+	if classJar == "" && hasClasses {
+		classJar = archive
+	}
+	if classJar != "" {
+		if err := runDecompiler(command, classJar, filepath.Join(outDir, "sources")); err != nil {
+			return fmt.Errorf("反编译 %s 失败: %v", artifact, err)
+		}
+	}
+
+	for _, rel := range nested {
+		name := filepath.Base(rel)
+		if depth >= maxArchiveDepth || a.excludedLib(name) {
+			continue
+		}
+		nestedArtifact := artifact + "!/" + filepath.ToSlash(rel)
+		if err := a.ingest(filepath.Join(unpacked, rel), nestedArtifact, filepath.Join(outDir, "nested", rel), command, depth+1); err != nil {
+			log.Printf("跳过嵌套归档 %s: %v", nestedArtifact, err)
+		}
+	}
+
+	// 标记文件最后写入，中途失败时下次会重新处理
+	return os.WriteFile(marker, []byte(artifact+"\n"), 0644)
+}
+
+// excludedLib 判断嵌套的依赖包是否匹配 exclude_libs 中的通配符
+func (a *ArchiveIngester) excludedLib(name string) bool {
+	for _, pattern := range a.config.Decompiler.ExcludeLibs {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// runDecompiler 运行反编译命令，Vineflower 等反编译器对 jar 输入会输出源码 jar，这里解开后删除
+func runDecompiler(command []string, input, output string) error {
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = strings.NewReplacer("{input}", input, "{output}", output).Replace(arg)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		// 只保留输出的最后一部分，反编译器的进度输出可能很长
+		if len(out) > 1000 {
+			out = out[len(out)-1000:]
+		}
+		return fmt.Errorf("%v, 输出: %s", err, string(out))
+	}
+
+	for _, archive := range listArchives(output) {
+		if err := unzipArchive(archive, output); err != nil {
+			return err
+		}
+		os.Remove(archive)
+	}
+	if zips, _ := filepath.Glob(filepath.Join(output, "*.zip")); len(zips) > 0 {
+		for _, archive := range zips {
+			if err := unzipArchive(archive, output); err != nil {
+				return err
+			}
+			os.Remove(archive)
+		}
+	}
+	return nil
+}
+
+// unzipArchive 解压归档到目标目录，拒绝指向目标目录之外的条目
+func unzipArchive(archive, targetDir string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("打开归档失败: %v", err)
+	}
+	defer r.Close()
+
+	base := filepath.Clean(targetDir) + string(os.PathSeparator)
+	for _, f := range r.File {
+		path := filepath.Join(targetDir, f.Name)
+		if !strings.HasPrefix(path, base) {
+			return fmt.Errorf("非法文件路径: %s", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return fmt.Errorf("创建目录失败: %v", err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("创建父目录失败: %v", err)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("打开归档内文件失败: %v", err)
+		}
+		err = writeFile(path, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("解压文件失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// zipDirectory 将目录中的文件按相对路径打包为 jar
+func zipDirectory(dir, target string) error {
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entry, err := w.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(entry, in)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// copyFile 复制文件，自动创建目标目录
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFile(dst, in)
+}
+
+// writeFile 将读取到的内容写入文件
+func writeFile(path string, r io.Reader) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// isDirectory 判断路径是否为已存在的目录
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"Fenrir-CodeAuditTool/configs"
+	"archive/zip"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeZip 按 条目名 -> 内容 生成 zip 格式的归档，返回归档内容
+func writeZip(t *testing.T, entries map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range entries {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestIsJavaArchive(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"app.jar", true},
+		{"/opt/app.WAR", true},
+		{"https://example.com/dist/app.ear?token=1", true},
+		{"https://example.com/repo.zip", false},
+		{"src/App.java", false},
+	}
+	for _, tt := range tests {
+		if got := IsJavaArchive(tt.path); got != tt.want {
+			t.Errorf("IsJavaArchive(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestArchiveIngesterPrepare(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("需要 sh 模拟反编译器")
+	}
+	dir := t.TempDir()
+	war := writeZip(t, map[string][]byte{
+		"WEB-INF/web.xml":               []byte("<web-app/>"),
+		"WEB-INF/classes/app/Ctl.class": {0xca, 0xfe},
+		"WEB-INF/lib/dep.jar":           writeZip(t, map[string][]byte{"lib/Dep.class": {0xca, 0xfe}}),
+		"WEB-INF/lib/spring-core.jar":   writeZip(t, map[string][]byte{"org/Spring.class": {0xca, 0xfe}}),
+	})
+	warPath := filepath.Join(dir, "app.war")
+	if err := os.WriteFile(warPath, war, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !IsArchiveInput(warPath) || !IsArchiveInput(dir) {
+		t.Fatalf("归档文件及只包含归档的目录应当需要反编译")
+	}
+
+	config := &configs.Config{}
+	config.Decompiler.WorkDir = filepath.Join(dir, "work")
+	config.Decompiler.ExcludeLibs = []string{"spring-*.jar"}
+	// 模拟反编译器：在输出目录中生成一个源文件
+	config.Decompiler.Command = []string{"sh", "-c", "mkdir -p {output}/gen && echo 'package gen; public class Gen {}' > {output}/gen/Gen.java"}
+
+	root, err := NewArchiveIngester(config).Prepare(warPath)
+	if err != nil {
+		t.Fatalf("Prepare 失败: %v", err)
+	}
+	for _, path := range []string{
+		"app.war/sources/gen/Gen.java",
+		"app.war/resources/WEB-INF/web.xml",
+		"app.war/nested/WEB-INF/lib/dep.jar/sources/gen/Gen.java",
+	} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Errorf("缺少 %s", path)
+		}
+	}
+	if isDirectory(filepath.Join(root, "app.war", "nested", "WEB-INF", "lib", "spring-core.jar")) {
+		t.Errorf("exclude_libs 中的依赖包不应反编译")
+	}
+
+	m := NewParserManager()
+	m.RegisterParser(&JavaParser{})
+	if err := m.BuildIndexFromDir(root); err != nil {
+		t.Fatal(err)
+	}
+	var artifacts []string
+	for _, node := range m.GetIndex().index {
+		if node.Type == "Class" {
+			artifacts = append(artifacts, node.Metadata["artifact"])
+		}
+	}
+	sort.Strings(artifacts)
+	if want := []string{"app.war", "app.war!/WEB-INF/lib/dep.jar"}; !reflect.DeepEqual(artifacts, want) {
+		t.Errorf("类节点的来源归档 = %v, want %v", artifacts, want)
+	}
+}
+
+func TestDecompilerCommandErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		decompilerType string
+		jarPath        string
+	}{
+		{"不支持的反编译器", "jad", "cfr.jar"},
+		{"未配置 jar 包", "cfr", ""},
+		{"jar 包不存在", "cfr", filepath.Join(t.TempDir(), "cfr.jar")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configs.Config{}
+			config.Decompiler.Type = tt.decompilerType
+			config.Decompiler.JarPath = tt.jarPath
+			if _, err := NewArchiveIngester(config).decompilerCommand(); err == nil {
+				t.Errorf("应当返回错误")
+			}
+		})
+	}
+}
+
+func TestUnzipArchiveRejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.jar")
+	if err := os.WriteFile(archive, writeZip(t, map[string][]byte{"../evil.txt": []byte("x")}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := unzipArchive(archive, filepath.Join(dir, "out")); err == nil {
+		t.Errorf("指向目标目录之外的条目应当返回错误")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.txt")); err == nil {
+		t.Errorf("不应写出目标目录之外的文件")
+	}
+}
@@ -23,6 +23,15 @@ func NewASTBuilderService(config *configs.Config) *ASTBuilderService {
 	// 注册解析器
 	manager.RegisterParser(&GoParser{})
 	manager.RegisterParser(&JavaParser{})
+	manager.RegisterParser(&WebXMLParser{})
+	manager.RegisterParser(&PythonParser{})
+	manager.RegisterParser(&JavaScriptParser{})
+	manager.RegisterParser(&TypeScriptParser{})
+	manager.RegisterParser(&PHPParser{})
+	manager.RegisterParser(&KotlinParser{})
+	manager.RegisterParser(&CSharpParser{})
+	manager.RegisterParser(&CParser{})
+	manager.RegisterParser(&CppParser{})
 	// 可以添加更多语言的解析器
 
 	// 创建持久化管理器
@@ -86,8 +95,14 @@ func (s *ASTBuilderService) buildAST() (*ASTIndex, error) {
 
 	log.Printf("正在分析代码仓库: %s", absPath)
 
+	// pom.xml 中声明的依赖作为 library 层一并索引
+	var libraryDirs []string
+	if s.config.Maven.Enabled {
+		libraryDirs = PrepareMavenLibraries(s.config, absPath)
+	}
+
 	// 构建索引
-	err = s.manager.BuildIndexFromDir(absPath)
+	err = s.manager.BuildIndexFromDir(absPath, libraryDirs...)
 	if err != nil {
 		return nil, fmt.Errorf("构建代码索引失败: %v", err)
 	}
@@ -138,6 +153,20 @@ func (s *ASTBuilderService) PrintStatistics(index *ASTIndex) error {
 		fmt.Printf("- %s: %d\n", nodeType, count)
 	}
 
+	// 按所属模块统计
+	moduleCount := make(map[string]int)
+	for _, node := range nodes {
+		if node.Module != "" {
+			moduleCount[node.Module]++
+		}
+	}
+	if len(moduleCount) > 0 {
+		fmt.Println("\n模块节点统计：")
+		for module, count := range moduleCount {
+			fmt.Printf("- %s: %d\n", module, count)
+		}
+	}
+
 	return nil
 }
 
@@ -194,7 +223,7 @@ func (s *ASTBuilderService) BuildClassHierarchy(index *ASTIndex) {
 			fmt.Printf("处理类: %s, SuperClasses 数量: %d\n", node.FullClassName, len(node.SuperClasses))
 			// 使用 SuperClasses 字段而不是 Metadata
 			for _, superClass := range node.SuperClasses {
-				superClassName := superClass.Package + "." + superClass.Name
+				superClassName := superClass.FullName()
 				if superClassName != "" {
 					parentToChildren[superClassName] = append(parentToChildren[superClassName], id)
 					// 调试输出
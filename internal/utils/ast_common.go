@@ -1,6 +1,13 @@
 package utils
 
-import "strings"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// globalNamespace 全局命名空间中函数（PHP、C/C++）的 className 和调用的接收者类型
+const globalNamespace = "global"
 
 // ClassRef 表示类的引用（包名+类名）
 type ClassRef struct {
@@ -8,6 +15,14 @@ type ClassRef struct {
 	Name    string `json:"name"`
 }
 
+// FullName 返回引用的全限定类名，没有包名（如 PHP 全局命名空间中的类）时只有类名
+func (r ClassRef) FullName() string {
+	if r.Package == "" {
+		return r.Name
+	}
+	return r.Package + "." + r.Name
+}
+
 // Relation 表示节点间的关系
 type Relation struct {
 	TargetID string `json:"target_id"` // 目标节点ID
@@ -39,6 +54,91 @@ type UniversalASTNode struct {
 	// 新增：类节点的父类和子类
 	SuperClasses []ClassRef `json:"superClasses"` // 所有父类
 	SubClasses   []ClassRef `json:"subClasses"`   // 所有子类
+
+	// 新增：类和方法上的注解，以及方法参数详情
+	Annotations []AnnotationInfo `json:"annotations"` // 注解列表
+	Params      []ParamInfo      `json:"params"`      // 方法参数（参数名、类型、注解）
+
+	// 新增：类和方法的修饰符、泛型类型参数，以及方法声明抛出的异常
+	Modifiers  []string `json:"modifiers"`  // 修饰符（public, static, synchronized 等）
+	TypeParams []string `json:"typeParams"` // 泛型类型参数，如 T extends Number
+	Throws     []string `json:"throws"`     // throws 子句中的异常类型
+
+	// 新增：方法调用的实参表达式
+	Arguments []ExprInfo `json:"arguments"`
+
+	// 新增：所属模块，取文件向上最近的构建清单，见 moduleResolver
+	Module string `json:"module,omitempty"`
+}
+
+// ExprInfo 表示一个表达式及其中引用的变量
+type ExprInfo struct {
+	Text string   `json:"text"` // 表达式原文
+	Uses []string `json:"uses"` // 引用的参数、局部变量或字段名，this.x 形式的字段保留 this. 前缀
+}
+
+// AnnotationInfo 表示注解信息
+type AnnotationInfo struct {
+	Name      string            `json:"name"`      // 注解简单名，如 RequestMapping
+	FullName  string            `json:"fullName"`  // 推断出的全限定名，如 org.springframework.web.bind.annotation.RequestMapping
+	Arguments map[string]string `json:"arguments"` // 注解参数，单值注解的参数名为 value
+}
+
+// ParamInfo 表示方法参数信息
+type ParamInfo struct {
+	Name        string           `json:"name"`               // 参数名
+	Type        string           `json:"type"`               // 参数类型
+	Annotations []AnnotationInfo `json:"annotations"`        // 参数上的注解
+	Optional    bool             `json:"optional,omitempty"` // 带默认值，调用时可以省略
+	Variadic    bool             `json:"variadic,omitempty"` // 可变参数，可以接收任意个实参
+}
+
+// FindAnnotation 按简单名或全限定名查找注解
+func FindAnnotation(annotations []AnnotationInfo, names ...string) (AnnotationInfo, bool) {
+	for _, annotation := range annotations {
+		for _, name := range names {
+			if annotation.Name == name || annotation.FullName == name {
+				return annotation, true
+			}
+		}
+	}
+	return AnnotationInfo{}, false
+}
+
+// String 返回注解的源码形式，如 @RequestMapping(method=RequestMethod.GET, value=/api)
+func (a AnnotationInfo) String() string {
+	if len(a.Arguments) == 0 {
+		return "@" + a.Name
+	}
+	if value, ok := a.Arguments["value"]; ok && len(a.Arguments) == 1 {
+		return fmt.Sprintf("@%s(%s)", a.Name, value)
+	}
+	keys := make([]string, 0, len(a.Arguments))
+	for key := range a.Arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, key+"="+a.Arguments[key])
+	}
+	return fmt.Sprintf("@%s(%s)", a.Name, strings.Join(args, ", "))
+}
+
+// AddRelation 添加一条到目标节点的关系，已存在的同类型关系不会重复添加
+func (n *UniversalASTNode) AddRelation(targetID, relType string) {
+	for _, rel := range n.Relations {
+		if rel.TargetID == targetID && rel.Type == relType {
+			return
+		}
+	}
+	n.Relations = append(n.Relations, Relation{TargetID: targetID, Type: relType})
+}
+
+// callNodeID 生成方法调用节点的ID：文件路径、方法名及调用表达式的起止偏移。
+// 链式调用 a.b().c() 中内外两层调用的起始位置相同，只能以结束位置区分
+func callNodeID(filePath, name string, start, end int) string {
+	return fmt.Sprintf("%s:%s:%d:%d", filePath, name, start, end)
 }
 
 // FieldInfo 表示类中的字段信息
@@ -49,6 +149,38 @@ type FieldInfo struct {
 	EndLine   int               `json:"endLine"`   // 字段结束行
 	Modifiers []string          `json:"modifiers"` // 字段修饰符（public, private, static等）
 	Metadata  map[string]string `json:"metadata"`  // 字段的其他元数据
+
+	Annotations []AnnotationInfo `json:"annotations"` // 字段上的注解
+}
+
+// nestedClassName 返回嵌套类的节点名 Outer$Inner，outerClass 为外部类的全限定名，
+// AddNode 据此生成全限定名 pkg.Outer$Inner，与 Java 的二进制类名一致
+func nestedClassName(pkg, outerClass, name string) string {
+	if pkg != "" {
+		outerClass = strings.TrimPrefix(outerClass, pkg+".")
+	}
+	return outerClass + "$" + name
+}
+
+// nestedTypeName 返回类型引用 Outer.Inner 的全限定名：outerClass 为 Outer 解析得到的全限定名，
+// rest 为其后以点分隔的嵌套类名，结果为 pkg.Outer$Inner
+func nestedTypeName(outerClass, rest string) string {
+	if rest == "" {
+		return outerClass
+	}
+	return outerClass + "$" + strings.ReplaceAll(rest, ".", "$")
+}
+
+// classLookupNames 返回类可以被引用的名称：全限定名，嵌套类另加以点连接的写法（如 app.Outer.Inner），
+// Java 的 Outer.Inner、C++ 的 Outer::Inner 等引用在解析单个文件时无法与包名、命名空间区分
+func classLookupNames(node UniversalASTNode) []string {
+	if node.FullClassName == "" {
+		return nil
+	}
+	if !node.IsInnerClass {
+		return []string{node.FullClassName}
+	}
+	return []string{node.FullClassName, strings.ReplaceAll(node.FullClassName, "$", ".")}
 }
 
 // ASTParser 通用 AST 解析器接口
@@ -59,7 +191,8 @@ type ASTParser interface {
 
 // ASTIndex 统一索引结构
 type ASTIndex struct {
-	index map[string]UniversalASTNode // ID -> Node
+	index          map[string]UniversalASTNode // ID -> Node
+	gradleProjects []GradleProject             // 代码仓库中的 Gradle 项目
 }
 
 // NewASTIndex 创建新索引
@@ -80,12 +213,11 @@ func (i *ASTIndex) AddNode(node UniversalASTNode) {
 		}
 	}
 
-	// 处理内部类标识
-	if strings.Contains(node.Name, "$") {
+	// 处理内部类标识：嵌套类的节点名为 Outer$Inner（多层嵌套为 A$B$C），外部类取直接外层类的全限定名
+	if idx := strings.LastIndex(node.Name, "$"); node.Type == "Class" && idx > 0 {
 		node.IsInnerClass = true
-		parts := strings.Split(node.Name, "$")
-		node.OuterClass = parts[0]
-		node.Name = parts[len(parts)-1]
+		node.OuterClass = joinDotted(node.Package, node.Name[:idx])
+		node.Name = node.Name[idx+1:]
 	}
 
 	i.index[node.ID] = node
@@ -11,6 +11,10 @@ import (
 	"Fenrir-CodeAuditTool/configs"
 )
 
+// CacheVersion 缓存文件格式版本，节点结构或关系的构建方式变化时需要递增，
+// 版本不一致的缓存不会被加载，而是重新构建索引
+const CacheVersion = "1.24"
+
 // ASTPersistenceManager AST持久化管理器
 type ASTPersistenceManager struct {
 	config *configs.Config
@@ -44,13 +48,14 @@ func (pm *ASTPersistenceManager) SaveASTIndex(index *ASTIndex) error {
 		"repository_path": pm.config.CodeAudit.RepositoryPath,
 		"build_time":      time.Now().Format(time.RFC3339),
 		"node_count":      len(data),
-		"cache_version":   "1.0",
+		"cache_version":   CacheVersion,
 	}
 
 	// 创建完整的缓存数据结构
 	cacheData := map[string]interface{}{
-		"metadata": metadata,
-		"nodes":    data,
+		"metadata":        metadata,
+		"nodes":           data,
+		"gradle_projects": index.gradleProjects,
 	}
 
 	// 序列化为JSON
@@ -99,13 +104,13 @@ func (pm *ASTPersistenceManager) LoadASTIndex() (*ASTIndex, error) {
 		return nil, fmt.Errorf("反序列化AST索引失败: %v", err)
 	}
 
-	// 提取元数据
-	if metadata, exists := cacheData["metadata"]; exists {
-		if metaMap, ok := metadata.(map[string]interface{}); ok {
-			if buildTime, exists := metaMap["build_time"]; exists {
-				fmt.Printf("加载缓存文件: %s (构建时间: %s)\n", cacheFilePath, buildTime)
-			}
-		}
+	// 提取元数据，旧版本的缓存缺少调用关系等信息，不能继续使用
+	metaMap, _ := cacheData["metadata"].(map[string]interface{})
+	if version, _ := metaMap["cache_version"].(string); version != CacheVersion {
+		return nil, fmt.Errorf("AST缓存文件版本 %q 与当前版本 %q 不一致", version, CacheVersion)
+	}
+	if buildTime, exists := metaMap["build_time"]; exists {
+		fmt.Printf("加载缓存文件: %s (构建时间: %s)\n", cacheFilePath, buildTime)
 	}
 
 	// 提取节点数据
@@ -132,6 +137,17 @@ func (pm *ASTPersistenceManager) LoadASTIndex() (*ASTIndex, error) {
 		index.index[id] = node
 	}
 
+	// 没有 Gradle 构建时 gradle_projects 为空
+	if projectsData, exists := cacheData["gradle_projects"]; exists && projectsData != nil {
+		projectsJSON, err := json.Marshal(projectsData)
+		if err != nil {
+			return nil, fmt.Errorf("处理 Gradle 项目数据失败: %v", err)
+		}
+		if err := json.Unmarshal(projectsJSON, &index.gradleProjects); err != nil {
+			return nil, fmt.Errorf("反序列化 Gradle 项目数据失败: %v", err)
+		}
+	}
+
 	return index, nil
 }
 
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"Fenrir-CodeAuditTool/configs"
+)
+
+func TestLoadASTIndexChecksCacheVersion(t *testing.T) {
+	config := &configs.Config{}
+	config.CodeAudit.RepositoryPath = "/src/demo"
+	config.CodeAudit.ASTCache.CacheDir = t.TempDir()
+	pm := NewASTPersistenceManager(config)
+
+	index := NewASTIndex()
+	index.AddNode(UniversalASTNode{ID: "Demo.java:Demo:0", Type: "Class", Name: "Demo", Package: "app"})
+	if err := pm.SaveASTIndex(index); err != nil {
+		t.Fatalf("保存缓存失败: %v", err)
+	}
+	loaded, err := pm.LoadASTIndex()
+	if err != nil {
+		t.Fatalf("加载当前版本的缓存失败: %v", err)
+	}
+	if node, ok := loaded.GetNode("Demo.java:Demo:0"); !ok || node.FullClassName != "app.Demo" {
+		t.Errorf("缓存中的节点不正确: %+v", node)
+	}
+
+	// 旧版本的缓存不能加载，调用方据此重新构建索引
+	path := config.GetCacheFilePath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := strings.Replace(string(data), `"cache_version": "`+CacheVersion+`"`, `"cache_version": "1.0"`, 1)
+	if old == string(data) {
+		t.Fatalf("缓存文件中没有 cache_version")
+	}
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.LoadASTIndex(); err == nil {
+		t.Errorf("加载旧版本的缓存应当失败")
+	}
+}
@@ -0,0 +1,363 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BuildCallGraph 根据 MethodCall 节点记录的接收者类型解析被调用方法，
+// 为调用方方法节点添加 calls 关系，为被调用方法节点添加 called_by 关系
+func BuildCallGraph(m *ParserManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	linkMemberDefinitions(m.index)
+	resolver := newCallResolver(m.index)
+	resolver.resolveDeferredReceivers()
+
+	for id, call := range m.index.index {
+		if call.Type != "MethodCall" || call.Metadata == nil {
+			continue
+		}
+		callerID := call.Metadata["callerID"]
+		if callerID == "" {
+			continue
+		}
+
+		for _, calleeID := range resolver.resolve(call) {
+			call.AddRelation(calleeID, "calls")
+
+			if caller, ok := m.index.index[callerID]; ok {
+				caller.AddRelation(calleeID, "calls")
+				m.index.index[callerID] = caller
+			}
+			if callee, ok := m.index.index[calleeID]; ok {
+				callee.AddRelation(callerID, "called_by")
+				m.index.index[calleeID] = callee
+			}
+		}
+		m.index.index[id] = call
+	}
+}
+
+// linkMemberDefinitions 为类外定义的成员函数（C++ 的 void Foo::bar() {}、Go 的方法）补全所属类节点ID，
+// 类一般声明在另一个文件中，解析单个文件时无法确定
+func linkMemberDefinitions(index *ASTIndex) {
+	classesByName := make(map[string][]string)
+	for id, node := range index.index {
+		if node.Type == "Class" {
+			for _, name := range classLookupNames(node) {
+				classesByName[name] = append(classesByName[name], id)
+			}
+		}
+	}
+	for id, node := range index.index {
+		if node.Type != "Method" || node.Metadata["classID"] != "" || node.Metadata["className"] == "" {
+			continue
+		}
+		if classIDs := classesByName[node.Metadata["className"]]; len(classIDs) > 0 {
+			// 类外定义的嵌套类成员（Outer::Inner::run）改用类节点的全限定名 ns.Outer$Inner
+			node.Metadata["classID"] = classIDs[0]
+			node.Metadata["className"] = index.index[classIDs[0]].FullClassName
+			index.index[id] = node
+		}
+	}
+}
+
+// callResolver 将方法调用解析为索引中的方法节点
+type callResolver struct {
+	index          *ASTIndex
+	classesByName  map[string][]string          // 全限定类名 -> 类节点ID
+	methodsByClass map[string][]string          // 类节点ID -> 方法节点ID
+	fieldTypes     map[string]map[string]string // 类节点ID -> 字段名 -> 字段类型全限定名
+	funcsByModule  map[string][]string          // 模块路径 -> 模块级函数节点ID（Python 等语言）
+}
+
+func newCallResolver(index *ASTIndex) *callResolver {
+	r := &callResolver{
+		index:          index,
+		classesByName:  make(map[string][]string),
+		methodsByClass: make(map[string][]string),
+		fieldTypes:     make(map[string]map[string]string),
+		funcsByModule:  make(map[string][]string),
+	}
+	for id, node := range index.index {
+		switch node.Type {
+		case "Class":
+			for _, name := range classLookupNames(node) {
+				r.classesByName[name] = append(r.classesByName[name], id)
+			}
+		case "Method":
+			if classID := node.Metadata["classID"]; classID != "" {
+				r.methodsByClass[classID] = append(r.methodsByClass[classID], id)
+			}
+		case "Function":
+			if node.Metadata["classID"] == "" && node.Metadata["className"] != "" {
+				r.funcsByModule[node.Metadata["className"]] = append(r.funcsByModule[node.Metadata["className"]], id)
+			}
+			// Kotlin 扩展函数同时按被扩展的类型登记，obj.ext() 按接收者类型查找
+			if extended := node.Metadata["extensionReceiver"]; extended != "" {
+				r.funcsByModule[extended] = append(r.funcsByModule[extended], id)
+			}
+		case "Field":
+			classID := node.Metadata["classID"]
+			if r.fieldTypes[classID] == nil {
+				r.fieldTypes[classID] = make(map[string]string)
+			}
+			r.fieldTypes[classID][node.Name] = node.Metadata["fullType"]
+		}
+	}
+	return r
+}
+
+// resolve 返回调用可能到达的所有方法节点ID：
+// 先在接收者类型及其父类中查找声明，再补充子类中的重写实现
+func (r *callResolver) resolve(call UniversalASTNode) []string {
+	receiverType := call.Metadata["receiverType"]
+	if receiverType == "" {
+		return nil
+	}
+	argCount, _ := strconv.Atoi(call.Metadata["argCount"])
+
+	classIDs := r.receiverClasses(call, receiverType)
+	// C# 的 using 只引入命名空间，解析时视为当前命名空间的类型可能位于某个 using 的命名空间中
+	for _, namespace := range strings.Split(call.Metadata["usings"], ",") {
+		if len(classIDs) > 0 || namespace == "" || receiverType != joinDotted(call.Package, ShortClassName(receiverType)) {
+			break
+		}
+		classIDs = r.classesByName[namespace+"."+ShortClassName(receiverType)]
+	}
+	if len(classIDs) == 0 {
+		// 接收者为模块时调用的是模块级函数
+		targets := r.matchFunctions(receiverType, call.Name, argCount)
+		// PHP、C++ 在命名空间中找不到函数时回退到全局函数
+		if len(targets) == 0 && (call.Language == "php" || call.Language == "cpp") {
+			targets = r.matchFunctions(globalNamespace, call.Name, argCount)
+		}
+		return targets
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	for _, classID := range classIDs {
+		// 构造器调用只匹配被创建类自身的构造方法
+		if call.Name == "<init>" {
+			constructor := r.index.index[classID].Name
+			switch call.Language {
+			case "python":
+				constructor = "__init__"
+			case "javascript", "typescript":
+				constructor = "constructor"
+			case "php":
+				constructor = "__construct"
+			}
+			targets = append(targets, r.matchMethods(classID, constructor, argCount)...)
+			continue
+		}
+
+		declared := r.findInHierarchy(classID, call.Name, argCount, make(map[string]bool))
+		if len(declared) == 0 {
+			continue
+		}
+		for _, methodID := range declared {
+			if !seen[methodID] {
+				seen[methodID] = true
+				targets = append(targets, methodID)
+			}
+		}
+		// 接收者可能是子类实例，补充子类中的重写方法
+		for _, methodID := range r.findOverrides(classID, call.Name, argCount, make(map[string]bool)) {
+			if !seen[methodID] {
+				seen[methodID] = true
+				targets = append(targets, methodID)
+			}
+		}
+	}
+	if len(targets) == 0 && call.Name != "<init>" {
+		switch call.Language {
+		case "kotlin":
+			// Kotlin 类中找不到的方法可能是扩展函数，不带接收者时也可能是同包的顶层函数
+			targets = r.matchFunctions(receiverType, call.Name, argCount)
+			if len(targets) == 0 && call.Metadata["receiver"] == "" {
+				targets = r.matchFunctions(call.Package, call.Name, argCount)
+			}
+		case "cpp":
+			// C++ 成员函数中不带接收者的调用也可能是命名空间或全局的函数
+			if call.Metadata["receiver"] == "" {
+				targets = r.matchFunctions(call.Package, call.Name, argCount)
+				if len(targets) == 0 {
+					targets = r.matchFunctions(globalNamespace, call.Name, argCount)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// findInHierarchy 在类及其父类链中查找匹配的方法声明，找到最近的一层即返回
+func (r *callResolver) findInHierarchy(classID, name string, argCount int, visited map[string]bool) []string {
+	if visited[classID] {
+		return nil
+	}
+	visited[classID] = true
+
+	if matched := r.matchMethods(classID, name, argCount); len(matched) > 0 {
+		return matched
+	}
+
+	class, ok := r.index.index[classID]
+	if !ok {
+		return nil
+	}
+	var results []string
+	for _, super := range class.SuperClasses {
+		for _, superID := range r.classesByName[super.FullName()] {
+			results = append(results, r.findInHierarchy(superID, name, argCount, visited)...)
+		}
+	}
+	return results
+}
+
+// findOverrides 递归查找子类中对同名同参数个数方法的重写
+func (r *callResolver) findOverrides(classID, name string, argCount int, visited map[string]bool) []string {
+	if visited[classID] {
+		return nil
+	}
+	visited[classID] = true
+
+	class, ok := r.index.index[classID]
+	if !ok {
+		return nil
+	}
+	var results []string
+	for _, sub := range class.SubClasses {
+		for _, subID := range r.classesByName[sub.FullName()] {
+			results = append(results, r.matchMethods(subID, name, argCount)...)
+			results = append(results, r.findOverrides(subID, name, argCount, visited)...)
+		}
+	}
+	return results
+}
+
+// matchMethods 返回类中方法名与参数个数均匹配的方法
+func (r *callResolver) matchMethods(classID, name string, argCount int) []string {
+	var matched []string
+	for _, methodID := range r.methodsByClass[classID] {
+		method := r.index.index[methodID]
+		if method.Name == name && arityMatches(method.Params, argCount) {
+			matched = append(matched, methodID)
+		}
+	}
+	return matched
+}
+
+// matchFunctions 返回模块中名称和参数个数匹配的模块级函数
+func (r *callResolver) matchFunctions(module, name string, argCount int) []string {
+	var matched []string
+	for _, funcID := range r.funcsByModule[module] {
+		function := r.index.index[funcID]
+		if function.Name == name && arityMatches(function.Params, argCount) {
+			matched = append(matched, funcID)
+		}
+	}
+	return matched
+}
+
+// arityMatches 判断实参个数是否与形参列表匹配：带默认值的参数可以省略，可变参数可以接收任意个实参
+func arityMatches(params []ParamInfo, argCount int) bool {
+	required, variadic := 0, false
+	for _, param := range params {
+		switch {
+		case param.Variadic:
+			variadic = true
+		case !param.Optional:
+			required++
+		}
+	}
+	return argCount >= required && (variadic || argCount <= len(params))
+}
+
+// FindCallers 查找调用了指定类中指定方法的所有方法，返回调用位置及调用方方法的代码片段
+func FindCallers(query *QueryEngine, className, methodSignature string) ([]string, error) {
+	if className == "" || methodSignature == "" {
+		return nil, fmt.Errorf("className and methodSignature are required")
+	}
+
+	targets := findMethodsInClass(query, className, methodSignature)
+
+	var results []string
+	for _, target := range targets {
+		for _, call := range query.index.index {
+			if call.Type != "MethodCall" || !hasRelation(call, target.ID, "calls") {
+				continue
+			}
+			caller, ok := query.index.index[call.Metadata["callerID"]]
+			if !ok {
+				continue
+			}
+
+			snippet, err := query.GetCodeSnippet(caller, 1)
+			if err != nil {
+				fmt.Printf("Error getting code snippet: %v\n", err)
+				continue
+			}
+			results = append(results, fmt.Sprintf("被调用方法: %s\n调用方法: %s\n调用位置: %s:%d\n%s",
+				describeMethod(target), describeMethod(caller), call.File, call.StartLine+1, snippet))
+		}
+	}
+	return results, nil
+}
+
+// findMethodsInClass 查找类（支持简单类名）中与方法签名匹配的方法节点
+func findMethodsInClass(query *QueryEngine, className, methodSignature string) []UniversalASTNode {
+	classIDs := make(map[string]bool)
+	for id, node := range query.index.index {
+		if node.Type == "Class" && IsMatchingClass(node, className) {
+			classIDs[id] = true
+		}
+	}
+
+	var methods []UniversalASTNode
+	for _, node := range query.index.index {
+		if node.Type == "Method" && classIDs[node.Metadata["classID"]] && IsMatchingMethod(node, methodSignature) {
+			methods = append(methods, node)
+		}
+	}
+	return methods
+}
+
+// hasRelation 判断节点是否存在指向目标节点的指定类型关系
+func hasRelation(node UniversalASTNode, targetID, relType string) bool {
+	for _, rel := range node.Relations {
+		if rel.TargetID == targetID && rel.Type == relType {
+			return true
+		}
+	}
+	return false
+}
+
+// describeMethod 以 全限定类名#方法名(参数类型) 的形式描述方法节点
+func describeMethod(method UniversalASTNode) string {
+	return fmt.Sprintf("%s#%s(%s)", method.Metadata["className"], method.Name, strings.Join(method.MethodParams, ", "))
+}
+
+// VisualizeMethodCallGraph 为类中与方法签名匹配的每个方法生成调用图
+func VisualizeMethodCallGraph(query *QueryEngine, className, methodSignature string, depth int, format string) ([]string, error) {
+	if className == "" || methodSignature == "" {
+		return nil, fmt.Errorf("className and methodSignature are required")
+	}
+	switch format {
+	case "", "text", "mermaid", "dot":
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	var results []string
+	for _, method := range findMethodsInClass(query, className, methodSignature) {
+		graph := query.VisualizeCallGraph(method, depth, format)
+		results = append(results, fmt.Sprintf("起始方法: %s (%s:%d)\n%s",
+			describeMethod(method), method.File, method.StartLine+1, graph))
+	}
+	return results, nil
+}
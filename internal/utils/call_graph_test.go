@@ -0,0 +1,510 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// indexSources 将 files（相对路径 -> 源码）写入临时目录，仅用给定的解析器建立索引
+func indexSources(t *testing.T, files map[string]string, parsers ...ASTParser) *ASTIndex {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewParserManager()
+	for _, parser := range parsers {
+		m.RegisterParser(parser)
+	}
+	if err := m.BuildIndexFromDir(dir); err != nil {
+		t.Fatalf("建立索引失败: %v", err)
+	}
+	return m.GetIndex()
+}
+
+// callTargets 返回 className 类（或模块）中 method 方法的 calls 关系所指向的方法，
+// 格式为 "所在类.方法名/形参个数"，按字典序排列
+func callTargets(index *ASTIndex, className, method string) []string {
+	targets := []string{}
+	for _, node := range index.index {
+		if (node.Type != "Method" && node.Type != "Function") || node.Name != method || node.Metadata["className"] != className {
+			continue
+		}
+		for _, rel := range node.Relations {
+			if rel.Type != "calls" {
+				continue
+			}
+			if callee, ok := index.index[rel.TargetID]; ok {
+				targets = append(targets, callee.Metadata["className"]+"."+callee.Name+"/"+strconv.Itoa(len(callee.MethodParams)))
+			}
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+const javaRepoSource = `package app;
+
+public class Repo {
+    String find(String key) { return key; }
+}
+`
+
+func TestJavaCallGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "全限定类名.方法名"
+		want   []string
+	}{
+		{
+			name: "局部变量接收者",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    String show(String key) {
+        Repo repo = new Repo();
+        return repo.find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.find/1"},
+		},
+		{
+			name: "字段接收者",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Repo repo;
+
+    String show(String key) {
+        repo.find(key);
+        return this.repo.find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.find/1"},
+		},
+		{
+			name: "本类方法与 super 调用",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/Ctl.java": `package app;
+
+public class Ctl extends Repo {
+    String show(String key) {
+        return check(super.find(key));
+    }
+
+    String check(String value) { return value; }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Ctl.check/1", "app.Repo.find/1"},
+		},
+		{
+			name: "静态方法与 import",
+			files: map[string]string{
+				"lib/Util.java": `package lib;
+
+public class Util {
+    public static String parse(String s) { return s; }
+}
+`,
+				"app/Ctl.java": `package app;
+
+import lib.Util;
+
+public class Ctl {
+    String show(String key) {
+        return Util.parse(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"lib.Util.parse/1"},
+		},
+		{
+			name: "继承的方法与子类重写",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/CachedRepo.java": `package app;
+
+public class CachedRepo extends Repo {
+    String find(String key) { return key; }
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    String show(String key) {
+        Repo repo = new Repo();
+        return repo.find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.CachedRepo.find/1", "app.Repo.find/1"},
+		},
+		{
+			name: "构造器调用",
+			files: map[string]string{
+				"app/Repo.java": `package app;
+
+public class Repo {
+    Repo() {}
+    Repo(String url) {}
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    Repo open(String url) {
+        return new Repo(url);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.open",
+			want:   []string{"app.Repo.Repo/1"},
+		},
+		{
+			name: "重载与可变参数按实参个数区分",
+			files: map[string]string{
+				"app/Log.java": `package app;
+
+public class Log {
+    void info(String msg) {}
+    void info(String msg, Throwable e) {}
+    void debug(String fmt, Object... args) {}
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    void show(Log log, Throwable e) {
+        log.info("a", e);
+        log.debug("b", 1, 2, 3);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Log.debug/2", "app.Log.info/2"},
+		},
+		{
+			name: "链式调用按返回类型解析",
+			files: map[string]string{
+				"app/Repo.java": `package app;
+
+public class Repo {
+    Repo self() { return this; }
+    String find(String key) { return key; }
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    String show(Repo repo, String key) {
+        return repo.self().find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.find/1", "app.Repo.self/0"},
+		},
+		{
+			name: "强制类型转换",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    String show(Object obj, String key) {
+        return ((Repo) obj).find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.find/1"},
+		},
+		{
+			name: "var 局部变量",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    String show(String key) {
+        var repo = new Repo();
+        return repo.find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.find/1"},
+		},
+		{
+			name: "继承自父类的字段",
+			files: map[string]string{
+				"app/Repo.java": javaRepoSource,
+				"app/Base.java": `package app;
+
+public class Base {
+    protected Repo repo;
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl extends Base {
+    String show(String key) {
+        return repo.find(key);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.find/1"},
+		},
+		{
+			name: "内部类",
+			files: map[string]string{
+				"app/Outer.java": `package app;
+
+public class Outer {
+    static class Inner {
+        void run(String cmd) {}
+    }
+
+    void start(String cmd) {
+        Inner inner = new Inner();
+        inner.run(cmd);
+    }
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Outer.Inner runner;
+
+    void show(String cmd) {
+        runner.run(cmd);
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Outer$Inner.run/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &JavaParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			class, method := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, class, method); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJavaChainedCallIDs 链式调用中同名的内外两层调用起始位置相同，节点ID不能冲突
+func TestJavaChainedCallIDs(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/Ctl.java": `package app;
+
+public class Ctl {
+    String show(StringBuilder sb) {
+        return sb.append("a").append("b").toString();
+    }
+}
+`,
+	}, &JavaParser{})
+
+	count := 0
+	for _, node := range index.index {
+		if node.Type == "MethodCall" && node.Name == "append" {
+			count++
+		}
+	}
+	if count != 2 {
+		t.Errorf("append 调用节点数 = %d, want 2", count)
+	}
+}
+
+func TestArityMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   []ParamInfo
+		argCount int
+		want     bool
+	}{
+		{"无参", nil, 0, true},
+		{"个数相同", []ParamInfo{{Type: "String"}, {Type: "int"}}, 2, true},
+		{"实参过少", []ParamInfo{{Type: "String"}, {Type: "int"}}, 1, false},
+		{"实参过多", []ParamInfo{{Type: "String"}}, 2, false},
+		{"可变参数为空", []ParamInfo{{Type: "String"}, {Type: "Object...", Variadic: true}}, 1, true},
+		{"可变参数多个", []ParamInfo{{Type: "String"}, {Type: "Object...", Variadic: true}}, 4, true},
+		{"可变参数前缺少必选参数", []ParamInfo{{Type: "String"}, {Type: "Object...", Variadic: true}}, 0, false},
+		{"省略默认参数", []ParamInfo{{Name: "a"}, {Name: "b", Optional: true}}, 1, true},
+		{"传入默认参数", []ParamInfo{{Name: "a"}, {Name: "b", Optional: true}}, 2, true},
+		{"默认参数之外实参过多", []ParamInfo{{Name: "a"}, {Name: "b", Optional: true}}, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := arityMatches(tt.params, tt.argCount); got != tt.want {
+				t.Errorf("arityMatches(%v, %d) = %v, want %v", tt.params, tt.argCount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCallers(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/Repo.java": `package app;
+
+public class Repo {
+    String find(String key) { return key; }
+    String find(String key, int limit) { return key; }
+}
+`,
+		"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Repo repo;
+
+    String show(String key) { return repo.find(key); }
+
+    String list(String key) { return repo.find(key, 10); }
+}
+`,
+	}, &JavaParser{})
+	query := NewQueryEngine(index)
+
+	tests := []struct {
+		name       string
+		className  string
+		signature  string
+		wantCaller []string
+	}{
+		{"全限定类名", "app.Repo", "find", []string{"app.Ctl#list(String)", "app.Ctl#show(String)"}},
+		{"简单类名与参数签名", "Repo", "find(String key, int limit)", []string{"app.Ctl#list(String)"}},
+		{"没有调用方", "app.Ctl", "show", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := FindCallers(query, tt.className, tt.signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var callers []string
+			for _, result := range results {
+				for _, line := range strings.Split(result, "\n") {
+					if caller, ok := strings.CutPrefix(line, "调用方法: "); ok {
+						callers = append(callers, caller)
+					}
+				}
+			}
+			sort.Strings(callers)
+			if !reflect.DeepEqual(callers, tt.wantCaller) {
+				t.Errorf("FindCallers(%s, %s) 调用方 = %v, want %v", tt.className, tt.signature, callers, tt.wantCaller)
+			}
+		})
+	}
+
+	if _, err := FindCallers(query, "", "find"); err == nil {
+		t.Errorf("缺少类名时应当返回错误")
+	}
+}
+
+func TestVisualizeMethodCallGraph(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/Db.java": `package app;
+
+public class Db {
+    String query(String sql) { return sql; }
+}
+`,
+		"app/Repo.java": `package app;
+
+public class Repo {
+    private Db db;
+
+    String find(String key) { return db.query(key); }
+}
+`,
+		"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Repo repo;
+
+    String show(String key) { return repo.find(key); }
+}
+`,
+	}, &JavaParser{})
+	query := NewQueryEngine(index)
+
+	tests := []struct {
+		name    string
+		depth   int
+		format  string
+		want    []string
+		notWant []string
+	}{
+		{"文本缩进", 2, "text", []string{"[java] Ctl.show(String)", "  [java] Repo.find(String)", "    [java] Db.query(String)"}, nil},
+		{"深度限制", 1, "mermaid", []string{"flowchart TD", `n1["Repo.find(String)"]`, "n0 --> n1"}, []string{"Db.query"}},
+		{"DOT", 2, "dot", []string{"digraph CallGraph {", `label="Db.query(String)\nDb.java:4"`, "n0 -> n1;", "n1 -> n2;"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := VisualizeMethodCallGraph(query, "app.Ctl", "show", tt.depth, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("调用图数量 = %d, want 1", len(results))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(results[0], want) {
+					t.Errorf("调用图缺少 %q:\n%s", want, results[0])
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(results[0], notWant) {
+					t.Errorf("调用图不应包含 %q:\n%s", notWant, results[0])
+				}
+			}
+		})
+	}
+
+	if _, err := VisualizeMethodCallGraph(query, "app.Ctl", "show", 1, "svg"); err == nil {
+		t.Errorf("不支持的输出格式应当返回错误")
+	}
+}
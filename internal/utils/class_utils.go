@@ -89,3 +89,9 @@ func IsNodeInClass(node UniversalASTNode, className string, allNodes map[string]
 		node.StartLine >= targetClass.StartLine &&
 		node.EndLine <= targetClass.EndLine
 }
+
+// isCapitalizedName 按命名惯例判断限定名的最后一段是否为类名（首字母大写）
+func isCapitalizedName(qualified string) bool {
+	name := qualified[strings.LastIndex(qualified, ".")+1:]
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
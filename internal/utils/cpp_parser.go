@@ -0,0 +1,907 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/cpp"
+)
+
+// CParser 实现 C 语言（.c）的 AST 解析
+type CParser struct{}
+
+func (p *CParser) Language() string {
+	return "c"
+}
+
+func (p *CParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	return (&cFamilyParser{language: "c"}).parseFile(filePath, c.GetLanguage())
+}
+
+// CppParser 实现 C++ 的 AST 解析，头文件（.h、.hpp）也按 C++ 解析
+type CppParser struct{}
+
+func (p *CppParser) Language() string {
+	return "cpp"
+}
+
+func (p *CppParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	return (&cFamilyParser{language: "cpp"}).parseFile(filePath, cpp.GetLanguage())
+}
+
+// cFamilyParser 是 C 和 C++ 共用的解析实现：命名空间（:: 替换为 .，如 app.net）记录为 Package，
+// 结构体、联合体、枚举和类记录为 Class，类中定义的成员函数、虚函数声明以及在类外定义的成员函数（Foo::bar）记录为 Method，
+// 其余函数记录为 Function（className 为命名空间，不在命名空间中时为 global），宏定义记录为 Macro，
+// 标准库函数调用的接收者类型记为 libc
+type cFamilyParser struct {
+	language string
+}
+
+// cScope 记录遍历到当前节点时所处的命名空间、类和函数上下文，用于推断调用的接收者类型
+type cScope struct {
+	namespace  string            // 所在命名空间
+	classID    string            // 所在类节点ID（类外定义的成员函数中为空）
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的第一个基类全限定名
+	methodID   string            // 所在函数节点ID
+	access     string            // 类中当前的访问控制
+	nested     map[string]string // 所在类及其外层类中声明的嵌套类名 -> 全限定名
+	fieldTypes map[string]string // 所在类的字段名 -> 类型全限定名
+	varTypes   map[string]string // 参数及局部变量名 -> 类型全限定名（未知时为空）
+}
+
+// cLibraryReceiver C 标准库及 POSIX 函数调用的接收者类型，高危 API 目录按 libc#函数名 匹配
+const cLibraryReceiver = "libc"
+
+// cStdlibFunctions 常用的 C 标准库及 POSIX 函数，C++ 中带 std:: 或 :: 前缀调用时同样按标准库处理
+var cStdlibFunctions = map[string]bool{
+	"strcpy": true, "strncpy": true, "strcat": true, "strncat": true, "strlen": true, "strcmp": true,
+	"strncmp": true, "strdup": true, "strchr": true, "strstr": true, "strtok": true,
+	"sprintf": true, "snprintf": true, "vsprintf": true, "vsnprintf": true, "printf": true, "fprintf": true,
+	"scanf": true, "sscanf": true, "fscanf": true, "gets": true, "fgets": true, "puts": true, "fputs": true,
+	"memcpy": true, "memmove": true, "memset": true, "memcmp": true,
+	"malloc": true, "calloc": true, "realloc": true, "free": true, "alloca": true,
+	"atoi": true, "atol": true, "strtol": true, "strtoul": true, "getenv": true, "setenv": true,
+	"system": true, "popen": true, "pclose": true, "fork": true, "execl": true, "execlp": true,
+	"execle": true, "execv": true, "execvp": true, "execve": true,
+	"fopen": true, "fclose": true, "fread": true, "fwrite": true, "open": true, "close": true,
+	"read": true, "write": true, "recv": true, "recvfrom": true, "send": true, "unlink": true,
+	"dlopen": true, "dlsym": true,
+}
+
+func (p *cFamilyParser) parseFile(filePath string, grammar *sitter.Language) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(grammar)
+	tree := parser.Parse(nil, code)
+	defer tree.Close()
+
+	var nodes []UniversalASTNode
+	p.traverseNode(tree.RootNode(), filePath, code, &cScope{varTypes: map[string]string{}}, &nodes)
+	return nodes, nil
+}
+
+func (p *cFamilyParser) traverseNode(node *sitter.Node, filePath string, code []byte, scope *cScope, nodes *[]UniversalASTNode) {
+	// 子节点默认沿用当前作用域，进入命名空间、类或函数时替换为新的作用域
+	childScope := scope
+
+	switch node.Type() {
+	case "namespace_definition":
+		if name := node.ChildByFieldName("name"); name != nil {
+			namespaceScope := *scope
+			namespaceScope.namespace = joinDotted(scope.namespace, strings.ReplaceAll(name.Content(code), "::", "."))
+			childScope = &namespaceScope
+		}
+	case "struct_specifier", "union_specifier", "class_specifier", "enum_specifier":
+		// 没有类体的是类型引用（如 struct node *next）
+		if node.ChildByFieldName("body") == nil {
+			break
+		}
+		if className := p.specifierName(node, code); className != "" {
+			childScope = p.classNode(node, className, filePath, code, scope, nodes)
+		}
+	case "access_specifier":
+		// 基类列表中的访问控制不影响类成员
+		if parent := node.Parent(); parent != nil && parent.Type() == "field_declaration_list" {
+			scope.access = node.Content(code)
+		}
+	case "function_definition":
+		declarator := cFunctionDeclarator(node.ChildByFieldName("declarator"))
+		if declarator == nil {
+			break
+		}
+		functionNode, ok := p.functionNode(node, declarator, filePath, code, scope)
+		if !ok {
+			break
+		}
+		*nodes = append(*nodes, functionNode)
+
+		childScope = &cScope{
+			namespace:  scope.namespace,
+			classID:    functionNode.Metadata["classID"],
+			className:  functionNode.Metadata["className"],
+			superClass: scope.superClass,
+			methodID:   functionNode.ID,
+			fieldTypes: scope.fieldTypes,
+			varTypes:   make(map[string]string),
+		}
+		if functionNode.Type == "Function" {
+			childScope.className = ""
+		}
+		if childScope.fieldTypes == nil {
+			childScope.fieldTypes = make(map[string]string)
+		}
+		for _, param := range functionNode.Params {
+			childScope.varTypes[param.Name] = p.resolveType(param.Type)
+		}
+	case "field_declaration", "declaration":
+		// 类中的虚函数声明记录为方法，重写方法的查找从基类的声明开始
+		if declarator := cFunctionDeclarator(node.ChildByFieldName("declarator")); declarator != nil {
+			if scope.classID != "" && cHasChild(node, "virtual") {
+				if methodNode, ok := p.functionNode(node, declarator, filePath, code, scope); ok {
+					*nodes = append(*nodes, methodNode)
+				}
+			}
+			break
+		}
+		// 函数中的局部变量：Foo *x = ...、auto x = new Foo()
+		if scope.methodID == "" {
+			break
+		}
+		declaredType := ""
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			declaredType = p.resolveType(cTypeName(typeNode, code))
+		}
+		for i := 0; i < int(node.ChildCount()); i++ {
+			if node.FieldNameForChild(i) != "declarator" {
+				continue
+			}
+			declarator := node.Child(i)
+			name, _ := cDeclaratorName(declarator, code)
+			if name == "" {
+				continue
+			}
+			varType := declaredType
+			if value := declarator.ChildByFieldName("value"); varType == "" && declarator.Type() == "init_declarator" && value != nil {
+				varType = p.expressionType(value, code, scope)
+			}
+			scope.varTypes[name] = varType
+		}
+	case "preproc_def", "preproc_function_def":
+		if macroNode, ok := p.macroNode(node, filePath, code, scope); ok {
+			*nodes = append(*nodes, macroNode)
+		}
+		// 宏定义体是未解析的文本，不再向下遍历
+		return
+	case "call_expression":
+		if callNode, ok := p.callNode(node, filePath, code, scope); ok {
+			*nodes = append(*nodes, callNode)
+		}
+	case "new_expression":
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			*nodes = append(*nodes, p.newCallNode(node, "<init>", "", p.resolveType(cTypeName(typeNode, code)),
+				"", "", node.ChildByFieldName("arguments"), filePath, code, scope))
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if child := node.Child(i); child != nil {
+			p.traverseNode(child, filePath, code, childScope, nodes)
+		}
+	}
+}
+
+// specifierName 返回结构体、联合体、枚举或类的名称，匿名结构体取 typedef 的名称（typedef struct {...} name_t）
+func (p *cFamilyParser) specifierName(node *sitter.Node, code []byte) string {
+	if name := node.ChildByFieldName("name"); name != nil {
+		return ShortClassName(strings.ReplaceAll(name.Content(code), "::", "."))
+	}
+	if parent := node.Parent(); parent != nil && parent.Type() == "type_definition" {
+		if declarator := parent.ChildByFieldName("declarator"); declarator != nil {
+			name, _ := cDeclaratorName(declarator, code)
+			return name
+		}
+	}
+	return ""
+}
+
+// classNode 为结构体、联合体、枚举或类创建 Class 节点及其字段节点，返回类体使用的作用域
+func (p *cFamilyParser) classNode(node *sitter.Node, className, filePath string, code []byte, scope *cScope, nodes *[]UniversalASTNode) *cScope {
+	id := fmt.Sprintf("%s:%s:%d", filePath, className, node.StartByte())
+	// 嵌套类的节点名为 Outer$Inner；类外定义的 Outer::Inner::run 按 ns.Outer.Inner 记录，查找时两种写法都能匹配
+	nodeName, fullName := className, joinDotted(scope.namespace, className)
+	if scope.classID != "" {
+		nodeName, fullName = nestedClassName(scope.namespace, scope.className, className), scope.className+"$"+className
+	}
+	kind := strings.TrimSuffix(node.Type(), "_specifier")
+
+	classNode := UniversalASTNode{
+		ID:         id,
+		Language:   p.language,
+		Type:       "Class",
+		Name:       nodeName,
+		File:       filePath,
+		Package:    scope.namespace,
+		StartLine:  int(node.StartPoint().Row),
+		EndLine:    int(node.EndPoint().Row),
+		Fields:     make([]FieldInfo, 0),
+		Metadata:   map[string]string{"kind": kind},
+		TypeParams: p.collectTemplateParams(node, code),
+	}
+
+	var superFullNames []string
+	if baseClause := cChild(node, "base_class_clause"); baseClause != nil {
+		for i := 0; i < int(baseClause.NamedChildCount()); i++ {
+			child := baseClause.NamedChild(i)
+			if child.Type() == "access_specifier" {
+				continue
+			}
+			fq := p.resolveType(cTypeName(child, code))
+			if fq == "" {
+				continue
+			}
+			// 未限定命名空间的基类依次按外层类中的嵌套类、同一命名空间中的类处理
+			if nested, ok := scope.nested[fq]; ok {
+				fq = nested
+			} else if !strings.Contains(fq, ".") && scope.namespace != "" {
+				fq = scope.namespace + "." + fq
+			}
+			superFullNames = append(superFullNames, fq)
+			pkg, name := "", fq
+			if idx := strings.LastIndex(fq, "."); idx != -1 {
+				pkg, name = fq[:idx], fq[idx+1:]
+			}
+			classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: name})
+		}
+	}
+	classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+
+	p.collectClassFields(node, code, &classNode)
+	*nodes = append(*nodes, classNode)
+
+	// class 的成员默认为 private，struct 和 union 的成员默认为 public
+	classScope := &cScope{
+		namespace:  scope.namespace,
+		classID:    id,
+		className:  fullName,
+		access:     "public",
+		nested:     p.nestedTypes(node, code, fullName, scope.nested),
+		fieldTypes: make(map[string]string),
+		varTypes:   make(map[string]string),
+	}
+	if kind == "class" {
+		classScope.access = "private"
+	}
+	if len(superFullNames) > 0 {
+		classScope.superClass = superFullNames[0]
+	}
+	for _, field := range classNode.Fields {
+		fieldType := p.resolveType(field.Metadata["baseType"])
+		classScope.fieldTypes[field.Name] = fieldType
+		*nodes = append(*nodes, UniversalASTNode{
+			ID:        fmt.Sprintf("%s:%s.%s:%d", filePath, className, field.Name, field.StartLine),
+			Language:  p.language,
+			Type:      "Field",
+			Name:      field.Name,
+			File:      filePath,
+			Package:   scope.namespace,
+			StartLine: field.StartLine,
+			EndLine:   field.EndLine,
+			Modifiers: field.Modifiers,
+			Metadata: map[string]string{
+				"fieldType": field.Type,
+				"fullType":  fieldType,
+				"classID":   id,
+				"className": fullName,
+			},
+		})
+	}
+	return classScope
+}
+
+// collectClassFields 收集类体中的成员变量（按访问控制记录修饰符）和枚举常量
+func (p *cFamilyParser) collectClassFields(classDecl *sitter.Node, code []byte, classNode *UniversalASTNode) {
+	body := classDecl.ChildByFieldName("body")
+	if body == nil {
+		return
+	}
+	access := "public"
+	if classNode.Metadata["kind"] == "class" {
+		access = "private"
+	}
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		member := body.NamedChild(i)
+		switch member.Type() {
+		case "access_specifier":
+			access = member.Content(code)
+		case "enumerator":
+			if nameNode := member.ChildByFieldName("name"); nameNode != nil {
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:      nameNode.Content(code),
+					Type:      classNode.Name,
+					StartLine: int(member.StartPoint().Row),
+					EndLine:   int(member.EndPoint().Row),
+					Modifiers: []string{"public", "static", "const"},
+					Metadata:  map[string]string{"baseType": classNode.Name},
+				})
+			}
+		case "field_declaration", "function_definition":
+			// 带初始值的成员变量（int count = 0;）可能被解析为 function_definition
+			typeNode := member.ChildByFieldName("type")
+			if typeNode == nil || (member.Type() == "function_definition" && member.ChildByFieldName("body") != nil) {
+				continue
+			}
+			modifiers := append([]string{access}, p.collectModifiers(member, code)...)
+			for j := 0; j < int(member.ChildCount()); j++ {
+				if member.FieldNameForChild(j) != "declarator" {
+					continue
+				}
+				declarator := member.Child(j)
+				// 成员函数声明不是字段，函数指针成员是字段
+				if cFunctionDeclarator(declarator) != nil && !isFunctionPointer(declarator) {
+					continue
+				}
+				name, suffix := cDeclaratorName(declarator, code)
+				if name == "" {
+					continue
+				}
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:      name,
+					Type:      strings.TrimSpace(typeNode.Content(code) + " " + suffix),
+					StartLine: int(member.StartPoint().Row),
+					EndLine:   int(member.EndPoint().Row),
+					Modifiers: modifiers,
+					Metadata:  map[string]string{"baseType": cTypeName(typeNode, code)},
+				})
+			}
+		}
+	}
+}
+
+// functionNode 为函数定义或类中的函数声明创建节点：类中或以 Foo:: 限定的记录为 Method，其余记录为 Function
+func (p *cFamilyParser) functionNode(node, declarator *sitter.Node, filePath string, code []byte, scope *cScope) (UniversalASTNode, bool) {
+	nameNode := declarator.ChildByFieldName("declarator")
+	if nameNode == nil {
+		return UniversalASTNode{}, false
+	}
+
+	owner, classID := scope.className, scope.classID
+	if nameNode.Type() == "qualified_identifier" {
+		// 类外定义的成员函数（void Foo::bar() {}），所属类节点在建立索引后按类名关联
+		qualified := strings.ReplaceAll(strings.TrimPrefix(cStripTemplateArgs(nameNode.Content(code)), "::"), "::", ".")
+		owner, classID = joinDotted(scope.namespace, qualified[:max(strings.LastIndex(qualified, "."), 0)]), ""
+		nameNode = cLastName(nameNode)
+	}
+	name := nameNode.Content(code)
+	if nameNode.Type() == "template_function" {
+		if inner := nameNode.ChildByFieldName("name"); inner != nil {
+			name = inner.Content(code)
+		}
+	}
+	name = strings.Join(strings.Fields(name), "")
+	if name == "" {
+		return UniversalASTNode{}, false
+	}
+
+	nodeType := "Method"
+	if owner == "" {
+		nodeType, owner = "Function", globalNamespace
+		if scope.namespace != "" {
+			owner = scope.namespace
+		}
+	}
+
+	params, methodParams := p.collectParams(declarator, code)
+	typeParams := p.collectTemplateParams(node, code)
+	returnType, returnFullType := "", ""
+	if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+		_, suffix := cDeclaratorName(node.ChildByFieldName("declarator"), code)
+		returnType = strings.TrimSpace(typeNode.Content(code) + " " + suffix)
+		returnFullType = p.resolveType(cTypeName(typeNode, code))
+		for _, typeParam := range typeParams {
+			if typeParam == cTypeName(typeNode, code) {
+				returnFullType = ""
+			}
+		}
+	}
+
+	modifiers := p.collectModifiers(node, code)
+	if nodeType == "Method" && scope.classID != "" && scope.access != "" {
+		modifiers = append([]string{scope.access}, modifiers...)
+	}
+	if cHasChild(node, "virtual") {
+		modifiers = appendUnique(modifiers, "virtual")
+	}
+	for i := 0; i < int(declarator.NamedChildCount()); i++ {
+		if child := declarator.NamedChild(i); child.Type() == "virtual_specifier" || child.Type() == "type_qualifier" {
+			modifiers = appendUnique(modifiers, child.Content(code))
+		}
+	}
+
+	return UniversalASTNode{
+		ID:           fmt.Sprintf("%s:%s:%d", filePath, name, node.StartByte()),
+		Language:     p.language,
+		Type:         nodeType,
+		Name:         name,
+		File:         filePath,
+		Package:      scope.namespace,
+		StartLine:    int(node.StartPoint().Row),
+		EndLine:      int(node.EndPoint().Row),
+		MethodParams: methodParams,
+		Params:       params,
+		Modifiers:    modifiers,
+		TypeParams:   typeParams,
+		Metadata: map[string]string{
+			"returnType":     returnType,
+			"returnFullType": returnFullType,
+			"classID":        classID,
+			"className":      owner,
+		},
+	}, true
+}
+
+// collectParams 解析函数参数：可变参数记为 ...，默认值不计入签名，(void) 视为没有参数
+func (p *cFamilyParser) collectParams(declarator *sitter.Node, code []byte) ([]ParamInfo, []string) {
+	paramsNode := declarator.ChildByFieldName("parameters")
+	if paramsNode == nil {
+		return nil, nil
+	}
+
+	var params []ParamInfo
+	var methodParams []string
+	for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+		child := paramsNode.NamedChild(i)
+		switch child.Type() {
+		case "parameter_declaration", "optional_parameter_declaration":
+			typeNode := child.ChildByFieldName("type")
+			if typeNode == nil {
+				continue
+			}
+			name, suffix := cDeclaratorName(child.ChildByFieldName("declarator"), code)
+			param := ParamInfo{Name: name, Type: strings.TrimSpace(typeNode.Content(code) + " " + suffix)}
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				if qualifier := child.NamedChild(j); qualifier.Type() == "type_qualifier" {
+					param.Type = qualifier.Content(code) + " " + param.Type
+				}
+			}
+			if param.Type == "void" && name == "" {
+				continue
+			}
+			param.Optional = child.ChildByFieldName("default_value") != nil
+			params = append(params, param)
+			methodParams = append(methodParams, param.Type)
+		case "variadic_parameter", "variadic_parameter_declaration":
+			params = append(params, ParamInfo{Type: "...", Variadic: true})
+			methodParams = append(methodParams, "...")
+		}
+	}
+	return params, methodParams
+}
+
+// macroNode 为宏定义创建 Macro 节点，带参数的宏记录参数名，宏体记录在 metadata 的 value 中
+func (p *cFamilyParser) macroNode(node *sitter.Node, filePath string, code []byte, scope *cScope) (UniversalASTNode, bool) {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return UniversalASTNode{}, false
+	}
+	value := ""
+	if valueNode := node.ChildByFieldName("value"); valueNode != nil {
+		value = strings.TrimSpace(valueNode.Content(code))
+	}
+
+	var params []ParamInfo
+	var methodParams []string
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.ChildCount()); i++ {
+			child := paramsNode.Child(i)
+			if child.Type() == "identifier" || child.Type() == "..." {
+				params = append(params, ParamInfo{Name: child.Content(code), Variadic: child.Type() == "..."})
+				methodParams = append(methodParams, child.Content(code))
+			}
+		}
+	}
+
+	return UniversalASTNode{
+		ID:           fmt.Sprintf("%s:%s:%d", filePath, nameNode.Content(code), node.StartByte()),
+		Language:     p.language,
+		Type:         "Macro",
+		Name:         nameNode.Content(code),
+		File:         filePath,
+		Package:      scope.namespace,
+		StartLine:    int(node.StartPoint().Row),
+		EndLine:      int(node.EndPoint().Row),
+		MethodParams: methodParams,
+		Params:       params,
+		Metadata:     map[string]string{"value": value},
+	}, true
+}
+
+// collectModifiers 收集存储类型说明符和类型限定符，如 static、extern、inline、const
+func (p *cFamilyParser) collectModifiers(declNode *sitter.Node, code []byte) []string {
+	var modifiers []string
+	for i := 0; i < int(declNode.NamedChildCount()); i++ {
+		if child := declNode.NamedChild(i); child.Type() == "storage_class_specifier" || child.Type() == "type_qualifier" {
+			modifiers = append(modifiers, child.Content(code))
+		}
+	}
+	return modifiers
+}
+
+// collectTemplateParams 收集 template <typename T> 中的类型参数名
+func (p *cFamilyParser) collectTemplateParams(declNode *sitter.Node, code []byte) []string {
+	var typeParams []string
+	parent := declNode.Parent()
+	if parent == nil || parent.Type() != "template_declaration" {
+		return typeParams
+	}
+	if paramsNode := parent.ChildByFieldName("parameters"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			if nameNode := cChild(paramsNode.NamedChild(i), "type_identifier"); nameNode != nil {
+				typeParams = append(typeParams, nameNode.Content(code))
+			}
+		}
+	}
+	return typeParams
+}
+
+// resolveType 将类型名转换为索引中的类名形式（:: 替换为 .），基本类型和 auto 返回空。
+// 没有 import 可供推断，未限定命名空间的类型由调用图按调用所在的命名空间补全
+func (p *cFamilyParser) resolveType(typeName string) string {
+	typeName = strings.TrimRight(cStripTemplateArgs(strings.TrimSpace(typeName)), " *&[]")
+	for _, keyword := range []string{"const ", "volatile ", "struct ", "union ", "enum ", "class ", "typename "} {
+		typeName = strings.TrimPrefix(typeName, keyword)
+	}
+	typeName = strings.ReplaceAll(strings.TrimPrefix(typeName, "::"), "::", ".")
+	if typeName == "" || typeName == "auto" || strings.ContainsAny(typeName, " ()") {
+		return ""
+	}
+	return typeName
+}
+
+// nestedTypes 返回类体中可以直接以短名引用的嵌套类：外层类中可见的嵌套类，以及本类体中声明的 Outer$Inner
+func (p *cFamilyParser) nestedTypes(node *sitter.Node, code []byte, className string, outer map[string]string) map[string]string {
+	nested := make(map[string]string)
+	for name, fullName := range outer {
+		nested[name] = fullName
+	}
+	body := node.ChildByFieldName("body")
+	if body == nil {
+		return nested
+	}
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		member := body.NamedChild(i)
+		if member.Type() == "field_declaration" && member.ChildByFieldName("type") != nil {
+			member = member.ChildByFieldName("type")
+		}
+		switch member.Type() {
+		case "struct_specifier", "union_specifier", "class_specifier", "enum_specifier":
+			if name := p.specifierName(member, code); name != "" {
+				nested[name] = className + "$" + name
+			}
+		}
+	}
+	return nested
+}
+
+// cTypeName 返回类型节点的类型名（不含 const、指针、泛型参数），基本类型返回原文
+func cTypeName(typeNode *sitter.Node, code []byte) string {
+	switch typeNode.Type() {
+	case "primitive_type", "sized_type_specifier", "placeholder_type_specifier":
+		return ""
+	case "struct_specifier", "union_specifier", "class_specifier", "enum_specifier", "template_type":
+		if name := typeNode.ChildByFieldName("name"); name != nil {
+			return cTypeName(name, code)
+		}
+		return ""
+	case "type_descriptor":
+		if inner := typeNode.ChildByFieldName("type"); inner != nil {
+			return cTypeName(inner, code)
+		}
+	case "qualified_identifier":
+		return cStripTemplateArgs(typeNode.Content(code))
+	}
+	return typeNode.Content(code)
+}
+
+// cStripTemplateArgs 去掉名称中的模板参数，如 Foo<T>::bar 返回 Foo::bar
+func cStripTemplateArgs(name string) string {
+	var builder strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>' && depth > 0:
+			depth--
+		case depth == 0:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// cFunctionDeclarator 返回声明符中的函数声明符，穿过指针、引用返回类型（如 Foo *create()）
+func cFunctionDeclarator(declarator *sitter.Node) *sitter.Node {
+	for declarator != nil {
+		switch declarator.Type() {
+		case "function_declarator":
+			return declarator
+		case "pointer_declarator", "reference_declarator":
+			if inner := declarator.ChildByFieldName("declarator"); inner != nil {
+				declarator = inner
+			} else {
+				declarator = declarator.NamedChild(int(declarator.NamedChildCount()) - 1)
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// isFunctionPointer 判断声明符是否为函数指针（int (*handler)(const char *)）
+func isFunctionPointer(declarator *sitter.Node) bool {
+	function := cFunctionDeclarator(declarator)
+	inner := function.ChildByFieldName("declarator")
+	return inner != nil && inner.Type() == "parenthesized_declarator"
+}
+
+// cDeclaratorName 返回声明符中的变量名，以及修饰在类型上的指针、引用和数组标记
+func cDeclaratorName(declarator *sitter.Node, code []byte) (string, string) {
+	suffix := ""
+	for declarator != nil {
+		switch declarator.Type() {
+		case "identifier", "field_identifier", "type_identifier":
+			return declarator.Content(code), suffix
+		case "pointer_declarator", "abstract_pointer_declarator":
+			suffix += "*"
+		case "reference_declarator", "abstract_reference_declarator":
+			suffix += "&"
+		case "array_declarator", "abstract_array_declarator":
+			suffix += "[]"
+		case "function_declarator", "init_declarator", "parenthesized_declarator":
+		default:
+			return "", suffix
+		}
+		inner := declarator.ChildByFieldName("declarator")
+		if inner == nil && declarator.NamedChildCount() > 0 {
+			inner = declarator.NamedChild(0)
+		}
+		declarator = inner
+	}
+	return "", suffix
+}
+
+// cLastName 返回限定名（a::b::c）最后一段的节点
+func cLastName(qualified *sitter.Node) *sitter.Node {
+	for qualified.Type() == "qualified_identifier" {
+		name := qualified.ChildByFieldName("name")
+		if name == nil {
+			break
+		}
+		qualified = name
+	}
+	return qualified
+}
+
+// cChild 返回第一个指定类型的命名子节点
+func cChild(node *sitter.Node, nodeType string) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == nodeType {
+			return child
+		}
+	}
+	return nil
+}
+
+// cHasChild 判断节点是否有指定类型的子节点（包括 virtual 等关键字）
+func cHasChild(node *sitter.Node, nodeType string) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.Child(i).Type() == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
+// callNode 为函数调用创建 MethodCall 节点：标准库函数的接收者类型为 libc，
+// 类中不带接收者的调用以当前类为接收者类型，找不到方法时由调用图回退到命名空间中的函数
+func (p *cFamilyParser) callNode(node *sitter.Node, filePath string, code []byte, scope *cScope) (UniversalASTNode, bool) {
+	function := node.ChildByFieldName("function")
+	if function == nil {
+		return UniversalASTNode{}, false
+	}
+
+	name, receiver, receiverType, receiverCallID, receiverField := "", "", "", "", ""
+	switch function.Type() {
+	case "identifier", "template_function":
+		name = cLastName(function).Content(code)
+		if inner := function.ChildByFieldName("name"); function.Type() == "template_function" && inner != nil {
+			name = inner.Content(code)
+		}
+		_, isVar := scope.varTypes[name]
+		switch {
+		case isVar:
+			// 调用函数指针变量，无法确定目标
+		case cStdlibFunctions[name]:
+			receiverType = cLibraryReceiver
+		case scope.className != "":
+			receiverType = scope.className
+		case scope.namespace != "":
+			receiverType = scope.namespace
+		default:
+			receiverType = globalNamespace
+		}
+	case "qualified_identifier":
+		// ns::f()、Foo::create()、std::memcpy()、::system()
+		nameNode := cLastName(function)
+		name = nameNode.Content(code)
+		if inner := nameNode.ChildByFieldName("name"); nameNode.Type() == "template_function" && inner != nil {
+			name = inner.Content(code)
+		}
+		qualifier := strings.TrimSuffix(strings.TrimSuffix(function.Content(code), nameNode.Content(code)), "::")
+		switch {
+		case cStdlibFunctions[name] && (qualifier == "" || qualifier == "std"):
+			receiverType = cLibraryReceiver
+		case qualifier == "":
+			receiverType = globalNamespace
+		default:
+			receiverType = p.resolveType(qualifier)
+		}
+	case "field_expression":
+		// obj.f()、ptr->f()
+		object, field := function.ChildByFieldName("argument"), function.ChildByFieldName("field")
+		if object == nil || field == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiver = field.Content(code), object.Content(code)
+		if inner := field.ChildByFieldName("name"); inner != nil {
+			name = inner.Content(code)
+		}
+		receiverType = p.expressionType(object, code, scope)
+		if receiverType != "" {
+			break
+		}
+		switch {
+		case object.Type() == "call_expression":
+			// 链式调用的接收者类型在建立索引后按前一个调用的返回类型推断
+			if inner := object.ChildByFieldName("function"); inner != nil {
+				innerName := cLastName(inner).Content(code)
+				if inner.Type() == "field_expression" && inner.ChildByFieldName("field") != nil {
+					innerName = inner.ChildByFieldName("field").Content(code)
+				}
+				receiverCallID = callNodeID(filePath, innerName, int(object.StartByte()), int(object.EndByte()))
+			}
+		case scope.className != "" && object.Type() == "identifier":
+			// 类外定义的成员函数中访问的成员变量，建立索引后按所属类的字段类型推断
+			if _, isVar := scope.varTypes[receiver]; !isVar {
+				receiverField = receiver
+			}
+		case scope.className != "" && object.Type() == "field_expression":
+			if this := object.ChildByFieldName("argument"); this != nil && this.Type() == "this" && object.ChildByFieldName("field") != nil {
+				receiverField = object.ChildByFieldName("field").Content(code)
+			}
+		}
+	default:
+		return UniversalASTNode{}, false
+	}
+	return p.newCallNode(node, name, receiver, receiverType, receiverCallID, receiverField, node.ChildByFieldName("arguments"), filePath, code, scope), true
+}
+
+// newCallNode 创建 MethodCall 节点
+func (p *cFamilyParser) newCallNode(node *sitter.Node, name, receiver, receiverType, receiverCallID, receiverField string, argsNode *sitter.Node, filePath string, code []byte, scope *cScope) UniversalASTNode {
+	var arguments []ExprInfo
+	if argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			arguments = append(arguments, p.exprInfo(argsNode.NamedChild(i), code))
+		}
+	}
+
+	callNode := UniversalASTNode{
+		ID:        callNodeID(filePath, name, int(node.StartByte()), int(node.EndByte())),
+		Language:  p.language,
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      filePath,
+		Package:   scope.namespace,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	if receiverField != "" {
+		callNode.Metadata["receiverField"] = receiverField
+	}
+	return callNode
+}
+
+// expressionType 推断表达式的类型：this、已知类型的变量和成员变量、new 表达式、类型转换以及解引用和取地址
+func (p *cFamilyParser) expressionType(expr *sitter.Node, code []byte, scope *cScope) string {
+	switch expr.Type() {
+	case "this":
+		return scope.className
+	case "identifier":
+		name := expr.Content(code)
+		if varType, ok := scope.varTypes[name]; ok {
+			return varType
+		}
+		return scope.fieldTypes[name]
+	case "field_expression":
+		object, field := expr.ChildByFieldName("argument"), expr.ChildByFieldName("field")
+		if object != nil && field != nil && object.Type() == "this" {
+			return scope.fieldTypes[field.Content(code)]
+		}
+	case "new_expression":
+		if typeNode := expr.ChildByFieldName("type"); typeNode != nil {
+			return p.resolveType(cTypeName(typeNode, code))
+		}
+	case "cast_expression":
+		if typeNode := expr.ChildByFieldName("type"); typeNode != nil {
+			return p.resolveType(cTypeName(typeNode, code))
+		}
+	case "pointer_expression", "parenthesized_expression":
+		if inner := expr.ChildByFieldName("argument"); inner != nil {
+			return p.expressionType(inner, code, scope)
+		}
+		if expr.NamedChildCount() > 0 {
+			return p.expressionType(expr.NamedChild(0), code, scope)
+		}
+	}
+	return ""
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名，this->x 记录为 this.x
+func (p *cFamilyParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "identifier":
+			info.Uses = appendUnique(info.Uses, node.Content(code))
+			return
+		case "field_expression":
+			// 只看接收者部分，成员名不是变量
+			if object := node.ChildByFieldName("argument"); object != nil {
+				if field := node.ChildByFieldName("field"); object.Type() == "this" && field != nil {
+					info.Uses = appendUnique(info.Uses, "this."+field.Content(code))
+					return
+				}
+				walk(object)
+			}
+			return
+		case "call_expression":
+			// 不带接收者的函数名不是变量
+			if function := node.ChildByFieldName("function"); function != nil && function.Type() != "identifier" && function.Type() != "qualified_identifier" {
+				walk(function)
+			}
+			if args := node.ChildByFieldName("arguments"); args != nil {
+				walk(args)
+			}
+			return
+		case "sizeof_expression", "type_descriptor":
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(exprNode)
+	return info
+}
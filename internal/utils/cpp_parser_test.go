@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCppParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		source     string
+		wantParams []string // 签名中的参数类型
+		wantInfo   []ParamInfo
+	}{
+		{
+			name:       "默认参数不计入签名",
+			file:       "a.cpp",
+			source:     "int f(const char *name, int flags = 0) { return 0; }\n",
+			wantParams: []string{"const char *", "int"},
+			wantInfo:   []ParamInfo{{Name: "name", Type: "const char *"}, {Name: "flags", Type: "int", Optional: true}},
+		},
+		{
+			name:       "可变参数",
+			file:       "a.c",
+			source:     "int f(const char *fmt, ...) { return 0; }\n",
+			wantParams: []string{"const char *", "..."},
+			wantInfo:   []ParamInfo{{Name: "fmt", Type: "const char *"}, {Type: "...", Variadic: true}},
+		},
+		{
+			name:       "void 参数列表",
+			file:       "a.c",
+			source:     "int f(void) { return 0; }\n",
+			wantParams: nil,
+			wantInfo:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, map[string]string{tt.file: tt.source}, &CParser{}, &CppParser{})
+			var found bool
+			for _, node := range index.index {
+				if node.Name != "f" || node.Type != "Function" {
+					continue
+				}
+				found = true
+				if !reflect.DeepEqual(node.MethodParams, tt.wantParams) {
+					t.Errorf("MethodParams = %q, want %q", node.MethodParams, tt.wantParams)
+				}
+				if !reflect.DeepEqual(node.Params, tt.wantInfo) {
+					t.Errorf("Params = %+v, want %+v", node.Params, tt.wantInfo)
+				}
+			}
+			if !found {
+				t.Fatalf("未找到函数 f")
+			}
+		})
+	}
+}
+
+func TestCppCallGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "类或命名空间.函数名"
+		want   []string
+	}{
+		{
+			name: "类外定义的成员函数与默认参数",
+			files: map[string]string{
+				"repo.h": `namespace app {
+class Repo {
+public:
+    int find(const char *key);
+    int count(int limit = 10) { return limit; }
+};
+}
+`,
+				"repo.cpp": `#include "repo.h"
+
+namespace app {
+int Repo::find(const char *key) { return 0; }
+}
+`,
+				"ctl.cpp": `#include "repo.h"
+
+namespace app {
+class Ctl {
+    Repo repo;
+public:
+    int show(const char *key) { return repo.find(key) + repo.count(); }
+};
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.count/1", "app.Repo.find/1"},
+		},
+		{
+			name: "全局命名空间中子类的重写",
+			files: map[string]string{
+				"handler.cpp": `class Handler {
+public:
+    virtual void handle(const char *req) {}
+};
+
+class AdminHandler : public Handler {
+public:
+    void handle(const char *req) override {}
+};
+
+class Server {
+public:
+    void dispatch(Handler *handler, const char *req) { handler->handle(req); }
+};
+`,
+			},
+			caller: "Server.dispatch",
+			want:   []string{"AdminHandler.handle/1", "Handler.handle/1"},
+		},
+		{
+			name: "命名空间中回退到全局函数",
+			files: map[string]string{
+				"util.c": `void log_msg(const char *fmt, ...) {}
+`,
+				"ctl.cpp": `namespace app {
+class Ctl {
+public:
+    void show(const char *key) { log_msg("%s %d", key, 1); }
+};
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"global.log_msg/2"},
+		},
+		{
+			name: "嵌套类的重写与类外定义",
+			files: map[string]string{
+				"server.h": `namespace app {
+class Server {
+public:
+    class Handler {
+    public:
+        virtual void handle(const char *req) {}
+        void audit(const char *req);
+    };
+    class AdminHandler : public Handler {
+    public:
+        void handle(const char *req) override {}
+    };
+    void dispatch(Handler *handler, const char *req) {
+        handler->audit(req);
+        handler->handle(req);
+    }
+};
+}
+`,
+				"server.cpp": `#include "server.h"
+
+namespace app {
+void Server::Handler::audit(const char *req) {}
+}
+`,
+			},
+			caller: "app.Server.dispatch",
+			want:   []string{"app.Server$AdminHandler.handle/1", "app.Server$Handler.audit/1", "app.Server$Handler.handle/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &CParser{}, &CppParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, function := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, function); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,933 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/csharp"
+)
+
+// CSharpParser 实现 C# 语言的 AST 解析（包括 ILSpy 等工具反编译出的代码），命名空间记录为 Package，
+// 因此类名与 Java 一样为 命名空间.类名：class、interface、struct、enum 和 record 记录为 Class，
+// 属性与字段一同记录为 Field（metadata 中 kind 为 property），特性（Attribute）按注解处理，构造函数为与类同名的 Method
+type CSharpParser struct{}
+
+// csharpScope 记录遍历到当前节点时所处的命名空间、类和方法上下文，用于推断调用的接收者类型
+type csharpScope struct {
+	namespace  string            // 所在命名空间
+	classID    string            // 所在类节点ID
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的父类全限定名
+	methodID   string            // 所在方法节点ID
+	fieldTypes map[string]string // 所在类的字段和属性名 -> 类型全限定名
+	varTypes   map[string]string // 参数及局部变量名 -> 类型全限定名（未知时为空）
+}
+
+// csharpFile 记录单个文件中的 using 指令和声明的类型，用于解析类型名
+type csharpFile struct {
+	path     string
+	code     []byte
+	usings   []string          // using 引入的命名空间
+	aliases  map[string]string // using 别名 -> 全限定名
+	declared map[string]string // 文件中声明的类型名 -> 全限定名
+}
+
+// csharpKnownTypes 常用 .NET 类型可能所在的命名空间。using 只引入命名空间，
+// 类型位于表中某个已引入的命名空间时解析为该命名空间下的类型
+var csharpKnownTypes = map[string][]string{
+	"Console": {"System"}, "Environment": {"System"}, "Activator": {"System"}, "Convert": {"System"},
+	"Type": {"System"}, "Uri": {"System"}, "AppDomain": {"System"}, "Exception": {"System"},
+	"Process": {"System.Diagnostics"}, "ProcessStartInfo": {"System.Diagnostics"},
+	"File": {"System.IO"}, "Directory": {"System.IO"}, "Path": {"System.IO"}, "FileInfo": {"System.IO"},
+	"FileStream": {"System.IO"}, "StreamReader": {"System.IO"}, "StreamWriter": {"System.IO"},
+	"Assembly": {"System.Reflection"}, "MethodInfo": {"System.Reflection"},
+	"SqlConnection":  {"System.Data.SqlClient", "Microsoft.Data.SqlClient"},
+	"SqlCommand":     {"System.Data.SqlClient", "Microsoft.Data.SqlClient"},
+	"SqlDataAdapter": {"System.Data.SqlClient", "Microsoft.Data.SqlClient"},
+	"XmlDocument":    {"System.Xml"}, "XmlReader": {"System.Xml"}, "XmlReaderSettings": {"System.Xml"},
+	"XmlSerializer": {"System.Xml.Serialization"}, "XslCompiledTransform": {"System.Xml.Xsl"},
+	"BinaryFormatter": {"System.Runtime.Serialization.Formatters.Binary"},
+	"LosFormatter":    {"System.Web.UI"}, "ObjectStateFormatter": {"System.Web.UI"},
+	"JavaScriptSerializer": {"System.Web.Script.Serialization"},
+	"JsonConvert":          {"Newtonsoft.Json"}, "JsonSerializer": {"System.Text.Json", "Newtonsoft.Json"},
+	"WebClient": {"System.Net"}, "WebRequest": {"System.Net"}, "HttpWebRequest": {"System.Net"},
+	"HttpClient":  {"System.Net.Http"},
+	"HttpContext": {"Microsoft.AspNetCore.Http", "System.Web"}, "HttpRequest": {"Microsoft.AspNetCore.Http", "System.Web"},
+	"Controller": {"Microsoft.AspNetCore.Mvc", "System.Web.Mvc"}, "ControllerBase": {"Microsoft.AspNetCore.Mvc"},
+	"List": {"System.Collections.Generic"}, "Dictionary": {"System.Collections.Generic"},
+	"IEnumerable": {"System.Collections.Generic"}, "StringBuilder": {"System.Text"}, "Encoding": {"System.Text"},
+	"Regex": {"System.Text.RegularExpressions"}, "Task": {"System.Threading.Tasks"},
+}
+
+// csharpImplicitUsings SDK 风格项目默认引入的命名空间（ImplicitUsings），源码中没有对应的 using 指令
+var csharpImplicitUsings = []string{
+	"System", "System.IO", "System.Collections.Generic", "System.Linq",
+	"System.Net.Http", "System.Threading", "System.Threading.Tasks",
+}
+
+// csharpBuiltinTypes 内置类型关键字对应的 .NET 类型，未列出的内置类型（int、bool 等）不参与类型推断
+var csharpBuiltinTypes = map[string]string{
+	"string": "System.String", "object": "System.Object",
+}
+
+func (p *CSharpParser) Language() string {
+	return "csharp"
+}
+
+func (p *CSharpParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(csharp.GetLanguage())
+	tree := parser.Parse(nil, code)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	file := &csharpFile{
+		path:     filePath,
+		code:     code,
+		aliases:  make(map[string]string),
+		declared: make(map[string]string),
+	}
+	p.collectUsings(root, file)
+	p.collectDeclaredTypes(root, "", "", file)
+
+	var nodes []UniversalASTNode
+	p.traverseNode(root, file, &csharpScope{varTypes: map[string]string{}}, &nodes)
+	return nodes, nil
+}
+
+// collectUsings 收集文件中的 using 指令（包括命名空间块中的）：using X = A.B 记录为别名，using static 不引入命名空间
+func (p *CSharpParser) collectUsings(node *sitter.Node, file *csharpFile) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "using_directive":
+			if child.NamedChildCount() == 0 {
+				continue
+			}
+			target := csharpQualifiedName(child.NamedChild(int(child.NamedChildCount()) - 1).Content(file.code))
+			if alias := child.ChildByFieldName("name"); alias != nil {
+				file.aliases[alias.Content(file.code)] = target
+			} else if !strings.HasPrefix(strings.TrimPrefix(child.Content(file.code), "global "), "using static") {
+				file.usings = appendUnique(file.usings, target)
+			}
+		case "namespace_declaration", "declaration_list":
+			p.collectUsings(child, file)
+		}
+	}
+}
+
+// collectDeclaredTypes 收集文件中声明的类型（包括嵌套类型）及其全限定名
+func (p *CSharpParser) collectDeclaredTypes(node *sitter.Node, namespace, outer string, file *csharpFile) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "file_scoped_namespace_declaration":
+			if name := child.ChildByFieldName("name"); name != nil {
+				namespace = csharpQualifiedName(name.Content(file.code))
+			}
+		case "namespace_declaration":
+			if name := child.ChildByFieldName("name"); name != nil {
+				if body := child.ChildByFieldName("body"); body != nil {
+					p.collectDeclaredTypes(body, joinDotted(namespace, csharpQualifiedName(name.Content(file.code))), "", file)
+				}
+			}
+		default:
+			if !isCSharpTypeDeclaration(child) {
+				continue
+			}
+			name := child.ChildByFieldName("name")
+			if name == nil {
+				continue
+			}
+			fullName := joinDotted(namespace, name.Content(file.code))
+			if outer != "" {
+				fullName = outer + "$" + name.Content(file.code)
+			}
+			if _, exists := file.declared[name.Content(file.code)]; !exists {
+				file.declared[name.Content(file.code)] = fullName
+			}
+			if body := child.ChildByFieldName("body"); body != nil {
+				p.collectDeclaredTypes(body, namespace, fullName, file)
+			}
+		}
+	}
+}
+
+// isCSharpTypeDeclaration 判断节点是否为类型声明
+func isCSharpTypeDeclaration(node *sitter.Node) bool {
+	switch node.Type() {
+	case "class_declaration", "interface_declaration", "struct_declaration", "enum_declaration",
+		"record_declaration", "record_struct_declaration":
+		return true
+	}
+	return false
+}
+
+// csharpQualifiedName 去掉限定名中的 global:: 前缀和空白
+func csharpQualifiedName(name string) string {
+	name = strings.Join(strings.Fields(name), "")
+	return strings.TrimPrefix(name, "global::")
+}
+
+// resolveType 将源码中的类型名解析为全限定名：依次查找 using 别名、文件中声明的类型、
+// 已引入命名空间中的常用 .NET 类型，都找不到时视为当前命名空间中的类型
+func (p *CSharpParser) resolveType(typeName string, file *csharpFile, namespace string) string {
+	typeName = csharpQualifiedName(typeName)
+	if idx := strings.Index(typeName, "<"); idx != -1 {
+		typeName = typeName[:idx]
+	}
+	typeName = strings.TrimRight(typeName, "?[],")
+	if typeName == "" || strings.ContainsAny(typeName, "(*") {
+		return ""
+	}
+	if builtin, ok := csharpBuiltinTypes[typeName]; ok {
+		return builtin
+	}
+	// 其余内置类型和 var、dynamic 均为小写关键字
+	if typeName[0] >= 'a' && typeName[0] <= 'z' && !strings.Contains(typeName, ".") {
+		return ""
+	}
+
+	head, rest, _ := strings.Cut(typeName, ".")
+	if full, ok := file.aliases[head]; ok {
+		return joinDotted(full, rest)
+	}
+	// 文件中声明的类型之后的部分为嵌套类型，如 Ctl.R 对应 App.Web.Ctl$R
+	if full, ok := file.declared[head]; ok {
+		return nestedTypeName(full, rest)
+	}
+	if rest != "" {
+		return typeName
+	}
+	for _, candidate := range csharpKnownTypes[typeName] {
+		for _, using := range append(file.usings, csharpImplicitUsings...) {
+			if using == candidate {
+				return candidate + "." + typeName
+			}
+		}
+	}
+	return joinDotted(namespace, typeName)
+}
+
+// csharpTypeName 返回类型节点的类型名（不含泛型参数）
+func csharpTypeName(typeNode *sitter.Node, code []byte) string {
+	switch typeNode.Type() {
+	case "generic_name":
+		if typeNode.NamedChildCount() > 0 {
+			return typeNode.NamedChild(0).Content(code)
+		}
+	case "nullable_type", "array_type", "pointer_type", "ref_type":
+		if inner := typeNode.ChildByFieldName("type"); inner != nil {
+			return csharpTypeName(inner, code)
+		}
+		if typeNode.NamedChildCount() > 0 {
+			return csharpTypeName(typeNode.NamedChild(0), code)
+		}
+	case "qualified_name":
+		text := csharpQualifiedName(typeNode.Content(code))
+		if idx := strings.Index(text, "<"); idx != -1 {
+			text = text[:idx]
+		}
+		return text
+	}
+	return typeNode.Content(code)
+}
+
+func (p *CSharpParser) traverseNode(node *sitter.Node, file *csharpFile, scope *csharpScope, nodes *[]UniversalASTNode) {
+	code := file.code
+	// 子节点默认沿用当前作用域，进入命名空间、类或方法时替换为新的作用域
+	childScope := scope
+
+	switch node.Type() {
+	case "file_scoped_namespace_declaration":
+		// namespace X; 之后的声明都属于该命名空间
+		if name := node.ChildByFieldName("name"); name != nil {
+			scope.namespace = csharpQualifiedName(name.Content(code))
+		}
+	case "namespace_declaration":
+		if name := node.ChildByFieldName("name"); name != nil {
+			namespaceScope := *scope
+			namespaceScope.namespace = joinDotted(scope.namespace, csharpQualifiedName(name.Content(code)))
+			childScope = &namespaceScope
+		}
+	case "class_declaration", "interface_declaration", "struct_declaration", "enum_declaration",
+		"record_declaration", "record_struct_declaration":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		childScope = p.classNode(node, nameNode.Content(code), file, scope, nodes)
+	case "method_declaration", "constructor_declaration":
+		name := ""
+		if node.Type() == "constructor_declaration" {
+			name = ShortClassName(scope.className)
+		} else if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			name = nameNode.Content(code)
+		}
+		if name == "" || scope.classID == "" {
+			break
+		}
+		methodNode := p.methodNode(node, name, file, scope)
+		*nodes = append(*nodes, methodNode)
+
+		childScope = &csharpScope{
+			namespace:  scope.namespace,
+			classID:    scope.classID,
+			className:  scope.className,
+			superClass: scope.superClass,
+			methodID:   methodNode.ID,
+			fieldTypes: scope.fieldTypes,
+			varTypes:   make(map[string]string),
+		}
+		for _, param := range methodNode.Params {
+			childScope.varTypes[param.Name] = p.resolveType(strings.TrimSuffix(param.Type, "..."), file, scope.namespace)
+		}
+	case "constructor_initializer":
+		// : base(...) 调用父类构造函数，: this(...) 调用本类的其他构造函数
+		receiverType := scope.className
+		if strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(node.Content(code), ":")), "base") {
+			receiverType = scope.superClass
+		}
+		*nodes = append(*nodes, p.newCallNode(node, "<init>", "", receiverType, "", csharpChild(node, "argument_list"), file, scope))
+	case "variable_declaration":
+		// 方法中的局部变量：Foo x = ...、var x = new Foo()
+		if scope.methodID == "" {
+			break
+		}
+		declaredType := ""
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil && typeNode.Type() != "implicit_type" {
+			declaredType = p.resolveType(csharpTypeName(typeNode, code), file, scope.namespace)
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			declarator := node.NamedChild(i)
+			nameNode := declarator.ChildByFieldName("name")
+			if declarator.Type() != "variable_declarator" || nameNode == nil {
+				continue
+			}
+			varType := declaredType
+			if value := declarator.NamedChild(int(declarator.NamedChildCount()) - 1); varType == "" && value != nameNode {
+				varType = p.expressionType(value, file, scope)
+			}
+			scope.varTypes[nameNode.Content(code)] = varType
+		}
+	case "invocation_expression":
+		if callNode, ok := p.callNode(node, file, scope); ok {
+			*nodes = append(*nodes, callNode)
+		}
+	case "object_creation_expression":
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			receiverType := p.resolveType(csharpTypeName(typeNode, code), file, scope.namespace)
+			*nodes = append(*nodes, p.newCallNode(node, "<init>", "", receiverType, "", node.ChildByFieldName("arguments"), file, scope))
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		// 特性已记录到声明上，不再作为调用处理
+		if child == nil || child.Type() == "attribute_list" {
+			continue
+		}
+		p.traverseNode(child, file, childScope, nodes)
+	}
+}
+
+// classNode 为类型声明创建 Class 节点及其字段节点，返回类体使用的作用域
+func (p *CSharpParser) classNode(node *sitter.Node, className string, file *csharpFile, scope *csharpScope, nodes *[]UniversalASTNode) *csharpScope {
+	code := file.code
+	id := fmt.Sprintf("%s:%s:%d", file.path, className, node.StartByte())
+	// 嵌套类型与 Java 内部类一样以 $ 连接外部类型，如 App.Web.Ctl$R，
+	// 节点名带上外部类型，AddNode 据此生成同样的全限定名并将 Name 还原为 R
+	nodeName, fullName := className, joinDotted(scope.namespace, className)
+	if scope.classID != "" {
+		nodeName, fullName = nestedClassName(scope.namespace, scope.className, className), scope.className+"$"+className
+	}
+
+	kind := strings.TrimSuffix(node.Type(), "_declaration")
+	if kind == "record_struct" {
+		kind = "record"
+	}
+
+	classNode := UniversalASTNode{
+		ID:          id,
+		Language:    "csharp",
+		Type:        "Class",
+		Name:        nodeName,
+		File:        file.path,
+		Package:     scope.namespace,
+		StartLine:   int(node.StartPoint().Row),
+		EndLine:     int(node.EndPoint().Row),
+		Fields:      make([]FieldInfo, 0),
+		Metadata:    map[string]string{"kind": kind},
+		Annotations: p.collectAnnotations(node, file, scope.namespace),
+		Modifiers:   p.collectModifiers(node, code),
+		TypeParams:  p.collectTypeParams(node, code),
+	}
+
+	// 基类列表中父类只能位于第一个，按命名惯例 I 开头的视为接口
+	var superFullNames []string
+	superClass := ""
+	if baseList := csharpChild(node, "base_list"); baseList != nil {
+		for i := 0; i < int(baseList.NamedChildCount()); i++ {
+			typeNode := baseList.NamedChild(i)
+			if typeNode.Type() == "primary_constructor_base_type" && typeNode.NamedChildCount() > 0 {
+				typeNode = typeNode.NamedChild(0)
+			}
+			typeName := csharpTypeName(typeNode, code)
+			fq := p.resolveType(typeName, file, scope.namespace)
+			if fq == "" {
+				continue
+			}
+			if i == 0 && (kind == "class" || kind == "record") && !isCSharpInterfaceName(ShortClassName(typeName)) {
+				superClass = fq
+			}
+			superFullNames = append(superFullNames, fq)
+			pkg, name := "", fq
+			if idx := strings.LastIndex(fq, "."); idx != -1 {
+				pkg, name = fq[:idx], fq[idx+1:]
+			}
+			classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: name})
+		}
+	}
+	classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+
+	p.collectClassFields(node, file, scope.namespace, &classNode)
+	*nodes = append(*nodes, classNode)
+
+	classScope := &csharpScope{
+		namespace:  scope.namespace,
+		classID:    id,
+		className:  fullName,
+		superClass: superClass,
+		fieldTypes: make(map[string]string),
+		varTypes:   make(map[string]string),
+	}
+	for _, field := range classNode.Fields {
+		fieldType := p.resolveType(field.Type, file, scope.namespace)
+		classScope.fieldTypes[field.Name] = fieldType
+		*nodes = append(*nodes, UniversalASTNode{
+			ID:          fmt.Sprintf("%s:%s.%s:%d", file.path, className, field.Name, field.StartLine),
+			Language:    "csharp",
+			Type:        "Field",
+			Name:        field.Name,
+			File:        file.path,
+			Package:     scope.namespace,
+			StartLine:   field.StartLine,
+			EndLine:     field.EndLine,
+			Annotations: field.Annotations,
+			Modifiers:   field.Modifiers,
+			Metadata: map[string]string{
+				"fieldType": field.Type,
+				"fullType":  fieldType,
+				"classID":   id,
+				"className": fullName,
+				"kind":      field.Metadata["kind"],
+			},
+		})
+	}
+	return classScope
+}
+
+// isCSharpInterfaceName 按命名惯例判断类型名是否为接口，如 IDisposable
+func isCSharpInterfaceName(name string) bool {
+	return len(name) > 1 && name[0] == 'I' && name[1] >= 'A' && name[1] <= 'Z'
+}
+
+// csharpChild 返回第一个指定类型的命名子节点
+func csharpChild(node *sitter.Node, nodeType string) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == nodeType {
+			return child
+		}
+	}
+	return nil
+}
+
+// collectClassFields 收集字段、事件、属性、枚举成员以及 record 主构造函数参数生成的属性
+func (p *CSharpParser) collectClassFields(classDecl *sitter.Node, file *csharpFile, namespace string, classNode *UniversalASTNode) {
+	code := file.code
+	// record Person(string First, string Last) 的参数即属性
+	if params := csharpChild(classDecl, "parameter_list"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			nameNode, typeNode := param.ChildByFieldName("name"), param.ChildByFieldName("type")
+			if param.Type() != "parameter" || nameNode == nil || typeNode == nil {
+				continue
+			}
+			classNode.Fields = append(classNode.Fields, FieldInfo{
+				Name:        nameNode.Content(code),
+				Type:        csharpTypeName(typeNode, code),
+				StartLine:   int(param.StartPoint().Row),
+				EndLine:     int(param.EndPoint().Row),
+				Modifiers:   []string{"public"},
+				Annotations: p.collectAnnotations(param, file, namespace),
+				Metadata:    map[string]string{"kind": "property"},
+			})
+		}
+	}
+
+	body := classDecl.ChildByFieldName("body")
+	if body == nil {
+		return
+	}
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		member := body.NamedChild(i)
+		switch member.Type() {
+		case "field_declaration", "event_field_declaration":
+			declaration := csharpChild(member, "variable_declaration")
+			if declaration == nil {
+				continue
+			}
+			fieldType := ""
+			if typeNode := declaration.ChildByFieldName("type"); typeNode != nil {
+				fieldType = csharpTypeName(typeNode, code)
+			}
+			kind := "field"
+			if member.Type() == "event_field_declaration" {
+				kind = "event"
+			}
+			for j := 0; j < int(declaration.NamedChildCount()); j++ {
+				declarator := declaration.NamedChild(j)
+				nameNode := declarator.ChildByFieldName("name")
+				if declarator.Type() != "variable_declarator" || nameNode == nil {
+					continue
+				}
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:        nameNode.Content(code),
+					Type:        fieldType,
+					StartLine:   int(member.StartPoint().Row),
+					EndLine:     int(member.EndPoint().Row),
+					Modifiers:   p.collectModifiers(member, code),
+					Annotations: p.collectAnnotations(member, file, namespace),
+					Metadata:    map[string]string{"kind": kind},
+				})
+			}
+		case "property_declaration":
+			nameNode, typeNode := member.ChildByFieldName("name"), member.ChildByFieldName("type")
+			if nameNode == nil || typeNode == nil {
+				continue
+			}
+			classNode.Fields = append(classNode.Fields, FieldInfo{
+				Name:        nameNode.Content(code),
+				Type:        csharpTypeName(typeNode, code),
+				StartLine:   int(member.StartPoint().Row),
+				EndLine:     int(member.EndPoint().Row),
+				Modifiers:   p.collectModifiers(member, code),
+				Annotations: p.collectAnnotations(member, file, namespace),
+				Metadata:    map[string]string{"kind": "property"},
+			})
+		case "enum_member_declaration":
+			if nameNode := member.ChildByFieldName("name"); nameNode != nil {
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:        nameNode.Content(code),
+					Type:        classNode.Name,
+					StartLine:   int(member.StartPoint().Row),
+					EndLine:     int(member.EndPoint().Row),
+					Modifiers:   []string{"public", "static", "const"},
+					Annotations: p.collectAnnotations(member, file, namespace),
+					Metadata:    map[string]string{"kind": "field"},
+				})
+			}
+		}
+	}
+}
+
+// methodNode 为方法和构造函数创建 Method 节点
+func (p *CSharpParser) methodNode(node *sitter.Node, name string, file *csharpFile, scope *csharpScope) UniversalASTNode {
+	code := file.code
+	params, methodParams := p.collectParams(node, file, scope.namespace)
+	typeParams := p.collectTypeParams(node, code)
+
+	returnType, returnFullType := "", ""
+	if returnsNode := node.ChildByFieldName("returns"); returnsNode != nil {
+		returnType = returnsNode.Content(code)
+		returnFullType = p.resolveType(csharpTypeName(returnsNode, code), file, scope.namespace)
+		for _, typeParam := range typeParams {
+			if typeParam == strings.TrimSuffix(returnType, "?") {
+				returnFullType = ""
+			}
+		}
+	}
+
+	return UniversalASTNode{
+		ID:           fmt.Sprintf("%s:%s:%d", file.path, name, node.StartByte()),
+		Language:     "csharp",
+		Type:         "Method",
+		Name:         name,
+		File:         file.path,
+		Package:      scope.namespace,
+		StartLine:    int(node.StartPoint().Row),
+		EndLine:      int(node.EndPoint().Row),
+		MethodParams: methodParams,
+		Params:       params,
+		Annotations:  p.collectAnnotations(node, file, scope.namespace),
+		Modifiers:    p.collectModifiers(node, code),
+		TypeParams:   typeParams,
+		Metadata: map[string]string{
+			"returnType":     returnType,
+			"returnFullType": returnFullType,
+			"classID":        scope.classID,
+			"className":      scope.className,
+		},
+	}
+}
+
+// collectParams 解析方法参数：params 参数类型追加 ...，ref / out / in 记录在类型前，默认值不计入签名
+func (p *CSharpParser) collectParams(node *sitter.Node, file *csharpFile, namespace string) ([]ParamInfo, []string) {
+	code := file.code
+	paramsNode := node.ChildByFieldName("parameters")
+	if paramsNode == nil {
+		return nil, nil
+	}
+
+	var params []ParamInfo
+	var methodParams []string
+	// params 参数不在 parameter 节点中，类型和参数名直接位于参数列表下
+	var variadic *ParamInfo
+	for i := 0; i < int(paramsNode.ChildCount()); i++ {
+		child := paramsNode.Child(i)
+		switch {
+		case child.Type() == "parameter":
+			param := ParamInfo{Annotations: p.collectAnnotations(child, file, namespace)}
+			nameNode, typeNode := child.ChildByFieldName("name"), child.ChildByFieldName("type")
+			if nameNode != nil {
+				param.Name = nameNode.Content(code)
+			}
+			if typeNode != nil {
+				param.Type = typeNode.Content(code)
+			}
+			signature := param.Type
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				part := child.NamedChild(j)
+				switch {
+				case part.Type() == "modifier":
+					signature = part.Content(code) + " " + signature
+				case part.Type() != "attribute_list" && part != nameNode && part != typeNode:
+					param.Optional = true
+				}
+			}
+			params = append(params, param)
+			methodParams = append(methodParams, signature)
+		case paramsNode.FieldNameForChild(i) == "type":
+			variadic = &ParamInfo{Type: child.Content(code) + "...", Variadic: true}
+		case paramsNode.FieldNameForChild(i) == "name" && variadic != nil:
+			variadic.Name = child.Content(code)
+			params = append(params, *variadic)
+			methodParams = append(methodParams, variadic.Type)
+			variadic = nil
+		}
+	}
+	return params, methodParams
+}
+
+// collectModifiers 收集声明的修饰符，如 public、static、async、override
+func (p *CSharpParser) collectModifiers(declNode *sitter.Node, code []byte) []string {
+	var modifiers []string
+	for i := 0; i < int(declNode.NamedChildCount()); i++ {
+		if child := declNode.NamedChild(i); child.Type() == "modifier" {
+			modifiers = append(modifiers, child.Content(code))
+		}
+	}
+	return modifiers
+}
+
+// collectTypeParams 收集泛型类型参数名
+func (p *CSharpParser) collectTypeParams(declNode *sitter.Node, code []byte) []string {
+	var typeParams []string
+	if paramsNode := csharpChild(declNode, "type_parameter_list"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			if nameNode := paramsNode.NamedChild(i).ChildByFieldName("name"); nameNode != nil {
+				typeParams = append(typeParams, nameNode.Content(code))
+			}
+		}
+	}
+	return typeParams
+}
+
+// collectAnnotations 收集声明上的特性：第一个位置参数记为 value，命名参数（Name = x 或 name: x）按名称记录。
+// 特性名按源码中的写法记录（省略 Attribute 后缀时不补全）
+func (p *CSharpParser) collectAnnotations(declNode *sitter.Node, file *csharpFile, namespace string) []AnnotationInfo {
+	code := file.code
+	var annotations []AnnotationInfo
+	for i := 0; i < int(declNode.NamedChildCount()); i++ {
+		attributeList := declNode.NamedChild(i)
+		if attributeList.Type() != "attribute_list" {
+			continue
+		}
+		for j := 0; j < int(attributeList.NamedChildCount()); j++ {
+			attribute := attributeList.NamedChild(j)
+			nameNode := attribute.ChildByFieldName("name")
+			if attribute.Type() != "attribute" || nameNode == nil {
+				continue
+			}
+			name := csharpTypeName(nameNode, code)
+			annotation := AnnotationInfo{
+				Name:      ShortClassName(name),
+				FullName:  p.resolveType(name, file, namespace),
+				Arguments: map[string]string{},
+			}
+			if argsNode := csharpChild(attribute, "attribute_argument_list"); argsNode != nil {
+				for k := 0; k < int(argsNode.NamedChildCount()); k++ {
+					arg := argsNode.NamedChild(k)
+					if arg.NamedChildCount() == 0 {
+						continue
+					}
+					value := arg.NamedChild(int(arg.NamedChildCount()) - 1)
+					switch {
+					case value.Type() == "assignment_expression" && value.ChildByFieldName("left") != nil && value.ChildByFieldName("right") != nil:
+						annotation.Arguments[value.ChildByFieldName("left").Content(code)] = csharpLiteralValue(value.ChildByFieldName("right"), code)
+					case arg.NamedChildCount() > 1:
+						annotation.Arguments[arg.NamedChild(0).Content(code)] = csharpLiteralValue(value, code)
+					default:
+						if _, exists := annotation.Arguments["value"]; !exists {
+							annotation.Arguments["value"] = csharpLiteralValue(value, code)
+						}
+					}
+				}
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// csharpLiteralValue 提取字面量的值，字符串去掉引号
+func csharpLiteralValue(node *sitter.Node, code []byte) string {
+	if node.Type() == "string_literal" || node.Type() == "verbatim_string_literal" || node.Type() == "raw_string_literal" {
+		var content strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			content.WriteString(node.NamedChild(i).Content(code))
+		}
+		if node.NamedChildCount() == 0 {
+			return strings.Trim(strings.TrimPrefix(node.Content(code), "@"), `"`)
+		}
+		return content.String()
+	}
+	return node.Content(code)
+}
+
+// callNode 为方法调用创建 MethodCall 节点：不带接收者的调用以当前类为接收者类型，
+// a?.B() 按 a.B() 处理，a.B().C() 中 C 的接收者类型在建立索引后按 B 的返回类型推断
+func (p *CSharpParser) callNode(node *sitter.Node, file *csharpFile, scope *csharpScope) (UniversalASTNode, bool) {
+	code := file.code
+	function := node.ChildByFieldName("function")
+	if function == nil {
+		return UniversalASTNode{}, false
+	}
+
+	name, receiver, receiverType, receiverCallID := "", "", "", ""
+	switch function.Type() {
+	case "identifier", "generic_name":
+		name = csharpTypeName(function, code)
+		// 调用委托类型的变量，无法确定目标
+		if _, isVar := scope.varTypes[name]; !isVar {
+			receiverType = scope.className
+		}
+	case "member_access_expression", "conditional_access_expression":
+		var object, nameNode *sitter.Node
+		if function.Type() == "member_access_expression" {
+			object, nameNode = function.ChildByFieldName("expression"), function.ChildByFieldName("name")
+		} else if binding := csharpChild(function, "member_binding_expression"); binding != nil {
+			object, nameNode = function.ChildByFieldName("condition"), binding.ChildByFieldName("name")
+		}
+		if object == nil || nameNode == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiver = csharpTypeName(nameNode, code), object.Content(code)
+		receiverType = p.expressionType(object, file, scope)
+		if receiverType == "" && object.Type() == "invocation_expression" {
+			if innerName := csharpCallName(object, code); innerName != "" {
+				receiverCallID = callNodeID(file.path, innerName, int(object.StartByte()), int(object.EndByte()))
+			}
+		}
+	default:
+		return UniversalASTNode{}, false
+	}
+	return p.newCallNode(node, name, receiver, receiverType, receiverCallID, node.ChildByFieldName("arguments"), file, scope), true
+}
+
+// csharpCallName 返回调用表达式的方法名
+func csharpCallName(call *sitter.Node, code []byte) string {
+	function := call.ChildByFieldName("function")
+	if function == nil {
+		return ""
+	}
+	switch function.Type() {
+	case "identifier", "generic_name":
+		return csharpTypeName(function, code)
+	case "member_access_expression":
+		if nameNode := function.ChildByFieldName("name"); nameNode != nil {
+			return csharpTypeName(nameNode, code)
+		}
+	case "conditional_access_expression":
+		if binding := csharpChild(function, "member_binding_expression"); binding != nil && binding.ChildByFieldName("name") != nil {
+			return csharpTypeName(binding.ChildByFieldName("name"), code)
+		}
+	}
+	return ""
+}
+
+// newCallNode 创建 MethodCall 节点，文件的 using 命名空间记录在 usings 中，供调用图查找未能确定命名空间的类型
+func (p *CSharpParser) newCallNode(node *sitter.Node, name, receiver, receiverType, receiverCallID string, argsNode *sitter.Node, file *csharpFile, scope *csharpScope) UniversalASTNode {
+	var arguments []ExprInfo
+	if argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			arg := argsNode.NamedChild(i)
+			if arg.NamedChildCount() > 0 {
+				arguments = append(arguments, p.exprInfo(arg.NamedChild(int(arg.NamedChildCount())-1), file.code))
+			}
+		}
+	}
+
+	callNode := UniversalASTNode{
+		ID:        callNodeID(file.path, name, int(node.StartByte()), int(node.EndByte())),
+		Language:  "csharp",
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      file.path,
+		Package:   scope.namespace,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	if len(file.usings) > 0 {
+		callNode.Metadata["usings"] = strings.Join(file.usings, ",")
+	}
+	return callNode
+}
+
+// isDottedCSharpName 判断表达式是否为由标识符组成的点分名称，如 System.IO.File
+func isDottedCSharpName(expr *sitter.Node) bool {
+	switch expr.Type() {
+	case "identifier", "alias_qualified_name", "qualified_name":
+		return true
+	case "member_access_expression":
+		object, name := expr.ChildByFieldName("expression"), expr.ChildByFieldName("name")
+		return object != nil && name != nil && name.Type() == "identifier" && isDottedCSharpName(object)
+	}
+	return false
+}
+
+// expressionType 推断表达式的类型：this、base、已知类型的变量和字段、构造调用、类型转换、类名（静态调用）以及字符串字面量
+func (p *CSharpParser) expressionType(expr *sitter.Node, file *csharpFile, scope *csharpScope) string {
+	code := file.code
+	switch expr.Type() {
+	case "this":
+		return scope.className
+	case "base":
+		return scope.superClass
+	case "identifier":
+		name := expr.Content(code)
+		if varType, ok := scope.varTypes[name]; ok {
+			return varType
+		}
+		if fieldType, ok := scope.fieldTypes[name]; ok {
+			return fieldType
+		}
+		if isCapitalizedName(name) {
+			return p.resolveType(name, file, scope.namespace)
+		}
+	case "predefined_type":
+		return p.resolveType(expr.Content(code), file, scope.namespace)
+	case "member_access_expression":
+		object, name := expr.ChildByFieldName("expression"), expr.ChildByFieldName("name")
+		if object == nil || name == nil {
+			return ""
+		}
+		if object.Type() == "this" {
+			return scope.fieldTypes[name.Content(code)]
+		}
+		// 全限定类名，如 System.IO.File，首段不能是变量
+		if isDottedCSharpName(expr) && isCapitalizedName(name.Content(code)) {
+			head, _, _ := strings.Cut(csharpQualifiedName(expr.Content(code)), ".")
+			_, isVar := scope.varTypes[head]
+			_, isField := scope.fieldTypes[head]
+			if !isVar && !isField {
+				return p.resolveType(expr.Content(code), file, scope.namespace)
+			}
+		}
+	case "object_creation_expression", "cast_expression":
+		if typeNode := expr.ChildByFieldName("type"); typeNode != nil {
+			return p.resolveType(csharpTypeName(typeNode, code), file, scope.namespace)
+		}
+	case "as_expression":
+		if typeNode := expr.ChildByFieldName("right"); typeNode != nil {
+			return p.resolveType(csharpTypeName(typeNode, code), file, scope.namespace)
+		}
+	case "parenthesized_expression":
+		if expr.NamedChildCount() > 0 {
+			return p.expressionType(expr.NamedChild(0), file, scope)
+		}
+	case "string_literal", "verbatim_string_literal", "raw_string_literal", "interpolated_string_expression":
+		return "System.String"
+	}
+	return ""
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名，this.x 形式的字段保留 this. 前缀
+func (p *CSharpParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "identifier":
+			info.Uses = appendUnique(info.Uses, node.Content(code))
+			return
+		case "member_access_expression":
+			// 只看接收者部分，成员名不是变量
+			if object := node.ChildByFieldName("expression"); object != nil {
+				if object.Type() == "this" {
+					info.Uses = appendUnique(info.Uses, node.Content(code))
+					return
+				}
+				walk(object)
+			}
+			return
+		case "invocation_expression":
+			// 不带接收者的方法名不是变量
+			if function := node.ChildByFieldName("function"); function != nil && function.Type() != "identifier" && function.Type() != "generic_name" {
+				walk(function)
+			}
+			if args := node.ChildByFieldName("arguments"); args != nil {
+				walk(args)
+			}
+			return
+		case "object_creation_expression", "cast_expression":
+			// 类型名不是变量
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); child != node.ChildByFieldName("type") {
+					walk(child)
+				}
+			}
+			return
+		case "argument":
+			// 命名参数的参数名不是变量
+			if node.NamedChildCount() > 0 {
+				walk(node.NamedChild(int(node.NamedChildCount()) - 1))
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(exprNode)
+	return info
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSharpParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantParams []string // 签名中的参数类型
+		wantInfo   []ParamInfo
+	}{
+		{
+			name:       "默认参数不计入签名",
+			source:     "namespace App { class A { void F(string a, int b = 1) {} } }\n",
+			wantParams: []string{"string", "int"},
+			wantInfo:   []ParamInfo{{Name: "a", Type: "string"}, {Name: "b", Type: "int", Optional: true}},
+		},
+		{
+			name:       "params 与 ref 参数",
+			source:     "namespace App { class A { void F(ref int count, params object[] args) {} } }\n",
+			wantParams: []string{"ref int", "object[]..."},
+			wantInfo:   []ParamInfo{{Name: "count", Type: "int"}, {Name: "args", Type: "object[]...", Variadic: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, map[string]string{"A.cs": tt.source}, &CSharpParser{})
+			var found bool
+			for _, node := range index.index {
+				if node.Name != "F" || node.Type != "Method" {
+					continue
+				}
+				found = true
+				if !reflect.DeepEqual(node.MethodParams, tt.wantParams) {
+					t.Errorf("MethodParams = %q, want %q", node.MethodParams, tt.wantParams)
+				}
+				for i := range node.Params {
+					node.Params[i].Annotations = nil
+				}
+				if !reflect.DeepEqual(node.Params, tt.wantInfo) {
+					t.Errorf("Params = %+v, want %+v", node.Params, tt.wantInfo)
+				}
+			}
+			if !found {
+				t.Fatalf("未找到方法 F")
+			}
+		})
+	}
+}
+
+func TestCSharpCallGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "全限定类名.方法名"
+		want   []string
+	}{
+		{
+			name: "using 引入的命名空间与默认参数",
+			files: map[string]string{
+				"Data/Repo.cs": `namespace App.Data
+{
+    public class Repo
+    {
+        public Repo(string url, int timeout = 10) {}
+        public string Find(string key, int limit = 10) { return key; }
+    }
+}
+`,
+				"Web/Ctl.cs": `using App.Data;
+
+namespace App.Web
+{
+    public class Ctl
+    {
+        public string Show(string key)
+        {
+            var repo = new Repo("db");
+            return repo.Find(key);
+        }
+    }
+}
+`,
+			},
+			caller: "App.Web.Ctl.Show",
+			want:   []string{"App.Data.Repo.Find/2", "App.Data.Repo.Repo/2"},
+		},
+		{
+			name: "params 参数与子类重写",
+			files: map[string]string{
+				"Log.cs": `namespace App
+{
+    public class Log
+    {
+        public virtual void Info(string fmt, params object[] args) {}
+    }
+
+    public class FileLog : Log
+    {
+        public override void Info(string fmt, params object[] args) {}
+    }
+
+    public class Ctl
+    {
+        private Log log;
+
+        public void Show(string key)
+        {
+            log.Info("{0} {1}", key, 1);
+        }
+    }
+}
+`,
+			},
+			caller: "App.Ctl.Show",
+			want:   []string{"App.FileLog.Info/2", "App.Log.Info/2"},
+		},
+		{
+			name: "嵌套类型以 Outer$Inner 命名",
+			files: map[string]string{
+				"Ctl.cs": `namespace App.Web
+{
+    public class Ctl
+    {
+        public class R
+        {
+            public void Run(string cmd) {}
+        }
+
+        public void Show(string cmd)
+        {
+            R r = new R();
+            r.Run(cmd);
+        }
+    }
+
+    public class Job
+    {
+        private Ctl.R runner;
+
+        public void Start(string cmd)
+        {
+            runner.Run(cmd);
+        }
+    }
+}
+`,
+			},
+			caller: "App.Web.Job.Start",
+			want:   []string{"App.Web.Ctl$R.Run/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &CSharpParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, method := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, method); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSharpNestedTypes(t *testing.T) {
+	index := indexSources(t, map[string]string{"Ctl.cs": `namespace App.Web
+{
+    public class Ctl
+    {
+        public class R {}
+    }
+}
+`}, &CSharpParser{})
+
+	var nested []UniversalASTNode
+	for _, node := range index.index {
+		if node.Type == "Class" && node.IsInnerClass {
+			nested = append(nested, node)
+		}
+	}
+	if len(nested) != 1 || nested[0].FullClassName != "App.Web.Ctl$R" || nested[0].Name != "R" {
+		t.Errorf("嵌套类型 = %+v, want App.Web.Ctl$R", nested)
+	}
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxDefUseDepth 方法内追溯变量定义的最大层数
+const maxDefUseDepth = 10
+
+// methodDataFlow 一个方法内的定义-使用信息
+type methodDataFlow struct {
+	method UniversalASTNode
+	defs   map[string][]UniversalASTNode // 变量名 -> LocalVariable / Assignment 节点（按行号排序）
+	params map[string]int                // 形参名 -> 参数下标
+	fields map[string]bool               // 所在类的字段名
+}
+
+func newMethodDataFlow(query *QueryEngine, method UniversalASTNode) *methodDataFlow {
+	var defs []UniversalASTNode
+	for _, node := range query.index.index {
+		if (node.Type == "LocalVariable" || node.Type == "Assignment") && node.Metadata["methodID"] == method.ID {
+			defs = append(defs, node)
+		}
+	}
+	return buildMethodDataFlow(query, method, defs)
+}
+
+// buildMethodDataFlow 由已收集的方法内 LocalVariable / Assignment 节点构建定义-使用信息
+func buildMethodDataFlow(query *QueryEngine, method UniversalASTNode, defs []UniversalASTNode) *methodDataFlow {
+	flow := &methodDataFlow{
+		method: method,
+		defs:   make(map[string][]UniversalASTNode),
+		params: make(map[string]int),
+		fields: make(map[string]bool),
+	}
+	for i, param := range method.Params {
+		flow.params[param.Name] = i
+	}
+	if class, ok := query.index.GetNode(method.Metadata["classID"]); ok {
+		for _, field := range class.Fields {
+			flow.fields[field.Name] = true
+		}
+	}
+	for _, def := range defs {
+		flow.defs[def.Name] = append(flow.defs[def.Name], def)
+	}
+	for name := range flow.defs {
+		defs := flow.defs[name]
+		sort.Slice(defs, func(i, j int) bool { return defs[i].StartLine < defs[j].StartLine })
+	}
+	return flow
+}
+
+// trace 从 uses 出发向上追溯变量定义，将过程写入 builder，到达的方法参数和字段记录到 origins
+func (f *methodDataFlow) trace(builder *strings.Builder, uses []string, line int, indent string, depth int, visited map[string]bool, origins map[string]bool) {
+	if depth > maxDefUseDepth {
+		return
+	}
+	for _, name := range uses {
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+
+		if strings.HasPrefix(name, "this.") || (f.fields[name] && len(f.defs[name]) == 0 && !f.hasParam(name)) {
+			builder.WriteString(fmt.Sprintf("%s%s: 字段\n", indent, name))
+			origins["字段 "+strings.TrimPrefix(name, "this.")] = true
+			continue
+		}
+		if i, ok := f.params[name]; ok {
+			param := f.method.Params[i]
+			builder.WriteString(fmt.Sprintf("%s%s: 方法参数 #%d %s %s\n", indent, name, i+1, param.Type, param.Name))
+			origins["方法参数 "+name] = true
+		}
+
+		for _, def := range f.reachingDefs(name, line) {
+			value := def.Metadata["value"]
+			if value == "" {
+				builder.WriteString(fmt.Sprintf("%s%s 声明未赋值 (%s:%d)\n", indent, name, filepath.Base(def.File), def.StartLine+1))
+				continue
+			}
+			operator := def.Metadata["operator"]
+			if operator == "" {
+				operator = "="
+			}
+			builder.WriteString(fmt.Sprintf("%s%s %s %s (%s:%d)\n", indent, name, operator, value, filepath.Base(def.File), def.StartLine+1))
+			var defUses []string
+			for _, use := range splitNonEmpty(def.Metadata["uses"]) {
+				// 复合赋值引用的自身在外层已经展开
+				if use != name || operator == "=" {
+					defUses = append(defUses, use)
+				}
+			}
+			f.trace(builder, defUses, def.StartLine, indent+"  ", depth+1, visited, origins)
+		}
+	}
+}
+
+// reachingDefs 返回可能影响 line 行处变量值的定义：只看使用位置之前的定义，
+// 使用位置之前没有定义时（如循环中）退回到全部定义
+func (f *methodDataFlow) reachingDefs(name string, line int) []UniversalASTNode {
+	var defs []UniversalASTNode
+	for _, def := range f.defs[name] {
+		if def.StartLine <= line {
+			defs = append(defs, def)
+		}
+	}
+	if len(defs) == 0 {
+		return f.defs[name]
+	}
+	return defs
+}
+
+// hasParam 判断名称是否为方法的形参
+func (f *methodDataFlow) hasParam(name string) bool {
+	_, ok := f.params[name]
+	return ok
+}
+
+// TraceCallArguments 列出方法中对 callName 的调用（为空表示全部调用），
+// 并在方法内逐个追溯实参的来源：方法参数、字段、局部变量的定义链
+func TraceCallArguments(query *QueryEngine, className, methodSignature, callName string) (string, error) {
+	if className == "" || methodSignature == "" {
+		return "", fmt.Errorf("className and methodSignature are required")
+	}
+	methods := findMethodsInClass(query, className, methodSignature)
+	if len(methods) == 0 {
+		return "", fmt.Errorf("未找到方法: %s#%s", className, methodSignature)
+	}
+
+	var builder strings.Builder
+	for _, method := range methods {
+		flow := newMethodDataFlow(query, method)
+		builder.WriteString(fmt.Sprintf("==== %s (%s:%d) ====\n", describeMethod(method), filepath.Base(method.File), method.StartLine+1))
+
+		var calls []UniversalASTNode
+		for _, node := range query.index.index {
+			if node.Type == "MethodCall" && node.Metadata["callerID"] == method.ID && (callName == "" || node.Name == callName) {
+				calls = append(calls, node)
+			}
+		}
+		sort.Slice(calls, func(i, j int) bool {
+			if calls[i].StartLine != calls[j].StartLine {
+				return calls[i].StartLine < calls[j].StartLine
+			}
+			return calls[i].ID < calls[j].ID
+		})
+		if len(calls) == 0 {
+			builder.WriteString("未找到匹配的方法调用\n\n")
+			continue
+		}
+
+		for _, call := range calls {
+			target := call.Name
+			if receiver := call.Metadata["receiver"]; receiver != "" {
+				target = receiver + "." + call.Name
+			} else if call.Name == "<init>" {
+				target = "new " + ShortClassName(call.Metadata["receiverType"])
+			}
+			args := make([]string, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				args = append(args, arg.Text)
+			}
+			builder.WriteString(fmt.Sprintf("调用 %s(%s) (%s:%d)\n", target, strings.Join(args, ", "), filepath.Base(call.File), call.StartLine+1))
+
+			for i, arg := range call.Arguments {
+				builder.WriteString(fmt.Sprintf("  实参 %d: %s\n", i+1, arg.Text))
+				origins := make(map[string]bool)
+				flow.trace(&builder, arg.Uses, call.StartLine, "    ", 0, make(map[string]bool), origins)
+				builder.WriteString(fmt.Sprintf("    来源: %s\n", describeOrigins(origins)))
+			}
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String(), nil
+}
+
+// describeOrigins 汇总实参最终来自哪些方法参数和字段
+func describeOrigins(origins map[string]bool) string {
+	if len(origins) == 0 {
+		return "常量或方法内部计算，未引用方法参数和字段"
+	}
+	list := make([]string, 0, len(origins))
+	for origin := range origins {
+		list = append(list, origin)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTraceCallArguments(t *testing.T) {
+	query := NewQueryEngine(indexSources(t, map[string]string{
+		"app/Runner.java": `package app;
+
+public class Runner {
+    private String prefix;
+
+    void run(String cmd, int n) throws Exception {
+        String full = prefix + cmd;
+        full += " -n " + n;
+        String fixed = "ls";
+        Runtime.getRuntime().exec(full);
+        Runtime.getRuntime().exec(fixed);
+        Runtime.getRuntime().exec(this.prefix);
+    }
+}
+`,
+	}, &JavaParser{}))
+
+	out, err := TraceCallArguments(query, "app.Runner", "run", "exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按调用顺序拆出每个 exec 调用的追溯结果
+	sections := strings.Split(out, "调用 Runtime.getRuntime().exec")[1:]
+	if len(sections) != 3 {
+		t.Fatalf("exec 调用数 = %d, want 3:\n%s", len(sections), out)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"复合赋值追溯到参数和字段", sections[0], []string{"full += \" -n \" + n", "full = prefix + cmd", "来源: 字段 prefix, 方法参数 cmd, 方法参数 n"}},
+		{"常量", sections[1], []string{"fixed = \"ls\"", "来源: 常量或方法内部计算"}},
+		{"this 字段", sections[2], []string{"this.prefix: 字段", "来源: 字段 prefix"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.text, want) {
+					t.Errorf("追溯结果缺少 %q:\n%s", want, tt.text)
+				}
+			}
+		})
+	}
+
+	if _, err := TraceCallArguments(query, "app.Runner", "missing", ""); err == nil {
+		t.Errorf("方法不存在时应当返回错误")
+	}
+}
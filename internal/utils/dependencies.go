@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManifestDependency 构建清单（pom.xml、build.gradle、package.json、go.mod）中声明的第三方组件
+type ManifestDependency struct {
+	Ecosystem string `json:"ecosystem"` // OSV 中的生态名称：Maven、npm、Go
+	Name      string `json:"name"`      // Maven 为 groupId:artifactId，npm 为包名，Go 为模块路径
+	Version   string `json:"version"`
+	Scope     string `json:"scope,omitempty"` // compile、test、devDependencies、indirect 等
+	Manifest  string `json:"manifest"`        // 声明依赖的文件
+}
+
+// goRequirePattern go.mod 中的一条 require，如 github.com/gin-gonic/gin v1.9.0 // indirect
+var goRequirePattern = regexp.MustCompile(`^([^\s()]+)\s+(v[^\s]+)(\s*//\s*indirect)?`)
+
+// npmVersionPrefix package.json 版本范围中需要去掉的前缀
+var npmVersionPrefix = regexp.MustCompile(`^[\s^~>=<v]+`)
+
+// CollectDependencies 收集代码仓库中所有构建清单声明的依赖，Gradle 项目使用建立索引时解析的结果
+func CollectDependencies(root string, gradleProjects []GradleProject, mavenRepository string) []ManifestDependency {
+	var dependencies []ManifestDependency
+
+	for _, dep := range NewMavenResolver(mavenRepository).FindDependencies(root) {
+		dependencies = append(dependencies, ManifestDependency{
+			Ecosystem: "Maven",
+			Name:      dep.Key(),
+			Version:   dep.Version,
+			Scope:     dep.Scope,
+			Manifest:  dep.DeclaredIn,
+		})
+	}
+
+	for _, project := range gradleProjects {
+		// 没有构建文件的中间项目只有 subprojects 块中的依赖
+		manifest := project.BuildFile
+		if manifest == "" {
+			manifest = project.Dir
+		}
+		for _, dep := range project.Dependencies {
+			if dep.ArtifactID == "" || dep.Version == "" {
+				continue
+			}
+			dependencies = append(dependencies, ManifestDependency{
+				Ecosystem: "Maven",
+				Name:      dep.GroupID + ":" + dep.ArtifactID,
+				Version:   dep.Version,
+				Scope:     dep.Configuration,
+				Manifest:  manifest,
+			})
+		}
+	}
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "node_modules", ".git", "vendor":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch info.Name() {
+		case "package.json":
+			dependencies = append(dependencies, npmDependencies(path)...)
+		case "go.mod":
+			dependencies = append(dependencies, goModDependencies(path)...)
+		}
+		return nil
+	})
+
+	// 同一组件在多个清单中声明时只保留一条
+	seen := make(map[string]bool)
+	var unique []ManifestDependency
+	for _, dep := range dependencies {
+		key := dep.Ecosystem + " " + dep.Name + "@" + dep.Version
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, dep)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Ecosystem != unique[j].Ecosystem {
+			return unique[i].Ecosystem < unique[j].Ecosystem
+		}
+		return unique[i].Name < unique[j].Name
+	})
+	return unique
+}
+
+// npmDependencies 读取 package.json 中的 dependencies 与 devDependencies，
+// 同目录下有 package-lock.json 时使用其中锁定的版本
+func npmDependencies(path string) []ManifestDependency {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+	locked := npmLockedVersions(filepath.Join(filepath.Dir(path), "package-lock.json"))
+
+	var dependencies []ManifestDependency
+	add := func(specs map[string]string, scope string) {
+		for name, spec := range specs {
+			version := locked[name]
+			if version == "" {
+				// 取版本范围的下限，如 ^4.17.1 取 4.17.1；git 地址、本地路径等无法确定版本
+				version = strings.Fields(npmVersionPrefix.ReplaceAllString(spec, "") + " ")[0]
+				if strings.ContainsAny(version, ":/*xX") || version == "" {
+					continue
+				}
+			}
+			dependencies = append(dependencies, ManifestDependency{
+				Ecosystem: "npm",
+				Name:      name,
+				Version:   version,
+				Scope:     scope,
+				Manifest:  path,
+			})
+		}
+	}
+	add(manifest.Dependencies, "dependencies")
+	add(manifest.DevDependencies, "devDependencies")
+	return dependencies
+}
+
+// npmLockedVersions 读取 package-lock.json 中直接依赖的锁定版本（兼容 v1 与 v2/v3 格式）
+func npmLockedVersions(path string) map[string]string {
+	versions := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return versions
+	}
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return versions
+	}
+	for name, dep := range lock.Dependencies {
+		versions[name] = dep.Version
+	}
+	for key, pkg := range lock.Packages {
+		if name, ok := strings.CutPrefix(key, "node_modules/"); ok && !strings.Contains(name, "/node_modules/") {
+			versions[name] = pkg.Version
+		}
+	}
+	return versions
+}
+
+// goModDependencies 读取 go.mod 中的 require
+func goModDependencies(path string) []ManifestDependency {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var dependencies []ManifestDependency
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "require ("), strings.HasPrefix(line, "require("):
+			inBlock = true
+			continue
+		case inBlock && strings.HasPrefix(line, ")"):
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require"))
+		case !inBlock:
+			continue
+		}
+		m := goRequirePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		scope := "require"
+		if m[3] != "" {
+			scope = "indirect"
+		}
+		dependencies = append(dependencies, ManifestDependency{
+			Ecosystem: "Go",
+			Name:      m[1],
+			Version:   m[2],
+			Scope:     scope,
+			Manifest:  path,
+		})
+	}
+	return dependencies
+}
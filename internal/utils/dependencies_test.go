@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectDependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"web/package.json":      `{"dependencies": {"lodash": "^4.17.0", "local": "file:../local"}, "devDependencies": {"jest": "~29.0.0"}}`,
+		"web/package-lock.json": `{"packages": {"node_modules/lodash": {"version": "4.17.21"}}}`,
+		"svc/go.mod": `module example.com/svc
+
+go 1.21
+
+require (
+	github.com/gin-gonic/gin v1.9.0
+	golang.org/x/text v0.3.7 // indirect
+)
+`,
+	})
+	gradleProjects := []GradleProject{{
+		Path:      ":",
+		BuildFile: "build.gradle",
+		Dependencies: []GradleDependency{
+			{Configuration: "implementation", GroupID: "commons-io", ArtifactID: "commons-io", Version: "2.11.0"},
+			{Configuration: "implementation", Project: ":core"},
+		},
+	}}
+
+	var got []string
+	for _, dep := range CollectDependencies(dir, gradleProjects, "") {
+		got = append(got, dep.Ecosystem+" "+dep.Name+"@"+dep.Version+" "+dep.Scope)
+	}
+	want := []string{
+		"Go github.com/gin-gonic/gin@v1.9.0 require",
+		"Go golang.org/x/text@v0.3.7 indirect",
+		"Maven commons-io:commons-io@2.11.0 implementation",
+		"npm jest@29.0.0 devDependencies",
+		"npm lodash@4.17.21 dependencies",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectDependencies = %v, want %v", got, want)
+	}
+}
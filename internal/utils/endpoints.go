@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// springMappingMethods Spring 组合注解对应的 HTTP 方法
+var springMappingMethods = map[string]string{
+	"GetMapping":    "GET",
+	"PostMapping":   "POST",
+	"PutMapping":    "PUT",
+	"DeleteMapping": "DELETE",
+	"PatchMapping":  "PATCH",
+}
+
+// jaxrsHTTPMethods JAX-RS 中表示 HTTP 方法的注解
+var jaxrsHTTPMethods = []string{"GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS", "PATCH"}
+
+// bindingAnnotations 表示请求参数绑定的注解
+var bindingAnnotations = []string{
+	"RequestParam", "PathVariable", "RequestBody", "RequestHeader", "CookieValue",
+	"ModelAttribute", "RequestPart", "MatrixVariable",
+	"PathParam", "QueryParam", "FormParam", "HeaderParam", "CookieParam", "MatrixParam", "BeanParam",
+}
+
+// Endpoint 表示一个 HTTP 路由
+type Endpoint struct {
+	HTTPMethod    string   `json:"httpMethod"`    // HTTP 方法，未限定时为 ANY
+	Path          string   `json:"path"`          // 完整路由
+	Framework     string   `json:"framework"`     // spring 或 jax-rs
+	HandlerClass  string   `json:"handlerClass"`  // 处理类全限定名
+	HandlerMethod string   `json:"handlerMethod"` // 处理方法签名
+	File          string   `json:"file"`          // 文件路径
+	Line          int      `json:"line"`          // 行号（从 1 开始）
+	Params        []string `json:"params"`        // 参数绑定，如 @RequestParam(name) String name
+}
+
+// ListEndpoints 根据类和方法上的注解组合出 Spring MVC / JAX-RS 路由表
+func ListEndpoints(query *QueryEngine) []Endpoint {
+	var endpoints []Endpoint
+	for _, node := range query.index.index {
+		if node.Type != "Method" {
+			continue
+		}
+		class, ok := query.index.GetNode(node.Metadata["classID"])
+		if !ok {
+			continue
+		}
+		// Feign 客户端接口声明的是对外调用，不是本服务暴露的路由
+		if _, ok := FindAnnotation(class.Annotations, "FeignClient"); ok {
+			continue
+		}
+
+		endpoint := Endpoint{
+			HandlerClass:  class.Package + "." + class.Name,
+			HandlerMethod: fmt.Sprintf("%s(%s)", node.Name, strings.Join(node.MethodParams, ", ")),
+			File:          node.File,
+			Line:          node.StartLine + 1,
+			Params:        endpointParams(node),
+		}
+		if class.Package == "" {
+			endpoint.HandlerClass = class.Name
+		}
+
+		if methods, paths, ok := springRoute(class, node); ok {
+			endpoint.Framework = "spring"
+			endpoints = appendRoutes(endpoints, endpoint, methods, paths)
+		} else if methods, paths, ok := jaxrsRoute(class, node); ok {
+			endpoint.Framework = "jax-rs"
+			endpoints = appendRoutes(endpoints, endpoint, methods, paths)
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		a, b := endpoints[i], endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.HTTPMethod != b.HTTPMethod {
+			return a.HTTPMethod < b.HTTPMethod
+		}
+		return a.HandlerClass < b.HandlerClass
+	})
+	return endpoints
+}
+
+// appendRoutes 按 HTTP 方法和路径的笛卡尔积展开路由
+func appendRoutes(endpoints []Endpoint, endpoint Endpoint, methods, paths []string) []Endpoint {
+	for _, path := range paths {
+		for _, method := range methods {
+			route := endpoint
+			route.HTTPMethod = method
+			route.Path = path
+			endpoints = append(endpoints, route)
+		}
+	}
+	return endpoints
+}
+
+// springRoute 解析 Spring MVC 路由，类上的 @RequestMapping 作为前缀
+func springRoute(class, method UniversalASTNode) ([]string, []string, bool) {
+	var methods, subPaths []string
+	found := false
+	for _, annotation := range method.Annotations {
+		if httpMethod, ok := springMappingMethods[annotation.Name]; ok {
+			methods = []string{httpMethod}
+		} else if annotation.Name == "RequestMapping" {
+			methods = requestMethods(annotation)
+		} else {
+			continue
+		}
+		subPaths = mappingPaths(annotation, "value", "path")
+		found = true
+		break
+	}
+	if !found {
+		return nil, nil, false
+	}
+
+	prefixes := []string{""}
+	if annotation, ok := FindAnnotation(class.Annotations, "RequestMapping"); ok {
+		prefixes = mappingPaths(annotation, "value", "path")
+	}
+	return methods, joinRoutePaths(prefixes, subPaths), true
+}
+
+// jaxrsRoute 解析 JAX-RS 路由，只有 @Path 没有 HTTP 方法注解的方法是子资源定位器
+func jaxrsRoute(class, method UniversalASTNode) ([]string, []string, bool) {
+	var methods []string
+	for _, name := range jaxrsHTTPMethods {
+		if _, ok := FindAnnotation(method.Annotations, name); ok {
+			methods = append(methods, name)
+		}
+	}
+	methodPath, hasPath := FindAnnotation(method.Annotations, "Path")
+	if len(methods) == 0 {
+		if !hasPath {
+			return nil, nil, false
+		}
+		methods = []string{"LOCATOR"}
+	}
+
+	prefixes := []string{""}
+	if annotation, ok := FindAnnotation(class.Annotations, "Path"); ok {
+		prefixes = mappingPaths(annotation, "value")
+	} else if !hasPath {
+		// 类和方法都没有 @Path，不是资源方法
+		return nil, nil, false
+	}
+	subPaths := []string{""}
+	if hasPath {
+		subPaths = mappingPaths(methodPath, "value")
+	}
+	return methods, joinRoutePaths(prefixes, subPaths), true
+}
+
+// requestMethods 解析 @RequestMapping 的 method 参数，如 RequestMethod.GET 或 RequestMethod.GET,RequestMethod.POST
+func requestMethods(annotation AnnotationInfo) []string {
+	value := annotation.Arguments["method"]
+	if value == "" {
+		return []string{"ANY"}
+	}
+	var methods []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			methods = append(methods, ShortClassName(item))
+		}
+	}
+	return methods
+}
+
+// mappingPaths 读取注解中的路径参数，可能有多个路径
+func mappingPaths(annotation AnnotationInfo, keys ...string) []string {
+	for _, key := range keys {
+		value, ok := annotation.Arguments[key]
+		if !ok {
+			continue
+		}
+		var paths []string
+		// 数组形式的路径在解析注解时已以逗号连接
+		for _, path := range strings.Split(value, ",") {
+			paths = append(paths, strings.TrimSpace(path))
+		}
+		return paths
+	}
+	return []string{""}
+}
+
+// joinRoutePaths 拼接类前缀和方法路径，并规范化斜杠
+func joinRoutePaths(prefixes, subPaths []string) []string {
+	var paths []string
+	for _, prefix := range prefixes {
+		for _, subPath := range subPaths {
+			path := "/" + strings.Trim(prefix, "/")
+			if subPath = strings.Trim(subPath, "/"); subPath != "" {
+				path = strings.TrimSuffix(path, "/") + "/" + subPath
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// endpointParams 描述方法参数的绑定方式，没有绑定注解的参数原样列出
+func endpointParams(method UniversalASTNode) []string {
+	var params []string
+	for _, param := range method.Params {
+		desc := strings.TrimSpace(param.Type + " " + param.Name)
+		if annotation, ok := FindAnnotation(param.Annotations, bindingAnnotations...); ok {
+			name := annotation.Arguments["value"]
+			if name == "" {
+				name = annotation.Arguments["name"]
+			}
+			if name != "" {
+				desc = fmt.Sprintf("@%s(%s) %s", annotation.Name, name, desc)
+			} else {
+				desc = fmt.Sprintf("@%s %s", annotation.Name, desc)
+			}
+		}
+		params = append(params, desc)
+	}
+	return params
+}
+
+// FormatEndpoints 格式化路由表，keyword 非空时只保留路径或处理类中包含关键字的路由
+func FormatEndpoints(endpoints []Endpoint, keyword string) string {
+	var builder strings.Builder
+	count := 0
+	for _, endpoint := range endpoints {
+		if keyword != "" && !strings.Contains(endpoint.Path, keyword) &&
+			!strings.Contains(endpoint.HandlerClass, keyword) {
+			continue
+		}
+		count++
+		builder.WriteString(fmt.Sprintf("%d. [%s] %s %s\n", count, endpoint.Framework, endpoint.HTTPMethod, endpoint.Path))
+		builder.WriteString(fmt.Sprintf("   处理方法: %s#%s (%s:%d)\n",
+			endpoint.HandlerClass, endpoint.HandlerMethod, filepath.Base(endpoint.File), endpoint.Line))
+		if len(endpoint.Params) > 0 {
+			builder.WriteString(fmt.Sprintf("   参数绑定: %s\n", strings.Join(endpoint.Params, "; ")))
+		}
+	}
+	if count == 0 {
+		return "未找到 HTTP 路由"
+	}
+	return fmt.Sprintf("共 %d 个路由:\n", count) + builder.String()
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListEndpoints(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string // "HTTP方法 路径 框架 处理方法 参数"
+	}{
+		{
+			name: "Spring 类前缀与组合注解",
+			file: `package app;
+
+@RestController
+@RequestMapping("/api")
+public class UserController {
+    @GetMapping("/users/{id}")
+    public User get(@PathVariable("id") Long id, @RequestParam(name = "v") String v) { return null; }
+
+    @RequestMapping(value = {"/a", "b"}, method = {RequestMethod.GET, RequestMethod.POST})
+    public void multi(HttpServletRequest request) {}
+
+    @PostMapping
+    public void create(@RequestBody User user) {}
+
+    private void helper() {}
+}
+`,
+			want: []string{
+				"POST /api spring create(User) @RequestBody User user",
+				"GET /api/a spring multi(HttpServletRequest) HttpServletRequest request",
+				"POST /api/a spring multi(HttpServletRequest) HttpServletRequest request",
+				"GET /api/b spring multi(HttpServletRequest) HttpServletRequest request",
+				"POST /api/b spring multi(HttpServletRequest) HttpServletRequest request",
+				"GET /api/users/{id} spring get(Long, String) @PathVariable(id) Long id,@RequestParam(v) String v",
+			},
+		},
+		{
+			name: "JAX-RS",
+			file: `package app;
+
+@Path("/orders")
+public class OrderResource {
+    @GET
+    @Path("{id}")
+    public Order get(@PathParam("id") String id) { return null; }
+
+    @DELETE
+    public void clear() {}
+
+    @Path("items")
+    public ItemResource items() { return null; }
+}
+`,
+			want: []string{
+				"DELETE /orders jax-rs clear() ",
+				"LOCATOR /orders/items jax-rs items() ",
+				"GET /orders/{id} jax-rs get(String) @PathParam(id) String id",
+			},
+		},
+		{
+			name: "Feign 客户端不是本服务路由",
+			file: `package app;
+
+@FeignClient("user")
+public interface UserClient {
+    @GetMapping("/users")
+    List<User> list();
+}
+`,
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := NewQueryEngine(indexSources(t, map[string]string{"app/Web.java": tt.file}, &JavaParser{}))
+			got := []string{}
+			for _, e := range ListEndpoints(query) {
+				got = append(got, strings.Join([]string{e.HTTPMethod, e.Path, e.Framework, e.HandlerMethod, strings.Join(e.Params, ",")}, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListEndpoints =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
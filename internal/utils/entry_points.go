@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// slingServletProperties Sling Servlet 注册属性到入口节点元数据键的映射
+var slingServletProperties = map[string]string{
+	"sling.servlet.paths":         "urlPatterns",
+	"sling.servlet.resourceTypes": "resourceTypes",
+	"sling.servlet.methods":       "methods",
+	"sling.servlet.selectors":     "selectors",
+	"sling.servlet.extensions":    "extensions",
+	"sling.filter.pattern":        "urlPatterns",
+	"sling.filter.scope":          "scope",
+}
+
+// javaEntryPoint 根据类上的 @WebServlet/@WebFilter/@WebListener 或 Sling Servlet 注册信息生成入口节点
+func javaEntryPoint(class UniversalASTNode) (UniversalASTNode, bool) {
+	kind, source := "", ""
+	values := make(map[string][]string)
+	addValues := func(key, value string) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values[key] = append(values[key], item)
+			}
+		}
+	}
+
+	for _, annotation := range class.Annotations {
+		args := annotation.Arguments
+		switch annotation.Name {
+		case "WebServlet":
+			kind, source = "servlet", "@WebServlet"
+			addValues("urlPatterns", args["value"])
+			addValues("urlPatterns", args["urlPatterns"])
+			addValues("name", args["name"])
+		case "WebFilter":
+			kind, source = "filter", "@WebFilter"
+			addValues("urlPatterns", args["value"])
+			addValues("urlPatterns", args["urlPatterns"])
+			// 按 Servlet 名称映射的过滤器记录为 servlet:名称
+			for _, name := range splitNonEmpty(args["servletNames"]) {
+				values["urlPatterns"] = append(values["urlPatterns"], "servlet:"+name)
+			}
+			addValues("name", args["filterName"])
+		case "WebListener":
+			kind, source = "listener", "@WebListener"
+		case "SlingServlet":
+			// Felix SCR 注解
+			kind, source = "servlet", "@SlingServlet"
+			addValues("urlPatterns", args["paths"])
+			addValues("resourceTypes", args["resourceTypes"])
+			addValues("methods", args["methods"])
+			addValues("selectors", args["selectors"])
+			addValues("extensions", args["extensions"])
+		case "SlingServletPaths":
+			kind, source = "servlet", "@SlingServletPaths"
+			addValues("urlPatterns", args["value"])
+		case "SlingServletResourceTypes":
+			kind, source = "servlet", "@SlingServletResourceTypes"
+			addValues("resourceTypes", args["resourceTypes"])
+			addValues("methods", args["methods"])
+			addValues("selectors", args["selectors"])
+			addValues("extensions", args["extensions"])
+		case "SlingServletFilter":
+			kind, source = "filter", "@SlingServletFilter"
+			addValues("urlPatterns", args["pattern"])
+			addValues("scope", args["scope"])
+		case "Component":
+			// OSGi DS 注解：property = {"sling.servlet.paths=/bin/foo", ...}
+			lastKey := ""
+			for _, item := range strings.Split(args["property"], ",") {
+				key, value, found := strings.Cut(item, "=")
+				key = strings.TrimSpace(key)
+				if !found {
+					// 属性值中本身含有逗号时，归入上一个属性
+					if lastKey != "" {
+						addValues(lastKey, item)
+					}
+					continue
+				}
+				lastKey = slingServletProperties[key]
+				if lastKey == "" {
+					continue
+				}
+				if strings.HasPrefix(key, "sling.filter.") {
+					kind = "filter"
+				} else if kind == "" {
+					kind = "servlet"
+				}
+				source = "@Component"
+				addValues(lastKey, value)
+			}
+		}
+	}
+	if kind == "" {
+		return UniversalASTNode{}, false
+	}
+
+	entry := UniversalASTNode{
+		ID:        fmt.Sprintf("%s:entry:%s:%d", class.File, class.Name, class.StartLine),
+		Language:  class.Language,
+		Type:      "EntryPoint",
+		Name:      class.Name,
+		File:      class.File,
+		Package:   class.Package,
+		StartLine: class.StartLine,
+		EndLine:   class.StartLine,
+		Metadata: map[string]string{
+			"kind":      kind,
+			"source":    source,
+			"className": class.Package + "." + class.Name,
+			"classID":   class.ID,
+		},
+	}
+	for key, items := range values {
+		entry.Metadata[key] = strings.Join(items, ",")
+	}
+	if name := entry.Metadata["name"]; name != "" {
+		entry.Name = name
+	}
+	entry.AddRelation(class.ID, "handled_by")
+	return entry, true
+}
+
+// EntryPoint 表示一个 Servlet / Filter / Listener 入口
+type EntryPoint struct {
+	Kind          string   `json:"kind"`          // servlet、filter 或 listener
+	Source        string   `json:"source"`        // 声明来源，如 web.xml、@WebServlet、@Component
+	Name          string   `json:"name"`          // 入口名称
+	Class         string   `json:"class"`         // 实现类全限定名
+	URLPatterns   []string `json:"urlPatterns"`   // 映射的 URL 或 Sling 路径
+	ResourceTypes []string `json:"resourceTypes"` // Sling 资源类型
+	Methods       []string `json:"methods"`       // 限定的 HTTP 方法
+	Handlers      []string `json:"handlers"`      // 实现类中处理请求的方法
+	File          string   `json:"file"`          // 声明所在文件
+	Line          int      `json:"line"`          // 行号（从 1 开始，web.xml 中为 0）
+}
+
+// servletHandlerMethods Servlet / Filter / Listener 中处理请求的方法名
+var servletHandlerMethods = map[string]bool{
+	"doGet": true, "doPost": true, "doPut": true, "doDelete": true, "doHead": true,
+	"doOptions": true, "doTrace": true, "doPatch": true, "doGeneric": true, "service": true,
+	"doFilter": true, "contextInitialized": true, "requestInitialized": true, "sessionCreated": true,
+}
+
+// ListEntryPoints 列出索引中的全部入口节点，并补充实现类中处理请求的方法
+func ListEntryPoints(query *QueryEngine) []EntryPoint {
+	classesByName := make(map[string][]string)
+	handlersByClass := make(map[string][]string)
+	for id, node := range query.index.index {
+		switch node.Type {
+		case "Class":
+			for _, name := range classLookupNames(node) {
+				classesByName[name] = append(classesByName[name], id)
+			}
+		case "Method":
+			if servletHandlerMethods[node.Name] {
+				handlersByClass[node.Metadata["classID"]] = append(handlersByClass[node.Metadata["classID"]], node.Name)
+			}
+		}
+	}
+
+	var entries []EntryPoint
+	for _, node := range query.index.index {
+		if node.Type != "EntryPoint" {
+			continue
+		}
+		entry := EntryPoint{
+			Kind:          node.Metadata["kind"],
+			Source:        node.Metadata["source"],
+			Name:          node.Name,
+			Class:         node.Metadata["className"],
+			URLPatterns:   splitNonEmpty(node.Metadata["urlPatterns"]),
+			ResourceTypes: splitNonEmpty(node.Metadata["resourceTypes"]),
+			Methods:       splitNonEmpty(node.Metadata["methods"]),
+			File:          node.File,
+		}
+		if node.Language != "xml" {
+			entry.Line = node.StartLine + 1
+		}
+
+		// web.xml 中声明的类通过全限定名关联到类节点
+		classIDs := classesByName[entry.Class]
+		if classID := node.Metadata["classID"]; classID != "" {
+			classIDs = []string{classID}
+		}
+		for _, classID := range classIDs {
+			entry.Handlers = append(entry.Handlers, handlersByClass[classID]...)
+		}
+		sort.Strings(entry.Handlers)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		return a.File < b.File
+	})
+	return entries
+}
+
+// splitNonEmpty 按逗号拆分，丢弃空元素
+func splitNonEmpty(value string) []string {
+	return trimAll(strings.Split(value, ","))
+}
+
+// FormatEntryPoints 格式化入口列表，keyword 非空时只保留类名、名称或路径中包含关键字的入口
+func FormatEntryPoints(entries []EntryPoint, keyword string) string {
+	var builder strings.Builder
+	count := 0
+	for _, entry := range entries {
+		if keyword != "" && !strings.Contains(entry.Class, keyword) && !strings.Contains(entry.Name, keyword) &&
+			!strings.Contains(strings.Join(entry.URLPatterns, ","), keyword) &&
+			!strings.Contains(strings.Join(entry.ResourceTypes, ","), keyword) {
+			continue
+		}
+		count++
+		builder.WriteString(fmt.Sprintf("%d. [%s] %s (%s)\n", count, entry.Kind, entry.Class, entry.Source))
+		if entry.Name != "" && entry.Name != ShortClassName(entry.Class) {
+			builder.WriteString(fmt.Sprintf("   名称: %s\n", entry.Name))
+		}
+		if len(entry.URLPatterns) > 0 {
+			builder.WriteString(fmt.Sprintf("   路径: %s\n", strings.Join(entry.URLPatterns, ", ")))
+		}
+		if len(entry.ResourceTypes) > 0 {
+			builder.WriteString(fmt.Sprintf("   资源类型: %s\n", strings.Join(entry.ResourceTypes, ", ")))
+		}
+		if len(entry.Methods) > 0 {
+			builder.WriteString(fmt.Sprintf("   HTTP 方法: %s\n", strings.Join(entry.Methods, ", ")))
+		}
+		if len(entry.Handlers) > 0 {
+			builder.WriteString(fmt.Sprintf("   处理方法: %s\n", strings.Join(entry.Handlers, ", ")))
+		}
+		if entry.Line > 0 {
+			builder.WriteString(fmt.Sprintf("   位置: %s:%d\n", filepath.Base(entry.File), entry.Line))
+		} else {
+			builder.WriteString(fmt.Sprintf("   位置: %s\n", entry.File))
+		}
+	}
+	if count == 0 {
+		return "未找到 Servlet 入口"
+	}
+	return fmt.Sprintf("共 %d 个入口:\n", count) + builder.String()
+}
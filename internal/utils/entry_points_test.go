@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListEntryPoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // "类型 来源 实现类 路径 处理方法"
+	}{
+		{
+			name: "web.xml",
+			files: map[string]string{
+				"WEB-INF/web.xml": `<?xml version="1.0"?>
+<web-app xmlns="http://xmlns.jcp.org/xml/ns/javaee">
+  <servlet>
+    <servlet-name>login</servlet-name>
+    <servlet-class>app.LoginServlet</servlet-class>
+  </servlet>
+  <servlet-mapping>
+    <servlet-name>login</servlet-name>
+    <url-pattern>/login</url-pattern>
+    <url-pattern>/signin</url-pattern>
+  </servlet-mapping>
+  <filter>
+    <filter-name>auth</filter-name>
+    <filter-class>app.AuthFilter</filter-class>
+  </filter>
+  <filter-mapping>
+    <filter-name>auth</filter-name>
+    <servlet-name>login</servlet-name>
+  </filter-mapping>
+  <listener>
+    <listener-class>app.StartupListener</listener-class>
+  </listener>
+</web-app>
+`,
+				"app/LoginServlet.java": `package app;
+
+public class LoginServlet extends HttpServlet {
+    protected void doPost(HttpServletRequest req, HttpServletResponse resp) {}
+    protected void doGet(HttpServletRequest req, HttpServletResponse resp) {}
+    private void helper() {}
+}
+`,
+			},
+			want: []string{
+				"filter web.xml app.AuthFilter servlet:login ",
+				"listener web.xml app.StartupListener  ",
+				"servlet web.xml app.LoginServlet /login,/signin doGet,doPost",
+			},
+		},
+		{
+			name: "Servlet 3.0 注解",
+			files: map[string]string{
+				"app/Api.java": `package app;
+
+@WebServlet(name = "api", urlPatterns = {"/api/*", "/v1/*"})
+public class Api extends HttpServlet {
+    protected void service(HttpServletRequest req, HttpServletResponse resp) {}
+}
+`,
+				"app/Cors.java": `package app;
+
+@WebFilter("/*")
+public class Cors implements Filter {
+    public void doFilter(ServletRequest req, ServletResponse resp, FilterChain chain) {}
+}
+`,
+			},
+			want: []string{
+				"filter @WebFilter app.Cors /* doFilter",
+				"servlet @WebServlet app.Api /api/*,/v1/* service",
+			},
+		},
+		{
+			name: "Sling Servlet",
+			files: map[string]string{
+				"app/Search.java": `package app;
+
+@Component(service = Servlet.class, property = {
+    "sling.servlet.resourceTypes=app/search",
+    "sling.servlet.methods=GET"
+})
+public class Search extends SlingSafeMethodsServlet {
+    protected void doGet(SlingHttpServletRequest req, SlingHttpServletResponse resp) {}
+}
+`,
+				"app/Upload.java": `package app;
+
+@SlingServletPaths("/bin/upload")
+public class Upload extends SlingAllMethodsServlet {
+    protected void doPost(SlingHttpServletRequest req, SlingHttpServletResponse resp) {}
+}
+`,
+			},
+			want: []string{
+				"servlet @Component app.Search  doGet",
+				"servlet @SlingServletPaths app.Upload /bin/upload doPost",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := NewQueryEngine(indexSources(t, tt.files, &JavaParser{}, &WebXMLParser{}))
+			got := []string{}
+			for _, e := range ListEntryPoints(query) {
+				got = append(got, strings.Join([]string{e.Kind, e.Source, e.Class, strings.Join(e.URLPatterns, ","), strings.Join(e.Handlers, ",")}, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListEntryPoints =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
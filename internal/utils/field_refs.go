@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BuildFieldReferences 将 FieldAccess 节点关联到字段节点：
+// 字段访问节点添加 reads / writes 关系，字段节点添加 read_at / written_at 关系。
+// 字段所属类型是项目外的类（如 System.out）时删除该访问节点，接收者类型未知的访问节点保留以便按字段名查找
+func BuildFieldReferences(m *ParserManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	classesByName := make(map[string][]string)
+	fieldsByClass := make(map[string]map[string]string) // 类节点ID -> 字段名 -> 字段节点ID
+	for id, node := range m.index.index {
+		switch node.Type {
+		case "Class":
+			for _, name := range classLookupNames(node) {
+				classesByName[name] = append(classesByName[name], id)
+			}
+		case "Field":
+			classID := node.Metadata["classID"]
+			if fieldsByClass[classID] == nil {
+				fieldsByClass[classID] = make(map[string]string)
+			}
+			fieldsByClass[classID][node.Name] = id
+		}
+	}
+
+	// findField 在类及其父类链中查找字段声明
+	var findField func(classID, name string, visited map[string]bool) string
+	findField = func(classID, name string, visited map[string]bool) string {
+		if visited[classID] {
+			return ""
+		}
+		visited[classID] = true
+		if fieldID, ok := fieldsByClass[classID][name]; ok {
+			return fieldID
+		}
+		for _, super := range m.index.index[classID].SuperClasses {
+			for _, superID := range classesByName[super.FullName()] {
+				if fieldID := findField(superID, name, visited); fieldID != "" {
+					return fieldID
+				}
+			}
+		}
+		return ""
+	}
+
+	for id, access := range m.index.index {
+		if access.Type != "FieldAccess" {
+			continue
+		}
+		ownerType := access.Metadata["ownerType"]
+		if ownerType == "" {
+			continue
+		}
+
+		classIDs := classesByName[ownerType]
+		if len(classIDs) == 0 {
+			// import * 推断的包名可能不准确，退回到访问方所在包
+			classIDs = classesByName[access.Package+"."+ShortClassName(ownerType)]
+		}
+		fieldID := ""
+		for _, classID := range classIDs {
+			if fieldID = findField(classID, access.Name, make(map[string]bool)); fieldID != "" {
+				break
+			}
+		}
+		if fieldID == "" {
+			delete(m.index.index, id)
+			continue
+		}
+
+		field := m.index.index[fieldID]
+		if access.Metadata["access"] == "write" {
+			access.AddRelation(fieldID, "writes")
+			field.AddRelation(id, "written_at")
+		} else {
+			access.AddRelation(fieldID, "reads")
+			field.AddRelation(id, "read_at")
+		}
+		m.index.index[fieldID] = field
+		m.index.index[id] = access
+	}
+}
+
+// FindFieldReferences 列出字段的声明及所有读写位置。className 为空表示全部类，
+// fieldName 为 * 结尾时按前缀匹配；接收者类型无法推断的同名访问会单独列出
+func FindFieldReferences(query *QueryEngine, className, fieldName string) (string, error) {
+	if fieldName == "" {
+		return "", fmt.Errorf("fieldName is required")
+	}
+
+	var fields []UniversalASTNode
+	for _, node := range query.index.index {
+		if node.Type != "Field" || !matchName(node.Name, fieldName) {
+			continue
+		}
+		if className != "" {
+			class, ok := query.index.GetNode(node.Metadata["classID"])
+			if !ok || !IsMatchingClass(class, className) {
+				continue
+			}
+		}
+		fields = append(fields, node)
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("未找到字段: %s %s", className, fieldName)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Metadata["className"] != fields[j].Metadata["className"] {
+			return fields[i].Metadata["className"] < fields[j].Metadata["className"]
+		}
+		return fields[i].Name < fields[j].Name
+	})
+
+	var builder strings.Builder
+	for _, field := range fields {
+		builder.WriteString(fmt.Sprintf("==== 字段 %s#%s (Type: %s, %s:%d) ====\n",
+			field.Metadata["className"], field.Name, field.Metadata["fieldType"], filepath.Base(field.File), field.StartLine+1))
+
+		var writes, reads []UniversalASTNode
+		for _, rel := range field.Relations {
+			access, ok := query.index.GetNode(rel.TargetID)
+			if !ok {
+				continue
+			}
+			if rel.Type == "written_at" {
+				writes = append(writes, access)
+			} else if rel.Type == "read_at" {
+				reads = append(reads, access)
+			}
+		}
+		writeFieldAccesses(&builder, query, "写入", writes)
+		writeFieldAccesses(&builder, query, "读取", reads)
+		builder.WriteString("\n")
+	}
+
+	// 接收者类型未知时无法确定字段归属，按字段名列出供人工确认
+	var unresolved []UniversalASTNode
+	for _, node := range query.index.index {
+		if node.Type == "FieldAccess" && node.Metadata["ownerType"] == "" && matchName(node.Name, fieldName) {
+			unresolved = append(unresolved, node)
+		}
+	}
+	if len(unresolved) > 0 {
+		builder.WriteString("==== 接收者类型未知的同名字段访问 ====\n")
+		writeFieldAccesses(&builder, query, "访问", unresolved)
+	}
+	return builder.String(), nil
+}
+
+// writeFieldAccesses 按文件和行号输出字段访问位置及所在方法
+func writeFieldAccesses(builder *strings.Builder, query *QueryEngine, label string, accesses []UniversalASTNode) {
+	sort.Slice(accesses, func(i, j int) bool {
+		if accesses[i].File != accesses[j].File {
+			return accesses[i].File < accesses[j].File
+		}
+		return accesses[i].StartLine < accesses[j].StartLine
+	})
+	builder.WriteString(fmt.Sprintf("%s (%d 处):\n", label, len(accesses)))
+	for _, access := range accesses {
+		location := access.Metadata["callerClass"]
+		if caller, ok := query.index.GetNode(access.Metadata["callerID"]); ok {
+			location = describeMethod(caller)
+		} else if location != "" {
+			location += " 字段初始化"
+		}
+		builder.WriteString(fmt.Sprintf("  - %s (%s:%d)\n", location, filepath.Base(access.File), access.StartLine+1))
+		// 节点行号从 0 开始，GetCodeSnippet 按从 1 开始的行号读取
+		line := access
+		line.StartLine++
+		line.EndLine = line.StartLine
+		if code, err := query.GetCodeSnippet(line, 0); err == nil {
+			builder.WriteString(fmt.Sprintf("    %s\n", strings.TrimSpace(code)))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBuildFieldReferences(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/Account.java": `package app;
+
+public class Account {
+    protected String owner;
+    private int balance = 0;
+
+    void deposit(int amount) {
+        balance += amount;
+        System.out.println(owner);
+    }
+
+    void rename(String owner) {
+        this.owner = owner;
+    }
+
+    int total() {
+        return balance;
+    }
+}
+`,
+		"app/Audit.java": `package app;
+
+public class Audit {
+    void check(Account account) {
+        account.owner = "audit";
+        String owner = "local";
+        Runnable r = () -> System.out.println(owner);
+    }
+}
+`,
+	}, &JavaParser{})
+
+	// accesses 返回字段节点某类关系指向的访问所在方法，格式为 "方法名:read|write"
+	accesses := func(className, field string) []string {
+		result := []string{}
+		for _, node := range index.index {
+			if node.Type != "Field" || node.Name != field || node.Metadata["className"] != className {
+				continue
+			}
+			for _, rel := range node.Relations {
+				access := index.index[rel.TargetID]
+				caller := index.index[access.Metadata["callerID"]]
+				result = append(result, caller.Name+":"+access.Metadata["access"])
+			}
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	tests := []struct {
+		className string
+		field     string
+		want      []string
+	}{
+		// 形参与局部变量遮蔽的同名标识符不算字段访问
+		{"app.Account", "owner", []string{"check:write", "deposit:read", "rename:write"}},
+		{"app.Account", "balance", []string{"deposit:write", "total:read"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.className+"#"+tt.field, func(t *testing.T) {
+			if got := accesses(tt.className, tt.field); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("字段访问 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 项目外类的字段（System.out）不保留访问节点
+	for _, node := range index.index {
+		if node.Type == "FieldAccess" && node.Name == "out" {
+			t.Errorf("项目外类的字段访问不应保留: %+v", node)
+		}
+	}
+}
+
+func TestFindFieldReferences(t *testing.T) {
+	query := NewQueryEngine(indexSources(t, map[string]string{
+		"app/Config.java": `package app;
+
+public class Config {
+    private String token;
+
+    void setToken(String value) { token = value; }
+
+    String getToken() { return token; }
+}
+`,
+	}, &JavaParser{}))
+
+	tests := []struct {
+		name      string
+		className string
+		fieldName string
+		want      []string
+		wantErr   bool
+	}{
+		{"按前缀", "Config", "tok*", []string{"==== 字段 app.Config#token", "写入 (1 处)", "app.Config#setToken(String) (Config.java:6)", "读取 (1 处)", "app.Config#getToken() (Config.java:8)"}, false},
+		{"字段不存在", "Config", "secret", nil, true},
+		{"缺少字段名", "Config", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindFieldReferences(query, tt.className, tt.fieldName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindFieldReferences err = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("结果缺少 %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
@@ -5,83 +5,831 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
-// GoParser 实现 Go 语言的 AST 解析
+// GoParser 实现 Go 语言的 AST 解析，节点的组织方式与 JavaParser 一致：
+// 包的导入路径（最近的 go.mod 中的模块路径加相对目录，如 example.com/app/handler）记录为 Package，
+// 具名类型（struct、interface 等）记录为 Class，类名为 导入路径.类型名，结构体字段记录为 Fields，嵌入的类型记为父类；
+// 带接收者的方法记录为 Method 并归属接收者类型，包级函数记录为 Function（className 为导入路径）
 type GoParser struct{}
 
+// goScope 记录遍历到当前节点时所处的类型和函数上下文，用于推断调用的接收者类型
+type goScope struct {
+	className string            // 方法接收者类型全限定名，包级函数中为空
+	receiver  string            // 方法接收者变量名
+	methodID  string            // 所在函数节点ID
+	varTypes  map[string]string // 参数及局部变量名 -> 类型全限定名（未知时为空）
+	varCalls  map[string]string // 由函数调用赋值的局部变量名 -> 调用节点ID，类型在建立索引后按返回类型推断
+}
+
+// goFile 解析单个文件时共享的上下文
+type goFile struct {
+	path        string
+	code        []byte
+	fset        *token.FileSet
+	packagePath string
+	imports     map[string]string            // 导入名 -> 导入路径
+	declared    map[string]string            // 文件中声明的类型名 -> 类节点ID
+	fieldTypes  map[string]map[string]string // 文件中声明的类型全限定名 -> 字段名 -> 类型全限定名
+	globals     map[string]string            // 包级变量名 -> 类型全限定名
+}
+
+// goBuiltins 内置函数与基本类型（类型转换），调用时接收者类型记为 builtin
+var goBuiltins = map[string]bool{
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true, "copy": true,
+	"delete": true, "imag": true, "len": true, "make": true, "max": true, "min": true, "new": true,
+	"panic": true, "print": true, "println": true, "real": true, "recover": true,
+}
+
+// goBasicTypes 预声明类型，不参与类型推断
+var goBasicTypes = map[string]bool{
+	"bool": true, "string": true, "error": true, "any": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
 func (p *GoParser) Language() string {
 	return "go"
 }
 
 func (p *GoParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
 	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filePath, nil, parser.AllErrors)
+	file, err := parser.ParseFile(fset, filePath, code, parser.AllErrors)
 	if err != nil {
 		return nil, err
 	}
 
+	f := &goFile{
+		path:        filePath,
+		code:        code,
+		fset:        fset,
+		packagePath: goPackagePath(filePath, file.Name.Name),
+		imports:     p.collectImports(file),
+		declared:    make(map[string]string),
+		fieldTypes:  make(map[string]map[string]string),
+		globals:     make(map[string]string),
+	}
+
+	// 先处理类型声明和包级变量，方法可能写在类型声明之前
 	var nodes []UniversalASTNode
-	packageName := file.Name.Name
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				nodes = append(nodes, p.typeNodes(genDecl, spec, f)...)
+			case *ast.ValueSpec:
+				if genDecl.Tok != token.VAR {
+					continue
+				}
+				for i, name := range spec.Names {
+					if spec.Type != nil {
+						f.globals[name.Name] = p.resolveType(spec.Type, f)
+					} else if i < len(spec.Values) {
+						f.globals[name.Name] = p.expressionType(spec.Values[i], f, &goScope{})
+					}
+				}
+			}
+		}
+	}
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			nodes = append(nodes, p.funcNodes(funcDecl, f)...)
+		}
+	}
+	return nodes, nil
+}
 
-	// 遍历 AST 节点
-	ast.Inspect(file, func(n ast.Node) bool {
-		if n == nil {
-			return false
+// goPackagePath 返回文件所在包的导入路径：最近的 go.mod 中的模块路径加相对目录，vendor 目录中的包取 vendor/ 之后的路径，
+// 找不到 go.mod 时为包名
+func goPackagePath(filePath, packageName string) string {
+	dir := filepath.Dir(filePath)
+	for current := dir; ; current = filepath.Dir(current) {
+		if module := goModulePath(filepath.Join(current, "go.mod")); module != "" {
+			rel, err := filepath.Rel(current, dir)
+			if err != nil || rel == "." {
+				return module
+			}
+			parts := strings.Split(filepath.ToSlash(rel), "/")
+			for i := len(parts) - 1; i >= 0; i-- {
+				if parts[i] == "vendor" && i < len(parts)-1 {
+					return strings.Join(parts[i+1:], "/")
+				}
+			}
+			return module + "/" + strings.Join(parts, "/")
+		}
+		if filepath.Dir(current) == current {
+			break
 		}
+	}
+	return packageName
+}
 
-		pos := fset.Position(n.Pos())
-		endPos := fset.Position(n.End())
-		id := fmt.Sprintf("%s:%d:%d", filePath, pos.Line, pos.Column)
+// goModulePath 读取 go.mod 中声明的模块路径，文件不存在时返回空
+func goModulePath(goMod string) string {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
 
-		switch node := n.(type) {
-		case *ast.FuncDecl:
-			if node.Name != nil {
-				nodes = append(nodes, UniversalASTNode{
-					ID:        id,
-					Language:  "go",
-					Type:      "Function",
-					Name:      node.Name.Name,
-					File:      filePath,
-					Package:   packageName,
-					StartLine: pos.Line,
-					EndLine:   endPos.Line,
-					Metadata:  map[string]string{},
-				})
+// collectImports 收集 import 列表，返回 导入名 -> 导入路径，未指定别名时按导入路径推断包名；点导入和空白导入不记录
+func (p *GoParser) collectImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := goImportName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			imports[name] = path
+		}
+	}
+	return imports
+}
+
+// goImportName 按惯例由导入路径推断包名：github.com/foo/bar/v2 -> bar，gopkg.in/yaml.v3 -> yaml，github.com/foo/go-bar -> bar
+func goImportName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if idx := strings.Index(name, ".v"); idx > 0 {
+		name = name[:idx]
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+}
+
+// resolveType 将类型表达式解析为全限定名（导入路径.类型名），指针和泛型实例化取基础类型，
+// 预声明类型以及切片、map、函数等复合类型返回空
+func (p *GoParser) resolveType(expr ast.Expr, f *goFile) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if goBasicTypes[expr.Name] {
+			return ""
+		}
+		return joinDotted(f.packagePath, expr.Name)
+	case *ast.StarExpr:
+		return p.resolveType(expr.X, f)
+	case *ast.ParenExpr:
+		return p.resolveType(expr.X, f)
+	case *ast.IndexExpr:
+		return p.resolveType(expr.X, f)
+	case *ast.IndexListExpr:
+		return p.resolveType(expr.X, f)
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok && f.imports[pkg.Name] != "" {
+			return f.imports[pkg.Name] + "." + expr.Sel.Name
+		}
+	}
+	return ""
+}
+
+// position 返回节点的起止行号（从 0 开始）
+func (f *goFile) position(start, end token.Pos) (int, int) {
+	return f.fset.Position(start).Line - 1, f.fset.Position(end).Line - 1
+}
+
+// source 返回节点对应的源码
+func (f *goFile) source(node ast.Node) string {
+	start, end := f.fset.Position(node.Pos()).Offset, f.fset.Position(node.End()).Offset
+	if start < 0 || end > len(f.code) || start > end {
+		return types.ExprString(node.(ast.Expr))
+	}
+	return string(f.code[start:end])
+}
+
+// typeNodes 为类型声明创建类节点及其字段节点、接口方法节点：
+// 结构体中嵌入的类型和接口中嵌入的接口记为父类，类型实现的接口在建立索引后由 LinkGoImplementations 补全
+func (p *GoParser) typeNodes(genDecl *ast.GenDecl, spec *ast.TypeSpec, f *goFile) []UniversalASTNode {
+	name := spec.Name.Name
+	id := fmt.Sprintf("%s:%s:%d", f.path, name, f.fset.Position(spec.Pos()).Offset)
+	fullName := joinDotted(f.packagePath, name)
+	f.declared[name] = id
+
+	kind := "type"
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		kind = "struct"
+	case *ast.InterfaceType:
+		kind = "interface"
+	case *ast.FuncType:
+		kind = "func"
+	}
+	if spec.Assign.IsValid() {
+		kind = "alias"
+	}
+
+	// 未分组的声明从 type 关键字开始
+	start := spec.Pos()
+	if !genDecl.Lparen.IsValid() {
+		start = genDecl.Pos()
+	}
+	startLine, endLine := f.position(start, spec.End())
+	classNode := UniversalASTNode{
+		ID:         id,
+		Language:   "go",
+		Type:       "Class",
+		Name:       name,
+		File:       f.path,
+		Package:    f.packagePath,
+		StartLine:  startLine,
+		EndLine:    endLine,
+		Fields:     make([]FieldInfo, 0),
+		Metadata:   map[string]string{"kind": kind, "underlyingType": types.ExprString(spec.Type)},
+		TypeParams: p.collectTypeParams(spec.TypeParams),
+	}
+
+	var nodes []UniversalASTNode
+	var superFullNames []string
+	addSuper := func(expr ast.Expr) {
+		if fq := p.resolveType(expr, f); fq != "" {
+			superFullNames = append(superFullNames, fq)
+			pkg, short := "", fq
+			if idx := strings.LastIndex(fq, "."); idx != -1 {
+				pkg, short = fq[:idx], fq[idx+1:]
 			}
+			classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: short})
+		}
+	}
 
-		case *ast.TypeSpec:
-			if node.Name != nil {
+	switch typeExpr := spec.Type.(type) {
+	case *ast.StructType:
+		f.fieldTypes[fullName] = make(map[string]string)
+		for _, field := range typeExpr.Fields.List {
+			fieldStart, fieldEnd := f.position(field.Pos(), field.End())
+			metadata := map[string]string{}
+			if field.Tag != nil {
+				if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+					metadata["tag"] = tag
+				}
+			}
+			names := make([]string, 0, len(field.Names))
+			for _, fieldName := range field.Names {
+				names = append(names, fieldName.Name)
+			}
+			// 嵌入字段的字段名为类型名，其方法和字段被提升到外层类型
+			if len(names) == 0 {
+				addSuper(field.Type)
+				metadata["embedded"] = "true"
+				names = append(names, ShortClassName(types.ExprString(goBaseType(field.Type))))
+			}
+			fieldType := types.ExprString(field.Type)
+			fullType := p.resolveType(field.Type, f)
+			for _, fieldName := range names {
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:      fieldName,
+					Type:      fieldType,
+					StartLine: fieldStart,
+					EndLine:   fieldEnd,
+					Metadata:  metadata,
+				})
+				f.fieldTypes[fullName][fieldName] = fullType
 				nodes = append(nodes, UniversalASTNode{
-					ID:        id,
+					ID:        fmt.Sprintf("%s:%s.%s:%d", f.path, name, fieldName, fieldStart),
 					Language:  "go",
-					Type:      "Type",
-					Name:      node.Name.Name,
-					File:      filePath,
-					Package:   packageName,
-					StartLine: pos.Line,
-					EndLine:   endPos.Line,
-					Metadata:  map[string]string{},
+					Type:      "Field",
+					Name:      fieldName,
+					File:      f.path,
+					Package:   f.packagePath,
+					StartLine: fieldStart,
+					EndLine:   fieldEnd,
+					Metadata: map[string]string{
+						"fieldType": fieldType,
+						"fullType":  fullType,
+						"classID":   id,
+						"className": fullName,
+					},
 				})
 			}
+		}
+	case *ast.InterfaceType:
+		for _, method := range typeExpr.Methods.List {
+			funcType, ok := method.Type.(*ast.FuncType)
+			if !ok {
+				// 嵌入的接口，类型约束中的 ~int | string 等解析为空
+				addSuper(method.Type)
+				continue
+			}
+			for _, methodName := range method.Names {
+				methodID := fmt.Sprintf("%s:%s:%d", f.path, methodName.Name, f.fset.Position(methodName.Pos()).Offset)
+				nodes = append(nodes, p.functionNode(methodID, methodName.Name, "Method", fullName, id, funcType, method, f))
+			}
+		}
+	}
+	classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+	return append([]UniversalASTNode{classNode}, nodes...)
+}
+
+// goBaseType 去掉指针和泛型实例化，返回类型表达式的基础类型
+func goBaseType(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
+
+// collectTypeParams 收集泛型类型参数，如 T comparable
+func (p *GoParser) collectTypeParams(fields *ast.FieldList) []string {
+	var typeParams []string
+	if fields == nil {
+		return typeParams
+	}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			typeParams = append(typeParams, name.Name+" "+types.ExprString(field.Type))
+		}
+	}
+	return typeParams
+}
+
+// funcNodes 为函数声明创建节点，并收集函数体中的调用：
+// 带接收者的方法归属接收者类型，类型声明在其他文件中时 classID 留空，建立索引后按 className 补全
+func (p *GoParser) funcNodes(decl *ast.FuncDecl, f *goFile) []UniversalASTNode {
+	name := decl.Name.Name
+	id := fmt.Sprintf("%s:%s:%d", f.path, name, f.fset.Position(decl.Pos()).Offset)
+	scope := &goScope{
+		methodID: id,
+		varTypes: make(map[string]string),
+		varCalls: make(map[string]string),
+	}
 
+	var funcNode UniversalASTNode
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		recv := decl.Recv.List[0]
+		typeName := types.ExprString(goBaseType(recv.Type))
+		scope.className = joinDotted(f.packagePath, typeName)
+		funcNode = p.functionNode(id, name, "Method", scope.className, f.declared[typeName], decl.Type, decl, f)
+		funcNode.Metadata["receiver"] = types.ExprString(recv.Type)
+		if len(recv.Names) > 0 && recv.Names[0].Name != "_" {
+			scope.receiver = recv.Names[0].Name
+			scope.varTypes[scope.receiver] = scope.className
+		}
+	} else {
+		funcNode = p.functionNode(id, name, "Function", f.packagePath, "", decl.Type, decl, f)
+	}
+	for _, field := range decl.Type.Params.List {
+		for _, ident := range field.Names {
+			p.bindVar(ident.Name, field.Type, nil, f, scope)
+		}
+	}
+
+	nodes := []UniversalASTNode{funcNode}
+	if decl.Body != nil {
+		nodes = append(nodes, p.collectCalls(decl.Body, f, scope)...)
+	}
+	return nodes
+}
+
+// functionNode 创建 Function 或 Method 节点：可变参数的类型记为 T...，返回类型取第一个返回值推断全限定名
+func (p *GoParser) functionNode(id, name, nodeType, owner, classID string, funcType *ast.FuncType, node ast.Node, f *goFile) UniversalASTNode {
+	var params []ParamInfo
+	var methodParams []string
+	for _, field := range funcType.Params.List {
+		paramType := types.ExprString(field.Type)
+		ellipsis, variadic := field.Type.(*ast.Ellipsis)
+		if variadic {
+			paramType = types.ExprString(ellipsis.Elt) + "..."
+		}
+		if len(field.Names) == 0 {
+			params = append(params, ParamInfo{Type: paramType, Variadic: variadic})
+			methodParams = append(methodParams, paramType)
+			continue
+		}
+		for _, paramName := range field.Names {
+			params = append(params, ParamInfo{Name: paramName.Name, Type: paramType, Variadic: variadic})
+			methodParams = append(methodParams, paramType)
+		}
+	}
+
+	returnType, returnFullType := "", ""
+	if funcType.Results != nil && len(funcType.Results.List) > 0 {
+		var results []string
+		for _, field := range funcType.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, types.ExprString(field.Type))
+			}
+		}
+		returnType = strings.Join(results, ", ")
+		if len(results) > 1 {
+			returnType = "(" + returnType + ")"
+		}
+		returnFullType = p.resolveType(funcType.Results.List[0].Type, f)
+	}
+
+	startLine, endLine := f.position(node.Pos(), node.End())
+	return UniversalASTNode{
+		ID:           id,
+		Language:     "go",
+		Type:         nodeType,
+		Name:         name,
+		File:         f.path,
+		Package:      f.packagePath,
+		StartLine:    startLine,
+		EndLine:      endLine,
+		MethodParams: methodParams,
+		Params:       params,
+		TypeParams:   p.collectTypeParams(funcType.TypeParams),
+		Metadata: map[string]string{
+			"returnType":     returnType,
+			"returnFullType": returnFullType,
+			"classID":        classID,
+			"className":      owner,
+		},
+	}
+}
+
+// collectCalls 遍历函数体，记录局部变量的类型并为每个调用创建 MethodCall 节点，函数字面量中的调用归属外层函数
+func (p *GoParser) collectCalls(body *ast.BlockStmt, f *goFile, scope *goScope) []UniversalASTNode {
+	var nodes []UniversalASTNode
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || ident.Name == "_" {
+					continue
+				}
+				// x := expr 按表达式推断类型；v, err := f() 中第一个变量为调用的返回值
+				var value ast.Expr
+				if len(node.Rhs) == len(node.Lhs) {
+					value = node.Rhs[i]
+				} else if i == 0 && len(node.Rhs) == 1 {
+					value = node.Rhs[0]
+				}
+				p.bindVar(ident.Name, nil, value, f, scope)
+			}
+		case *ast.ValueSpec:
+			for i, ident := range node.Names {
+				var value ast.Expr
+				if i < len(node.Values) {
+					value = node.Values[i]
+				}
+				p.bindVar(ident.Name, node.Type, value, f, scope)
+			}
+		case *ast.RangeStmt:
+			for _, expr := range []ast.Expr{node.Key, node.Value} {
+				if ident, ok := expr.(*ast.Ident); ok {
+					p.bindVar(ident.Name, nil, nil, f, scope)
+				}
+			}
+		case *ast.FuncLit:
+			for _, field := range node.Type.Params.List {
+				for _, ident := range field.Names {
+					p.bindVar(ident.Name, field.Type, nil, f, scope)
+				}
+			}
 		case *ast.CallExpr:
-			if ident, ok := node.Fun.(*ast.Ident); ok {
-				nodes = append(nodes, UniversalASTNode{
-					ID:        id,
-					Language:  "go",
-					Type:      "FunctionCall",
-					Name:      ident.Name,
-					File:      filePath,
-					Package:   packageName,
-					StartLine: pos.Line,
-					EndLine:   endPos.Line,
-					Metadata:  map[string]string{},
-				})
+			if callNode, ok := p.callNode(node, f, scope); ok {
+				nodes = append(nodes, callNode)
 			}
 		}
 		return true
 	})
+	return nodes
+}
 
-	return nodes, nil
+// bindVar 记录局部变量的类型：优先使用声明的类型，其次按初始值推断，初始值为函数调用时记录调用节点ID
+func (p *GoParser) bindVar(name string, typeExpr, value ast.Expr, f *goFile, scope *goScope) {
+	if name == "_" {
+		return
+	}
+	delete(scope.varCalls, name)
+	switch {
+	case typeExpr != nil:
+		scope.varTypes[name] = p.resolveType(typeExpr, f)
+	case value != nil:
+		scope.varTypes[name] = p.expressionType(value, f, scope)
+		if call, ok := ast.Unparen(value).(*ast.CallExpr); ok && scope.varTypes[name] == "" {
+			if callID := p.callID(call, f); callID != "" {
+				scope.varCalls[name] = callID
+			}
+		}
+	default:
+		scope.varTypes[name] = ""
+	}
+}
+
+// goCalleeName 返回调用的函数名或方法名，泛型实例化 F[int]() 取函数名
+func goCalleeName(call *ast.CallExpr) string {
+	switch fun := goBaseType(call.Fun).(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// callID 返回调用对应的 MethodCall 节点ID
+func (p *GoParser) callID(call *ast.CallExpr, f *goFile) string {
+	name := goCalleeName(call)
+	if name == "" {
+		return ""
+	}
+	return callNodeID(f.path, name, f.fset.Position(call.Pos()).Offset, f.fset.Position(call.End()).Offset)
+}
+
+// importPath 名称未被局部变量或包级变量遮蔽且为导入名时，返回导入路径
+func (f *goFile) importPath(name string, scope *goScope) string {
+	if _, isGlobal := f.globals[name]; isGlobal || scope.isLocal(name) {
+		return ""
+	}
+	return f.imports[name]
+}
+
+// isLocal 判断名称是否为参数或局部变量
+func (s *goScope) isLocal(name string) bool {
+	_, isVar := s.varTypes[name]
+	_, isCall := s.varCalls[name]
+	return isVar || isCall
+}
+
+// callNode 为函数调用创建 MethodCall 节点：不带限定的调用以当前包为接收者类型，pkg.Func() 以导入路径为接收者类型，
+// 方法调用按变量、字段的类型推断接收者类型，无法推断时记录前一个调用（链式调用、由调用赋值的变量）或接收者的字段名
+func (p *GoParser) callNode(call *ast.CallExpr, f *goFile, scope *goScope) (UniversalASTNode, bool) {
+	name, receiver, receiverType, receiverCallID, receiverField := "", "", "", "", ""
+	switch fun := goBaseType(call.Fun).(type) {
+	case *ast.Ident:
+		name = fun.Name
+		switch {
+		case scope.isLocal(name):
+			// 调用函数类型的变量，无法确定目标
+		case goBuiltins[name] || goBasicTypes[name]:
+			receiverType = "builtin"
+		default:
+			receiverType = f.packagePath
+		}
+	case *ast.SelectorExpr:
+		name, receiver = fun.Sel.Name, f.source(fun.X)
+		object := ast.Unparen(fun.X)
+		if ident, ok := object.(*ast.Ident); ok && f.importPath(ident.Name, scope) != "" {
+			receiverType = f.importPath(ident.Name, scope)
+			break
+		}
+		receiverType = p.expressionType(object, f, scope)
+		if receiverType != "" {
+			break
+		}
+		switch object := object.(type) {
+		case *ast.Ident:
+			receiverCallID = scope.varCalls[object.Name]
+		case *ast.CallExpr:
+			receiverCallID = p.callID(object, f)
+		case *ast.SelectorExpr:
+			// 接收者的字段可能声明在其他文件或嵌入的类型中
+			if ident, ok := object.X.(*ast.Ident); ok && ident.Name == scope.receiver && scope.receiver != "" {
+				receiverField = object.Sel.Name
+			}
+		}
+	default:
+		return UniversalASTNode{}, false
+	}
+
+	arguments := make([]ExprInfo, 0, len(call.Args))
+	for _, arg := range call.Args {
+		arguments = append(arguments, p.exprInfo(arg, f, scope))
+	}
+
+	startLine, endLine := f.position(call.Pos(), call.End())
+	callNode := UniversalASTNode{
+		ID:        p.callID(call, f),
+		Language:  "go",
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      f.path,
+		Package:   f.packagePath,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	if receiverField != "" {
+		callNode.Metadata["receiverField"] = receiverField
+	}
+	return callNode, true
+}
+
+// expressionType 推断表达式的类型：已知类型的变量、包级变量、同一文件中声明的结构体字段、
+// 复合字面量（&T{}）、new(T) 以及类型断言
+func (p *GoParser) expressionType(expr ast.Expr, f *goFile, scope *goScope) string {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if scope.isLocal(expr.Name) {
+			return scope.varTypes[expr.Name]
+		}
+		return f.globals[expr.Name]
+	case *ast.UnaryExpr:
+		if expr.Op == token.AND {
+			return p.expressionType(expr.X, f, scope)
+		}
+	case *ast.StarExpr:
+		return p.expressionType(expr.X, f, scope)
+	case *ast.CompositeLit:
+		if expr.Type != nil {
+			return p.resolveType(expr.Type, f)
+		}
+	case *ast.TypeAssertExpr:
+		if expr.Type != nil {
+			return p.resolveType(expr.Type, f)
+		}
+	case *ast.CallExpr:
+		if ident, ok := expr.Fun.(*ast.Ident); ok && ident.Name == "new" && !scope.isLocal("new") && len(expr.Args) == 1 {
+			return p.resolveType(expr.Args[0], f)
+		}
+	case *ast.SelectorExpr:
+		if ownerType := p.expressionType(expr.X, f, scope); ownerType != "" {
+			return f.fieldTypes[ownerType][expr.Sel.Name]
+		}
+	}
+	return ""
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名，接收者的字段 r.x 记为 this.x，包名限定的标识符不记录
+func (p *GoParser) exprInfo(expr ast.Expr, f *goFile, scope *goScope) ExprInfo {
+	info := ExprInfo{Text: f.source(expr)}
+	var walk func(node ast.Node)
+	walk = func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.Ident:
+			switch node.Name {
+			case "_", "nil", "true", "false", "iota":
+			default:
+				info.Uses = appendUnique(info.Uses, node.Name)
+			}
+			return
+		case *ast.SelectorExpr:
+			// 只看接收者部分，成员名不是变量
+			if ident, ok := node.X.(*ast.Ident); ok {
+				switch {
+				case scope.receiver != "" && ident.Name == scope.receiver:
+					info.Uses = appendUnique(info.Uses, "this."+node.Sel.Name)
+				case f.importPath(ident.Name, scope) == "":
+					info.Uses = appendUnique(info.Uses, ident.Name)
+				}
+				return
+			}
+			walk(node.X)
+			return
+		case *ast.CallExpr:
+			// 函数名不是变量
+			if _, ok := node.Fun.(*ast.Ident); !ok {
+				walk(node.Fun)
+			}
+			for _, arg := range node.Args {
+				walk(arg)
+			}
+			return
+		case *ast.CompositeLit:
+			// 结构体字面量的字段名不是变量
+			for _, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if _, isIdent := kv.Key.(*ast.Ident); isIdent {
+						walk(kv.Value)
+						continue
+					}
+				}
+				walk(elt)
+			}
+			return
+		}
+		ast.Inspect(node, func(child ast.Node) bool {
+			if child == nil || child == node {
+				return child == node
+			}
+			walk(child)
+			return false
+		})
+	}
+	walk(expr)
+	return info
+}
+
+// LinkGoImplementations 按方法集判断 Go 类型实现了哪些接口（方法名与参数个数均匹配，嵌入类型的方法一并计入），
+// 将接口记入类型的 SuperClasses，随后由 FillSubClasses 反向填充接口的 SubClasses，调用图据此找到接口方法的实现
+func LinkGoImplementations(m *ParserManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	classes := make(map[string]string)         // 类型全限定名 -> 类节点ID
+	methods := make(map[string]map[string]int) // 类型全限定名 -> 方法名 -> 参数个数
+	for id, node := range m.index.index {
+		if node.Language != "go" {
+			continue
+		}
+		switch node.Type {
+		case "Class":
+			classes[node.FullClassName] = id
+		case "Method":
+			className := node.Metadata["className"]
+			if methods[className] == nil {
+				methods[className] = make(map[string]int)
+			}
+			methods[className][node.Name] = len(node.MethodParams)
+		}
+	}
+
+	// 完整方法集包括嵌入的类型（父类）提升上来的方法，外层类型的同名方法优先
+	var collect func(typeName string, set map[string]int, visited map[string]bool)
+	collect = func(typeName string, set map[string]int, visited map[string]bool) {
+		if visited[typeName] {
+			return
+		}
+		visited[typeName] = true
+		for name, arity := range methods[typeName] {
+			if _, ok := set[name]; !ok {
+				set[name] = arity
+			}
+		}
+		if id, ok := classes[typeName]; ok {
+			for _, super := range m.index.index[id].SuperClasses {
+				collect(super.FullName(), set, visited)
+			}
+		}
+	}
+	methodSets := make(map[string]map[string]int)
+	var interfaces []string
+	for typeName, id := range classes {
+		set := make(map[string]int)
+		collect(typeName, set, make(map[string]bool))
+		methodSets[typeName] = set
+		// 空接口（interface{}、any）不参与匹配
+		if m.index.index[id].Metadata["kind"] == "interface" && len(set) > 0 {
+			interfaces = append(interfaces, typeName)
+		}
+	}
+
+	for typeName, id := range classes {
+		node := m.index.index[id]
+		if node.Metadata["kind"] == "interface" || len(methodSets[typeName]) == 0 {
+			continue
+		}
+		changed := false
+		for _, iface := range interfaces {
+			if !goImplements(methodSets[typeName], methodSets[iface]) {
+				continue
+			}
+			ifaceNode := m.index.index[classes[iface]]
+			node.SuperClasses = append(node.SuperClasses, ClassRef{Package: ifaceNode.Package, Name: ifaceNode.Name})
+			node.Metadata["superClasses"] = strings.Trim(node.Metadata["superClasses"]+","+iface, ",")
+			changed = true
+		}
+		if changed {
+			m.index.index[id] = node
+		}
+	}
+}
+
+// goImplements 判断方法集是否包含接口要求的全部方法
+func goImplements(methodSet, required map[string]int) bool {
+	for name, arity := range required {
+		if got, ok := methodSet[name]; !ok || got != arity {
+			return false
+		}
+	}
+	return true
 }
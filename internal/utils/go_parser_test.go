@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoCallGraph(t *testing.T) {
+	goMod := "module example.com/app\n\ngo 1.21\n"
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "类型或导入路径.函数名"
+		want   []string
+	}{
+		{
+			name: "其他文件中声明的方法与导入包中的函数",
+			files: map[string]string{
+				"go.mod": goMod,
+				"store/repo.go": `package store
+
+type Repo struct{}
+
+func NewRepo(dsn string) *Repo { return &Repo{} }
+`,
+				"store/find.go": `package store
+
+func (r *Repo) Find(key string) string { return key }
+`,
+				"handler/ctl.go": `package handler
+
+import "example.com/app/store"
+
+func Show(key string) string {
+	repo := store.NewRepo("db")
+	return repo.Find(key)
+}
+`,
+			},
+			caller: "example.com/app/handler.Show",
+			want:   []string{"example.com/app/store.NewRepo/1", "example.com/app/store.Repo.Find/1"},
+		},
+		{
+			name: "嵌入类型的方法与可变参数",
+			files: map[string]string{
+				"go.mod": goMod,
+				"log/log.go": `package log
+
+type Logger struct{}
+
+func (l *Logger) Printf(format string, args ...any) {}
+
+type Service struct {
+	Logger
+	name string
+}
+
+func (s *Service) Run(key string) {
+	s.Printf("%s %s", s.name, key)
+}
+`,
+			},
+			caller: "example.com/app/log.Service.Run",
+			want:   []string{"example.com/app/log.Logger.Printf/2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &GoParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, function := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, function); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGoChainedCallIDs 同名的链式调用 b.Add(1).Add(2) 应当各自记录为一个节点，外层调用的接收者类型按内层的返回类型推断
+func TestGoChainedCallIDs(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"go.mod": "module example.com/app\n",
+		"calc/calc.go": `package calc
+
+type Builder struct{}
+
+func (b *Builder) Add(n int) *Builder { return b }
+
+func Build(b *Builder) {
+	b.Add(1).Add(2)
+}
+`,
+	}, &GoParser{})
+
+	var receiverTypes []string
+	for _, node := range index.index {
+		if node.Type == "MethodCall" && node.Name == "Add" {
+			receiverTypes = append(receiverTypes, node.Metadata["receiverType"])
+		}
+	}
+	want := []string{"example.com/app/calc.Builder", "example.com/app/calc.Builder"}
+	if !reflect.DeepEqual(receiverTypes, want) {
+		t.Errorf("Add 调用的接收者类型 = %v, want %v", receiverTypes, want)
+	}
+}
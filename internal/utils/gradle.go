@@ -0,0 +1,763 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GradleProject Gradle 构建中的一个项目（根项目或子项目）
+type GradleProject struct {
+	Path         string             `json:"path"`      // 项目路径，根项目为 :，子项目如 :app、:lib:core
+	Name         string             `json:"name"`      // 项目名
+	Dir          string             `json:"dir"`       // 项目目录
+	BuildFile    string             `json:"buildFile"` // build.gradle 或 build.gradle.kts，没有构建文件时为空
+	RootDir      string             `json:"rootDir"`   // 所属构建的根目录
+	Group        string             `json:"group,omitempty"`
+	Version      string             `json:"version,omitempty"`
+	Plugins      []string           `json:"plugins,omitempty"`
+	SourceSets   []GradleSourceSet  `json:"sourceSets,omitempty"`
+	Dependencies []GradleDependency `json:"dependencies,omitempty"`
+}
+
+// GradleSourceSet 源码集及其源码目录（相对项目目录）
+type GradleSourceSet struct {
+	Name string   `json:"name"`
+	Dirs []string `json:"dirs"`
+}
+
+// GradleDependency dependencies 块中声明的依赖
+type GradleDependency struct {
+	Configuration string `json:"configuration"`     // implementation、api、testImplementation 等
+	GroupID       string `json:"groupId,omitempty"` // 外部依赖的坐标
+	ArtifactID    string `json:"artifactId,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Project       string `json:"project,omitempty"` // 项目依赖的路径，如 :core
+	Notation      string `json:"notation"`          // 原始写法
+}
+
+// Coordinate 返回 groupId:artifactId:version，项目依赖返回 project(路径)
+func (d GradleDependency) Coordinate() string {
+	if d.Project != "" {
+		return "project(" + d.Project + ")"
+	}
+	if d.ArtifactID == "" {
+		return d.Notation
+	}
+	if d.Version == "" {
+		return d.GroupID + ":" + d.ArtifactID
+	}
+	return d.GroupID + ":" + d.ArtifactID + ":" + d.Version
+}
+
+var (
+	// gradleIncludePattern settings 中的 include，参数可以跨行
+	gradleIncludePattern = regexp.MustCompile(`\binclude\b\s*\(?((?:\s*["'][^"']+["']\s*,?)+)`)
+	// gradleProjectDirPattern settings 中修改子项目目录的语句
+	gradleProjectDirPattern = regexp.MustCompile(`project\s*\(\s*["']([^"']+)["']\s*\)\.projectDir\s*=\s*(?:new\s+File\s*\(\s*(?:settingsDir|rootDir)\s*,\s*|file\s*\()\s*["']([^"']+)["']`)
+	// gradleRootNamePattern settings 中的根项目名
+	gradleRootNamePattern = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+	// gradleQuotedPattern 引号中的字符串
+	gradleQuotedPattern = regexp.MustCompile(`["']([^"']*)["']`)
+	// gradleAssignPattern 构建脚本和 ext 块中的属性赋值，如 springVersion = '5.3.1'、val springVersion = "5.3.1"
+	gradleAssignPattern = regexp.MustCompile(`(?m)(?:^|[{;])\s*(?:ext\.|project\.ext\.|def\s+|val\s+|var\s+|extra\[["'])?([\w.]+)(?:["']\])?\s*=\s*["']([^"'$]*)["']`)
+	// gradlePropertyCallPattern 字符串模板中读取属性的写法，如 ${property("springVersion")}、${rootProject.extra["x"]}
+	gradlePropertyCallPattern = regexp.MustCompile(`(?:(?:find)?[pP]roperty\s*\(|(?:rootProject\.|project\.)?(?:extra|ext)\s*\[)\s*\\?["']([\w.]+)\\?["']\s*[)\]](?:\s*as\s+String\??)?`)
+	// gradleVariablePattern 字符串中的变量引用，如 $springVersion、${springVersion}
+	gradleVariablePattern = regexp.MustCompile(`\$\{?([\w.]+)\}?`)
+	// gradleConfigurationPattern 依赖的配置名
+	gradleConfigurationPattern = regexp.MustCompile(`^\w*(?:[iI]mplementation|[aA]pi|[cC]ompileOnly|[rR]untimeOnly|[cC]ompile|[rR]untime|[aA]nnotationProcessor|[kK]apt|[kK]sp|[pP]rovidedCompile|[pP]rovidedRuntime|developmentOnly|[cC]lasspath)$`)
+	// gradleDependencyLinePattern dependencies 块中的一行依赖声明
+	gradleDependencyLinePattern = regexp.MustCompile(`^\s*(\w+)\s*(?:\(|\s)\s*(.+)$`)
+	// gradleProjectRefPattern project(':core') 或 project(path: ':core')
+	gradleProjectRefPattern = regexp.MustCompile(`project\s*\(\s*(?:path\s*[:=]\s*)?["']([^"']+)["']`)
+	// gradleMapArgPattern map 写法中的参数，如 group: 'x'、name = "y"
+	gradleMapArgPattern = regexp.MustCompile(`\b(group|name|version)\s*[:=]\s*["']([^"']*)["']`)
+	// gradleKotlinModulePattern Kotlin DSL 中的 kotlin("stdlib")
+	gradleKotlinModulePattern = regexp.MustCompile(`\bkotlin\s*\(\s*["']([\w-]+)["']`)
+	// gradleCatalogRefPattern 版本目录中的依赖别名，如 libs.spring.core
+	gradleCatalogRefPattern = regexp.MustCompile(`\blibs\.([\w.]+)`)
+	// gradlePluginPatterns plugins 块与 apply plugin 中的插件
+	gradlePluginIDPattern    = regexp.MustCompile(`\bid\s*\(?\s*["']([^"']+)["']`)
+	gradleApplyPluginPattern = regexp.MustCompile(`\bapply\s*\(?\s*plugin\s*[:=]\s*["']([^"']+)["']`)
+	gradleBarePluginPattern  = regexp.MustCompile("(?m)^\\s*`?([a-z][\\w-]*)`?\\s*$")
+	// gradleSrcDirPattern srcDir / srcDirs 语句
+	gradleSrcDirPattern = regexp.MustCompile(`\b(java|kotlin|groovy|scala|resources)\s*\.\s*(?:srcDirs?|setSrcDirs)\b\s*[=(+]?\s*(.*)`)
+	// gradleSourceSetRefPattern sourceSets 外部对源码集的引用，如 sourceSets.main.java.srcDirs、sourceSets["main"]
+	gradleSourceSetRefPattern = regexp.MustCompile(`sourceSets\s*(?:\.getByName\s*\(\s*["']|\[\s*["']|\.)(\w+)["')\]]*\s*\.?\s*`)
+	// gradleSourceSetNamePattern sourceSets 块中源码集的声明
+	gradleSourceSetNamePattern = regexp.MustCompile(`(?:(?:create|getByName|register|named|maybeCreate)\s*\(\s*["'](\w+)["']|val\s+(\w+)\s+by\s+(?:creating|getting)|^(\w+)$)`)
+)
+
+// gradleSourceKinds 源码集中常见的源码目录类型
+var gradleSourceKinds = []string{"java", "kotlin", "groovy", "scala", "resources"}
+
+// FindGradleProjects 查找目录下的 Gradle 构建，解析 settings.gradle(.kts) 中的子项目，
+// 以及每个项目 build.gradle(.kts) 中的插件、源码集和依赖
+func FindGradleProjects(root string) []GradleProject {
+	var settingsFiles, buildFiles []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "build", ".gradle", "node_modules", ".git", "buildSrc":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch info.Name() {
+		case "settings.gradle", "settings.gradle.kts":
+			settingsFiles = append(settingsFiles, path)
+		case "build.gradle", "build.gradle.kts":
+			buildFiles = append(buildFiles, path)
+		}
+		return nil
+	})
+
+	var projects []GradleProject
+	covered := make(map[string]bool) // 已归属到某个构建的项目目录
+	for _, settingsFile := range settingsFiles {
+		for _, project := range parseGradleBuild(filepath.Dir(settingsFile), settingsFile) {
+			covered[project.Dir] = true
+			projects = append(projects, project)
+		}
+	}
+	// 没有 settings 文件的构建按单项目处理
+	for _, buildFile := range buildFiles {
+		if dir := filepath.Dir(buildFile); !covered[dir] {
+			for _, project := range parseGradleBuild(dir, "") {
+				covered[project.Dir] = true
+				projects = append(projects, project)
+			}
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].RootDir != projects[j].RootDir {
+			return projects[i].RootDir < projects[j].RootDir
+		}
+		return projects[i].Path < projects[j].Path
+	})
+	return projects
+}
+
+// parseGradleBuild 解析一个构建中的根项目和全部子项目，settingsFile 为空时只有根项目
+func parseGradleBuild(rootDir, settingsFile string) []GradleProject {
+	rootName := filepath.Base(rootDir)
+	paths := []string{":"}
+	dirs := map[string]string{":": rootDir}
+
+	if settingsFile != "" {
+		settings := readGradleScript(settingsFile)
+		if m := gradleRootNamePattern.FindStringSubmatch(settings); m != nil {
+			rootName = m[1]
+		}
+		for _, m := range gradleIncludePattern.FindAllStringSubmatch(settings, -1) {
+			for _, q := range gradleQuotedPattern.FindAllStringSubmatch(m[1], -1) {
+				path := q[1]
+				if !strings.HasPrefix(path, ":") {
+					path = ":" + path
+				}
+				// include ':a:b' 同时包含 :a 和 :a:b
+				segments := strings.Split(strings.TrimPrefix(path, ":"), ":")
+				for i := range segments {
+					parent := ":" + strings.Join(segments[:i+1], ":")
+					if _, ok := dirs[parent]; !ok {
+						dirs[parent] = filepath.Join(append([]string{rootDir}, segments[:i+1]...)...)
+						paths = append(paths, parent)
+					}
+				}
+			}
+		}
+		for _, m := range gradleProjectDirPattern.FindAllStringSubmatch(settings, -1) {
+			path := m[1]
+			if !strings.HasPrefix(path, ":") {
+				path = ":" + path
+			}
+			if _, ok := dirs[path]; ok {
+				dirs[path] = filepath.Join(rootDir, filepath.FromSlash(m[2]))
+			}
+		}
+	}
+
+	// 根项目的属性、版本目录以及 subprojects / allprojects 块对子项目生效
+	rootProperties := readGradleProperties(filepath.Join(rootDir, "gradle.properties"))
+	catalog := readGradleCatalog(filepath.Join(rootDir, "gradle", "libs.versions.toml"))
+	var shared, sharedAll string
+	if rootScript := readGradleScript(gradleBuildFile(rootDir)); rootScript != "" {
+		for k, v := range gradleAssignments(removeGradleBlocks(rootScript, "subprojects", "allprojects", "project")) {
+			if _, ok := rootProperties[k]; !ok {
+				rootProperties[k] = v
+			}
+		}
+		shared = strings.Join(gradleBlocks(rootScript, "subprojects"), "\n")
+		sharedAll = strings.Join(gradleBlocks(rootScript, "allprojects"), "\n")
+	}
+
+	var projects []GradleProject
+	for _, path := range paths {
+		dir := dirs[path]
+		project := GradleProject{
+			Path:      path,
+			Name:      rootName,
+			Dir:       dir,
+			BuildFile: gradleBuildFile(dir),
+			RootDir:   rootDir,
+		}
+		if path != ":" {
+			project.Name = path[strings.LastIndex(path, ":")+1:]
+		}
+
+		properties := make(map[string]string)
+		for k, v := range rootProperties {
+			properties[k] = v
+		}
+		for k, v := range readGradleProperties(filepath.Join(dir, "gradle.properties")) {
+			properties[k] = v
+		}
+
+		// 子项目的脚本为 allprojects / subprojects 块加上自身的构建文件
+		scripts := []string{sharedAll}
+		if path != ":" {
+			scripts = append(scripts, shared)
+		}
+		script := readGradleScript(project.BuildFile)
+		if path == ":" {
+			script = removeGradleBlocks(script, "subprojects", "allprojects", "project")
+		}
+		scripts = append(scripts, script)
+		for _, s := range scripts {
+			for k, v := range gradleAssignments(s) {
+				properties[k] = v
+			}
+		}
+
+		project.Group = properties["group"]
+		project.Version = properties["version"]
+		for _, s := range scripts {
+			for _, plugin := range gradlePlugins(s) {
+				project.Plugins = appendUnique(project.Plugins, plugin)
+			}
+			project.Dependencies = append(project.Dependencies, gradleDependencies(s, properties, catalog)...)
+		}
+		project.SourceSets = gradleSourceSets(dir, script)
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+// gradleBuildFile 返回目录下的构建文件，不存在时返回空字符串
+func gradleBuildFile(dir string) string {
+	for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+		if path := filepath.Join(dir, name); fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// readGradleScript 读取构建脚本并去掉注释
+func readGradleScript(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return stripGradleComments(string(data))
+}
+
+// stripGradleComments 去掉 // 和 /* */ 注释，字符串中的内容保持不变
+func stripGradleComments(src string) string {
+	var builder strings.Builder
+	var quote byte
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if quote != 0 {
+			builder.WriteByte(c)
+			if c == '\\' && i+1 < len(src) {
+				i++
+				builder.WriteByte(src[i])
+			} else if c == quote || c == '\n' {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				builder.WriteByte('\n')
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return builder.String()
+			}
+			// 保留换行，使按行解析的语句不会合并
+			builder.WriteString(strings.Repeat("\n", strings.Count(src[i:i+2+end], "\n")))
+			i += end + 3
+			continue
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
+
+// gradleBlockEnd 返回 open 位置的 '{' 对应的 '}' 的位置，字符串中的括号不计入
+func gradleBlockEnd(src string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(src); i++ {
+		c := src[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// gradleBlockPattern 匹配语句开头名为 name 的块，如 dependencies {、project(':app') {，
+// 第一个分组为块名到 '{' 的部分
+func gradleBlockPattern(names ...string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)(?:^|[;{}])[ \t]*((?:` + strings.Join(names, "|") + `)\s*(?:\([^)]*\))?\s*\{)`)
+}
+
+// gradleBlocks 返回名为 name 的所有块的内容
+func gradleBlocks(src, name string) []string {
+	var blocks []string
+	for _, loc := range gradleBlockPattern(name).FindAllStringSubmatchIndex(src, -1) {
+		end := gradleBlockEnd(src, loc[3]-1)
+		if end > loc[3] {
+			blocks = append(blocks, src[loc[3]:end])
+		} else {
+			blocks = append(blocks, "")
+		}
+	}
+	return blocks
+}
+
+// removeGradleBlocks 去掉指定名称的块，避免把其中的依赖算到当前项目
+func removeGradleBlocks(src string, names ...string) string {
+	pattern := gradleBlockPattern(names...)
+	for {
+		loc := pattern.FindStringSubmatchIndex(src)
+		if loc == nil {
+			return src
+		}
+		end := gradleBlockEnd(src, loc[3]-1)
+		if end >= len(src) {
+			return src[:loc[2]]
+		}
+		src = src[:loc[2]] + src[end+1:]
+	}
+}
+
+// gradleChildBlock 块中直接包含的子块
+type gradleChildBlock struct {
+	header string // '{' 之前的声明，如 main、create("integrationTest")
+	body   string
+}
+
+// gradleChildBlocks 返回块中直接包含的子块，以及去掉子块后剩下的语句
+func gradleChildBlocks(body string) ([]gradleChildBlock, string) {
+	var children []gradleChildBlock
+	var rest strings.Builder
+	lineStart := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\n', ';', '}':
+			rest.WriteString(body[lineStart : i+1])
+			lineStart = i + 1
+		case '"', '\'':
+			// 跳过字符串
+			quote := body[i]
+			for i++; i < len(body) && body[i] != quote && body[i] != '\n'; i++ {
+				if body[i] == '\\' {
+					i++
+				}
+			}
+		case '{':
+			end := gradleBlockEnd(body, i)
+			if end >= len(body) {
+				end = len(body) - 1
+			}
+			children = append(children, gradleChildBlock{
+				header: strings.TrimSpace(body[lineStart:i]),
+				body:   body[i+1 : end],
+			})
+			i = end
+			lineStart = end + 1
+		}
+	}
+	if lineStart < len(body) {
+		rest.WriteString(body[lineStart:])
+	}
+	return children, rest.String()
+}
+
+// readGradleProperties 读取 gradle.properties
+func readGradleProperties(path string) map[string]string {
+	properties := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return properties
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		} else if key, value, ok := strings.Cut(line, ":"); ok {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return properties
+}
+
+// readGradleCatalog 读取版本目录 libs.versions.toml，返回别名（- 与 _ 替换为 .）到 groupId:artifactId:version 的映射
+func readGradleCatalog(path string) map[string]string {
+	catalog := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return catalog
+	}
+
+	versions := make(map[string]string)
+	libraries := make(map[string]map[string]string)
+	section := ""
+	pairPattern := regexp.MustCompile(`([\w.-]+)\s*=\s*"([^"]*)"`)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[] ")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		value = strings.TrimSpace(value)
+		switch section {
+		case "versions":
+			if m := gradleQuotedPattern.FindStringSubmatch(value); m != nil {
+				versions[key] = m[1]
+			}
+		case "libraries":
+			fields := make(map[string]string)
+			if strings.HasPrefix(value, "{") {
+				for _, m := range pairPattern.FindAllStringSubmatch(value, -1) {
+					fields[m[1]] = m[2]
+				}
+			} else if m := gradleQuotedPattern.FindStringSubmatch(value); m != nil {
+				fields["module"] = m[1]
+			}
+			libraries[key] = fields
+		}
+	}
+
+	for alias, fields := range libraries {
+		module := fields["module"]
+		if module == "" && fields["group"] != "" {
+			module = fields["group"] + ":" + fields["name"]
+		}
+		if module == "" {
+			continue
+		}
+		version := fields["version"]
+		if ref := fields["version.ref"]; ref != "" {
+			version = versions[ref]
+		}
+		if version != "" && strings.Count(module, ":") == 1 {
+			module += ":" + version
+		}
+		catalog[strings.NewReplacer("-", ".", "_", ".").Replace(alias)] = module
+	}
+	return catalog
+}
+
+// gradleAssignments 返回构建脚本中的字符串属性赋值
+func gradleAssignments(script string) map[string]string {
+	assignments := make(map[string]string)
+	for _, m := range gradleAssignPattern.FindAllStringSubmatch(script, -1) {
+		assignments[m[1]] = m[2]
+	}
+	return assignments
+}
+
+// expandGradleVariables 替换字符串中的 $var 和 ${var}，未知变量保持原样
+func expandGradleVariables(value string, properties map[string]string) string {
+	return gradleVariablePattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		name = strings.TrimPrefix(name, "project.")
+		name = strings.TrimPrefix(name, "rootProject.")
+		if v, ok := properties[name]; ok {
+			return v
+		}
+		return ref
+	})
+}
+
+// gradlePlugins 返回脚本中 plugins 块和 apply plugin 声明的插件
+func gradlePlugins(script string) []string {
+	var plugins []string
+	for _, block := range gradleBlocks(script, "plugins") {
+		for _, m := range gradlePluginIDPattern.FindAllStringSubmatch(block, -1) {
+			plugins = appendUnique(plugins, m[1])
+		}
+		for _, m := range gradleKotlinModulePattern.FindAllStringSubmatch(block, -1) {
+			plugins = appendUnique(plugins, "org.jetbrains.kotlin."+m[1])
+		}
+		for _, m := range gradleBarePluginPattern.FindAllStringSubmatch(block, -1) {
+			plugins = appendUnique(plugins, m[1])
+		}
+	}
+	for _, m := range gradleApplyPluginPattern.FindAllStringSubmatch(script, -1) {
+		plugins = appendUnique(plugins, m[1])
+	}
+	return plugins
+}
+
+// gradleDependencies 返回脚本中 dependencies 块声明的依赖，buildscript 中的构建插件依赖不计入
+func gradleDependencies(script string, properties, catalog map[string]string) []GradleDependency {
+	script = removeGradleBlocks(script, "buildscript", "subprojects", "allprojects", "project", "constraints")
+
+	var dependencies []GradleDependency
+	for _, block := range gradleBlocks(script, "dependencies") {
+		for _, line := range strings.FieldsFunc(block, func(r rune) bool { return r == '\n' || r == ';' }) {
+			m := gradleDependencyLinePattern.FindStringSubmatch(line)
+			if m == nil || !gradleConfigurationPattern.MatchString(m[1]) {
+				continue
+			}
+			args := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(m[2]), "{"))
+			dependencies = append(dependencies, parseGradleDependency(m[1], args, properties, catalog))
+		}
+	}
+	return dependencies
+}
+
+// parseGradleDependency 解析一条依赖声明的参数
+func parseGradleDependency(configuration, args string, properties, catalog map[string]string) GradleDependency {
+	// 去掉 implementation("...") 写法多出的右括号
+	for strings.HasSuffix(args, ")") && strings.Count(args, ")") > strings.Count(args, "(") {
+		args = strings.TrimSpace(strings.TrimSuffix(args, ")"))
+	}
+	args = gradlePropertyCallPattern.ReplaceAllString(args, "$1")
+	dependency := GradleDependency{
+		Configuration: configuration,
+		Notation:      args,
+	}
+
+	if m := gradleProjectRefPattern.FindStringSubmatch(args); m != nil {
+		dependency.Project = m[1]
+		if !strings.HasPrefix(dependency.Project, ":") {
+			dependency.Project = ":" + dependency.Project
+		}
+		return dependency
+	}
+
+	var coordinate string
+	if m := gradleKotlinModulePattern.FindStringSubmatch(args); m != nil {
+		coordinate = "org.jetbrains.kotlin:kotlin-" + m[1]
+		if quoted := gradleQuotedPattern.FindAllStringSubmatch(args, -1); len(quoted) > 1 {
+			coordinate += ":" + quoted[1][1]
+		}
+	} else if m := gradleCatalogRefPattern.FindStringSubmatch(args); m != nil && !strings.Contains(args, `"`) && !strings.Contains(args, `'`) {
+		coordinate = catalog[strings.TrimSuffix(m[1], ".get")]
+	} else if pairs := gradleMapArgPattern.FindAllStringSubmatch(args, -1); len(pairs) > 0 && strings.Contains(args, "name") {
+		fields := make(map[string]string)
+		for _, pair := range pairs {
+			fields[pair[1]] = pair[2]
+		}
+		coordinate = fields["group"] + ":" + fields["name"] + ":" + fields["version"]
+	} else if m := gradleQuotedPattern.FindStringSubmatch(args); m != nil {
+		coordinate = m[1]
+	}
+
+	coordinate = expandGradleVariables(coordinate, properties)
+	// 去掉 @jar 等扩展名
+	if i := strings.Index(coordinate, "@"); i >= 0 {
+		coordinate = coordinate[:i]
+	}
+	parts := strings.Split(coordinate, ":")
+	if len(parts) >= 2 && parts[1] != "" {
+		dependency.GroupID = parts[0]
+		dependency.ArtifactID = parts[1]
+		if len(parts) >= 3 {
+			dependency.Version = parts[2]
+		}
+	}
+	return dependency
+}
+
+// gradleSourceSets 返回项目的源码集：sourceSets 中配置的目录，以及按约定位置（src/<源码集>/<类型>）存在的目录
+func gradleSourceSets(dir, script string) []GradleSourceSet {
+	configured := make(map[string][]string)
+	var names []string
+	addDirs := func(name string, dirs ...string) {
+		if _, ok := configured[name]; !ok {
+			names = append(names, name)
+			configured[name] = nil
+		}
+		for _, dir := range dirs {
+			configured[name] = appendUnique(configured[name], dir)
+		}
+	}
+
+	for _, block := range gradleBlocks(script, "sourceSets") {
+		children, rest := gradleChildBlocks(block)
+		for _, child := range children {
+			m := gradleSourceSetNamePattern.FindStringSubmatch(child.header)
+			if m == nil {
+				continue
+			}
+			name := m[1] + m[2] + m[3]
+			addDirs(name)
+			// 源码集中的 java { srcDirs = [...] } 写法
+			kinds, statements := gradleChildBlocks(child.body)
+			for _, kind := range kinds {
+				for _, line := range strings.Split(kind.body, "\n") {
+					if strings.Contains(line, "srcDir") {
+						addDirs(name, gradleQuotedStrings(line)...)
+					}
+				}
+			}
+			for _, line := range strings.Split(statements, "\n") {
+				if m := gradleSrcDirPattern.FindStringSubmatch(line); m != nil {
+					addDirs(name, gradleQuotedStrings(m[2])...)
+				}
+			}
+		}
+		// main.java.srcDirs = [...] 写法
+		for _, line := range strings.Split(rest, "\n") {
+			if m := gradleSrcDirPattern.FindStringSubmatch(line); m != nil {
+				if fields := strings.Fields(strings.Split(line, ".")[0]); len(fields) > 0 {
+					addDirs(fields[len(fields)-1], gradleQuotedStrings(m[2])...)
+				}
+			}
+		}
+	}
+	// sourceSets.main.java.srcDirs = [...] 写法
+	for _, line := range strings.Split(script, "\n") {
+		if ref := gradleSourceSetRefPattern.FindStringSubmatchIndex(line); ref != nil {
+			if m := gradleSrcDirPattern.FindStringSubmatch(line[ref[1]:]); m != nil {
+				addDirs(line[ref[2]:ref[3]], gradleQuotedStrings(m[2])...)
+			}
+		}
+	}
+
+	for _, name := range []string{"main", "test"} {
+		if _, ok := configured[name]; !ok {
+			addDirs(name)
+		}
+	}
+
+	var sourceSets []GradleSourceSet
+	for _, name := range names {
+		dirs := configured[name]
+		for _, kind := range gradleSourceKinds {
+			conventional := "src/" + name + "/" + kind
+			if isDirectory(filepath.Join(dir, filepath.FromSlash(conventional))) {
+				dirs = appendUnique(dirs, conventional)
+			}
+		}
+		if len(dirs) > 0 {
+			sourceSets = append(sourceSets, GradleSourceSet{Name: name, Dirs: dirs})
+		}
+	}
+	return sourceSets
+}
+
+// gradleQuotedStrings 返回语句中所有引号中的字符串
+func gradleQuotedStrings(s string) []string {
+	var values []string
+	for _, m := range gradleQuotedPattern.FindAllStringSubmatch(s, -1) {
+		if m[1] != "" {
+			values = append(values, m[1])
+		}
+	}
+	return values
+}
+
+// GradleProjects 返回建立索引时解析到的 Gradle 项目
+func (q *QueryEngine) GradleProjects() []GradleProject {
+	return q.index.gradleProjects
+}
+
+// FormatGradleProjects 格式化 Gradle 项目，filter 非空时只输出路径或名称匹配的项目
+func FormatGradleProjects(projects []GradleProject, filter string) string {
+	if len(projects) == 0 {
+		return "代码仓库中没有 Gradle 构建文件"
+	}
+
+	var builder strings.Builder
+	count := 0
+	for _, project := range projects {
+		if filter != "" && project.Path != filter && project.Name != filter && ":"+filter != project.Path {
+			continue
+		}
+		count++
+		builder.WriteString(fmt.Sprintf("==== %s (%s) ====\n", project.Path, project.Name))
+		builder.WriteString(fmt.Sprintf("  目录: %s\n", project.Dir))
+		if project.BuildFile != "" {
+			builder.WriteString(fmt.Sprintf("  构建文件: %s\n", filepath.Base(project.BuildFile)))
+		}
+		if project.Group != "" || project.Version != "" {
+			builder.WriteString(fmt.Sprintf("  group: %s, version: %s\n", project.Group, project.Version))
+		}
+		if len(project.Plugins) > 0 {
+			builder.WriteString(fmt.Sprintf("  插件: %s\n", strings.Join(project.Plugins, ", ")))
+		}
+		for _, sourceSet := range project.SourceSets {
+			builder.WriteString(fmt.Sprintf("  源码集 %s: %s\n", sourceSet.Name, strings.Join(sourceSet.Dirs, ", ")))
+		}
+		if len(project.Dependencies) > 0 {
+			builder.WriteString(fmt.Sprintf("  依赖 (%d):\n", len(project.Dependencies)))
+			for _, dependency := range project.Dependencies {
+				builder.WriteString(fmt.Sprintf("    %s %s\n", dependency.Configuration, dependency.Coordinate()))
+			}
+		}
+		builder.WriteString("\n")
+	}
+	if count == 0 {
+		return "未找到项目: " + filter
+	}
+	return builder.String()
+}
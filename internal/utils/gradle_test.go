@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"Fenrir-CodeAuditTool/configs"
+)
+
+func TestFindGradleProjects(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"settings.gradle": `rootProject.name = 'shop'
+include ':app', ':libs:core'
+project(':libs:core').projectDir = file('core')
+`,
+		"build.gradle": `ext {
+    springVersion = '5.3.1'
+}
+subprojects {
+    apply plugin: 'java'
+    group = 'com.shop'
+}
+`,
+		"gradle.properties":         "jacksonVersion=2.15.0\n",
+		"gradle/libs.versions.toml": "[versions]\nguava = \"32.1.0-jre\"\n\n[libraries]\nguava = { module = \"com.google.guava:guava\", version.ref = \"guava\" }\n",
+		"app/build.gradle": `plugins {
+    id 'org.springframework.boot' version '2.7.0'
+}
+dependencies {
+    implementation project(':libs:core')
+    implementation "org.springframework:spring-web:$springVersion"
+    implementation libs.guava
+    testImplementation group: 'junit', name: 'junit', version: '4.13'
+}
+`,
+		"app/src/main/java/app/App.java": "package app;\n",
+		"core/build.gradle.kts": `sourceSets {
+    main {
+        java.srcDirs("src")
+    }
+}
+dependencies {
+    api("com.fasterxml.jackson.core:jackson-databind:${property("jacksonVersion")}")
+}
+`,
+	})
+
+	projects := FindGradleProjects(dir)
+	if len(projects) != 4 {
+		t.Fatalf("项目数 = %d, want 4: %+v", len(projects), projects)
+	}
+	byPath := make(map[string]GradleProject)
+	for _, project := range projects {
+		byPath[project.Path] = project
+	}
+
+	tests := []struct {
+		path         string
+		name         string
+		dir          string
+		group        string
+		plugins      []string
+		sourceSets   []GradleSourceSet
+		dependencies []string
+	}{
+		{path: ":", name: "shop", dir: dir},
+		{path: ":libs", name: "libs", dir: filepath.Join(dir, "libs"), group: "com.shop", plugins: []string{"java"}},
+		{
+			path:       ":app",
+			name:       "app",
+			dir:        filepath.Join(dir, "app"),
+			group:      "com.shop",
+			plugins:    []string{"java", "org.springframework.boot"},
+			sourceSets: []GradleSourceSet{{Name: "main", Dirs: []string{"src/main/java"}}},
+			dependencies: []string{
+				"implementation project(:libs:core)",
+				"implementation org.springframework:spring-web:5.3.1",
+				"implementation com.google.guava:guava:32.1.0-jre",
+				"testImplementation junit:junit:4.13",
+			},
+		},
+		{
+			path:         ":libs:core",
+			name:         "core",
+			dir:          filepath.Join(dir, "core"),
+			group:        "com.shop",
+			plugins:      []string{"java"},
+			sourceSets:   []GradleSourceSet{{Name: "main", Dirs: []string{"src"}}},
+			dependencies: []string{"api com.fasterxml.jackson.core:jackson-databind:2.15.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			project, ok := byPath[tt.path]
+			if !ok {
+				t.Fatalf("未找到项目 %s", tt.path)
+			}
+			if project.Name != tt.name || project.Dir != tt.dir || project.Group != tt.group {
+				t.Errorf("项目 = %s %s %q, want %s %s %q", project.Name, project.Dir, project.Group, tt.name, tt.dir, tt.group)
+			}
+			if !reflect.DeepEqual(project.Plugins, tt.plugins) {
+				t.Errorf("插件 = %v, want %v", project.Plugins, tt.plugins)
+			}
+			if !reflect.DeepEqual(project.SourceSets, tt.sourceSets) {
+				t.Errorf("源码集 = %+v, want %+v", project.SourceSets, tt.sourceSets)
+			}
+			var dependencies []string
+			for _, dependency := range project.Dependencies {
+				dependencies = append(dependencies, dependency.Configuration+" "+dependency.Coordinate())
+			}
+			if !reflect.DeepEqual(dependencies, tt.dependencies) {
+				t.Errorf("依赖 = %v, want %v", dependencies, tt.dependencies)
+			}
+		})
+	}
+}
+
+func TestGradleProjectsCached(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"build.gradle": "dependencies {\n    implementation 'commons-io:commons-io:2.11.0'\n}\n",
+	})
+	m := NewParserManager()
+	m.RegisterParser(&JavaParser{})
+	if err := m.BuildIndexFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &configs.Config{}
+	config.CodeAudit.RepositoryPath = dir
+	config.CodeAudit.ASTCache.CacheDir = t.TempDir()
+	pm := NewASTPersistenceManager(config)
+	if err := pm.SaveASTIndex(m.GetIndex()); err != nil {
+		t.Fatalf("保存缓存失败: %v", err)
+	}
+	loaded, err := pm.LoadASTIndex()
+	if err != nil {
+		t.Fatalf("加载缓存失败: %v", err)
+	}
+	if got, want := NewQueryEngine(loaded).GradleProjects(), m.GetIndex().gradleProjects; !reflect.DeepEqual(got, want) || len(got) != 1 {
+		t.Errorf("缓存中的 Gradle 项目 = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// BuildIncludeGraph 将 Include 节点关联到被包含文件的 File 节点：
+// Include 节点和包含方的 File 节点添加 includes 关系，被包含文件的 File 节点添加 included_by 关系。
+// target 为相对路径时先相对包含方所在目录查找，找不到时在索引的文件中按路径后缀唯一匹配
+func BuildIncludeGraph(m *ParserManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var files []string
+	for id, node := range m.index.index {
+		if node.Type == "File" {
+			files = append(files, id)
+		}
+	}
+
+	for id, include := range m.index.index {
+		if include.Type != "Include" || include.Metadata["target"] == "" {
+			continue
+		}
+		targetID := resolveIncludeTarget(m.index, files, include.File, include.Metadata["target"])
+		if targetID == "" {
+			continue
+		}
+
+		include.AddRelation(targetID, "includes")
+		m.index.index[id] = include
+		if includer, ok := m.index.index[include.File]; ok {
+			includer.AddRelation(targetID, "includes")
+			m.index.index[include.File] = includer
+		}
+		if included, ok := m.index.index[targetID]; ok {
+			included.AddRelation(include.File, "included_by")
+			m.index.index[targetID] = included
+		}
+	}
+}
+
+// resolveIncludeTarget 返回被包含文件的 File 节点ID，无法确定时返回空
+func resolveIncludeTarget(index *ASTIndex, files []string, includer, target string) string {
+	if filepath.IsAbs(target) {
+		if _, ok := index.index[filepath.Clean(target)]; ok {
+			return filepath.Clean(target)
+		}
+	} else if candidate := filepath.Join(filepath.Dir(includer), target); index.index[candidate].Type == "File" {
+		return candidate
+	}
+
+	// 依赖 include_path 或入口脚本目录的相对路径，按路径后缀匹配
+	suffix := "/" + strings.TrimLeft(filepath.ToSlash(filepath.Clean(target)), "./")
+	matched := ""
+	for _, file := range files {
+		if strings.HasSuffix(filepath.ToSlash(file), suffix) {
+			if matched != "" {
+				return ""
+			}
+			matched = file
+		}
+	}
+	return matched
+}
@@ -3,7 +3,10 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	// 使用第三方 Java 解析器
 	sitter "github.com/smacker/go-tree-sitter"
@@ -49,6 +52,10 @@ func (p *JavaParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
 				if idNode != nil && (idNode.Type() == "scoped_identifier" || idNode.Type() == "identifier") {
 					importPath = idNode.Content(code)
 				}
+				// tree-sitter 将 import a.b.* 中的 * 解析为单独的 asterisk 节点
+				if idNode != nil && idNode.Type() == "asterisk" && importPath != "" {
+					importPath += ".*"
+				}
 			}
 			if strings.HasSuffix(importPath, ".*") {
 				importStar = append(importStar, strings.TrimSuffix(importPath, ".*"))
@@ -60,12 +67,37 @@ func (p *JavaParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
 		}
 	}
 
+	// 文件中声明的类（包括内部类）按简单名登记，类型引用据此解析为 pkg.Outer$Inner
+	p.collectDeclaredTypes(root, code, packageName, "", importMap)
+
 	// 遍历 AST 提取关键信息
-	p.traverseNode(root, filePath, code, packageName, importMap, importStar, &nodes)
+	p.traverseNode(root, filePath, code, packageName, importMap, importStar, &javaScope{}, &nodes)
 
 	return nodes, nil
 }
 
+// collectDeclaredTypes 将文件中声明的类型登记到 importMap，内部类记录为 pkg.Outer$Inner，已 import 的同名类型优先
+func (p *JavaParser) collectDeclaredTypes(node *sitter.Node, code []byte, packageName, outer string, importMap map[string]string) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		nextOuter := outer
+		switch child.Type() {
+		case "class_declaration", "interface_declaration", "enum_declaration", "record_declaration", "annotation_type_declaration":
+			if nameNode := child.ChildByFieldName("name"); nameNode != nil {
+				name := nameNode.Content(code)
+				nextOuter = packageName + "." + name
+				if outer != "" {
+					nextOuter = outer + "$" + name
+				}
+				if _, exists := importMap[name]; !exists {
+					importMap[name] = nextOuter
+				}
+			}
+		}
+		p.collectDeclaredTypes(child, code, packageName, nextOuter, importMap)
+	}
+}
+
 // 提取包名 - 在文件级别提取，只执行一次
 func (p *JavaParser) extractPackageName(root *sitter.Node, code []byte) string {
 	// 查找包声明节点
@@ -100,7 +132,20 @@ func (p *JavaParser) extractPackageName(root *sitter.Node, code []byte) string {
 	return "default.package"
 }
 
-func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byte, packageName string, importMap map[string]string, importStar []string, nodes *[]UniversalASTNode) {
+// javaScope 记录遍历到当前节点时所处的类和方法上下文，用于推断方法调用的接收者类型
+type javaScope struct {
+	classID    string            // 所在类节点ID
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的直接父类全限定名（用于 super 调用）
+	methodID   string            // 所在方法节点ID
+	fieldTypes map[string]string // 所在类的字段名 -> 字段类型
+	varTypes   map[string]string // 方法参数及局部变量名 -> 声明类型
+}
+
+func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byte, packageName string, importMap map[string]string, importStar []string, scope *javaScope, nodes *[]UniversalASTNode) {
+	// 子节点默认沿用当前作用域，进入类或方法时替换为新的作用域
+	childScope := scope
+
 	// 处理不同类型的节点
 	switch node.Type() {
 	case "class_declaration", "interface_declaration", "annotation_type_declaration":
@@ -108,6 +153,14 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 		if nameNode != nil {
 			className := nameNode.Content(code)
 			id := fmt.Sprintf("%s:%s:%d", filePath, className, node.StartByte())
+			resolveType := func(name string) string {
+				return resolveJavaType(name, filepath.Dir(filePath), packageName, importMap, importStar)
+			}
+			// 内部类的节点名为 Outer$Inner
+			nodeName, fullName := className, packageName+"."+className
+			if scope.classID != "" {
+				nodeName, fullName = nestedClassName(packageName, scope.className, className), scope.className+"$"+className
+			}
 
 			// 调试：打印 class_declaration 的所有子节点
 			fmt.Printf("=== 分析类: %s ===\n", className)
@@ -125,20 +178,24 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 				ID:        id,
 				Language:  "java",
 				Type:      "Class",
-				Name:      className,
+				Name:      nodeName,
 				File:      filePath,
 				Package:   packageName,
 				StartLine: int(node.StartPoint().Row),
 				EndLine:   int(node.EndPoint().Row),
 				Fields:    make([]FieldInfo, 0),
 				Metadata:  map[string]string{},
+				// 类上的注解，如 @RestController、@RequestMapping
+				Annotations: p.collectAnnotations(node, code, resolveType),
+				Modifiers:   p.collectModifiers(node, code),
+				TypeParams:  p.collectTypeParams(node, code),
 			}
 
 			// 获取类体节点
 			bodyNode := node.ChildByFieldName("body")
 			if bodyNode != nil {
 				// 收集类中的字段信息
-				p.collectClassFields(bodyNode, code, &classNode)
+				p.collectClassFields(bodyNode, code, &classNode, resolveType)
 			}
 
 			// 获取所有父类和接口
@@ -154,9 +211,14 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 
 			// 2. 拿 implements 对应的接口列表，注意 field name 要写对
 			if impls := node.ChildByFieldName("interfaces"); impls != nil {
-				for i := 0; i < int(impls.NamedChildCount()); i++ {
-					typeNode := impls.NamedChild(i)
-					superClassNames = append(superClassNames, p.extractTypeName(typeNode, code))
+				superClassNames = append(superClassNames, p.collectTypeList(impls, code)...)
+			}
+			// 接口继承的父接口（interface A extends B, C）
+			if node.Type() == "interface_declaration" {
+				for i := 0; i < int(node.NamedChildCount()); i++ {
+					if child := node.NamedChild(i); child != nil && child.Type() == "extends_interfaces" {
+						superClassNames = append(superClassNames, p.collectTypeList(child, code)...)
+					}
 				}
 			}
 
@@ -164,21 +226,9 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 			var superFullNames []string
 			var superClassRefs []ClassRef
 			for _, sc := range superClassNames {
-				fq := ""
-				if v, ok := importMap[sc]; ok {
-					fq = v
-				} else if len(importStar) > 0 {
-					for _, pkg := range importStar {
-						candidate := pkg + "." + sc
-						fq = candidate
-						break
-					}
-				}
-				if fq == "" && packageName != "" {
-					fq = packageName + "." + sc
-				}
+				fq := resolveJavaType(sc, filepath.Dir(filePath), packageName, importMap, importStar)
 				if fq == "" {
-					fq = sc // 兜底：直接用类名
+					continue
 				}
 				superFullNames = append(superFullNames, fq)
 				// 新增：填充 SuperClasses 字段
@@ -200,32 +250,116 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 			}
 
 			*nodes = append(*nodes, classNode)
+
+			// 字段同时记录为独立节点，用于关联读写位置
+			for _, field := range classNode.Fields {
+				*nodes = append(*nodes, UniversalASTNode{
+					ID:          fmt.Sprintf("%s:%s.%s:%d", filePath, className, field.Name, field.StartLine),
+					Language:    "java",
+					Type:        "Field",
+					Name:        field.Name,
+					File:        filePath,
+					Package:     packageName,
+					StartLine:   field.StartLine,
+					EndLine:     field.EndLine,
+					Annotations: field.Annotations,
+					Modifiers:   fieldKeywords(field.Modifiers),
+					Metadata: map[string]string{
+						"fieldType": field.Type,
+						"fullType":  resolveType(field.Type),
+						"classID":   id,
+						"className": fullName,
+					},
+				})
+			}
+
+			// 通过注解注册的 Servlet / Filter / Listener 额外记录为入口节点
+			if entry, ok := javaEntryPoint(classNode); ok {
+				*nodes = append(*nodes, entry)
+			}
+
+			// 类体内的节点使用新的类作用域
+			childScope = &javaScope{
+				classID:    id,
+				className:  fullName,
+				fieldTypes: make(map[string]string),
+				varTypes:   make(map[string]string),
+			}
+			if node.Type() == "class_declaration" && len(superFullNames) > 0 && node.ChildByFieldName("superclass") != nil {
+				childScope.superClass = superFullNames[0]
+			}
+			for _, field := range classNode.Fields {
+				childScope.fieldTypes[field.Name] = field.Type
+			}
 		}
 	case "method_declaration", "constructor_declaration", "annotation_type_element_declaration":
 		nameNode := node.ChildByFieldName("name")
 		if nameNode != nil {
 			methodName := nameNode.Content(code)
 			id := fmt.Sprintf("%s:%s:%d", filePath, methodName, node.StartByte())
+			resolveType := func(name string) string {
+				return resolveJavaType(name, filepath.Dir(filePath), packageName, importMap, importStar)
+			}
+
+			// 方法体使用新的方法作用域，参数类型记录到变量表中
+			childScope = &javaScope{
+				classID:    scope.classID,
+				className:  scope.className,
+				superClass: scope.superClass,
+				methodID:   id,
+				fieldTypes: scope.fieldTypes,
+				varTypes:   make(map[string]string),
+			}
 
 			// 获取方法参数
 			var methodParams []string
+			var params []ParamInfo
 			parametersNode := node.ChildByFieldName("parameters")
 			if parametersNode != nil {
 				for i := 0; i < int(parametersNode.ChildCount()); i++ {
 					paramNode := parametersNode.Child(i)
-					if paramNode != nil && paramNode.Type() == "formal_parameter" {
+					if paramNode == nil {
+						continue
+					}
+					param := ParamInfo{Annotations: p.collectAnnotations(paramNode, code, resolveType)}
+					if paramNode.Type() == "formal_parameter" {
 						typeNode := paramNode.ChildByFieldName("type")
-						if typeNode != nil {
-							// 获取完整的参数类型，包括泛型信息
-							paramType := typeNode.Content(code)
-							// 处理泛型类型
-							if strings.Contains(paramType, "?") {
-								// 保留泛型信息
-								methodParams = append(methodParams, paramType)
-							} else {
-								methodParams = append(methodParams, paramType)
+						if typeNode == nil {
+							continue
+						}
+						// 获取完整的参数类型，包括泛型信息
+						param.Type = typeNode.Content(code)
+						if paramName := paramNode.ChildByFieldName("name"); paramName != nil {
+							param.Name = paramName.Content(code)
+						}
+					} else if paramNode.Type() == "spread_parameter" {
+						// 可变参数：类型节点为第一个非修饰符的命名子节点，形参名在 variable_declarator 中
+						for j := 0; j < int(paramNode.NamedChildCount()); j++ {
+							child := paramNode.NamedChild(j)
+							switch child.Type() {
+							case "modifiers":
+							case "variable_declarator":
+								if paramName := child.ChildByFieldName("name"); paramName != nil {
+									param.Name = paramName.Content(code)
+								}
+							default:
+								if param.Type == "" {
+									param.Type = child.Content(code) + "..."
+									param.Variadic = true
+								}
 							}
 						}
+						if param.Type == "" {
+							continue
+						}
+					} else {
+						continue
+					}
+
+					methodParams = append(methodParams, param.Type)
+					params = append(params, param)
+					if param.Name != "" {
+						childScope.varTypes[param.Name] = param.Type
 					}
 				}
 			}
@@ -237,18 +371,38 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 				if typeNode != nil {
 					returnType = typeNode.Content(code)
 				}
-			} else {
-				returnTypeNode := node.ChildByFieldName("return_type")
+			} else if node.Type() == "method_declaration" {
+				// tree-sitter 中方法返回类型的字段名为 type
+				returnTypeNode := node.ChildByFieldName("type")
 				if returnTypeNode != nil {
 					returnType = returnTypeNode.Content(code)
 				}
 			}
 
+			// 声明抛出的异常
+			var throws []string
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); child.Type() == "throws" {
+					for j := 0; j < int(child.NamedChildCount()); j++ {
+						throws = append(throws, child.NamedChild(j).Content(code))
+					}
+				}
+			}
+
+			// 返回类型的全限定名，用于推断链式调用的接收者类型；方法自身的泛型参数无法推断
+			typeParams := p.collectTypeParams(node, code)
+			returnFullType := resolveType(returnType)
+			for _, typeParam := range typeParams {
+				if strings.Fields(typeParam)[0] == strings.TrimSuffix(returnType, "[]") {
+					returnFullType = ""
+				}
+			}
+
 			// 打印调试信息
 			fmt.Printf("Found method: %s.%s (Return: %s, Params: %v)\n",
 				packageName, methodName, returnType, methodParams)
 
-			*nodes = append(*nodes, UniversalASTNode{
+			methodNode := UniversalASTNode{
 				ID:           id,
 				Language:     "java",
 				Type:         "Method",
@@ -258,8 +412,146 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 				StartLine:    int(node.StartPoint().Row),
 				EndLine:      int(node.EndPoint().Row),
 				MethodParams: methodParams,
+				Params:       params,
+				Annotations:  p.collectAnnotations(node, code, resolveType),
+				Modifiers:    p.collectModifiers(node, code),
+				TypeParams:   typeParams,
+				Throws:       throws,
 				Metadata: map[string]string{
-					"returnType": returnType,
+					"returnType":     returnType,
+					"returnFullType": returnFullType,
+					"classID":        scope.classID,
+					"className":      scope.className,
+				},
+			}
+			*nodes = append(*nodes, methodNode)
+		}
+	case "local_variable_declaration":
+		// 记录局部变量的声明类型，供后续调用解析接收者类型
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil && scope.varTypes != nil {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				decl := node.NamedChild(i)
+				if decl.Type() != "variable_declarator" {
+					continue
+				}
+				if varName := decl.ChildByFieldName("name"); varName != nil {
+					varType := typeNode.Content(code)
+					if varType == "var" {
+						// var x = new Foo() / (Foo) y 可以从初始化表达式得到类型
+						varType = p.initializerType(decl.ChildByFieldName("value"), code)
+					}
+					scope.varTypes[varName.Content(code)] = varType
+					if scope.methodID != "" {
+						*nodes = append(*nodes, p.localVariableNode(decl, varName.Content(code), typeNode.Content(code), decl.ChildByFieldName("value"), code, filePath, packageName, scope))
+					}
+				}
+			}
+		}
+	case "assignment_expression":
+		// 记录赋值语句：目标变量与右侧表达式引用的变量，复合赋值同时引用目标自身
+		left, right := node.ChildByFieldName("left"), node.ChildByFieldName("right")
+		if left != nil && right != nil && scope.methodID != "" {
+			target := left
+			if target.Type() == "array_access" {
+				// arr[i] = x 视为对数组变量 arr 的定义
+				if array := target.ChildByFieldName("array"); array != nil {
+					target = array
+				}
+			}
+			value := p.exprInfo(right, code)
+			operator := "="
+			if op := node.ChildByFieldName("operator"); op != nil {
+				operator = op.Content(code)
+			}
+			if operator != "=" {
+				value.Uses = appendUnique(value.Uses, target.Content(code))
+			}
+			*nodes = append(*nodes, UniversalASTNode{
+				ID:        fmt.Sprintf("%s:assign:%s:%d", filePath, target.Content(code), node.StartByte()),
+				Language:  "java",
+				Type:      "Assignment",
+				Name:      target.Content(code),
+				File:      filePath,
+				Package:   packageName,
+				StartLine: int(node.StartPoint().Row),
+				EndLine:   int(node.EndPoint().Row),
+				Metadata: map[string]string{
+					"operator": operator,
+					"value":    value.Text,
+					"uses":     strings.Join(value.Uses, ","),
+					"methodID": scope.methodID,
+				},
+			})
+		}
+	case "lambda_expression":
+		// lambda 参数会遮蔽同名字段，类型未声明时记录为空
+		if params := node.ChildByFieldName("parameters"); params != nil && scope.varTypes != nil {
+			if params.Type() == "identifier" {
+				scope.varTypes[params.Content(code)] = ""
+			}
+			for i := 0; i < int(params.NamedChildCount()); i++ {
+				param := params.NamedChild(i)
+				if param.Type() == "identifier" {
+					scope.varTypes[param.Content(code)] = ""
+				} else if name := param.ChildByFieldName("name"); name != nil {
+					if typeNode := param.ChildByFieldName("type"); typeNode != nil {
+						scope.varTypes[name.Content(code)] = typeNode.Content(code)
+					} else {
+						scope.varTypes[name.Content(code)] = ""
+					}
+				}
+			}
+		}
+	case "field_access":
+		// obj.field 形式的字段访问，字段所属类型按接收者推断
+		if field := node.ChildByFieldName("field"); field != nil && scope.classID != "" {
+			objectNode := node.ChildByFieldName("object")
+			receiver := ""
+			if objectNode != nil {
+				receiver = objectNode.Content(code)
+			}
+			ownerType := p.resolveReceiverType(objectNode, code, filepath.Dir(filePath), packageName, importMap, importStar, scope)
+			*nodes = append(*nodes, p.fieldAccessNode(node, field.Content(code), receiver, ownerType, isWriteTarget(node), filePath, packageName, scope))
+		}
+	case "enhanced_for_statement", "resource":
+		if typeNode, varName := node.ChildByFieldName("type"), node.ChildByFieldName("name"); typeNode != nil && varName != nil && scope.varTypes != nil {
+			scope.varTypes[varName.Content(code)] = typeNode.Content(code)
+			// for (T x : list) 中 x 的值来自 list，try (T x = open()) 中 x 的值来自初始化表达式
+			if scope.methodID != "" {
+				*nodes = append(*nodes, p.localVariableNode(node, varName.Content(code), typeNode.Content(code), node.ChildByFieldName("value"), code, filePath, packageName, scope))
+			}
+		}
+	case "catch_formal_parameter":
+		// 多重 catch 只能按声明的第一个异常类型处理
+		if varName := node.ChildByFieldName("name"); varName != nil && scope.varTypes != nil {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				catchType := node.NamedChild(i)
+				if catchType.Type() == "catch_type" && catchType.NamedChildCount() > 0 {
+					scope.varTypes[varName.Content(code)] = catchType.NamedChild(0).Content(code)
+				}
+			}
+		}
+	case "object_creation_expression":
+		// 构造器调用按 JVM 习惯以 <init> 命名，接收者类型为被创建的类
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			id := callNodeID(filePath, "<init>", int(node.StartByte()), int(node.EndByte()))
+			arguments := p.argumentInfos(node.ChildByFieldName("arguments"), code)
+			*nodes = append(*nodes, UniversalASTNode{
+				ID:        id,
+				Language:  "java",
+				Type:      "MethodCall",
+				Name:      "<init>",
+				Arguments: arguments,
+				File:      filePath,
+				Package:   packageName,
+				StartLine: int(node.StartPoint().Row),
+				EndLine:   int(node.EndPoint().Row),
+				Metadata: map[string]string{
+					"receiver":     "",
+					"receiverType": resolveJavaType(typeNode.Content(code), filepath.Dir(filePath), packageName, importMap, importStar),
+					"argCount":     strconv.Itoa(len(arguments)),
+					"callerID":     scope.methodID,
+					"callerClass":  scope.className,
 				},
 			})
 		}
@@ -267,17 +559,48 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 		nameNode := node.ChildByFieldName("name")
 		if nameNode != nil {
 			methodName := nameNode.Content(code)
-			id := fmt.Sprintf("%s:%s:%d", filePath, methodName, node.StartByte())
-			*nodes = append(*nodes, UniversalASTNode{
+			id := callNodeID(filePath, methodName, int(node.StartByte()), int(node.EndByte()))
+
+			receiver := ""
+			objectNode := node.ChildByFieldName("object")
+			if objectNode != nil {
+				receiver = objectNode.Content(code)
+			}
+			arguments := p.argumentInfos(node.ChildByFieldName("arguments"), code)
+
+			callNode := UniversalASTNode{
 				ID:        id,
 				Language:  "java",
 				Type:      "MethodCall",
 				Name:      methodName,
+				Arguments: arguments,
 				File:      filePath,
 				Package:   packageName,
 				StartLine: int(node.StartPoint().Row),
 				EndLine:   int(node.EndPoint().Row),
-			})
+				Metadata: map[string]string{
+					"receiver":     receiver,
+					"receiverType": p.resolveReceiverType(objectNode, code, filepath.Dir(filePath), packageName, importMap, importStar, scope),
+					"argCount":     strconv.Itoa(len(arguments)),
+					"callerID":     scope.methodID,
+					"callerClass":  scope.className,
+				},
+			}
+			// 接收者类型需要建立索引后才能确定的情况：链式调用取前一个调用的返回类型，
+			// 未在当前类声明的字段可能继承自父类
+			if callNode.Metadata["receiverType"] == "" && objectNode != nil {
+				switch inner := unwrapParens(objectNode); inner.Type() {
+				case "method_invocation":
+					if innerName := inner.ChildByFieldName("name"); innerName != nil {
+						callNode.Metadata["receiverCallID"] = callNodeID(filePath, innerName.Content(code), int(inner.StartByte()), int(inner.EndByte()))
+					}
+				case "identifier":
+					if _, isVar := scope.varTypes[inner.Content(code)]; !isVar {
+						callNode.Metadata["receiverField"] = inner.Content(code)
+					}
+				}
+			}
+			*nodes = append(*nodes, callNode)
 		}
 	}
 
@@ -285,13 +608,445 @@ func (p *JavaParser) traverseNode(node *sitter.Node, filePath string, code []byt
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
 		if child != nil {
-			p.traverseNode(child, filePath, code, packageName, importMap, importStar, nodes)
+			// 不是参数或局部变量的裸标识符可能引用当前类或父类的字段，
+			// 建立索引后找不到对应字段的访问节点会被删除
+			if child.Type() == "identifier" && isFieldReferenceContext(node, i) && childScope.classID != "" {
+				name := child.Content(code)
+				if _, isVar := childScope.varTypes[name]; !isVar {
+					*nodes = append(*nodes, p.fieldAccessNode(child, name, "", childScope.className, isWriteTarget(child), filePath, packageName, childScope))
+				}
+			}
+			p.traverseNode(child, filePath, code, packageName, importMap, importStar, childScope, nodes)
+		}
+	}
+}
+
+// localVariableNode 创建局部变量声明节点，valueNode 为初始化表达式（可以为空）
+func (p *JavaParser) localVariableNode(node *sitter.Node, name, varType string, valueNode *sitter.Node, code []byte, filePath, packageName string, scope *javaScope) UniversalASTNode {
+	var value ExprInfo
+	if valueNode != nil {
+		value = p.exprInfo(valueNode, code)
+	}
+	return UniversalASTNode{
+		ID:        fmt.Sprintf("%s:local:%s:%d", filePath, name, node.StartByte()),
+		Language:  "java",
+		Type:      "LocalVariable",
+		Name:      name,
+		File:      filePath,
+		Package:   packageName,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"varType":  varType,
+			"value":    value.Text,
+			"uses":     strings.Join(value.Uses, ","),
+			"methodID": scope.methodID,
+		},
+	}
+}
+
+// argumentInfos 收集调用的实参表达式
+func (p *JavaParser) argumentInfos(argsNode *sitter.Node, code []byte) []ExprInfo {
+	var arguments []ExprInfo
+	if argsNode == nil {
+		return arguments
+	}
+	for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+		if arg := argsNode.NamedChild(i); arg.Type() != "comment" {
+			arguments = append(arguments, p.exprInfo(arg, code))
+		}
+	}
+	return arguments
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名（参数、局部变量或字段）
+func (p *JavaParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	if exprNode.Type() == "identifier" || isThisFieldAccess(exprNode) {
+		info.Uses = []string{info.Text}
+		return info
+	}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		for i := 0; i < int(node.ChildCount()); i++ {
+			child := node.Child(i)
+			if child.Type() == "identifier" {
+				if isFieldReferenceContext(node, i) {
+					info.Uses = appendUnique(info.Uses, child.Content(code))
+				}
+				continue
+			}
+			// this.x 记录为 this.x，便于和同名局部变量区分
+			if isThisFieldAccess(child) {
+				info.Uses = appendUnique(info.Uses, child.Content(code))
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(exprNode)
+	return info
+}
+
+// isThisFieldAccess 判断是否为 this.x 形式的字段访问
+func isThisFieldAccess(node *sitter.Node) bool {
+	if node.Type() != "field_access" {
+		return false
+	}
+	obj := node.ChildByFieldName("object")
+	return obj != nil && obj.Type() == "this"
+}
+
+// appendUnique 追加不重复的元素
+func appendUnique(list []string, value string) []string {
+	for _, item := range list {
+		if item == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// fieldAccessNode 创建一次字段读写的节点，字段所属类型为 ownerType
+func (p *JavaParser) fieldAccessNode(node *sitter.Node, fieldName, receiver, ownerType string, write bool, filePath, packageName string, scope *javaScope) UniversalASTNode {
+	access := "read"
+	if write {
+		access = "write"
+	}
+	return UniversalASTNode{
+		ID:        fmt.Sprintf("%s:field:%s:%d", filePath, fieldName, node.StartByte()),
+		Language:  "java",
+		Type:      "FieldAccess",
+		Name:      fieldName,
+		File:      filePath,
+		Package:   packageName,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"access":      access,
+			"receiver":    receiver,
+			"ownerType":   ownerType,
+			"callerID":    scope.methodID,
+			"callerClass": scope.className,
+		},
+	}
+}
+
+// isWriteTarget 判断表达式是否为赋值左侧或自增自减的操作数
+func isWriteTarget(node *sitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return false
+	}
+	switch parent.Type() {
+	case "assignment_expression":
+		left := parent.ChildByFieldName("left")
+		return left != nil && left.StartByte() == node.StartByte() && left.EndByte() == node.EndByte()
+	case "update_expression":
+		return true
+	}
+	return false
+}
+
+// isFieldReferenceContext 判断 parent 的第 idx 个子标识符是否处于表达式位置，
+// 排除声明名、方法名、字段访问的字段名、标签和注解参数名等
+func isFieldReferenceContext(parent *sitter.Node, idx int) bool {
+	switch parent.FieldNameForChild(idx) {
+	case "name", "field", "key", "parameters", "label", "type", "dimensions":
+		return false
+	}
+	switch parent.Type() {
+	case "scoped_identifier", "scoped_type_identifier", "import_declaration", "package_declaration",
+		"labeled_statement", "break_statement", "continue_statement", "inferred_parameters",
+		"method_reference", "marker_annotation", "annotation", "enum_constant", "formal_parameter",
+		"catch_formal_parameter", "spread_parameter", "variable_declarator", "type_parameter":
+		// variable_declarator 的初始值在 value 字段中，其余位置都是声明名
+		return parent.Type() == "variable_declarator" && parent.FieldNameForChild(idx) == "value"
+	}
+	return true
+}
+
+// resolveReceiverType 推断方法调用接收者的声明类型（全限定名），无法推断时返回空字符串
+func (p *JavaParser) resolveReceiverType(objectNode *sitter.Node, code []byte, dir, packageName string, importMap map[string]string, importStar []string, scope *javaScope) string {
+	// 无接收者的调用即调用当前类（或其父类）的方法
+	if objectNode == nil {
+		return scope.className
+	}
+
+	declared := ""
+	switch objectNode = unwrapParens(objectNode); objectNode.Type() {
+	case "this":
+		return scope.className
+	case "super":
+		return scope.superClass
+	case "identifier":
+		name := objectNode.Content(code)
+		if t, ok := scope.varTypes[name]; ok {
+			declared = t
+		} else if t, ok := scope.fieldTypes[name]; ok {
+			declared = t
+		} else if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+			// 首字母大写的标识符按类名处理（静态方法调用）
+			declared = name
 		}
+	case "field_access":
+		// this.field.method()
+		if obj := objectNode.ChildByFieldName("object"); obj != nil && obj.Type() == "this" {
+			if field := objectNode.ChildByFieldName("field"); field != nil {
+				declared = scope.fieldTypes[field.Content(code)]
+			}
+		}
+	case "scoped_identifier":
+		// 全限定类名的静态调用，如 java.lang.Runtime.getRuntime()
+		declared = objectNode.Content(code)
+	case "object_creation_expression", "cast_expression", "string_literal":
+		// new Foo().bar()、((Foo) obj).bar()、"abc".getBytes()
+		declared = p.initializerType(objectNode, code)
 	}
+
+	return resolveJavaType(declared, dir, packageName, importMap, importStar)
+}
+
+// initializerType 返回表达式本身能确定的类型：new 表达式、强制类型转换和字符串字面量，其他表达式返回空
+func (p *JavaParser) initializerType(valueNode *sitter.Node, code []byte) string {
+	if valueNode == nil {
+		return ""
+	}
+	switch valueNode = unwrapParens(valueNode); valueNode.Type() {
+	case "object_creation_expression", "cast_expression":
+		if typeNode := valueNode.ChildByFieldName("type"); typeNode != nil {
+			return typeNode.Content(code)
+		}
+	case "string_literal":
+		return "String"
+	}
+	return ""
+}
+
+// unwrapParens 去掉表达式外层的括号
+func unwrapParens(node *sitter.Node) *sitter.Node {
+	for node.Type() == "parenthesized_expression" && node.NamedChildCount() > 0 {
+		node = node.NamedChild(0)
+	}
+	return node
+}
+
+// collectAnnotations 收集声明节点 modifiers 中的注解及其参数，resolveType 用于推断注解的全限定名
+func (p *JavaParser) collectAnnotations(declNode *sitter.Node, code []byte, resolveType func(string) string) []AnnotationInfo {
+	var annotations []AnnotationInfo
+	for i := 0; i < int(declNode.NamedChildCount()); i++ {
+		modNode := declNode.NamedChild(i)
+		if modNode.Type() != "modifiers" {
+			continue
+		}
+		for j := 0; j < int(modNode.NamedChildCount()); j++ {
+			annotationNode := modNode.NamedChild(j)
+			if annotationNode.Type() != "annotation" && annotationNode.Type() != "marker_annotation" {
+				continue
+			}
+			nameNode := annotationNode.ChildByFieldName("name")
+			if nameNode == nil {
+				continue
+			}
+			annotation := AnnotationInfo{
+				Name:      ShortClassName(nameNode.Content(code)),
+				FullName:  resolveType(nameNode.Content(code)),
+				Arguments: map[string]string{},
+			}
+			if argsNode := annotationNode.ChildByFieldName("arguments"); argsNode != nil {
+				for k := 0; k < int(argsNode.NamedChildCount()); k++ {
+					arg := argsNode.NamedChild(k)
+					if arg.Type() == "element_value_pair" {
+						key, value := arg.ChildByFieldName("key"), arg.ChildByFieldName("value")
+						if key != nil && value != nil {
+							annotation.Arguments[key.Content(code)] = p.annotationValue(value, code)
+						}
+					} else if arg.Type() != "comment" {
+						// 单值注解 @Foo("x") 等价于 @Foo(value = "x")
+						annotation.Arguments["value"] = p.annotationValue(arg, code)
+					}
+				}
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// collectModifiers 收集声明节点的修饰符关键字（public、static、final 等），不含注解
+func (p *JavaParser) collectModifiers(declNode *sitter.Node, code []byte) []string {
+	var modifiers []string
+	for i := 0; i < int(declNode.NamedChildCount()); i++ {
+		modNode := declNode.NamedChild(i)
+		if modNode.Type() != "modifiers" {
+			continue
+		}
+		for j := 0; j < int(modNode.ChildCount()); j++ {
+			child := modNode.Child(j)
+			if child.Type() != "annotation" && child.Type() != "marker_annotation" && child.Type() != "comment" {
+				modifiers = append(modifiers, child.Content(code))
+			}
+		}
+	}
+	return modifiers
+}
+
+// collectTypeParams 收集泛型类型参数，如 T、V extends Comparable<V>
+func (p *JavaParser) collectTypeParams(declNode *sitter.Node, code []byte) []string {
+	var typeParams []string
+	if paramsNode := declNode.ChildByFieldName("type_parameters"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			typeParams = append(typeParams, paramsNode.NamedChild(i).Content(code))
+		}
+	}
+	return typeParams
+}
+
+// annotationValue 提取注解参数值：字符串去掉引号，数组以逗号连接各元素，常量拼接的字符串会合并
+func (p *JavaParser) annotationValue(valueNode *sitter.Node, code []byte) string {
+	switch valueNode.Type() {
+	case "string_literal":
+		return strings.Trim(valueNode.Content(code), "\"")
+	case "element_value_array_initializer":
+		var values []string
+		for i := 0; i < int(valueNode.NamedChildCount()); i++ {
+			if child := valueNode.NamedChild(i); child.Type() != "comment" {
+				values = append(values, p.annotationValue(child, code))
+			}
+		}
+		return strings.Join(values, ",")
+	case "binary_expression":
+		left, right := valueNode.ChildByFieldName("left"), valueNode.ChildByFieldName("right")
+		if left != nil && right != nil {
+			return p.annotationValue(left, code) + p.annotationValue(right, code)
+		}
+	}
+	return valueNode.Content(code)
+}
+
+// collectTypeList 收集 implements / extends 列表中的类型名
+func (p *JavaParser) collectTypeList(listNode *sitter.Node, code []byte) []string {
+	var names []string
+	for i := 0; i < int(listNode.NamedChildCount()); i++ {
+		child := listNode.NamedChild(i)
+		if child.Type() == "type_list" {
+			names = append(names, p.collectTypeList(child, code)...)
+			continue
+		}
+		names = append(names, p.extractTypeName(child, code))
+	}
+	return names
+}
+
+// javaLangTypes java.lang 包下常用的类型，无需 import 即可直接使用
+var javaLangTypes = map[string]bool{
+	"Object": true, "String": true, "StringBuilder": true, "StringBuffer": true,
+	"Class": true, "ClassLoader": true, "System": true, "Runtime": true,
+	"Process": true, "ProcessBuilder": true, "Thread": true, "Math": true,
+	"Integer": true, "Long": true, "Short": true, "Byte": true, "Character": true,
+	"Boolean": true, "Double": true, "Float": true, "Number": true, "Enum": true,
+	"Iterable": true, "Runnable": true, "Throwable": true, "Exception": true,
+	"RuntimeException": true, "Error": true, "Comparable": true, "CharSequence": true,
+	"Override": true, "Deprecated": true, "SuppressWarnings": true, "FunctionalInterface": true,
+	"SafeVarargs": true,
+}
+
+// jdkPackageOf 常见 JDK 类所在的包，用于在多个 import * 中确定类的来源
+var jdkPackageOf = map[string]string{
+	"File": "java.io", "FileInputStream": "java.io", "FileOutputStream": "java.io",
+	"FileReader": "java.io", "FileWriter": "java.io", "RandomAccessFile": "java.io",
+	"InputStream": "java.io", "OutputStream": "java.io", "Reader": "java.io", "Writer": "java.io",
+	"ObjectInputStream": "java.io", "ObjectOutputStream": "java.io", "BufferedReader": "java.io",
+	"InputStreamReader": "java.io", "PrintWriter": "java.io", "Serializable": "java.io",
+	"Files": "java.nio.file", "Paths": "java.nio.file", "Path": "java.nio.file",
+	"Connection": "java.sql", "Statement": "java.sql", "PreparedStatement": "java.sql",
+	"CallableStatement": "java.sql", "ResultSet": "java.sql", "DriverManager": "java.sql",
+	"URL": "java.net", "URI": "java.net", "URLConnection": "java.net", "HttpURLConnection": "java.net",
+	"Socket": "java.net", "URLClassLoader": "java.net",
+	"List": "java.util", "Map": "java.util", "Set": "java.util", "ArrayList": "java.util",
+	"HashMap": "java.util", "HashSet": "java.util", "Properties": "java.util", "Scanner": "java.util",
+	"Method": "java.lang.reflect", "Field": "java.lang.reflect", "Constructor": "java.lang.reflect",
+	"InitialContext": "javax.naming", "Context": "javax.naming",
+}
+
+// javaPrimitiveTypes 基本类型没有对应的类，不参与类型推断
+var javaPrimitiveTypes = map[string]bool{
+	"int": true, "long": true, "short": true, "byte": true, "char": true,
+	"boolean": true, "double": true, "float": true, "void": true, "var": true,
+}
+
+// resolveJavaType 根据 import 与包名将类型名推断为全限定名，
+// dir 为当前源文件所在目录，用于识别同包下的类
+func resolveJavaType(typeName, dir, packageName string, importMap map[string]string, importStar []string) string {
+	typeName = strings.TrimSpace(typeName)
+	// 去掉泛型参数与数组维度
+	if idx := strings.Index(typeName, "<"); idx != -1 {
+		typeName = typeName[:idx]
+	}
+	typeName = strings.TrimSuffix(strings.TrimSuffix(typeName, "..."), "[]")
+	if typeName == "" || javaPrimitiveTypes[typeName] {
+		return ""
+	}
+	if head, rest, found := strings.Cut(typeName, "."); found {
+		// Outer.Inner：Outer 为导入或文件中声明的类时，其后的部分为内部类
+		if full, ok := importMap[head]; ok {
+			return nestedTypeName(full, rest)
+		}
+		// 已经是全限定名
+		return typeName
+	}
+
+	if v, ok := importMap[typeName]; ok {
+		return v
+	}
+	// 同包的类优先于 import *，按 Java 惯例同包类与当前文件位于同一目录
+	if dir != "" && packageName != "" {
+		if javaDirClasses(dir)[typeName] {
+			return packageName + "." + typeName
+		}
+	}
+	if javaLangTypes[typeName] {
+		return "java.lang." + typeName
+	}
+	if len(importStar) > 0 {
+		// 多个 import * 时优先选择已知包含该类的 JDK 包
+		if pkg, ok := jdkPackageOf[typeName]; ok {
+			for _, star := range importStar {
+				if star == pkg {
+					return pkg + "." + typeName
+				}
+			}
+		}
+		return importStar[0] + "." + typeName
+	}
+	if packageName != "" {
+		return packageName + "." + typeName
+	}
+	return typeName
+}
+
+// javaDirClassCache 缓存各目录下的 Java 类名，避免每次类型推断都访问文件系统
+var javaDirClassCache sync.Map
+
+// javaDirClasses 返回目录下所有 .java 文件对应的类名，每个目录只读取一次
+func javaDirClasses(dir string) map[string]bool {
+	if cached, ok := javaDirClassCache.Load(dir); ok {
+		return cached.(map[string]bool)
+	}
+	classes := make(map[string]bool)
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, ".java") {
+				classes[strings.TrimSuffix(name, ".java")] = true
+			}
+		}
+	}
+	actual, _ := javaDirClassCache.LoadOrStore(dir, classes)
+	return actual.(map[string]bool)
 }
 
 // collectClassFields 收集类中的字段信息
-func (p *JavaParser) collectClassFields(classNode *sitter.Node, code []byte, node *UniversalASTNode) {
+func (p *JavaParser) collectClassFields(classNode *sitter.Node, code []byte, node *UniversalASTNode, resolveType func(string) string) {
 	// 遍历类体的所有子节点
 	for i := 0; i < int(classNode.ChildCount()); i++ {
 		child := classNode.Child(i)
@@ -354,6 +1109,8 @@ func (p *JavaParser) collectClassFields(classNode *sitter.Node, code []byte, nod
 							StartLine: startLine,
 							EndLine:   endLine,
 							Modifiers: modifiers,
+							// 字段上的注解，如 @Autowired、@Value
+							Annotations: p.collectAnnotations(child, code, resolveType),
 							Metadata: map[string]string{
 								"fullType": fieldType, // 保存完整的类型信息
 							},
@@ -412,8 +1169,10 @@ func FillSubClasses(m *ParserManager) {
 	// 1. 构建全限定名 -> 节点ID 映射
 	classMap := make(map[string]string)
 	for id, node := range m.index.index {
-		if node.Type == "Class" && node.FullClassName != "" {
-			classMap[node.FullClassName] = id
+		if node.Type == "Class" {
+			for _, name := range classLookupNames(node) {
+				classMap[name] = id
+			}
 		}
 	}
 
@@ -423,24 +1182,25 @@ func FillSubClasses(m *ParserManager) {
 			continue
 		}
 
+		// 子类引用与父类引用一样取自全限定名：AddNode 会去掉嵌套类型 Name 中的外部类前缀，
+		// 直接使用 Package 和 Name 会把 App.Web.Ctl$R 记录成 App.Web.R
+		subRef := ClassRef{Package: node.Package, Name: strings.TrimPrefix(strings.TrimPrefix(node.FullClassName, node.Package), ".")}
+
 		for _, superClassRef := range node.SuperClasses {
-			superClassName := superClassRef.Package + "." + superClassRef.Name
+			superClassName := superClassRef.FullName()
 			if parentID, ok := classMap[superClassName]; ok {
 				// 获取父节点的指针以进行修改
 				if parentNode, exists := m.index.index[parentID]; exists {
 					// 避免重复添加
 					found := false
 					for _, subClass := range parentNode.SubClasses {
-						if subClass.Name == node.Name && subClass.Package == node.Package {
+						if subClass == subRef {
 							found = true
 							break
 						}
 					}
 					if !found {
-						parentNode.SubClasses = append(parentNode.SubClasses, ClassRef{
-							Package: node.Package,
-							Name:    node.Name,
-						})
+						parentNode.SubClasses = append(parentNode.SubClasses, subRef)
 						// 将修改后的节点写回map
 						m.index.index[parentID] = parentNode
 					}
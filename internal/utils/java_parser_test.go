@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestJavaParserMethodDeclarations(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/Cache.java": `package app;
+
+public abstract class Cache<K, V extends Comparable<V>> {
+    public static synchronized <T> T load(final String key, @Nullable Class<T> type) throws IOException, SQLException {
+        return null;
+    }
+
+    protected abstract void put(K key, V... values);
+}
+`,
+	}, &JavaParser{})
+
+	tests := []struct {
+		method         string
+		wantModifiers  []string
+		wantTypeParams []string
+		wantThrows     []string
+		wantParams     []string // 类型 形参名
+	}{
+		{"load", []string{"public", "static", "synchronized"}, []string{"T"}, []string{"IOException", "SQLException"}, []string{"String key", "Class<T> type"}},
+		{"put", []string{"protected", "abstract"}, nil, nil, []string{"K key", "V... values"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			nodes := index.FindNodes(func(n UniversalASTNode) bool { return n.Type == "Method" && n.Name == tt.method })
+			if len(nodes) != 1 {
+				t.Fatalf("方法 %s 节点数 = %d, want 1", tt.method, len(nodes))
+			}
+			method := nodes[0]
+			var params []string
+			for _, p := range method.Params {
+				params = append(params, p.Type+" "+p.Name)
+			}
+			if !reflect.DeepEqual(method.Modifiers, tt.wantModifiers) || !reflect.DeepEqual(method.TypeParams, tt.wantTypeParams) ||
+				!reflect.DeepEqual(method.Throws, tt.wantThrows) || !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("%s: modifiers=%v typeParams=%v throws=%v params=%v", tt.method, method.Modifiers, method.TypeParams, method.Throws, params)
+			}
+		})
+	}
+
+	classes := index.FindNodes(func(n UniversalASTNode) bool { return n.Type == "Class" && n.Name == "Cache" })
+	if len(classes) != 1 || !reflect.DeepEqual(classes[0].Modifiers, []string{"public", "abstract"}) ||
+		!reflect.DeepEqual(classes[0].TypeParams, []string{"K", "V extends Comparable<V>"}) {
+		t.Errorf("类 Cache 的修饰符或类型参数不正确: %+v", classes)
+	}
+}
+
+func TestFillSubClasses(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		parser ASTParser
+		parent string
+		want   []ClassRef
+	}{
+		{
+			name: "Java 内部类",
+			files: map[string]string{
+				"app/Base.java": "package app;\n\npublic class Base {}\n",
+				"app/Outer.java": `package app;
+
+public class Outer extends Base {
+    static class Inner extends Base {}
+}
+`,
+			},
+			parser: &JavaParser{},
+			parent: "app.Base",
+			want:   []ClassRef{{Package: "app", Name: "Outer"}, {Package: "app", Name: "Outer$Inner"}},
+		},
+		{
+			name: "C# 嵌套类型",
+			files: map[string]string{"Ctl.cs": `namespace App.Web
+{
+    public class Ctl
+    {
+        public class R : Ctl {}
+    }
+}
+`},
+			parser: &CSharpParser{},
+			parent: "App.Web.Ctl",
+			want:   []ClassRef{{Package: "App.Web", Name: "Ctl$R"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, tt.parser)
+			var got []ClassRef
+			for _, node := range index.FindNodes(func(n UniversalASTNode) bool { return n.Type == "Class" && n.FullClassName == tt.parent }) {
+				got = append(got, node.SubClasses...)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].FullName() < got[j].FullName() })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 的子类 = %v, want %v", tt.parent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJavaInnerClasses(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/A.java": `package app;
+
+public class A {
+    class B {
+        class C {
+            void run() {}
+        }
+    }
+
+    void start() {
+        B.C c = null;
+        c.run();
+    }
+}
+`,
+	}, &JavaParser{})
+
+	tests := []struct {
+		fullName  string
+		name      string
+		inner     bool
+		outer     string
+		className string // 类中方法记录的所属类
+	}{
+		{"app.A", "A", false, "", "app.A"},
+		{"app.A$B", "B", true, "app.A", ""},
+		{"app.A$B$C", "C", true, "app.A$B", "app.A$B$C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fullName, func(t *testing.T) {
+			classes := index.FindNodes(func(n UniversalASTNode) bool { return n.Type == "Class" && n.FullClassName == tt.fullName })
+			if len(classes) != 1 {
+				t.Fatalf("类 %s 节点数 = %d, want 1", tt.fullName, len(classes))
+			}
+			class := classes[0]
+			if class.Name != tt.name || class.IsInnerClass != tt.inner || class.OuterClass != tt.outer {
+				t.Errorf("类 %s: name=%q inner=%v outer=%q", tt.fullName, class.Name, class.IsInnerClass, class.OuterClass)
+			}
+			if tt.className == "" {
+				return
+			}
+			methods := index.FindNodes(func(n UniversalASTNode) bool { return n.Type == "Method" && n.Metadata["classID"] == class.ID })
+			if len(methods) != 1 || methods[0].Metadata["className"] != tt.className {
+				t.Errorf("类 %s 的方法 = %+v, want 所属类 %s", tt.fullName, methods, tt.className)
+			}
+		})
+	}
+
+	if got := callTargets(index, "app.A", "start"); !reflect.DeepEqual(got, []string{"app.A$B$C.run/0"}) {
+		t.Errorf("B.C 类型的调用 = %v, want [app.A$B$C.run/0]", got)
+	}
+}
@@ -0,0 +1,965 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+)
+
+// JavaScriptParser 实现 JavaScript（.js、.jsx、.mjs、.cjs）的 AST 解析
+type JavaScriptParser struct{}
+
+func (p *JavaScriptParser) Language() string {
+	return "javascript"
+}
+
+func (p *JavaScriptParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	return (&esParser{language: "javascript"}).parseFile(filePath, javascript.GetLanguage())
+}
+
+// TypeScriptParser 实现 TypeScript（.ts、.tsx）的 AST 解析
+type TypeScriptParser struct{}
+
+func (p *TypeScriptParser) Language() string {
+	return "typescript"
+}
+
+func (p *TypeScriptParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	grammar := typescript.GetLanguage()
+	if strings.HasSuffix(filePath, ".tsx") {
+		grammar = tsx.GetLanguage()
+	}
+	return (&esParser{language: "typescript"}).parseFile(filePath, grammar)
+}
+
+// esParser JavaScript 与 TypeScript 共用的解析逻辑。
+// 模块路径（相对最近的 package.json 所在目录、去掉扩展名，如 src/routes/user.controller）记录为 Package，
+// 类的全限定名为 模块路径.类名；类中的方法记录为 Method，函数声明及绑定到名称的函数表达式、箭头函数记录为 Function
+type esParser struct {
+	language string
+}
+
+// esImport 表示一个导入的绑定：来源模块及导出名（default 为默认导出，* 为整个模块）
+type esImport struct {
+	module string
+	name   string
+}
+
+// esScope 记录遍历到当前节点时所处的类和函数上下文，用于推断调用的接收者类型
+type esScope struct {
+	classID    string            // 所在类节点ID
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的父类全限定名
+	methodID   string            // 所在函数节点ID
+	fieldTypes map[string]string // 所在类的字段名 -> 类型全限定名
+	varTypes   map[string]string // 参数及局部变量名 -> 类型全限定名（未知时为空）
+}
+
+// esExtensions 按顺序尝试的源文件扩展名，用于解析相对导入
+var esExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+
+// esGlobals 全局对象和函数，调用时接收者类型记为 globalThis
+var esGlobals = map[string]bool{
+	"eval": true, "Function": true, "alert": true, "setTimeout": true, "setInterval": true, "require": true,
+	"parseInt": true, "parseFloat": true, "encodeURIComponent": true, "decodeURIComponent": true,
+	"encodeURI": true, "decodeURI": true, "fetch": true, "Object": true, "Array": true, "String": true,
+	"Number": true, "Boolean": true, "JSON": true, "Math": true, "Promise": true, "Date": true,
+	"RegExp": true, "Error": true, "Map": true, "Set": true, "Buffer": true, "console": true,
+	"process": true, "Symbol": true, "URL": true,
+}
+
+func (p *esParser) parseFile(filePath string, grammar *sitter.Language) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(grammar)
+	tree := parser.Parse(nil, code)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	moduleName := esModuleName(filePath)
+	imports := p.collectImports(root, code, filePath)
+
+	var nodes []UniversalASTNode
+	p.traverseNode(root, filePath, code, moduleName, imports, &esScope{varTypes: map[string]string{}}, &nodes)
+	return nodes, nil
+}
+
+// esModuleName 返回文件相对最近的 package.json 所在目录的路径（去掉扩展名），找不到 package.json 时为文件名
+func esModuleName(filePath string) string {
+	stem := strings.TrimSuffix(filePath, filepath.Ext(filePath))
+	for dir := filepath.Dir(filePath); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "package.json")); err == nil {
+			if rel, err := filepath.Rel(dir, stem); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return filepath.Base(stem)
+}
+
+// resolveModule 将导入说明符转换为模块路径：相对路径按文件系统查找对应的源文件（含目录下的 index），包名保持不变
+func (p *esParser) resolveModule(specifier, filePath string) string {
+	if !strings.HasPrefix(specifier, ".") {
+		return specifier
+	}
+	target := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(specifier))
+	candidates := []string{target}
+	for _, ext := range esExtensions {
+		candidates = append(candidates, target+ext)
+	}
+	for _, ext := range esExtensions {
+		candidates = append(candidates, filepath.Join(target, "index"+ext))
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return esModuleName(candidate)
+		}
+	}
+	// 文件不存在时按路径推断
+	for _, ext := range esExtensions {
+		specifier = strings.TrimSuffix(specifier, ext)
+	}
+	return path.Join(path.Dir(esModuleName(filePath)), specifier)
+}
+
+// collectImports 收集 import 语句和 require 调用，返回 本地名称 -> 导入来源
+func (p *esParser) collectImports(root *sitter.Node, code []byte, filePath string) map[string]esImport {
+	imports := make(map[string]esImport)
+
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "import_statement":
+			source := node.ChildByFieldName("source")
+			if source == nil {
+				return
+			}
+			module := p.resolveModule(p.literalValue(source, code), filePath)
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				clause := node.NamedChild(i)
+				if clause.Type() != "import_clause" {
+					continue
+				}
+				for j := 0; j < int(clause.NamedChildCount()); j++ {
+					binding := clause.NamedChild(j)
+					switch binding.Type() {
+					case "identifier":
+						imports[binding.Content(code)] = esImport{module: module, name: "default"}
+					case "namespace_import":
+						if binding.NamedChildCount() > 0 {
+							imports[binding.NamedChild(0).Content(code)] = esImport{module: module, name: "*"}
+						}
+					case "named_imports":
+						for k := 0; k < int(binding.NamedChildCount()); k++ {
+							specifier := binding.NamedChild(k)
+							name, alias := specifier.ChildByFieldName("name"), specifier.ChildByFieldName("alias")
+							if name == nil {
+								continue
+							}
+							local := name
+							if alias != nil {
+								local = alias
+							}
+							imports[local.Content(code)] = esImport{module: module, name: name.Content(code)}
+						}
+					}
+				}
+			}
+			return
+		case "variable_declarator":
+			// const x = require('m') 与 const { a, b: c } = require('m')
+			name, value := node.ChildByFieldName("name"), node.ChildByFieldName("value")
+			if name == nil || value == nil {
+				break
+			}
+			specifier, ok := p.requireSpecifier(value, code)
+			if !ok {
+				break
+			}
+			module := p.resolveModule(specifier, filePath)
+			switch name.Type() {
+			case "identifier":
+				imports[name.Content(code)] = esImport{module: module, name: "*"}
+			case "object_pattern":
+				for i := 0; i < int(name.NamedChildCount()); i++ {
+					property := name.NamedChild(i)
+					switch property.Type() {
+					case "shorthand_property_identifier_pattern":
+						imports[property.Content(code)] = esImport{module: module, name: property.Content(code)}
+					case "pair_pattern":
+						if key, local := property.ChildByFieldName("key"), property.ChildByFieldName("value"); key != nil && local != nil {
+							imports[local.Content(code)] = esImport{module: module, name: key.Content(code)}
+						}
+					}
+				}
+			}
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(root)
+	return imports
+}
+
+// requireSpecifier 判断表达式是否为 require('m') 调用并返回模块说明符
+func (p *esParser) requireSpecifier(node *sitter.Node, code []byte) (string, bool) {
+	if node.Type() != "call_expression" {
+		return "", false
+	}
+	function, args := node.ChildByFieldName("function"), node.ChildByFieldName("arguments")
+	if function == nil || args == nil || function.Content(code) != "require" || args.NamedChildCount() == 0 {
+		return "", false
+	}
+	if arg := args.NamedChild(0); arg.Type() == "string" {
+		return p.literalValue(arg, code), true
+	}
+	return "", false
+}
+
+// resolveName 将源码中的类名或点分名称解析为 模块路径.名称，依次查导入、全局对象，最后视为当前模块中的定义
+func (p *esParser) resolveName(name, moduleName string, imports map[string]esImport) string {
+	name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), ":"))
+	// 去掉泛型参数与数组维度
+	if idx := strings.Index(name, "<"); idx != -1 {
+		name = name[:idx]
+	}
+	name = strings.TrimSuffix(name, "[]")
+	if name == "" || esPrimitiveTypes[name] {
+		return ""
+	}
+	head, rest, _ := strings.Cut(name, ".")
+	if imp, ok := imports[head]; ok {
+		switch {
+		case imp.name != "*" && imp.name != "default":
+			return joinDotted(imp.module, joinDotted(imp.name, rest))
+		case rest != "":
+			return imp.module + "." + rest
+		default:
+			// 默认导出或整个模块按本地名称作为类名
+			return imp.module + "." + head
+		}
+	}
+	if esGlobals[head] {
+		return name
+	}
+	return joinDotted(moduleName, name)
+}
+
+// esPrimitiveTypes TypeScript 的基本类型，不参与类型推断
+var esPrimitiveTypes = map[string]bool{
+	"string": true, "number": true, "boolean": true, "any": true, "unknown": true, "void": true,
+	"never": true, "object": true, "null": true, "undefined": true, "bigint": true, "symbol": true,
+}
+
+func (p *esParser) traverseNode(node *sitter.Node, filePath string, code []byte, moduleName string, imports map[string]esImport, scope *esScope, nodes *[]UniversalASTNode) {
+	// 子节点默认沿用当前作用域，进入类或函数时替换为新的作用域
+	childScope := scope
+
+	switch node.Type() {
+	case "class_declaration", "abstract_class_declaration", "interface_declaration", "class":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		className := nameNode.Content(code)
+		id := fmt.Sprintf("%s:%s:%d", filePath, className, node.StartByte())
+		nodeName, fullName := className, joinDotted(moduleName, className)
+		if scope.classID != "" {
+			nodeName, fullName = nestedClassName(moduleName, scope.className, className), scope.className+"$"+className
+		}
+
+		classNode := UniversalASTNode{
+			ID:          id,
+			Language:    p.language,
+			Type:        "Class",
+			Name:        nodeName,
+			File:        filePath,
+			Package:     moduleName,
+			StartLine:   int(node.StartPoint().Row),
+			EndLine:     int(node.EndPoint().Row),
+			Fields:      make([]FieldInfo, 0),
+			Metadata:    map[string]string{},
+			Annotations: p.collectDecorators(node, code, moduleName, imports),
+			Modifiers:   p.collectModifiers(node, code),
+			TypeParams:  p.collectTypeParams(node, code),
+		}
+
+		var superFullNames []string
+		superClass := ""
+		for _, super := range p.superTypes(node, code) {
+			fq := p.resolveName(super.name, moduleName, imports)
+			if fq == "" {
+				continue
+			}
+			if super.extends && superClass == "" {
+				superClass = fq
+			}
+			superFullNames = append(superFullNames, fq)
+			pkg, name := "", fq
+			if idx := strings.LastIndex(fq, "."); idx != -1 {
+				pkg, name = fq[:idx], fq[idx+1:]
+			}
+			classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: name})
+		}
+		classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+
+		if body := node.ChildByFieldName("body"); body != nil {
+			p.collectClassFields(body, code, &classNode)
+		}
+		*nodes = append(*nodes, classNode)
+
+		childScope = &esScope{
+			classID:    id,
+			className:  fullName,
+			superClass: superClass,
+			fieldTypes: make(map[string]string),
+			varTypes:   make(map[string]string),
+		}
+		for _, field := range classNode.Fields {
+			fieldType := p.resolveName(field.Type, moduleName, imports)
+			childScope.fieldTypes[field.Name] = fieldType
+			*nodes = append(*nodes, UniversalASTNode{
+				ID:        fmt.Sprintf("%s:%s.%s:%d", filePath, className, field.Name, field.StartLine),
+				Language:  p.language,
+				Type:      "Field",
+				Name:      field.Name,
+				File:      filePath,
+				Package:   moduleName,
+				StartLine: field.StartLine,
+				EndLine:   field.EndLine,
+				Modifiers: field.Modifiers,
+				Metadata: map[string]string{
+					"fieldType": field.Type,
+					"fullType":  fieldType,
+					"classID":   id,
+					"className": fullName,
+				},
+			})
+		}
+	case "method_definition", "method_signature", "abstract_method_signature",
+		"function_declaration", "generator_function_declaration",
+		"function_expression", "function", "arrow_function", "generator_function":
+		funcName := p.functionName(node, code)
+		if funcName == "" {
+			// 匿名回调（如 app.get('/x', (req, res) => {...})）中的调用归属外层函数
+			childScope = p.functionScope(scope, scope.methodID)
+			p.bindParams(node, code, moduleName, imports, childScope)
+			break
+		}
+		id := fmt.Sprintf("%s:%s:%d", filePath, funcName, node.StartByte())
+		isMethod := strings.HasPrefix(node.Type(), "method_") || node.Type() == "abstract_method_signature"
+		childScope = p.functionScope(scope, id)
+		params, methodParams := p.bindParams(node, code, moduleName, imports, childScope)
+
+		typeParams := p.collectTypeParams(node, code)
+		returnType, returnFullType := "", ""
+		if returnNode := node.ChildByFieldName("return_type"); returnNode != nil {
+			returnType = strings.TrimSpace(strings.TrimPrefix(returnNode.Content(code), ":"))
+			// 返回方法自身的泛型参数时无法推断
+			returnFullType = p.resolveName(returnType, moduleName, imports)
+			for _, typeParam := range typeParams {
+				if strings.Fields(typeParam)[0] == strings.TrimSuffix(returnType, "[]") {
+					returnFullType = ""
+				}
+			}
+		}
+
+		nodeType, owner, classID := "Function", moduleName, ""
+		if isMethod && scope.classID != "" {
+			nodeType, owner, classID = "Method", scope.className, scope.classID
+		}
+		*nodes = append(*nodes, UniversalASTNode{
+			ID:           id,
+			Language:     p.language,
+			Type:         nodeType,
+			Name:         funcName,
+			File:         filePath,
+			Package:      moduleName,
+			StartLine:    int(node.StartPoint().Row),
+			EndLine:      int(node.EndPoint().Row),
+			MethodParams: methodParams,
+			Params:       params,
+			Annotations:  p.collectDecorators(node, code, moduleName, imports),
+			Modifiers:    p.collectModifiers(node, code),
+			TypeParams:   typeParams,
+			Metadata: map[string]string{
+				"returnType":     returnType,
+				"returnFullType": returnFullType,
+				"classID":        classID,
+				"className":      owner,
+			},
+		})
+	case "variable_declarator":
+		// 记录变量类型：const x: Foo = ...、const x = new Foo()；require 绑定已作为导入处理
+		name, value := node.ChildByFieldName("name"), node.ChildByFieldName("value")
+		if name == nil || name.Type() != "identifier" {
+			break
+		}
+		if value != nil {
+			if _, isRequire := p.requireSpecifier(value, code); isRequire {
+				break
+			}
+		}
+		varType := ""
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			varType = p.resolveName(typeNode.Content(code), moduleName, imports)
+		} else if value != nil {
+			varType = p.expressionType(value, code, moduleName, imports, scope)
+		}
+		scope.varTypes[name.Content(code)] = varType
+	case "call_expression", "new_expression":
+		if callNode, ok := p.callNode(node, filePath, code, moduleName, imports, scope); ok {
+			*nodes = append(*nodes, callNode)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		// 装饰器已记录为注解，不再作为调用处理
+		if child == nil || child.Type() == "decorator" {
+			continue
+		}
+		p.traverseNode(child, filePath, code, moduleName, imports, childScope, nodes)
+	}
+}
+
+// functionScope 创建函数体的作用域，闭包可以访问外层函数的变量
+func (p *esParser) functionScope(scope *esScope, methodID string) *esScope {
+	childScope := &esScope{
+		classID:    scope.classID,
+		className:  scope.className,
+		superClass: scope.superClass,
+		methodID:   methodID,
+		fieldTypes: scope.fieldTypes,
+		varTypes:   make(map[string]string),
+	}
+	for name, varType := range scope.varTypes {
+		childScope.varTypes[name] = varType
+	}
+	return childScope
+}
+
+// functionName 返回函数的名称：声明或方法的名称，以及函数表达式、箭头函数绑定到的变量名、
+// exports.x / module.exports.x 属性名或对象字面量中的属性名。匿名函数返回空
+func (p *esParser) functionName(node *sitter.Node, code []byte) string {
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Content(code)
+	}
+	parent := node.Parent()
+	if parent == nil {
+		return ""
+	}
+	switch parent.Type() {
+	case "variable_declarator":
+		if name := parent.ChildByFieldName("name"); name != nil && name.Type() == "identifier" {
+			return name.Content(code)
+		}
+	case "assignment_expression":
+		if left := parent.ChildByFieldName("left"); left != nil {
+			if left.Type() == "identifier" {
+				return left.Content(code)
+			}
+			if property := left.ChildByFieldName("property"); left.Type() == "member_expression" && property != nil {
+				return property.Content(code)
+			}
+		}
+	case "pair", "public_field_definition", "field_definition":
+		for _, field := range []string{"key", "name", "property"} {
+			if key := parent.ChildByFieldName(field); key != nil {
+				return strings.Trim(key.Content(code), "'\"")
+			}
+		}
+	}
+	return ""
+}
+
+// bindParams 解析函数的形参并记录到作用域的变量表，返回参数信息和用于签名匹配的类型：
+// 未标注类型的参数类型为 any，剩余参数追加 ...，默认值不计入签名。
+// JavaScript 调用时缺少的实参为 undefined，因此所有参数都是可选的；TypeScript 中只有 x?、带默认值的参数可选
+func (p *esParser) bindParams(node *sitter.Node, code []byte, moduleName string, imports map[string]esImport, scope *esScope) ([]ParamInfo, []string) {
+	var params []ParamInfo
+	var methodParams []string
+
+	var paramNodes []*sitter.Node
+	if single := node.ChildByFieldName("parameter"); single != nil {
+		// 单个参数的箭头函数 x => ...
+		paramNodes = append(paramNodes, single)
+	} else if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			paramNodes = append(paramNodes, paramsNode.NamedChild(i))
+		}
+	}
+
+	for _, paramNode := range paramNodes {
+		param := ParamInfo{Optional: p.language == "javascript"}
+		pattern := paramNode
+		switch paramNode.Type() {
+		case "required_parameter", "optional_parameter":
+			pattern = paramNode.ChildByFieldName("pattern")
+			if typeNode := paramNode.ChildByFieldName("type"); typeNode != nil {
+				param.Type = strings.TrimSpace(strings.TrimPrefix(typeNode.Content(code), ":"))
+			}
+			if paramNode.ChildByFieldName("value") != nil || paramNode.Type() == "optional_parameter" {
+				param.Optional = true
+			}
+			for i := 0; i < int(paramNode.NamedChildCount()); i++ {
+				if decorator := paramNode.NamedChild(i); decorator.Type() == "decorator" {
+					param.Annotations = append(param.Annotations, p.decoratorInfo(decorator, code, moduleName, imports))
+				}
+			}
+		case "assignment_pattern":
+			pattern = paramNode.ChildByFieldName("left")
+			param.Optional = true
+		case "comment":
+			continue
+		}
+		if pattern == nil {
+			continue
+		}
+		if pattern.Type() == "rest_pattern" {
+			param.Optional, param.Variadic = false, true
+			if pattern.NamedChildCount() > 0 {
+				pattern = pattern.NamedChild(0)
+			}
+		}
+		// 解构参数 { a, b } 没有单一的参数名，以原文记录
+		param.Name = pattern.Content(code)
+		if pattern.Type() == "identifier" {
+			scope.varTypes[param.Name] = p.resolveName(param.Type, moduleName, imports)
+		}
+
+		signature := param.Type
+		if signature == "" {
+			signature = "any"
+		}
+		if param.Variadic {
+			signature += "..."
+		}
+		params = append(params, param)
+		methodParams = append(methodParams, signature)
+	}
+	return params, methodParams
+}
+
+// esSuperType 类声明中的父类或实现的接口
+type esSuperType struct {
+	name    string
+	extends bool // extends 子句中的父类（而非 implements 的接口）
+}
+
+// superTypes 收集类的 extends / implements 以及接口的 extends 列表
+func (p *esParser) superTypes(node *sitter.Node, code []byte) []esSuperType {
+	var supers []esSuperType
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "class_heritage":
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				clause := child.NamedChild(j)
+				switch clause.Type() {
+				case "extends_clause":
+					if value := clause.ChildByFieldName("value"); value != nil {
+						supers = append(supers, esSuperType{name: value.Content(code), extends: true})
+					}
+				case "implements_clause":
+					for k := 0; k < int(clause.NamedChildCount()); k++ {
+						supers = append(supers, esSuperType{name: clause.NamedChild(k).Content(code)})
+					}
+				default:
+					// JavaScript 的 class_heritage 直接包含父类表达式
+					supers = append(supers, esSuperType{name: clause.Content(code), extends: true})
+				}
+			}
+		case "extends_type_clause":
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				supers = append(supers, esSuperType{name: child.NamedChild(j).Content(code)})
+			}
+		}
+	}
+	return supers
+}
+
+// collectClassFields 收集类字段，以及 TypeScript 构造函数中带访问修饰符的参数属性
+func (p *esParser) collectClassFields(body *sitter.Node, code []byte, classNode *UniversalASTNode) {
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		member := body.NamedChild(i)
+		switch member.Type() {
+		case "public_field_definition", "field_definition", "property_signature":
+			name := member.ChildByFieldName("name")
+			if name == nil {
+				name = member.ChildByFieldName("property")
+			}
+			if name == nil {
+				continue
+			}
+			field := FieldInfo{
+				Name:      name.Content(code),
+				StartLine: int(member.StartPoint().Row),
+				EndLine:   int(member.EndPoint().Row),
+				Modifiers: p.collectModifiers(member, code),
+				Metadata:  map[string]string{},
+			}
+			if typeNode := member.ChildByFieldName("type"); typeNode != nil {
+				field.Type = strings.TrimSpace(strings.TrimPrefix(typeNode.Content(code), ":"))
+			} else if value := member.ChildByFieldName("value"); value != nil && value.Type() == "new_expression" {
+				if constructor := value.ChildByFieldName("constructor"); constructor != nil {
+					field.Type = constructor.Content(code)
+				}
+			}
+			classNode.Fields = append(classNode.Fields, field)
+		case "method_definition":
+			if name := member.ChildByFieldName("name"); name == nil || name.Content(code) != "constructor" {
+				continue
+			}
+			params := member.ChildByFieldName("parameters")
+			if params == nil {
+				continue
+			}
+			for j := 0; j < int(params.NamedChildCount()); j++ {
+				param := params.NamedChild(j)
+				modifiers := p.collectModifiers(param, code)
+				pattern := param.ChildByFieldName("pattern")
+				if len(modifiers) == 0 || pattern == nil {
+					continue
+				}
+				field := FieldInfo{
+					Name:      pattern.Content(code),
+					StartLine: int(param.StartPoint().Row),
+					EndLine:   int(param.EndPoint().Row),
+					Modifiers: modifiers,
+					Metadata:  map[string]string{},
+				}
+				if typeNode := param.ChildByFieldName("type"); typeNode != nil {
+					field.Type = strings.TrimSpace(strings.TrimPrefix(typeNode.Content(code), ":"))
+				}
+				classNode.Fields = append(classNode.Fields, field)
+			}
+		}
+	}
+}
+
+// esModifierKeywords 作为修饰符记录的关键字
+var esModifierKeywords = map[string]bool{
+	"static": true, "async": true, "get": true, "set": true, "readonly": true, "abstract": true,
+	"override": true, "declare": true, "export": true, "default": true, "*": true,
+}
+
+// collectModifiers 收集声明上的修饰符关键字和访问修饰符；导出的声明追加 export
+func (p *esParser) collectModifiers(node *sitter.Node, code []byte) []string {
+	var modifiers []string
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		switch {
+		case child.Type() == "accessibility_modifier" || child.Type() == "override_modifier":
+			modifiers = append(modifiers, child.Content(code))
+		case !child.IsNamed() && esModifierKeywords[child.Type()]:
+			modifiers = append(modifiers, child.Type())
+		}
+	}
+	if node.Type() == "abstract_class_declaration" {
+		modifiers = appendUnique(modifiers, "abstract")
+	}
+	// 绑定到变量的函数表达式看变量声明是否被导出
+	parent := node.Parent()
+	for parent != nil && (parent.Type() == "variable_declarator" || parent.Type() == "lexical_declaration" || parent.Type() == "variable_declaration") {
+		parent = parent.Parent()
+	}
+	if parent != nil && parent.Type() == "export_statement" {
+		modifiers = append(modifiers, "export")
+	}
+	// 生成器函数的 * 改为可读的 generator
+	for i, modifier := range modifiers {
+		if modifier == "*" {
+			modifiers[i] = "generator"
+		}
+	}
+	return modifiers
+}
+
+// collectTypeParams 收集 TypeScript 泛型类型参数
+func (p *esParser) collectTypeParams(node *sitter.Node, code []byte) []string {
+	var typeParams []string
+	if paramsNode := node.ChildByFieldName("type_parameters"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			typeParams = append(typeParams, paramsNode.NamedChild(i).Content(code))
+		}
+	}
+	return typeParams
+}
+
+// collectDecorators 收集声明上的装饰器：类的装饰器位于声明或 export 语句中，方法的装饰器位于类体中方法之前
+func (p *esParser) collectDecorators(node *sitter.Node, code []byte, moduleName string, imports map[string]esImport) []AnnotationInfo {
+	var decorators []*sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "decorator" {
+			decorators = append(decorators, child)
+		}
+	}
+	if parent := node.Parent(); parent != nil {
+		switch parent.Type() {
+		case "export_statement":
+			for i := 0; i < int(parent.NamedChildCount()); i++ {
+				if child := parent.NamedChild(i); child.Type() == "decorator" {
+					decorators = append(decorators, child)
+				}
+			}
+		case "class_body":
+			var preceding []*sitter.Node
+			for sibling := node.PrevNamedSibling(); sibling != nil && sibling.Type() == "decorator"; sibling = sibling.PrevNamedSibling() {
+				preceding = append([]*sitter.Node{sibling}, preceding...)
+			}
+			decorators = append(preceding, decorators...)
+		}
+	}
+
+	var annotations []AnnotationInfo
+	for _, decorator := range decorators {
+		annotations = append(annotations, p.decoratorInfo(decorator, code, moduleName, imports))
+	}
+	return annotations
+}
+
+// decoratorInfo 将装饰器转换为注解：第一个位置参数记为 value，对象字面量参数按属性名记录
+func (p *esParser) decoratorInfo(decorator *sitter.Node, code []byte, moduleName string, imports map[string]esImport) AnnotationInfo {
+	annotation := AnnotationInfo{Arguments: map[string]string{}}
+	if decorator.NamedChildCount() == 0 {
+		return annotation
+	}
+	expr := decorator.NamedChild(0)
+	var argsNode *sitter.Node
+	if expr.Type() == "call_expression" {
+		argsNode = expr.ChildByFieldName("arguments")
+		expr = expr.ChildByFieldName("function")
+	}
+	if expr == nil {
+		return annotation
+	}
+	dotted := expr.Content(code)
+	annotation.Name = dotted[strings.LastIndex(dotted, ".")+1:]
+	annotation.FullName = p.resolveName(dotted, moduleName, imports)
+
+	if argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			arg := argsNode.NamedChild(i)
+			if arg.Type() == "object" {
+				for j := 0; j < int(arg.NamedChildCount()); j++ {
+					pair := arg.NamedChild(j)
+					if key, value := pair.ChildByFieldName("key"), pair.ChildByFieldName("value"); pair.Type() == "pair" && key != nil && value != nil {
+						annotation.Arguments[strings.Trim(key.Content(code), "'\"")] = p.literalValue(value, code)
+					}
+				}
+			} else if _, exists := annotation.Arguments["value"]; !exists && arg.Type() != "comment" {
+				annotation.Arguments["value"] = p.literalValue(arg, code)
+			}
+		}
+	}
+	return annotation
+}
+
+// literalValue 提取字面量的值：字符串去掉引号，数组以逗号连接各元素
+func (p *esParser) literalValue(node *sitter.Node, code []byte) string {
+	switch node.Type() {
+	case "string":
+		var content strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			content.WriteString(node.NamedChild(i).Content(code))
+		}
+		return content.String()
+	case "template_string":
+		return strings.Trim(node.Content(code), "`")
+	case "array":
+		var values []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); child.Type() != "comment" {
+				values = append(values, p.literalValue(child, code))
+			}
+		}
+		return strings.Join(values, ",")
+	}
+	return node.Content(code)
+}
+
+// callNode 为函数调用和 new 表达式创建 MethodCall 节点：
+// new Foo() 记为 <init>，obj.m() 按接收者推断类型，导入的函数和当前模块的函数以模块路径为接收者类型
+func (p *esParser) callNode(node *sitter.Node, filePath string, code []byte, moduleName string, imports map[string]esImport, scope *esScope) (UniversalASTNode, bool) {
+	name, receiver, receiverType, receiverCallID := "", "", "", ""
+	if node.Type() == "new_expression" {
+		constructor := node.ChildByFieldName("constructor")
+		if constructor == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiverType = "<init>", p.resolveName(constructor.Content(code), moduleName, imports)
+	} else {
+		function := node.ChildByFieldName("function")
+		if function == nil {
+			return UniversalASTNode{}, false
+		}
+		switch function.Type() {
+		case "identifier":
+			name = function.Content(code)
+			if _, isVar := scope.varTypes[name]; isVar {
+				// 调用保存在变量或参数中的函数，无法确定目标
+				break
+			}
+			if imp, ok := imports[name]; ok {
+				receiverType = imp.module
+				if imp.name != "*" && imp.name != "default" {
+					name = imp.name
+				}
+			} else if esGlobals[name] {
+				receiverType = "globalThis"
+			} else {
+				receiverType = moduleName
+			}
+		case "member_expression":
+			property, object := function.ChildByFieldName("property"), function.ChildByFieldName("object")
+			if property == nil || object == nil {
+				return UniversalASTNode{}, false
+			}
+			name, receiver = property.Content(code), object.Content(code)
+			receiverType = p.expressionType(object, code, moduleName, imports, scope)
+			// 链式调用的接收者类型在建立索引后按前一个调用的返回类型推断
+			if receiverType == "" && object.Type() == "call_expression" {
+				if inner := object.ChildByFieldName("function"); inner != nil {
+					innerName := inner.Content(code)
+					if innerProperty := inner.ChildByFieldName("property"); inner.Type() == "member_expression" && innerProperty != nil {
+						innerName = innerProperty.Content(code)
+					}
+					receiverCallID = callNodeID(filePath, innerName, int(object.StartByte()), int(object.EndByte()))
+				}
+			}
+		default:
+			// super(...) 以及立即执行函数等无法确定目标
+			return UniversalASTNode{}, false
+		}
+	}
+
+	var arguments []ExprInfo
+	if argsNode := node.ChildByFieldName("arguments"); argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			if arg := argsNode.NamedChild(i); arg.Type() != "comment" {
+				arguments = append(arguments, p.exprInfo(arg, code))
+			}
+		}
+	}
+
+	callNode := UniversalASTNode{
+		ID:        callNodeID(filePath, name, int(node.StartByte()), int(node.EndByte())),
+		Language:  p.language,
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      filePath,
+		Package:   moduleName,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	return callNode, true
+}
+
+// expressionType 推断表达式的类型：this、super、已知类型的变量和字段、new 表达式、导入的模块，无法推断时返回空
+func (p *esParser) expressionType(expr *sitter.Node, code []byte, moduleName string, imports map[string]esImport, scope *esScope) string {
+	switch expr.Type() {
+	case "this":
+		return scope.className
+	case "super":
+		return scope.superClass
+	case "identifier":
+		name := expr.Content(code)
+		if varType, ok := scope.varTypes[name]; ok {
+			return varType
+		}
+		if imp, ok := imports[name]; ok {
+			// 整个模块或默认导出的对象，如 fs、express
+			if imp.name == "*" || (imp.name == "default" && !isCapitalizedName(name)) {
+				return imp.module
+			}
+			return p.resolveName(name, moduleName, imports)
+		}
+		if esGlobals[name] {
+			return name
+		}
+		if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+			return p.resolveName(name, moduleName, imports)
+		}
+	case "member_expression":
+		object, property := expr.ChildByFieldName("object"), expr.ChildByFieldName("property")
+		if object != nil && property != nil && object.Type() == "this" {
+			return scope.fieldTypes[property.Content(code)]
+		}
+	case "new_expression":
+		if constructor := expr.ChildByFieldName("constructor"); constructor != nil {
+			return p.resolveName(constructor.Content(code), moduleName, imports)
+		}
+	case "as_expression", "satisfies_expression":
+		// x as Foo
+		if expr.NamedChildCount() > 1 {
+			return p.resolveName(expr.NamedChild(1).Content(code), moduleName, imports)
+		}
+	case "await_expression", "parenthesized_expression", "non_null_expression":
+		if expr.NamedChildCount() > 0 {
+			return p.expressionType(expr.NamedChild(0), code, moduleName, imports, scope)
+		}
+	case "string", "template_string":
+		return "String"
+	}
+	return ""
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名，this.x 形式的字段保留 this. 前缀
+func (p *esParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "identifier", "shorthand_property_identifier":
+			info.Uses = appendUnique(info.Uses, node.Content(code))
+			return
+		case "member_expression":
+			if object := node.ChildByFieldName("object"); object != nil {
+				if object.Type() == "this" {
+					info.Uses = appendUnique(info.Uses, node.Content(code))
+					return
+				}
+				walk(object)
+			}
+			return
+		case "pair":
+			if value := node.ChildByFieldName("value"); value != nil {
+				walk(value)
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(exprNode)
+	return info
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJavaScriptParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		source     string
+		parser     ASTParser
+		wantParams []string // 签名中的参数类型
+		wantInfo   []ParamInfo
+	}{
+		{
+			name:       "JavaScript 参数均可省略",
+			file:       "mod.js",
+			source:     "function f(a, b = 5, ...rest) {}\n",
+			parser:     &JavaScriptParser{},
+			wantParams: []string{"any", "any", "any..."},
+			wantInfo:   []ParamInfo{{Name: "a", Optional: true}, {Name: "b", Optional: true}, {Name: "rest", Variadic: true}},
+		},
+		{
+			name:       "TypeScript 可选参数与默认值",
+			file:       "mod.ts",
+			source:     "function f(a: string, b?: number, c: number = 5, ...rest: string[]) {}\n",
+			parser:     &TypeScriptParser{},
+			wantParams: []string{"string", "number", "number", "string[]..."},
+			wantInfo: []ParamInfo{
+				{Name: "a", Type: "string"},
+				{Name: "b", Type: "number", Optional: true},
+				{Name: "c", Type: "number", Optional: true},
+				{Name: "rest", Type: "string[]", Variadic: true},
+			},
+		},
+		{
+			name:       "箭头函数",
+			file:       "mod.ts",
+			source:     "const f = (req: Request, res) => {};\n",
+			parser:     &TypeScriptParser{},
+			wantParams: []string{"Request", "any"},
+			wantInfo:   []ParamInfo{{Name: "req", Type: "Request"}, {Name: "res"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, map[string]string{tt.file: tt.source}, tt.parser)
+			var found bool
+			for _, node := range index.index {
+				if node.Name != "f" || node.Type != "Function" {
+					continue
+				}
+				found = true
+				if !reflect.DeepEqual(node.MethodParams, tt.wantParams) {
+					t.Errorf("MethodParams = %q, want %q", node.MethodParams, tt.wantParams)
+				}
+				if !reflect.DeepEqual(node.Params, tt.wantInfo) {
+					t.Errorf("Params = %+v, want %+v", node.Params, tt.wantInfo)
+				}
+			}
+			if !found {
+				t.Fatalf("未找到函数 f")
+			}
+		})
+	}
+}
+
+func TestJavaScriptCallGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "模块或类.函数名"
+		want   []string
+	}{
+		{
+			name: "JavaScript 省略实参",
+			files: map[string]string{
+				"package.json": "{}",
+				"src/repo.js": `export class Repo {
+  constructor(url, options) {}
+  find(key, limit) { return key; }
+}
+`,
+				"src/ctl.js": `import { Repo } from './repo';
+
+export function show(key) {
+  const repo = new Repo('db');
+  return repo.find(key);
+}
+`,
+			},
+			caller: "src/ctl.show",
+			want:   []string{"src/repo.Repo.constructor/2", "src/repo.Repo.find/2"},
+		},
+		{
+			name: "TypeScript 可选参数",
+			files: map[string]string{
+				"package.json": "{}",
+				"src/repo.ts": `export class Repo {
+  find(key: string, limit?: number): string { return key; }
+  save(key: string, value: string): void {}
+}
+`,
+				"src/ctl.ts": `import { Repo } from './repo';
+
+export function show(repo: Repo, key: string) {
+  repo.save(key);
+  return repo.find(key);
+}
+`,
+			},
+			caller: "src/ctl.show",
+			want:   []string{"src/repo.Repo.find/2"},
+		},
+		{
+			name: "TypeScript 剩余参数",
+			files: map[string]string{
+				"package.json": "{}",
+				"src/log.ts": `export function log(fmt: string, ...args: any[]): void {}
+`,
+				"src/ctl.ts": `import { log } from './log';
+
+export function show(key: string) {
+  log('%s %s', key, 1);
+}
+`,
+			},
+			caller: "src/ctl.show",
+			want:   []string{"src/log.log/2"},
+		},
+		{
+			name: "方法中声明的类",
+			files: map[string]string{
+				"package.json": "{}",
+				"src/job.js": `export class Job {
+  start(cmd) {
+    class Step {
+      run(cmd) {}
+    }
+    const step = new Step();
+    step.run(cmd);
+  }
+}
+`,
+			},
+			caller: "src/job.Job.start",
+			want:   []string{"src/job.Job$Step.run/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &JavaScriptParser{}, &TypeScriptParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, function := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, function); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,957 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/kotlin"
+)
+
+// KotlinParser 实现 Kotlin 语言的 AST 解析，节点的组织方式与 JavaParser 一致以便混合项目中互相解析：
+// class、interface、enum class、data class 和 object 记录为 Class，伴生对象的成员按静态成员并入外部类，
+// 主构造函数和次构造函数记录为与类同名的 Method，顶层函数和扩展函数记录为 Function（className 为包名）
+type KotlinParser struct{}
+
+// kotlinScope 记录遍历到当前节点时所处的类和函数上下文，用于推断调用的接收者类型
+type kotlinScope struct {
+	classID    string            // 所在类节点ID
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的父类全限定名
+	thisType   string            // this 的类型：类中为当前类，扩展函数中为被扩展的类型
+	methodID   string            // 所在函数节点ID
+	companion  bool              // 是否位于伴生对象中
+	fieldTypes map[string]string // 所在类的属性名 -> 类型全限定名
+	varTypes   map[string]string // 参数及局部变量名 -> 类型全限定名（未知时为空）
+}
+
+// kotlinJvmTypes Kotlin 内置类型在 JVM 上对应的 Java 类型
+var kotlinJvmTypes = map[string]string{
+	"String": "java.lang.String", "Any": "java.lang.Object", "CharSequence": "java.lang.CharSequence",
+	"Throwable": "java.lang.Throwable", "Comparable": "java.lang.Comparable",
+	"List": "java.util.List", "MutableList": "java.util.List", "ArrayList": "java.util.ArrayList",
+	"Map": "java.util.Map", "MutableMap": "java.util.Map", "HashMap": "java.util.HashMap",
+	"Set": "java.util.Set", "MutableSet": "java.util.Set", "HashSet": "java.util.HashSet",
+	"Collection": "java.util.Collection", "MutableCollection": "java.util.Collection",
+	"Iterable": "java.lang.Iterable", "Iterator": "java.util.Iterator",
+}
+
+// kotlinPrimitiveTypes 基本类型与特殊类型，不参与类型推断
+var kotlinPrimitiveTypes = map[string]bool{
+	"Int": true, "Long": true, "Short": true, "Byte": true, "Double": true, "Float": true,
+	"Char": true, "Boolean": true, "Unit": true, "Nothing": true, "Array": true,
+	"IntArray": true, "ByteArray": true, "CharArray": true, "LongArray": true,
+}
+
+// kotlinStdlibFunctions 常用的标准库顶层函数，调用时接收者类型记为 kotlin
+var kotlinStdlibFunctions = map[string]bool{
+	"println": true, "print": true, "readLine": true, "listOf": true, "mutableListOf": true,
+	"arrayOf": true, "mapOf": true, "mutableMapOf": true, "setOf": true, "mutableSetOf": true,
+	"emptyList": true, "emptyMap": true, "require": true, "requireNotNull": true, "check": true,
+	"error": true, "TODO": true, "run": true, "let": true, "apply": true, "also": true,
+	"with": true, "repeat": true, "lazy": true, "synchronized": true, "maxOf": true, "minOf": true,
+}
+
+func (p *KotlinParser) Language() string {
+	return "kotlin"
+}
+
+func (p *KotlinParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(kotlin.GetLanguage())
+	tree := parser.Parse(nil, code)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	packageName, importMap, importStar := p.collectHeader(root, code)
+	// 文件中声明的类优先于 import *，Kotlin 的一个文件可以声明多个类
+	declared := make(map[string]string)
+	p.collectDeclaredClasses(root, code, "", declared)
+	resolveType := func(name string) string {
+		head, rest, _ := strings.Cut(strings.TrimSuffix(name, "?"), ".")
+		if path, ok := declared[head]; ok {
+			return nestedTypeName(joinDotted(packageName, path), rest)
+		}
+		return p.resolveType(name, filepath.Dir(filePath), packageName, importMap, importStar)
+	}
+
+	var nodes []UniversalASTNode
+	p.traverseNode(root, filePath, code, packageName, importMap, resolveType, &kotlinScope{varTypes: map[string]string{}}, &nodes)
+	return nodes, nil
+}
+
+// collectHeader 读取包声明和 import 列表，import 按别名（没有别名时为最后一段）记录全限定名
+func (p *KotlinParser) collectHeader(root *sitter.Node, code []byte) (string, map[string]string, []string) {
+	packageName := ""
+	importMap := make(map[string]string)
+	var importStar []string
+
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+		switch child.Type() {
+		case "package_header":
+			if id := kotlinChild(child, "identifier"); id != nil {
+				packageName = id.Content(code)
+			}
+		case "import_list", "import_header":
+			headers := []*sitter.Node{child}
+			if child.Type() == "import_list" {
+				headers = nil
+				for j := 0; j < int(child.NamedChildCount()); j++ {
+					headers = append(headers, child.NamedChild(j))
+				}
+			}
+			for _, header := range headers {
+				id := kotlinChild(header, "identifier")
+				if id == nil {
+					continue
+				}
+				fullName := id.Content(code)
+				switch {
+				case kotlinChild(header, "wildcard_import") != nil:
+					importStar = append(importStar, fullName)
+				case kotlinChild(header, "import_alias") != nil:
+					alias := kotlinChild(header, "import_alias")
+					importMap[alias.NamedChild(0).Content(code)] = fullName
+				default:
+					importMap[ShortClassName(fullName)] = fullName
+				}
+			}
+		}
+	}
+	return packageName, importMap, importStar
+}
+
+// collectDeclaredClasses 收集文件中声明的类名（包括嵌套类），记录类名到相对包名的路径，嵌套类为 Outer$Inner
+func (p *KotlinParser) collectDeclaredClasses(node *sitter.Node, code []byte, outer string, declared map[string]string) {
+	if node.Type() == "class_declaration" || node.Type() == "object_declaration" {
+		if nameNode := kotlinChild(node, "type_identifier"); nameNode != nil {
+			name := nameNode.Content(code)
+			if outer != "" {
+				name = outer + "$" + name
+			}
+			if _, exists := declared[nameNode.Content(code)]; !exists {
+				declared[nameNode.Content(code)] = name
+			}
+			outer = name
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		p.collectDeclaredClasses(node.NamedChild(i), code, outer, declared)
+	}
+}
+
+// resolveType 将源码中的类型名解析为全限定名，Kotlin 内置类型映射为对应的 Java 类型
+func (p *KotlinParser) resolveType(typeName, dir, packageName string, importMap map[string]string, importStar []string) string {
+	typeName = strings.TrimSuffix(strings.TrimSpace(typeName), "?")
+	if idx := strings.Index(typeName, "<"); idx != -1 {
+		typeName = typeName[:idx]
+	}
+	if typeName == "" || kotlinPrimitiveTypes[typeName] || strings.ContainsAny(typeName, "(-") {
+		return ""
+	}
+	head, rest, _ := strings.Cut(typeName, ".")
+	if full, ok := importMap[head]; ok {
+		return nestedTypeName(full, rest)
+	}
+	if jvmType, ok := kotlinJvmTypes[typeName]; ok {
+		return jvmType
+	}
+	// 同包的 Kotlin 类可能与文件同名
+	if dir != "" && packageName != "" && rest == "" {
+		if _, err := os.Stat(filepath.Join(dir, typeName+".kt")); err == nil {
+			return packageName + "." + typeName
+		}
+	}
+	return resolveJavaType(typeName, dir, packageName, importMap, importStar)
+}
+
+// kotlinChild 返回第一个指定类型的命名子节点
+func kotlinChild(node *sitter.Node, nodeType string) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == nodeType {
+			return child
+		}
+	}
+	return nil
+}
+
+// kotlinTypeName 返回类型节点的类型名（不含泛型参数和可空标记），函数类型等返回原文
+func kotlinTypeName(typeNode *sitter.Node, code []byte) string {
+	switch typeNode.Type() {
+	case "user_type":
+		var parts []string
+		for i := 0; i < int(typeNode.NamedChildCount()); i++ {
+			if child := typeNode.NamedChild(i); child.Type() == "type_identifier" {
+				parts = append(parts, child.Content(code))
+			}
+		}
+		return strings.Join(parts, ".")
+	case "nullable_type", "parenthesized_type", "constructor_invocation", "explicit_delegation":
+		if typeNode.NamedChildCount() > 0 {
+			return kotlinTypeName(typeNode.NamedChild(0), code)
+		}
+	}
+	return typeNode.Content(code)
+}
+
+// isKotlinType 判断节点是否为类型节点
+func isKotlinType(node *sitter.Node) bool {
+	switch node.Type() {
+	case "user_type", "nullable_type", "function_type", "parenthesized_type":
+		return true
+	}
+	return false
+}
+
+func (p *KotlinParser) traverseNode(node *sitter.Node, filePath string, code []byte, packageName string, importMap map[string]string, resolveType func(string) string, scope *kotlinScope, nodes *[]UniversalASTNode) {
+	// 子节点默认沿用当前作用域，进入类或函数时替换为新的作用域
+	childScope := scope
+
+	switch node.Type() {
+	case "class_declaration", "object_declaration":
+		nameNode := kotlinChild(node, "type_identifier")
+		if nameNode == nil {
+			break
+		}
+		className := nameNode.Content(code)
+		id := fmt.Sprintf("%s:%s:%d", filePath, className, node.StartByte())
+		nodeName, fullName := className, joinDotted(packageName, className)
+		if scope.classID != "" {
+			nodeName, fullName = nestedClassName(packageName, scope.className, className), scope.className+"$"+className
+		}
+
+		kind := "class"
+		for i := 0; i < int(node.ChildCount()); i++ {
+			switch node.Child(i).Type() {
+			case "interface":
+				kind = "interface"
+			case "enum":
+				kind = "enum"
+			}
+		}
+		if node.Type() == "object_declaration" {
+			kind = "object"
+		}
+
+		classNode := UniversalASTNode{
+			ID:          id,
+			Language:    "kotlin",
+			Type:        "Class",
+			Name:        nodeName,
+			File:        filePath,
+			Package:     packageName,
+			StartLine:   int(node.StartPoint().Row),
+			EndLine:     int(node.EndPoint().Row),
+			Fields:      make([]FieldInfo, 0),
+			Metadata:    map[string]string{"kind": kind},
+			Annotations: p.collectAnnotations(node, code, resolveType),
+			Modifiers:   p.collectModifiers(node, code),
+			TypeParams:  p.collectTypeParams(node, code),
+		}
+
+		// 父类带构造调用（: Base()），接口没有；Kotlin 中未调用构造的第一个父类型也可能是父类
+		var superFullNames []string
+		superClass := ""
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			specifier := node.NamedChild(i)
+			if specifier.Type() != "delegation_specifier" || specifier.NamedChildCount() == 0 {
+				continue
+			}
+			typeNode := specifier.NamedChild(0)
+			fq := resolveType(kotlinTypeName(typeNode, code))
+			if fq == "" {
+				continue
+			}
+			if typeNode.Type() == "constructor_invocation" && superClass == "" {
+				superClass = fq
+			}
+			superFullNames = append(superFullNames, fq)
+			pkg, name := "", fq
+			if idx := strings.LastIndex(fq, "."); idx != -1 {
+				pkg, name = fq[:idx], fq[idx+1:]
+			}
+			classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: name})
+		}
+		classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+
+		p.collectClassFields(node, code, &classNode)
+		*nodes = append(*nodes, classNode)
+
+		childScope = &kotlinScope{
+			classID:    id,
+			className:  fullName,
+			superClass: superClass,
+			thisType:   fullName,
+			fieldTypes: make(map[string]string),
+			varTypes:   make(map[string]string),
+		}
+		for _, field := range classNode.Fields {
+			fieldType := resolveType(field.Type)
+			childScope.fieldTypes[field.Name] = fieldType
+			*nodes = append(*nodes, UniversalASTNode{
+				ID:          fmt.Sprintf("%s:%s.%s:%d", filePath, className, field.Name, field.StartLine),
+				Language:    "kotlin",
+				Type:        "Field",
+				Name:        field.Name,
+				File:        filePath,
+				Package:     packageName,
+				StartLine:   field.StartLine,
+				EndLine:     field.EndLine,
+				Annotations: field.Annotations,
+				Modifiers:   field.Modifiers,
+				Metadata: map[string]string{
+					"fieldType": field.Type,
+					"fullType":  fieldType,
+					"classID":   id,
+					"className": fullName,
+				},
+			})
+		}
+
+		// 主构造函数记录为与类同名的方法
+		if constructor := kotlinChild(node, "primary_constructor"); constructor != nil {
+			*nodes = append(*nodes, p.functionNode(constructor, className, "", filePath, code, packageName, resolveType, childScope))
+		}
+	case "companion_object":
+		// 伴生对象的成员按外部类的静态成员处理
+		companionScope := *scope
+		companionScope.companion = true
+		childScope = &companionScope
+	case "function_declaration", "secondary_constructor":
+		name := ""
+		if node.Type() == "secondary_constructor" {
+			name = ShortClassName(scope.className)
+		} else if nameNode := kotlinChild(node, "simple_identifier"); nameNode != nil {
+			name = nameNode.Content(code)
+		}
+		if name == "" {
+			break
+		}
+		// 扩展函数名前的类型为被扩展的类型
+		extended := ""
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child.Type() == "simple_identifier" {
+				break
+			}
+			if isKotlinType(child) {
+				extended = resolveType(kotlinTypeName(child, code))
+			}
+		}
+		functionNode := p.functionNode(node, name, extended, filePath, code, packageName, resolveType, scope)
+		*nodes = append(*nodes, functionNode)
+
+		childScope = &kotlinScope{
+			classID:    scope.classID,
+			className:  scope.className,
+			superClass: scope.superClass,
+			thisType:   scope.thisType,
+			methodID:   functionNode.ID,
+			fieldTypes: scope.fieldTypes,
+			varTypes:   make(map[string]string),
+		}
+		if extended != "" {
+			childScope.thisType = extended
+		}
+		for _, param := range functionNode.Params {
+			childScope.varTypes[param.Name] = resolveType(strings.TrimSuffix(param.Type, "..."))
+		}
+	case "anonymous_initializer":
+		// init 块中的调用归属主构造函数
+		if constructor := p.primaryConstructorID(node, filePath, code); constructor != "" {
+			initScope := *scope
+			initScope.methodID = constructor
+			childScope = &initScope
+		}
+	case "lambda_literal":
+		// lambda 参数会遮蔽外层同名变量，类型未声明时记录为空
+		lambdaScope := *scope
+		lambdaScope.varTypes = make(map[string]string)
+		for name, varType := range scope.varTypes {
+			lambdaScope.varTypes[name] = varType
+		}
+		lambdaScope.varTypes["it"] = ""
+		if params := kotlinChild(node, "lambda_parameters"); params != nil {
+			for i := 0; i < int(params.NamedChildCount()); i++ {
+				if declaration := params.NamedChild(i); declaration.Type() == "variable_declaration" {
+					if nameNode := kotlinChild(declaration, "simple_identifier"); nameNode != nil {
+						lambdaScope.varTypes[nameNode.Content(code)] = p.declaredType(declaration, code, resolveType)
+					}
+				}
+			}
+		}
+		childScope = &lambdaScope
+	case "property_declaration":
+		// 函数中的局部变量：val x: Foo = ...、val x = Foo()
+		if scope.methodID == "" {
+			break
+		}
+		declaration := kotlinChild(node, "variable_declaration")
+		if declaration == nil {
+			break
+		}
+		nameNode := kotlinChild(declaration, "simple_identifier")
+		if nameNode == nil {
+			break
+		}
+		varType := p.declaredType(declaration, code, resolveType)
+		if varType == "" {
+			if value := declaration.NextNamedSibling(); value != nil {
+				varType = p.expressionType(value, code, packageName, resolveType, scope)
+			}
+		}
+		scope.varTypes[nameNode.Content(code)] = varType
+	case "call_expression":
+		if callNode, ok := p.callNode(node, filePath, code, packageName, importMap, resolveType, scope); ok {
+			*nodes = append(*nodes, callNode)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		// 注解已记录到声明上，不再作为调用处理
+		if child == nil || child.Type() == "modifiers" || child.Type() == "annotation" {
+			continue
+		}
+		p.traverseNode(child, filePath, code, packageName, importMap, resolveType, childScope, nodes)
+	}
+}
+
+// primaryConstructorID 返回 init 块所在类的主构造函数节点ID，没有主构造函数时返回空
+func (p *KotlinParser) primaryConstructorID(node *sitter.Node, filePath string, code []byte) string {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Type() != "class_declaration" {
+			continue
+		}
+		constructor := kotlinChild(parent, "primary_constructor")
+		name := kotlinChild(parent, "type_identifier")
+		if constructor == nil || name == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s:%s:%d", filePath, name.Content(code), constructor.StartByte())
+	}
+	return ""
+}
+
+// declaredType 返回变量声明中标注的类型（全限定名），未标注时返回空
+func (p *KotlinParser) declaredType(declaration *sitter.Node, code []byte, resolveType func(string) string) string {
+	for i := 0; i < int(declaration.NamedChildCount()); i++ {
+		if child := declaration.NamedChild(i); isKotlinType(child) {
+			return resolveType(kotlinTypeName(child, code))
+		}
+	}
+	return ""
+}
+
+// functionNode 为函数、构造函数创建节点：类中的记录为 Method，顶层函数和扩展函数记录为 Function。
+// extended 为扩展函数被扩展类型的全限定名，用于按接收者类型解析扩展函数调用
+func (p *KotlinParser) functionNode(node *sitter.Node, name, extended, filePath string, code []byte, packageName string, resolveType func(string) string, scope *kotlinScope) UniversalASTNode {
+	id := fmt.Sprintf("%s:%s:%d", filePath, name, node.StartByte())
+	params, methodParams := p.collectParams(node, code, resolveType)
+
+	// 返回类型位于参数列表之后
+	returnType := ""
+	afterParams := false
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() == "function_value_parameters" {
+			afterParams = true
+		} else if afterParams && isKotlinType(child) {
+			returnType = child.Content(code)
+			break
+		}
+	}
+	typeParams := p.collectTypeParams(node, code)
+	returnFullType := resolveType(returnType)
+	for _, typeParam := range typeParams {
+		if typeParam == strings.TrimSuffix(returnType, "?") {
+			returnFullType = ""
+		}
+	}
+
+	modifiers := p.collectModifiers(node, code)
+	if scope.companion {
+		modifiers = appendUnique(modifiers, "static")
+	}
+
+	nodeType, owner, classID := "Function", packageName, ""
+	if scope.classID != "" && extended == "" {
+		nodeType, owner, classID = "Method", scope.className, scope.classID
+	}
+	metadata := map[string]string{
+		"returnType":     returnType,
+		"returnFullType": returnFullType,
+		"classID":        classID,
+		"className":      owner,
+	}
+	if extended != "" {
+		metadata["extensionReceiver"] = extended
+	}
+	return UniversalASTNode{
+		ID:           id,
+		Language:     "kotlin",
+		Type:         nodeType,
+		Name:         name,
+		File:         filePath,
+		Package:      packageName,
+		StartLine:    int(node.StartPoint().Row),
+		EndLine:      int(node.EndPoint().Row),
+		MethodParams: methodParams,
+		Params:       params,
+		Annotations:  p.collectAnnotations(node, code, resolveType),
+		Modifiers:    modifiers,
+		TypeParams:   typeParams,
+		Metadata:     metadata,
+	}
+}
+
+// collectParams 解析函数参数或主构造函数参数：vararg 参数类型追加 ...，默认值不计入签名
+func (p *KotlinParser) collectParams(node *sitter.Node, code []byte, resolveType func(string) string) ([]ParamInfo, []string) {
+	paramsNode := kotlinChild(node, "function_value_parameters")
+	if node.Type() == "primary_constructor" {
+		paramsNode = node
+	}
+	if paramsNode == nil {
+		return nil, nil
+	}
+
+	var params []ParamInfo
+	var methodParams []string
+	vararg := false
+	for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+		child := paramsNode.NamedChild(i)
+		switch child.Type() {
+		case "parameter_modifiers":
+			// 修饰符节点位于所修饰的参数之前
+			vararg = strings.Contains(child.Content(code), "vararg")
+		case "parameter", "class_parameter":
+			param := ParamInfo{}
+			afterType := false
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				part := child.NamedChild(j)
+				switch {
+				case part.Type() == "modifiers":
+					param.Annotations = p.collectAnnotations(child, code, resolveType)
+					if strings.Contains(part.Content(code), "vararg") {
+						vararg = true
+					}
+				case part.Type() == "simple_identifier" && param.Name == "":
+					param.Name = part.Content(code)
+				case isKotlinType(part) && !afterType:
+					param.Type = part.Content(code)
+					afterType = true
+				case afterType && part.Type() != "binding_pattern_kind":
+					param.Optional = true
+				}
+			}
+			if vararg {
+				param.Type += "..."
+				param.Variadic = true
+			}
+			// 函数参数的默认值是紧随其后的兄弟节点
+			if next := child.NextNamedSibling(); child.Type() == "parameter" && next != nil &&
+				next.Type() != "parameter" && next.Type() != "parameter_modifiers" {
+				param.Optional = true
+			}
+			params = append(params, param)
+			methodParams = append(methodParams, param.Type)
+			vararg = false
+		}
+	}
+	return params, methodParams
+}
+
+// collectClassFields 收集类属性、主构造函数中 val / var 声明的属性、伴生对象中的属性（记为 static）以及枚举常量
+func (p *KotlinParser) collectClassFields(classDecl *sitter.Node, code []byte, classNode *UniversalASTNode) {
+	if constructor := kotlinChild(classDecl, "primary_constructor"); constructor != nil {
+		for i := 0; i < int(constructor.NamedChildCount()); i++ {
+			param := constructor.NamedChild(i)
+			nameNode := kotlinChild(param, "simple_identifier")
+			if param.Type() != "class_parameter" || kotlinChild(param, "binding_pattern_kind") == nil || nameNode == nil {
+				continue
+			}
+			field := FieldInfo{
+				Name:        nameNode.Content(code),
+				StartLine:   int(param.StartPoint().Row),
+				EndLine:     int(param.EndPoint().Row),
+				Modifiers:   p.collectModifiers(param, code),
+				Annotations: p.collectAnnotations(param, code, nil),
+				Metadata:    map[string]string{},
+			}
+			for j := 0; j < int(param.NamedChildCount()); j++ {
+				if child := param.NamedChild(j); isKotlinType(child) {
+					field.Type = kotlinTypeName(child, code)
+					break
+				}
+			}
+			classNode.Fields = append(classNode.Fields, field)
+		}
+	}
+
+	var collectBody func(body *sitter.Node, static bool)
+	collectBody = func(body *sitter.Node, static bool) {
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			member := body.NamedChild(i)
+			switch member.Type() {
+			case "property_declaration":
+				declaration := kotlinChild(member, "variable_declaration")
+				if declaration == nil {
+					continue
+				}
+				nameNode := kotlinChild(declaration, "simple_identifier")
+				if nameNode == nil {
+					continue
+				}
+				field := FieldInfo{
+					Name:        nameNode.Content(code),
+					StartLine:   int(member.StartPoint().Row),
+					EndLine:     int(member.EndPoint().Row),
+					Modifiers:   p.collectModifiers(member, code),
+					Annotations: p.collectAnnotations(member, code, nil),
+					Metadata:    map[string]string{},
+				}
+				if static {
+					field.Modifiers = appendUnique(field.Modifiers, "static")
+				}
+				for j := 0; j < int(declaration.NamedChildCount()); j++ {
+					if child := declaration.NamedChild(j); isKotlinType(child) {
+						field.Type = kotlinTypeName(child, code)
+					}
+				}
+				// 未标注类型时按构造调用推断：val cache = Cache()
+				if value := declaration.NextNamedSibling(); field.Type == "" && value != nil && value.Type() == "call_expression" {
+					if callee := value.NamedChild(0); callee != nil && callee.Type() == "simple_identifier" && isCapitalizedName(callee.Content(code)) {
+						field.Type = callee.Content(code)
+					}
+				}
+				classNode.Fields = append(classNode.Fields, field)
+			case "enum_entry":
+				if nameNode := kotlinChild(member, "simple_identifier"); nameNode != nil {
+					classNode.Fields = append(classNode.Fields, FieldInfo{
+						Name:      nameNode.Content(code),
+						Type:      classNode.Name,
+						StartLine: int(member.StartPoint().Row),
+						EndLine:   int(member.EndPoint().Row),
+						Modifiers: []string{"static", "final"},
+						Metadata:  map[string]string{},
+					})
+				}
+			case "companion_object":
+				if companionBody := kotlinChild(member, "class_body"); companionBody != nil {
+					collectBody(companionBody, true)
+				}
+			}
+		}
+	}
+	if body := kotlinChild(classDecl, "class_body"); body != nil {
+		collectBody(body, false)
+	} else if body := kotlinChild(classDecl, "enum_class_body"); body != nil {
+		collectBody(body, false)
+	}
+}
+
+// collectModifiers 收集 modifiers 中除注解以外的修饰符，如 private、open、data、override、suspend
+func (p *KotlinParser) collectModifiers(declNode *sitter.Node, code []byte) []string {
+	var modifiers []string
+	modifiersNode := kotlinChild(declNode, "modifiers")
+	if modifiersNode == nil {
+		return modifiers
+	}
+	for i := 0; i < int(modifiersNode.NamedChildCount()); i++ {
+		if child := modifiersNode.NamedChild(i); child.Type() != "annotation" {
+			modifiers = append(modifiers, child.Content(code))
+		}
+	}
+	return modifiers
+}
+
+// collectTypeParams 收集泛型类型参数名
+func (p *KotlinParser) collectTypeParams(declNode *sitter.Node, code []byte) []string {
+	var typeParams []string
+	if paramsNode := kotlinChild(declNode, "type_parameters"); paramsNode != nil {
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			if nameNode := kotlinChild(paramsNode.NamedChild(i), "type_identifier"); nameNode != nil {
+				typeParams = append(typeParams, nameNode.Content(code))
+			}
+		}
+	}
+	return typeParams
+}
+
+// collectAnnotations 收集 modifiers 中的注解：第一个位置参数记为 value，命名参数按名称记录；
+// resolveType 为空时只记录注解名
+func (p *KotlinParser) collectAnnotations(declNode *sitter.Node, code []byte, resolveType func(string) string) []AnnotationInfo {
+	var annotations []AnnotationInfo
+	modifiersNode := kotlinChild(declNode, "modifiers")
+	if modifiersNode == nil {
+		return annotations
+	}
+	for i := 0; i < int(modifiersNode.NamedChildCount()); i++ {
+		annotationNode := modifiersNode.NamedChild(i)
+		if annotationNode.Type() != "annotation" {
+			continue
+		}
+		var typeNode, argsNode *sitter.Node
+		if invocation := kotlinChild(annotationNode, "constructor_invocation"); invocation != nil {
+			typeNode, argsNode = kotlinChild(invocation, "user_type"), kotlinChild(invocation, "value_arguments")
+		} else {
+			typeNode = kotlinChild(annotationNode, "user_type")
+		}
+		if typeNode == nil {
+			continue
+		}
+		name := kotlinTypeName(typeNode, code)
+		annotation := AnnotationInfo{
+			Name:      ShortClassName(name),
+			FullName:  name,
+			Arguments: map[string]string{},
+		}
+		if resolveType != nil {
+			annotation.FullName = resolveType(name)
+		}
+		if argsNode != nil {
+			for j := 0; j < int(argsNode.NamedChildCount()); j++ {
+				arg := argsNode.NamedChild(j)
+				if arg.NamedChildCount() == 0 {
+					continue
+				}
+				value := arg.NamedChild(int(arg.NamedChildCount()) - 1)
+				if arg.NamedChildCount() > 1 && arg.NamedChild(0).Type() == "simple_identifier" {
+					annotation.Arguments[arg.NamedChild(0).Content(code)] = p.literalValue(value, code)
+				} else if _, exists := annotation.Arguments["value"]; !exists {
+					annotation.Arguments["value"] = p.literalValue(value, code)
+				}
+			}
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
+
+// literalValue 提取字面量的值：字符串去掉引号，集合字面量以逗号连接各元素
+func (p *KotlinParser) literalValue(node *sitter.Node, code []byte) string {
+	switch node.Type() {
+	case "string_literal":
+		var content strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			content.WriteString(node.NamedChild(i).Content(code))
+		}
+		return content.String()
+	case "collection_literal":
+		var values []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			values = append(values, p.literalValue(node.NamedChild(i), code))
+		}
+		return strings.Join(values, ",")
+	}
+	return node.Content(code)
+}
+
+// callNode 为函数调用创建 MethodCall 节点：首字母大写的调用为构造调用（<init>），
+// 类中不带接收者的调用以当前类为接收者类型，找不到方法时由调用图回退到同包的顶层函数
+func (p *KotlinParser) callNode(node *sitter.Node, filePath string, code []byte, packageName string, importMap map[string]string, resolveType func(string) string, scope *kotlinScope) (UniversalASTNode, bool) {
+	callee := node.NamedChild(0)
+	suffix := kotlinChild(node, "call_suffix")
+	if callee == nil || suffix == nil {
+		return UniversalASTNode{}, false
+	}
+
+	name, receiver, receiverType, receiverCallID := "", "", "", ""
+	switch callee.Type() {
+	case "simple_identifier":
+		name = callee.Content(code)
+		_, isVar := scope.varTypes[name]
+		switch {
+		case isVar:
+			// 调用函数类型的变量，无法确定目标
+		case isCapitalizedName(name):
+			name, receiverType = "<init>", resolveType(name)
+		case importMap[name] != "":
+			receiverType = importMap[name][:max(strings.LastIndex(importMap[name], "."), 0)]
+		case kotlinStdlibFunctions[name]:
+			receiverType = "kotlin"
+		case scope.className != "":
+			receiverType = scope.className
+		default:
+			receiverType = packageName
+		}
+	case "navigation_expression":
+		object, navSuffix := callee.NamedChild(0), kotlinChild(callee, "navigation_suffix")
+		if object == nil || navSuffix == nil {
+			return UniversalASTNode{}, false
+		}
+		nameNode := kotlinChild(navSuffix, "simple_identifier")
+		if nameNode == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiver = nameNode.Content(code), object.Content(code)
+		if isCapitalizedName(name) && isDottedKotlinName(object) {
+			// 全限定类名的构造调用，如 java.io.File(path)
+			name, receiverType = "<init>", receiver+"."+name
+			receiver = ""
+			break
+		}
+		receiverType = p.expressionType(object, code, packageName, resolveType, scope)
+		// 链式调用的接收者类型在建立索引后按前一个调用的返回类型推断
+		if receiverType == "" && object.Type() == "call_expression" {
+			innerName := ""
+			if inner := object.NamedChild(0); inner != nil && inner.Type() == "simple_identifier" {
+				innerName = inner.Content(code)
+			} else if inner != nil && inner.Type() == "navigation_expression" {
+				if innerSuffix := kotlinChild(inner, "navigation_suffix"); innerSuffix != nil && innerSuffix.NamedChildCount() > 0 {
+					innerName = innerSuffix.NamedChild(0).Content(code)
+				}
+			}
+			if innerName != "" && !isCapitalizedName(innerName) {
+				receiverCallID = callNodeID(filePath, innerName, int(object.StartByte()), int(object.EndByte()))
+			}
+		}
+	default:
+		return UniversalASTNode{}, false
+	}
+
+	var arguments []ExprInfo
+	if argsNode := kotlinChild(suffix, "value_arguments"); argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			arg := argsNode.NamedChild(i)
+			if arg.NamedChildCount() > 0 {
+				arguments = append(arguments, p.exprInfo(arg.NamedChild(int(arg.NamedChildCount())-1), code))
+			}
+		}
+	}
+	// 尾随 lambda 也是一个实参
+	if lambda := kotlinChild(suffix, "annotated_lambda"); lambda != nil {
+		arguments = append(arguments, ExprInfo{Text: lambda.Content(code)})
+	}
+
+	callNode := UniversalASTNode{
+		ID:        callNodeID(filePath, name, int(node.StartByte()), int(node.EndByte())),
+		Language:  "kotlin",
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      filePath,
+		Package:   packageName,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	return callNode, true
+}
+
+// isDottedKotlinName 判断表达式是否为由标识符组成的点分名称，如 java.io
+func isDottedKotlinName(expr *sitter.Node) bool {
+	switch expr.Type() {
+	case "simple_identifier":
+		return true
+	case "navigation_expression":
+		return expr.NamedChildCount() == 2 && isDottedKotlinName(expr.NamedChild(0)) &&
+			expr.NamedChild(1).Type() == "navigation_suffix" && kotlinChild(expr.NamedChild(1), "simple_identifier") != nil
+	}
+	return false
+}
+
+// expressionType 推断表达式的类型：this、super、已知类型的变量和属性、构造调用、类名（静态调用）以及字符串字面量
+func (p *KotlinParser) expressionType(expr *sitter.Node, code []byte, packageName string, resolveType func(string) string, scope *kotlinScope) string {
+	switch expr.Type() {
+	case "this_expression":
+		return scope.thisType
+	case "super_expression":
+		return scope.superClass
+	case "simple_identifier":
+		name := expr.Content(code)
+		if varType, ok := scope.varTypes[name]; ok {
+			return varType
+		}
+		if fieldType, ok := scope.fieldTypes[name]; ok {
+			return fieldType
+		}
+		if isCapitalizedName(name) {
+			return resolveType(name)
+		}
+	case "navigation_expression":
+		object, navSuffix := expr.NamedChild(0), kotlinChild(expr, "navigation_suffix")
+		if object == nil || navSuffix == nil {
+			return ""
+		}
+		if object.Type() == "this_expression" && navSuffix.NamedChildCount() > 0 {
+			return scope.fieldTypes[navSuffix.NamedChild(0).Content(code)]
+		}
+		// 全限定类名，如 java.lang.Runtime
+		if isDottedKotlinName(expr) && isCapitalizedName(ShortClassName(expr.Content(code))) {
+			return resolveType(expr.Content(code))
+		}
+	case "call_expression":
+		if callee := expr.NamedChild(0); callee != nil && callee.Type() == "simple_identifier" && isCapitalizedName(callee.Content(code)) {
+			return resolveType(callee.Content(code))
+		}
+	case "as_expression":
+		if expr.NamedChildCount() > 1 {
+			return resolveType(kotlinTypeName(expr.NamedChild(int(expr.NamedChildCount())-1), code))
+		}
+	case "parenthesized_expression":
+		if expr.NamedChildCount() > 0 {
+			return p.expressionType(expr.NamedChild(0), code, packageName, resolveType, scope)
+		}
+	case "string_literal":
+		return "java.lang.String"
+	}
+	return ""
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名，this.x 形式的属性保留 this. 前缀
+func (p *KotlinParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "simple_identifier":
+			info.Uses = appendUnique(info.Uses, node.Content(code))
+			return
+		case "navigation_expression":
+			// 只看接收者部分，成员名不是变量
+			if object := node.NamedChild(0); object != nil {
+				if object.Type() == "this_expression" {
+					info.Uses = appendUnique(info.Uses, "this."+strings.TrimPrefix(node.Content(code)[len(object.Content(code)):], "."))
+					return
+				}
+				walk(object)
+			}
+			return
+		case "call_suffix":
+			if args := kotlinChild(node, "value_arguments"); args != nil {
+				walk(args)
+			}
+			return
+		case "value_argument":
+			// 命名参数的参数名不是变量
+			if node.NamedChildCount() > 0 {
+				walk(node.NamedChild(int(node.NamedChildCount()) - 1))
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(exprNode)
+	return info
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKotlinParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantParams []string // 签名中的参数类型
+		wantInfo   []ParamInfo
+	}{
+		{
+			name:       "默认参数不计入签名",
+			source:     "package app\n\nfun f(a: String, b: Int = 1) {}\n",
+			wantParams: []string{"String", "Int"},
+			wantInfo:   []ParamInfo{{Name: "a", Type: "String"}, {Name: "b", Type: "Int", Optional: true}},
+		},
+		{
+			name:       "可变参数",
+			source:     "package app\n\nfun f(fmt: String, vararg args: Any) {}\n",
+			wantParams: []string{"String", "Any..."},
+			wantInfo:   []ParamInfo{{Name: "fmt", Type: "String"}, {Name: "args", Type: "Any...", Variadic: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, map[string]string{"app/mod.kt": tt.source}, &KotlinParser{})
+			var found bool
+			for _, node := range index.index {
+				if node.Name != "f" || node.Type != "Function" {
+					continue
+				}
+				found = true
+				if !reflect.DeepEqual(node.MethodParams, tt.wantParams) {
+					t.Errorf("MethodParams = %q, want %q", node.MethodParams, tt.wantParams)
+				}
+				for i := range node.Params {
+					node.Params[i].Annotations = nil
+				}
+				if !reflect.DeepEqual(node.Params, tt.wantInfo) {
+					t.Errorf("Params = %+v, want %+v", node.Params, tt.wantInfo)
+				}
+			}
+			if !found {
+				t.Fatalf("未找到函数 f")
+			}
+		})
+	}
+}
+
+func TestKotlinCallGraph(t *testing.T) {
+	repoSource := `package app
+
+class Repo(val url: String, val timeout: Int = 10) {
+    fun find(key: String, limit: Int = 10): String = key
+}
+`
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "类或包名.方法名"
+		want   []string
+	}{
+		{
+			name: "构造与省略默认参数",
+			files: map[string]string{
+				"app/Repo.kt": repoSource,
+				"app/Ctl.kt": `package app
+
+class Ctl {
+    fun show(key: String): String {
+        val repo = Repo("db")
+        return repo.find(key)
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Repo.Repo/2", "app.Repo.find/2"},
+		},
+		{
+			name: "扩展函数与同包顶层函数",
+			files: map[string]string{
+				"app/Repo.kt": repoSource,
+				"app/Ext.kt": `package app
+
+fun Repo.findAll(vararg keys: String): List<String> = keys.map { find(it) }
+
+fun audit(message: String) {}
+`,
+				"app/Ctl.kt": `package app
+
+class Ctl {
+    fun show(repo: Repo) {
+        repo.findAll("a", "b")
+        audit("show")
+    }
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.audit/1", "app.findAll/1"},
+		},
+		{
+			name: "调用 Java 类",
+			files: map[string]string{
+				"app/Db.java": `package app;
+
+public class Db {
+    public String query(String sql) { return sql; }
+}
+`,
+				"app/Ctl.kt": `package app
+
+class Ctl(private val db: Db) {
+    fun show(sql: String) = db.query(sql)
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Db.query/1"},
+		},
+		{
+			name: "嵌套类",
+			files: map[string]string{
+				"app/Outer.kt": `package app
+
+class Outer {
+    class Inner {
+        fun run(cmd: String) {}
+    }
+}
+`,
+				"app/Ctl.kt": `package app
+
+class Ctl(private val runner: Outer.Inner) {
+    fun show(cmd: String) = runner.run(cmd)
+}
+`,
+			},
+			caller: "app.Ctl.show",
+			want:   []string{"app.Outer$Inner.run/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &JavaParser{}, &KotlinParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, method := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, method); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package utils
+
+import (
+	"Fenrir-CodeAuditTool/configs"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxParentDepth 父 POM 的最大继承层数，防止循环引用
+const maxParentDepth = 10
+
+// defaultMavenScopes 默认参与索引的依赖 scope，空 scope 等同于 compile
+var defaultMavenScopes = []string{"compile", "provided", "runtime"}
+
+// mavenPropertyPattern pom.xml 中的属性引用，如 ${spring.version}
+var mavenPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// MavenDependency pom.xml 中声明的一个依赖
+type MavenDependency struct {
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+	Version    string `json:"version"`
+	Scope      string `json:"scope"`
+	Type       string `json:"type,omitempty"`
+	Classifier string `json:"classifier,omitempty"`
+	Optional   bool   `json:"optional,omitempty"`
+	DeclaredIn string `json:"declaredIn"` // 声明依赖的 pom.xml
+}
+
+// Key 返回 groupId:artifactId
+func (d MavenDependency) Key() string {
+	return d.GroupID + ":" + d.ArtifactID
+}
+
+// Coordinate 返回 groupId:artifactId:version
+func (d MavenDependency) Coordinate() string {
+	return d.Key() + ":" + d.Version
+}
+
+// pomXML pom.xml 中用到的部分
+type pomXML struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID      string  `xml:"groupId"`
+		ArtifactID   string  `xml:"artifactId"`
+		Version      string  `xml:"version"`
+		RelativePath *string `xml:"relativePath"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	DependencyManagement []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Dependencies         []pomDependency `xml:"dependencies>dependency"`
+}
+
+// pomDependency pom.xml 中的 dependency 元素
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	Type       string `xml:"type"`
+	Classifier string `xml:"classifier"`
+	Optional   string `xml:"optional"`
+}
+
+// effectivePOM 合并父 POM 之后的项目信息
+type effectivePOM struct {
+	file         string
+	groupID      string
+	artifactID   string
+	version      string
+	properties   map[string]string
+	managed      map[string]pomDependency // groupId:artifactId -> 版本管理中的依赖
+	dependencies []pomDependency
+}
+
+// MavenResolver 解析 pom.xml 并在本地仓库中查找依赖
+type MavenResolver struct {
+	repository string
+	cache      map[string]*effectivePOM // pom.xml 路径 -> 解析结果
+}
+
+// NewMavenResolver 创建 Maven 依赖解析器，repository 为空时使用 ~/.m2/repository
+func NewMavenResolver(repository string) *MavenResolver {
+	if repository == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			repository = filepath.Join(home, ".m2", "repository")
+		}
+	}
+	return &MavenResolver{
+		repository: repository,
+		cache:      make(map[string]*effectivePOM),
+	}
+}
+
+// FindDependencies 查找目录下所有 pom.xml 中声明的依赖（包括从父 POM 继承的依赖），
+// 版本按 dependencyManagement 和属性补全，多模块项目中模块之间的依赖不计入
+func (r *MavenResolver) FindDependencies(root string) []MavenDependency {
+	var pomFiles []string
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "target", "node_modules", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == "pom.xml" {
+			pomFiles = append(pomFiles, path)
+		}
+		return nil
+	})
+
+	modules := make(map[string]bool)
+	var poms []*effectivePOM
+	for _, pomFile := range pomFiles {
+		pom, err := r.load(pomFile, 0)
+		if err != nil {
+			log.Printf("解析 %s 失败: %v", pomFile, err)
+			continue
+		}
+		modules[pom.groupID+":"+pom.artifactID] = true
+		poms = append(poms, pom)
+	}
+
+	seen := make(map[string]bool)
+	var dependencies []MavenDependency
+	for _, pom := range poms {
+		for _, dep := range pom.dependencies {
+			dependency := MavenDependency{
+				GroupID:    dep.GroupID,
+				ArtifactID: dep.ArtifactID,
+				Version:    dep.Version,
+				Scope:      dep.Scope,
+				Type:       dep.Type,
+				Classifier: dep.Classifier,
+				Optional:   dep.Optional == "true",
+				DeclaredIn: pom.file,
+			}
+			if dependency.Scope == "" {
+				dependency.Scope = "compile"
+			}
+			key := dependency.Coordinate() + ":" + dependency.Classifier
+			if modules[dependency.Key()] || seen[key] {
+				continue
+			}
+			seen[key] = true
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Coordinate() < dependencies[j].Coordinate()
+	})
+	return dependencies
+}
+
+// ArtifactPath 返回依赖在本地仓库中的 jar 路径，classifier 为空时使用依赖自身的 classifier
+func (r *MavenResolver) ArtifactPath(dep MavenDependency, classifier string) string {
+	if classifier == "" {
+		classifier = dep.Classifier
+	}
+	name := dep.ArtifactID + "-" + dep.Version
+	if classifier != "" {
+		name += "-" + classifier
+	}
+	return filepath.Join(r.repository, filepath.FromSlash(strings.ReplaceAll(dep.GroupID, ".", "/")),
+		dep.ArtifactID, dep.Version, name+".jar")
+}
+
+// pomPath 返回本地仓库中的 pom 文件路径
+func (r *MavenResolver) pomPath(groupID, artifactID, version string) string {
+	return filepath.Join(r.repository, filepath.FromSlash(strings.ReplaceAll(groupID, ".", "/")),
+		artifactID, version, artifactID+"-"+version+".pom")
+}
+
+// load 解析 pom 文件并合并父 POM 中的属性、版本管理和依赖
+func (r *MavenResolver) load(pomFile string, depth int) (*effectivePOM, error) {
+	if pom, ok := r.cache[pomFile]; ok {
+		return pom, nil
+	}
+	if depth > maxParentDepth {
+		return nil, fmt.Errorf("父 POM 层数过多")
+	}
+
+	data, err := os.ReadFile(pomFile)
+	if err != nil {
+		return nil, err
+	}
+	var raw pomXML
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	pom := &effectivePOM{
+		file:       pomFile,
+		groupID:    raw.GroupID,
+		artifactID: raw.ArtifactID,
+		version:    raw.Version,
+		properties: make(map[string]string),
+		managed:    make(map[string]pomDependency),
+	}
+
+	// 继承父 POM
+	if raw.Parent.ArtifactID != "" {
+		if parent := r.loadParent(pomFile, raw, depth); parent != nil {
+			for k, v := range parent.properties {
+				pom.properties[k] = v
+			}
+			for k, v := range parent.managed {
+				pom.managed[k] = v
+			}
+			pom.dependencies = append(pom.dependencies, parent.dependencies...)
+		}
+		if pom.groupID == "" {
+			pom.groupID = raw.Parent.GroupID
+		}
+		if pom.version == "" {
+			pom.version = raw.Parent.Version
+		}
+		pom.properties["project.parent.groupId"] = raw.Parent.GroupID
+		pom.properties["project.parent.version"] = raw.Parent.Version
+	}
+
+	for _, entry := range raw.Properties.Entries {
+		pom.properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+	for _, prefix := range []string{"project.", "pom.", ""} {
+		pom.properties[prefix+"groupId"] = pom.groupID
+		pom.properties[prefix+"artifactId"] = pom.artifactID
+		pom.properties[prefix+"version"] = pom.version
+	}
+
+	// 版本管理：import scope 的 BOM 优先级低于直接声明的版本
+	for _, dep := range raw.DependencyManagement {
+		dep = pom.interpolate(dep)
+		if dep.Scope == "import" && dep.Type == "pom" {
+			bom, err := r.load(r.pomPath(dep.GroupID, dep.ArtifactID, dep.Version), depth+1)
+			if err != nil {
+				continue
+			}
+			for k, v := range bom.managed {
+				if _, ok := pom.managed[k]; !ok {
+					pom.managed[k] = v
+				}
+			}
+			continue
+		}
+		pom.managed[dep.GroupID+":"+dep.ArtifactID] = dep
+	}
+
+	for _, dep := range raw.Dependencies {
+		dep = pom.interpolate(dep)
+		if managed, ok := pom.managed[dep.GroupID+":"+dep.ArtifactID]; ok {
+			if dep.Version == "" {
+				dep.Version = managed.Version
+			}
+			if dep.Scope == "" {
+				dep.Scope = managed.Scope
+			}
+		}
+		pom.dependencies = append(pom.dependencies, dep)
+	}
+
+	r.cache[pomFile] = pom
+	return pom, nil
+}
+
+// loadParent 按 relativePath（默认 ../pom.xml）查找父 POM，找不到时从本地仓库查找
+func (r *MavenResolver) loadParent(pomFile string, raw pomXML, depth int) *effectivePOM {
+	relativePath := "../pom.xml"
+	if raw.Parent.RelativePath != nil {
+		relativePath = strings.TrimSpace(*raw.Parent.RelativePath)
+	}
+	if relativePath != "" {
+		parentFile := filepath.Join(filepath.Dir(pomFile), filepath.FromSlash(relativePath))
+		if isDirectory(parentFile) {
+			parentFile = filepath.Join(parentFile, "pom.xml")
+		}
+		if parent, err := r.load(parentFile, depth+1); err == nil && parent.artifactID == raw.Parent.ArtifactID {
+			return parent
+		}
+	}
+
+	parent, err := r.load(r.pomPath(raw.Parent.GroupID, raw.Parent.ArtifactID, raw.Parent.Version), depth+1)
+	if err != nil {
+		log.Printf("未找到 %s 的父 POM %s:%s:%s", pomFile, raw.Parent.GroupID, raw.Parent.ArtifactID, raw.Parent.Version)
+		return nil
+	}
+	return parent
+}
+
+// interpolate 替换依赖中引用的属性
+func (pom *effectivePOM) interpolate(dep pomDependency) pomDependency {
+	dep.GroupID = pom.expand(dep.GroupID)
+	dep.ArtifactID = pom.expand(dep.ArtifactID)
+	dep.Version = pom.expand(dep.Version)
+	dep.Scope = strings.TrimSpace(dep.Scope)
+	dep.Type = strings.TrimSpace(dep.Type)
+	dep.Classifier = pom.expand(dep.Classifier)
+	return dep
+}
+
+// expand 替换字符串中的 ${...} 属性引用，属性值中引用的属性也会被替换，未定义的属性保持原样
+func (pom *effectivePOM) expand(value string) string {
+	value = strings.TrimSpace(value)
+	for i := 0; i < 5 && strings.Contains(value, "${"); i++ {
+		value = mavenPropertyPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			if v, ok := pom.properties[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+	return value
+}
+
+// PrepareMavenLibraries 在本地仓库中查找项目依赖的 jar，解压源码 jar 或反编译 class jar，
+// 返回每个依赖对应的源码目录，供作为 library 层加入索引。每个目录中的标记文件记录依赖坐标
+func PrepareMavenLibraries(config *configs.Config, root string) []string {
+	resolver := NewMavenResolver(config.Maven.LocalRepository)
+	scopes := config.Maven.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultMavenScopes
+	}
+
+	workDir := config.Decompiler.WorkDir
+	if workDir == "" {
+		workDir = "./work"
+	}
+	libraryRoot, err := filepath.Abs(filepath.Join(workDir, "maven"))
+	if err != nil {
+		log.Printf("无法获取绝对路径: %v", err)
+		return nil
+	}
+
+	ingester := NewArchiveIngester(config)
+	command, commandErr := ingester.decompilerCommand()
+
+	var dirs []string
+	for _, dep := range resolver.FindDependencies(root) {
+		if !slices.Contains(scopes, dep.Scope) || (dep.Type != "" && dep.Type != "jar") || !mavenDependencySelected(config, dep) {
+			continue
+		}
+		if dep.Version == "" || strings.ContainsAny(dep.Version, "$[(") {
+			log.Printf("无法确定依赖版本，跳过: %s", dep.Key())
+			continue
+		}
+
+		outDir := filepath.Join(libraryRoot, filepath.FromSlash(strings.ReplaceAll(dep.GroupID, ".", "/")), dep.ArtifactID+"-"+dep.Version)
+		if dep.Classifier != "" {
+			outDir += "-" + dep.Classifier
+		}
+
+		// 优先使用源码 jar，没有时反编译 class jar
+		if sources := resolver.ArtifactPath(dep, "sources"); fileExists(sources) {
+			if err := extractSourcesJar(sources, dep.Coordinate(), outDir); err != nil {
+				log.Printf("解压 %s 失败: %v", sources, err)
+				continue
+			}
+		} else if jar := resolver.ArtifactPath(dep, ""); fileExists(jar) {
+			if commandErr != nil {
+				log.Printf("依赖 %s 没有源码 jar，且反编译器不可用，跳过: %v", dep.Coordinate(), commandErr)
+				continue
+			}
+			// 依赖的 jar 中嵌套的归档不再展开
+			if err := ingester.ingest(jar, dep.Coordinate(), outDir, command, maxArchiveDepth); err != nil {
+				log.Printf("跳过依赖 %s: %v", dep.Coordinate(), err)
+				continue
+			}
+		} else {
+			log.Printf("本地仓库中未找到依赖: %s", dep.Coordinate())
+			continue
+		}
+		dirs = append(dirs, outDir)
+	}
+
+	log.Printf("共准备 %d 个 Maven 依赖", len(dirs))
+	return dirs
+}
+
+// extractSourcesJar 解压源码 jar，jar 没有变化时沿用已有的目录
+func extractSourcesJar(jar, coordinate, outDir string) error {
+	marker := filepath.Join(outDir, ArtifactMarkerFile)
+	if markerInfo, err := os.Stat(marker); err == nil {
+		if jarInfo, err := os.Stat(jar); err == nil && markerInfo.ModTime().After(jarInfo.ModTime()) {
+			return nil
+		}
+	}
+	if err := os.RemoveAll(outDir); err != nil {
+		return err
+	}
+	if err := unzipArchive(jar, filepath.Join(outDir, "sources")); err != nil {
+		return err
+	}
+	return os.WriteFile(marker, []byte(coordinate+"\n"), 0644)
+}
+
+// mavenDependencySelected 判断依赖是否匹配 include 且不匹配 exclude
+func mavenDependencySelected(config *configs.Config, dep MavenDependency) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, dep.Key()); matched {
+				return true
+			}
+		}
+		return false
+	}
+	if len(config.Maven.Include) > 0 && !matches(config.Maven.Include) {
+		return false
+	}
+	return !matches(config.Maven.Exclude)
+}
+
+// fileExists 判断路径是否为已存在的文件
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"Fenrir-CodeAuditTool/configs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles 将 files（相对路径 -> 内容）写入 dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindDependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <artifactId>parent</artifactId>
+  <version>1.0</version>
+  <properties>
+    <sling.version>2.22.0</sling.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.apache.sling</groupId>
+        <artifactId>org.apache.sling.api</artifactId>
+        <version>${sling.version}</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>
+`,
+		"core/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0</version>
+  </parent>
+  <artifactId>core</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>org.apache.sling</groupId>
+      <artifactId>org.apache.sling.api</artifactId>
+      <scope>provided</scope>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>4.13</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"web/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0</version>
+  </parent>
+  <artifactId>web</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>core</artifactId>
+      <version>1.0</version>
+    </dependency>
+    <dependency>
+      <groupId>commons-io</groupId>
+      <artifactId>commons-io</artifactId>
+      <version>2.11.0</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+	})
+
+	var got []string
+	for _, dep := range NewMavenResolver(filepath.Join(dir, "repo")).FindDependencies(dir) {
+		got = append(got, dep.Coordinate()+" "+dep.Scope)
+	}
+	want := []string{
+		"commons-io:commons-io:2.11.0 compile",
+		"junit:junit:4.13 test",
+		"org.apache.sling:org.apache.sling.api:2.22.0 provided",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDependencies = %v, want %v", got, want)
+	}
+}
+
+func TestMavenDependencySelected(t *testing.T) {
+	dep := MavenDependency{GroupID: "org.apache.sling", ArtifactID: "org.apache.sling.api"}
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"未配置", nil, nil, true},
+		{"匹配 include", []string{"org.apache.sling:*"}, nil, true},
+		{"不匹配 include", []string{"org.springframework:*"}, nil, false},
+		{"匹配 exclude", nil, []string{"*:org.apache.sling.api"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configs.Config{}
+			config.Maven.Include, config.Maven.Exclude = tt.include, tt.exclude
+			if got := mavenDependencySelected(config, dep); got != tt.want {
+				t.Errorf("mavenDependencySelected = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareMavenLibraries(t *testing.T) {
+	dir := t.TempDir()
+	project, repo := filepath.Join(dir, "project"), filepath.Join(dir, "repo")
+	writeFiles(t, project, map[string]string{
+		"pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0</version>
+  <dependencies>
+    <dependency>
+      <groupId>com.lib</groupId>
+      <artifactId>base</artifactId>
+      <version>1.2</version>
+    </dependency>
+    <dependency>
+      <groupId>com.lib</groupId>
+      <artifactId>missing</artifactId>
+      <version>1.0</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"src/main/java/app/Ctl.java": `package app;
+
+import lib.Base;
+
+public class Ctl extends Base {
+    void show(String cmd) throws Exception {
+        Runtime.getRuntime().exec(cmd);
+    }
+}
+`,
+	})
+	sourcesJar := filepath.Join(repo, "com", "lib", "base", "1.2", "base-1.2-sources.jar")
+	if err := os.MkdirAll(filepath.Dir(sourcesJar), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sourcesJar, writeZip(t, map[string][]byte{"lib/Base.java": []byte(`package lib;
+
+public class Base {
+    protected void run(String cmd) throws Exception {
+        Runtime.getRuntime().exec(cmd);
+    }
+}
+`)}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &configs.Config{}
+	config.Maven.LocalRepository = repo
+	config.Decompiler.WorkDir = filepath.Join(dir, "work")
+	libraryDirs := PrepareMavenLibraries(config, project)
+	if len(libraryDirs) != 1 {
+		t.Fatalf("依赖目录 = %v, want 1 个", libraryDirs)
+	}
+
+	m := NewParserManager()
+	m.RegisterParser(&JavaParser{})
+	if err := m.BuildIndexFromDir(project, libraryDirs...); err != nil {
+		t.Fatal(err)
+	}
+	query := NewQueryEngine(m.GetIndex())
+
+	base, ok := query.index.index[findClassID(t, query, "lib.Base")]
+	if !ok || base.Metadata["layer"] != "library" || base.Metadata["artifact"] != "com.lib:base:1.2" {
+		t.Errorf("lib.Base 的 metadata = %v, want library 层且来源为 com.lib:base:1.2", base.Metadata)
+	}
+	if !reflect.DeepEqual(base.SubClasses, []ClassRef{{Package: "app", Name: "Ctl"}}) {
+		t.Errorf("lib.Base 的子类 = %v, want [app.Ctl]", base.SubClasses)
+	}
+
+	// 依赖库内部的高危调用不报告
+	var methods []string
+	for _, f := range FindSinks(query, nil) {
+		methods = append(methods, f.Method)
+	}
+	if want := []string{"app.Ctl#show(String)"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("FindSinks 所在方法 = %v, want %v", methods, want)
+	}
+}
+
+// findClassID 按全限定名查找类节点ID
+func findClassID(t *testing.T, query *QueryEngine, fullName string) string {
+	t.Helper()
+	for id, node := range query.index.index {
+		if node.Type == "Class" && node.FullClassName == fullName {
+			return id
+		}
+	}
+	t.Fatalf("未找到类 %s", fullName)
+	return ""
+}
@@ -5,62 +5,86 @@ import (
 	"strings"
 )
 
-// ParseMethodSignature 解析方法签名为方法名和参数列表
+// ParseMethodSignature 解析方法签名为方法名和参数类型列表
 func ParseMethodSignature(methodSignature string) (string, []string) {
+	methodName, params := ParseMethodSignatureParams(methodSignature)
+	var types []string
+	for _, param := range params {
+		types = append(types, param.Type)
+	}
+	return methodName, types
+}
+
+// ParseMethodSignatureParams 解析方法签名为方法名和参数列表，参数可以写成 类型、类型 形参名 或只写形参名，
+// 只有一个单词时无法区分类型和形参名，Type 与 Name 都会填入该单词
+func ParseMethodSignatureParams(methodSignature string) (string, []ParamInfo) {
 	// 如果方法签名不包含括号，直接返回
 	if !strings.Contains(methodSignature, "(") {
 		return methodSignature, nil
 	}
 
 	// 分离方法名和参数部分
-	parts := strings.Split(methodSignature, "(")
-	methodName := parts[0]
-
-	// 处理参数部分
-	paramsStr := strings.TrimSuffix(parts[1], ")")
+	idx := strings.Index(methodSignature, "(")
+	methodName := strings.TrimSpace(methodSignature[:idx])
+	paramsStr := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(methodSignature[idx+1:]), ")"))
 	if paramsStr == "" {
 		return methodName, nil
 	}
 
-	// 分割参数
-	var params []string
+	// 按不在泛型尖括号内的逗号分割参数
+	var rawParams []string
 	currentParam := ""
 	bracketCount := 0
-
 	for _, char := range paramsStr {
 		switch char {
 		case '<':
 			bracketCount++
-			currentParam += string(char)
 		case '>':
 			bracketCount--
-			currentParam += string(char)
 		case ',':
 			if bracketCount == 0 {
-				// 处理参数类型和形参名称
-				paramParts := strings.Fields(strings.TrimSpace(currentParam))
-				if len(paramParts) > 0 {
-					// 只保留参数类型，去掉形参名称
-					paramType := strings.Join(paramParts[:len(paramParts)-1], " ")
-					params = append(params, strings.TrimSpace(paramType))
-				}
+				rawParams = append(rawParams, currentParam)
 				currentParam = ""
-			} else {
-				currentParam += string(char)
+				continue
 			}
-		default:
-			currentParam += string(char)
 		}
+		currentParam += string(char)
 	}
+	rawParams = append(rawParams, currentParam)
 
-	if currentParam != "" {
-		// 处理最后一个参数
-		paramParts := strings.Fields(strings.TrimSpace(currentParam))
-		if len(paramParts) > 0 {
-
-			paramType := strings.Join(paramParts[:len(paramParts)-1], " ")
-			params = append(params, strings.TrimSpace(ShortClassName(paramType)))
+	var params []ParamInfo
+	for _, raw := range rawParams {
+		// 去掉默认值（Python / JavaScript 的 b = 1）
+		if idx := strings.Index(raw, "="); idx != -1 {
+			raw = raw[:idx]
+		}
+		// 去掉 final 和参数注解
+		var words []string
+		for _, word := range strings.Fields(raw) {
+			if word != "final" && !strings.HasPrefix(word, "@") {
+				words = append(words, word)
+			}
 		}
+		if len(words) == 0 {
+			continue
+		}
+		last := words[len(words)-1]
+		// 最后一个单词以 > ] ... 结尾或只有一个单词时，整体视为类型
+		if len(words) == 1 || strings.HasSuffix(last, ">") || strings.HasSuffix(last, "]") || strings.HasSuffix(last, "...") {
+			paramType := strings.Join(words, " ")
+			param := ParamInfo{Type: paramType}
+			if len(words) == 1 {
+				// Python 的 *args / **kwargs 按形参名匹配
+				param.Name = strings.TrimLeft(paramType, "*")
+				param.Type = param.Name
+			}
+			params = append(params, param)
+			continue
+		}
+		params = append(params, ParamInfo{
+			Type: strings.Join(words[:len(words)-1], " "),
+			Name: last,
+		})
 	}
 
 	// 打印调试信息
@@ -76,10 +100,10 @@ func ShortClassName(fullName string) string {
 	return fullName
 }
 
-// IsMatchingMethod 检查方法是否匹配指定的方法签名
+// IsMatchingMethod 检查方法是否匹配指定的方法签名，参数既可以按类型匹配也可以按形参名匹配
 func IsMatchingMethod(node UniversalASTNode, methodSignature string) bool {
 	// 解析方法签名
-	targetName, targetParams := ParseMethodSignature(methodSignature)
+	targetName, targetParams := ParseMethodSignatureParams(methodSignature)
 
 	// 打印调试信息
 	fmt.Printf("Checking method: %s against signature: %s\n", node.Name, methodSignature)
@@ -91,28 +115,71 @@ func IsMatchingMethod(node UniversalASTNode, methodSignature string) bool {
 		return false
 	}
 
-	// 如果没有参数要求，直接返回true
-	if len(targetParams) == 0 {
+	// 不带括号时不限制参数
+	if !strings.Contains(methodSignature, "(") {
 		return true
 	}
 
-	// 检查参数数量是否匹配
+	// 检查参数数量是否匹配，myMethod() 只匹配无参方法
 	if len(node.MethodParams) != len(targetParams) {
 		return false
 	}
 
 	// 检查每个参数是否匹配
-	for i := range targetParams {
-		nodeParam := strings.TrimSpace(node.MethodParams[i])
-		targetParam := strings.TrimSpace(targetParams[i])
+	for i, target := range targetParams {
+		nodeType := strings.TrimSpace(node.MethodParams[i])
+		nodeName := ""
+		if i < len(node.Params) {
+			nodeName = node.Params[i].Name
+		}
 
-		// 打印参数比较信息
-		fmt.Printf("Comparing params: %s vs %s\n", nodeParam, targetParam)
+		// 只写了一个单词：与参数类型或形参名之一相同即可
+		if target.Type == target.Name {
+			if !paramTypeMatches(nodeType, target.Type) && target.Name != nodeName {
+				return false
+			}
+			continue
+		}
 
-		if !strings.EqualFold(nodeParam, targetParam) {
+		if !paramTypeMatches(nodeType, target.Type) {
+			return false
+		}
+		// 索引中记录了形参名时同时比较形参名，arg0、arg1 这类占位名不参与比较
+		if nodeName != "" && target.Name != "" && !isPlaceholderParamName(target.Name) && target.Name != nodeName {
 			return false
 		}
 	}
 
 	return true
 }
+
+// paramTypeMatches 比较参数类型，忽略大小写、空白和包名
+func paramTypeMatches(nodeType, targetType string) bool {
+	normalize := func(t string) string {
+		t = strings.Join(strings.Fields(t), "")
+		// 可变参数等同于数组，且 ... 会被当作包名分隔符，先去掉再补回 []
+		suffix := ""
+		if strings.HasSuffix(t, "...") {
+			t, suffix = strings.TrimSuffix(t, "..."), "[]"
+		}
+		if idx := strings.Index(t, "<"); idx != -1 {
+			// 只对泛型外层的类型去掉包名
+			return ShortClassName(t[:idx]) + t[idx:] + suffix
+		}
+		return ShortClassName(t) + suffix
+	}
+	return strings.EqualFold(normalize(nodeType), normalize(targetType))
+}
+
+// isPlaceholderParamName 判断是否为 arg0、arg1 这类没有实际含义的形参名
+func isPlaceholderParamName(name string) bool {
+	if !strings.HasPrefix(name, "arg") || len(name) == 3 {
+		return false
+	}
+	for _, c := range name[3:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
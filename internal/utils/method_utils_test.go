@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMethodSignatureParams(t *testing.T) {
+	tests := []struct {
+		signature  string
+		wantName   string
+		wantParams []ParamInfo
+	}{
+		{"login", "login", nil},
+		{"login()", "login", nil},
+		{"login(String)", "login", []ParamInfo{{Type: "String", Name: "String"}}},
+		{"login(String username, String password)", "login", []ParamInfo{{Type: "String", Name: "username"}, {Type: "String", Name: "password"}}},
+		{"find(Map<String, Object> params)", "find", []ParamInfo{{Type: "Map<String, Object>", Name: "params"}}},
+		{"find(Map<String, Object>)", "find", []ParamInfo{{Type: "Map<String, Object>"}}},
+		{"save(final @RequestBody User user)", "save", []ParamInfo{{Type: "User", Name: "user"}}},
+		{"format(String... args)", "format", []ParamInfo{{Type: "String...", Name: "args"}}},
+		{"format(Object...)", "format", []ParamInfo{{Type: "Object...", Name: "Object..."}}},
+		{"read(byte[])", "read", []ParamInfo{{Type: "byte[]", Name: "byte[]"}}},
+		{"fetch(url, timeout=10)", "fetch", []ParamInfo{{Type: "url", Name: "url"}, {Type: "timeout", Name: "timeout"}}},
+		{"run(*args, **kwargs)", "run", []ParamInfo{{Type: "args", Name: "args"}, {Type: "kwargs", Name: "kwargs"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.signature, func(t *testing.T) {
+			name, params := ParseMethodSignatureParams(tt.signature)
+			if name != tt.wantName || !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("ParseMethodSignatureParams(%q) = %q, %v, want %q, %v", tt.signature, name, params, tt.wantName, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestIsMatchingMethod(t *testing.T) {
+	login := UniversalASTNode{
+		Name:         "login",
+		MethodParams: []string{"java.lang.String", "String"},
+		Params:       []ParamInfo{{Name: "username", Type: "java.lang.String"}, {Name: "password", Type: "String"}},
+	}
+	format := UniversalASTNode{
+		Name:         "format",
+		MethodParams: []string{"String", "Object..."},
+		Params:       []ParamInfo{{Name: "pattern", Type: "String"}, {Name: "args", Type: "Object..."}},
+	}
+	tests := []struct {
+		name      string
+		node      UniversalASTNode
+		signature string
+		want      bool
+	}{
+		{"只写方法名", login, "login", true},
+		{"方法名不同", login, "logout", false},
+		{"无参只匹配无参方法", login, "login()", false},
+		{"按类型匹配并忽略包名", login, "login(String, String)", true},
+		{"按形参名匹配", login, "login(username, password)", true},
+		{"类型与形参名", login, "login(String username, String password)", true},
+		{"形参名不同", login, "login(String user, String password)", false},
+		{"占位形参名不参与比较", login, "login(String arg0, String arg1)", true},
+		{"参数个数不同", login, "login(String)", false},
+		{"类型不同", login, "login(int, String)", false},
+		{"可变参数等同于数组", format, "format(String, Object[])", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMatchingMethod(tt.node, tt.signature); got != tt.want {
+				t.Errorf("IsMatchingMethod(%s, %q) = %v, want %v", tt.node.Name, tt.signature, got, tt.want)
+			}
+		})
+	}
+}
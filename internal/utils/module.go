@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// moduleResolver 按文件向上查找最近的构建清单（pom.xml、build.gradle、go.mod、package.json）确定所属模块，
+// 查找不越过索引根目录；没有构建清单时使用所在归档名，反编译得到的副本据此与项目源码区分
+type moduleResolver struct {
+	root      string
+	artifacts map[string]string // 归档解包目录 -> 归档名，见 ArtifactMarkerFile
+	gradle    map[string]string // Gradle 项目目录 -> 项目路径，根项目使用项目名
+	cache     map[string]string // 目录 -> 模块名
+}
+
+// newModuleResolver 创建模块解析器，gradleProjects 为建立索引时解析的 Gradle 项目
+func newModuleResolver(root string, artifacts map[string]string, gradleProjects []GradleProject) *moduleResolver {
+	gradle := make(map[string]string)
+	for _, project := range gradleProjects {
+		if project.Path == ":" {
+			gradle[project.Dir] = project.Name
+		} else {
+			gradle[project.Dir] = project.Path
+		}
+	}
+	return &moduleResolver{
+		root:      root,
+		artifacts: artifacts,
+		gradle:    gradle,
+		cache:     make(map[string]string),
+	}
+}
+
+// resolve 返回文件所属的模块名，无法确定时返回空字符串
+func (r *moduleResolver) resolve(path string) string {
+	var visited []string
+	module := ""
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if cached, ok := r.cache[dir]; ok {
+			module = cached
+			break
+		}
+		visited = append(visited, dir)
+		if module = r.manifestModule(dir); module != "" {
+			break
+		}
+		if artifact, ok := r.artifacts[dir]; ok {
+			module = artifact
+			break
+		}
+		if dir == r.root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	for _, dir := range visited {
+		r.cache[dir] = module
+	}
+	return module
+}
+
+// manifestModule 读取目录中的构建清单得到模块名，同一目录有多个清单时依次取 pom.xml、build.gradle、go.mod、package.json
+func (r *moduleResolver) manifestModule(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "pom.xml")); err == nil {
+		var pom pomXML
+		if xml.Unmarshal(data, &pom) == nil && pom.ArtifactID != "" {
+			return pom.ArtifactID
+		}
+	}
+	if project, ok := r.gradle[dir]; ok {
+		return project
+	}
+	if gradleBuildFile(dir) != "" {
+		return filepath.Base(dir)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+				return strings.Trim(fields[1], `"`)
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &manifest) == nil && manifest.Name != "" {
+			return manifest.Name
+		}
+		return filepath.Base(dir)
+	}
+	return ""
+}
+
+// MatchModule 判断节点是否属于 module，module 为空表示不筛选；
+// Gradle 子项目可省略开头的冒号，如 app 匹配 :app
+func MatchModule(node UniversalASTNode, module string) bool {
+	if module == "" {
+		return true
+	}
+	return node.Module == module || strings.TrimPrefix(node.Module, ":") == strings.TrimPrefix(module, ":")
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModuleResolution(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pom.xml":      "<project><artifactId>parent</artifactId></project>\n",
+		"core/pom.xml": "<project><artifactId>core-module</artifactId></project>\n",
+		"core/src/main/java/com/ex/Base.java": `package com.ex;
+
+public class Base {
+    public void hello() {}
+}
+`,
+		"core/src/main/java/com/ex/Foo.java": `package com.ex;
+
+public class Foo extends Base {
+    public void hello() {}
+}
+`,
+		"web/pom.xml": "<project><artifactId>web-module</artifactId></project>\n",
+		"web/src/main/java/com/ex/Foo.java": `package com.ex;
+
+public class Foo extends Object {
+    public void hello() {}
+}
+`,
+		"tools/settings.gradle":       "include ':cli'\n",
+		"tools/cli/build.gradle":      "apply plugin: 'java'\n",
+		"tools/cli/src/cli/Main.java": "package cli;\n\npublic class Main {}\n",
+		"scripts/util/Helper.java":    "package util;\n\npublic class Helper {}\n",
+	})
+
+	m := NewParserManager()
+	m.RegisterParser(&JavaParser{})
+	if err := m.BuildIndexFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	query := NewQueryEngine(m.GetIndex())
+
+	modules := make(map[string][]string)
+	for _, node := range query.index.FindNodes(func(node UniversalASTNode) bool { return node.Type == "Class" }) {
+		modules[node.FullClassName] = append(modules[node.FullClassName], node.Module)
+	}
+	tests := []struct {
+		class string
+		want  []string
+	}{
+		{"com.ex.Base", []string{"core-module"}},
+		{"cli.Main", []string{":cli"}},
+		// 没有更近的构建清单时归属根目录的 pom.xml
+		{"util.Helper", []string{"parent"}},
+	}
+	for _, tt := range tests {
+		if got := modules[tt.class]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s 所属模块 = %v, want %v", tt.class, got, tt.want)
+		}
+	}
+
+	supers := GetAllSuperClasses(query, "com.ex.Foo", "")
+	if len(supers) != 2 || len(supers["core-module"]) != 1 || supers["core-module"][0].FullName() != "com.ex.Base" {
+		t.Errorf("父类应按模块分组: %v", supers)
+	}
+	if supers := GetAllSuperClasses(query, "com.ex.Foo", "web-module"); len(supers) != 1 || supers["core-module"] != nil {
+		t.Errorf("按模块筛选父类: %v", supers)
+	}
+
+	for _, node := range query.InModule("cli").GetAllNodes() {
+		if node.Module != ":cli" {
+			t.Errorf("InModule(cli) 包含其他模块的节点: %s (%s)", node.ID, node.Module)
+		}
+	}
+	if len(query.InModule("cli").GetAllNodes()) == 0 {
+		t.Errorf("Gradle 子项目可省略开头的冒号")
+	}
+}
@@ -0,0 +1,627 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// esImportSourcePattern JavaScript / TypeScript 的导入来源：import ... from 'x'、import 'x'、require('x')、import('x')
+var esImportSourcePattern = regexp.MustCompile(`(?:\bfrom\s*|\brequire\s*\(\s*|\bimport\s*\(?\s*)["']([^"']+)["']`)
+
+// osvAdvisory OSV 格式的漏洞公告中用到的部分，参见 https://ossf.github.io/osv-schema/
+type osvAdvisory struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Introduced   string `json:"introduced"`
+				Fixed        string `json:"fixed"`
+				LastAffected string `json:"last_affected"`
+			} `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// OSVDatabase 离线的 OSV 漏洞库，按 生态/包名 索引公告
+type OSVDatabase struct {
+	advisories map[string][]*osvAdvisory
+	count      int
+}
+
+// Advisory 一条命中的漏洞公告
+type Advisory struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases,omitempty"` // CVE 等编号
+	Summary  string   `json:"summary"`
+	Severity string   `json:"severity,omitempty"`
+	Fixed    []string `json:"fixed,omitempty"` // 修复版本
+}
+
+// VulnerableDependency 存在已知漏洞的依赖，以及索引中实际导入它的类
+type VulnerableDependency struct {
+	Dependency ManifestDependency `json:"dependency"`
+	Advisories []Advisory         `json:"advisories"`
+	Importers  []string           `json:"importers"`
+	Inferred   bool               `json:"inferred,omitempty"` // Java 包名按 groupId 推断，导入关系可能不准确
+	// UnknownVersion 依赖版本未能解析（如父 POM 不在本地仓库、属性未定义或为版本区间），公告未按版本核对
+	UnknownVersion bool `json:"unknown_version,omitempty"`
+}
+
+// osvDir 离线漏洞库所在目录：缓存目录下的 osv 目录
+func osvDir(cacheDir string) string {
+	if cacheDir == "" {
+		cacheDir = "./cache"
+	}
+	return filepath.Join(cacheDir, "osv")
+}
+
+// LoadOSVDatabase 加载缓存目录下 osv 目录中的 OSV 导出文件，支持单条公告或公告数组的 .json 文件，
+// 以及 OSV 官方按生态导出的 all.zip
+func LoadOSVDatabase(cacheDir string) (*OSVDatabase, error) {
+	dir := osvDir(cacheDir)
+	if !isDirectory(dir) {
+		return nil, fmt.Errorf("未找到离线漏洞库目录 %s，请将 OSV 导出文件（如 https://osv-vulnerabilities.storage.googleapis.com/Maven/all.zip）放到该目录", dir)
+	}
+
+	db := &OSVDatabase{advisories: make(map[string][]*osvAdvisory)}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			db.add(data)
+		case ".zip":
+			return db.addZip(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取离线漏洞库失败: %v", err)
+	}
+	if db.count == 0 {
+		return nil, fmt.Errorf("离线漏洞库目录 %s 中没有 OSV 公告", dir)
+	}
+	return db, nil
+}
+
+// addZip 加载 zip 中的全部 .json 公告
+func (db *OSVDatabase) addZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		db.add(data)
+	}
+	return nil
+}
+
+// add 加载一个 JSON 文件中的公告，内容可以是单条公告或公告数组，无法解析的文件忽略
+func (db *OSVDatabase) add(data []byte) {
+	var advisories []*osvAdvisory
+	if err := json.Unmarshal(data, &advisories); err != nil {
+		var advisory osvAdvisory
+		if err := json.Unmarshal(data, &advisory); err != nil || advisory.ID == "" {
+			return
+		}
+		advisories = []*osvAdvisory{&advisory}
+	}
+	for _, advisory := range advisories {
+		db.count++
+		for _, affected := range advisory.Affected {
+			key := osvKey(affected.Package.Ecosystem, affected.Package.Name)
+			db.advisories[key] = append(db.advisories[key], advisory)
+		}
+	}
+}
+
+// osvKey 返回 生态/包名 索引键，生态名去掉 Maven:central 这类后缀
+func osvKey(ecosystem, name string) string {
+	ecosystem, _, _ = strings.Cut(ecosystem, ":")
+	return ecosystem + "/" + name
+}
+
+// Match 返回影响该依赖版本的公告；版本未能解析时无法判断是否受影响，返回该组件的全部公告，
+// 调用方需要据此标注版本未知，而不是当作已确认的漏洞
+func (db *OSVDatabase) Match(dep ManifestDependency) []Advisory {
+	known := versionResolved(dep.Version)
+	var matches []Advisory
+	seen := make(map[string]bool)
+	for _, advisory := range db.advisories[osvKey(dep.Ecosystem, dep.Name)] {
+		// 同一公告可能同时出现在 .json 和 all.zip 中
+		if seen[advisory.ID] {
+			continue
+		}
+		seen[advisory.ID] = true
+		for _, affected := range advisory.Affected {
+			if osvKey(affected.Package.Ecosystem, affected.Package.Name) != osvKey(dep.Ecosystem, dep.Name) {
+				continue
+			}
+
+			vulnerable := !known
+			for _, version := range affected.Versions {
+				if known && compareVersions(version, dep.Version) == 0 {
+					vulnerable = true
+				}
+			}
+			var fixed []string
+			for _, r := range affected.Ranges {
+				if r.Type != "ECOSYSTEM" && r.Type != "SEMVER" {
+					continue
+				}
+				// 按事件顺序判断版本是否落在 [introduced, fixed) 或 [introduced, last_affected] 区间内
+				introduced := ""
+				for _, event := range r.Events {
+					switch {
+					case event.Introduced != "":
+						introduced = event.Introduced
+					case event.Fixed != "":
+						fixed = append(fixed, event.Fixed)
+						if known && introduced != "" && versionAtLeast(dep.Version, introduced) && compareVersions(dep.Version, event.Fixed) < 0 {
+							vulnerable = true
+						}
+						introduced = ""
+					case event.LastAffected != "":
+						if known && introduced != "" && versionAtLeast(dep.Version, introduced) && compareVersions(dep.Version, event.LastAffected) <= 0 {
+							vulnerable = true
+						}
+						introduced = ""
+					}
+				}
+				if known && introduced != "" && versionAtLeast(dep.Version, introduced) {
+					vulnerable = true
+				}
+			}
+
+			if vulnerable {
+				matches = append(matches, Advisory{
+					ID:       advisory.ID,
+					Aliases:  advisory.Aliases,
+					Summary:  advisory.summary(),
+					Severity: advisory.severity(),
+					Fixed:    fixed,
+				})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// summary 返回公告摘要，没有 summary 时取 details 的第一行
+func (a *osvAdvisory) summary() string {
+	if a.Summary != "" {
+		return a.Summary
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(a.Details), "\n")
+	return line
+}
+
+// severity 返回公告的严重程度，优先使用数据库给出的等级（如 GitHub 的 HIGH），否则使用 CVSS 向量
+func (a *osvAdvisory) severity() string {
+	if a.DatabaseSpecific.Severity != "" {
+		return strings.ToUpper(a.DatabaseSpecific.Severity)
+	}
+	for _, severity := range a.Severity {
+		if severity.Score != "" {
+			return severity.Score
+		}
+	}
+	return ""
+}
+
+// versionResolved 判断版本号是否为确定的版本：空版本、未展开的 ${...} 属性和 [1.0,2.0) 这类区间都无法与公告比较
+func versionResolved(version string) bool {
+	return version != "" && !strings.ContainsAny(version, "$[(")
+}
+
+// versionAtLeast 判断 version >= introduced，introduced 为 0 表示所有版本
+func versionAtLeast(version, introduced string) bool {
+	return introduced == "0" || compareVersions(version, introduced) >= 0
+}
+
+// preReleaseQualifiers 低于正式版本的版本限定符
+var preReleaseQualifiers = map[string]bool{
+	"alpha": true, "a": true, "beta": true, "b": true, "milestone": true, "m": true,
+	"rc": true, "cr": true, "pre": true, "preview": true, "snapshot": true, "dev": true,
+}
+
+// compareVersions 比较两个版本号，兼容 Maven、npm 和 Go 的常见写法：数字部分按数值比较，
+// 1.0 与 1.0.0 相等，alpha、beta、rc 等预发布版本低于对应的正式版本
+func compareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		switch {
+		case i >= len(ta):
+			return -trailingVersionSign(tb[i:])
+		case i >= len(tb):
+			return trailingVersionSign(ta[i:])
+		}
+		na, errA := strconv.Atoi(ta[i])
+		nb, errB := strconv.Atoi(tb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case errA == nil:
+			// 数字高于限定符：1.0.1 > 1.0-rc1
+			if preReleaseQualifiers[tb[i]] {
+				return 1
+			}
+			return -1
+		case errB == nil:
+			if preReleaseQualifiers[ta[i]] {
+				return -1
+			}
+			return 1
+		default:
+			if c := strings.Compare(ta[i], tb[i]); c != 0 {
+				pa, pb := preReleaseQualifiers[ta[i]], preReleaseQualifiers[tb[i]]
+				if pa != pb {
+					if pa {
+						return -1
+					}
+					return 1
+				}
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// trailingVersionSign 判断较长版本号多出的部分使其更高（1）、相等（0）还是更低（-1）
+func trailingVersionSign(rest []string) int {
+	for _, token := range rest {
+		if n, err := strconv.Atoi(token); err == nil {
+			if n > 0 {
+				return 1
+			}
+			continue
+		}
+		if preReleaseQualifiers[token] {
+			return -1
+		}
+		// final、release、ga 等与正式版本相同
+		if token == "final" || token == "release" || token == "ga" {
+			continue
+		}
+		return 1
+	}
+	return 0
+}
+
+// versionTokens 将版本号拆分为数字和字母部分，如 v1.2.0-RC1 -> [1 2 0 rc 1]
+func versionTokens(version string) []string {
+	version = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(version), "v"))
+	var tokens []string
+	current := ""
+	digit := false
+	for _, r := range version {
+		isDigit := r >= '0' && r <= '9'
+		isLetter := r >= 'a' && r <= 'z'
+		if !isDigit && !isLetter {
+			if current != "" {
+				tokens = append(tokens, current)
+			}
+			current = ""
+			continue
+		}
+		if current != "" && isDigit != digit {
+			tokens = append(tokens, current)
+			current = ""
+		}
+		current += string(r)
+		digit = isDigit
+	}
+	if current != "" {
+		tokens = append(tokens, current)
+	}
+	return tokens
+}
+
+// FindVulnerableDependencies 将依赖与离线漏洞库匹配，并找出索引中导入了存在漏洞组件的类。
+// Java 组件的包名优先取自 library 层中该依赖的节点，其次取自本地 Maven 仓库中的 jar，都没有时按 groupId 推断
+func FindVulnerableDependencies(query *QueryEngine, db *OSVDatabase, dependencies []ManifestDependency, mavenRepository string) []VulnerableDependency {
+	imports := collectFileImports(query)
+	resolver := NewMavenResolver(mavenRepository)
+
+	var results []VulnerableDependency
+	for _, dep := range dependencies {
+		advisories := db.Match(dep)
+		if len(advisories) == 0 {
+			continue
+		}
+		result := VulnerableDependency{Dependency: dep, Advisories: advisories, UnknownVersion: !versionResolved(dep.Version)}
+
+		var matches func(imported string) bool
+		switch dep.Ecosystem {
+		case "Maven":
+			var packages []string
+			packages, result.Inferred = mavenPackages(query, resolver, dep)
+			matches = func(imported string) bool {
+				for _, pkg := range packages {
+					if strings.HasPrefix(imported, pkg+".") {
+						return true
+					}
+				}
+				return false
+			}
+		default:
+			// npm 包名与 Go 模块路径都是导入路径的前缀
+			matches = func(imported string) bool {
+				return imported == dep.Name || strings.HasPrefix(imported, dep.Name+"/")
+			}
+		}
+
+		importers := make(map[string]bool)
+		for class, imported := range imports {
+			for _, name := range imported {
+				if matches(name) {
+					importers[class] = true
+					break
+				}
+			}
+		}
+		for class := range importers {
+			result.Importers = append(result.Importers, class)
+		}
+		sort.Strings(result.Importers)
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return severityRank(results[i]) > severityRank(results[j])
+	})
+	return results
+}
+
+// severityRank 按依赖命中公告的最高严重程度排序，已确认版本受影响的排在版本未知的前面，导入次数多的排在前面
+func severityRank(result VulnerableDependency) int {
+	rank := 0
+	for _, advisory := range result.Advisories {
+		r := 0
+		switch advisory.Severity {
+		case "CRITICAL":
+			r = 4
+		case "HIGH":
+			r = 3
+		case "MODERATE", "MEDIUM":
+			r = 2
+		case "LOW":
+			r = 1
+		}
+		if r > rank {
+			rank = r
+		}
+	}
+	if len(result.Importers) > 0 {
+		rank += 10
+	}
+	if !result.UnknownVersion {
+		rank += 20
+	}
+	return rank
+}
+
+// mavenPackages 返回 Maven 依赖中包含的 Java 包，第二个返回值表示包名是否按 groupId 推断
+func mavenPackages(query *QueryEngine, resolver *MavenResolver, dep ManifestDependency) ([]string, bool) {
+	groupID, artifactID, _ := strings.Cut(dep.Name, ":")
+	coordinate := dep.Name + ":" + dep.Version
+
+	packages := make(map[string]bool)
+	for _, node := range query.index.index {
+		if node.Type == "Class" && node.Metadata["layer"] == "library" && node.Metadata["artifact"] == coordinate && node.Package != "" {
+			packages[node.Package] = true
+		}
+	}
+	if len(packages) == 0 {
+		jar := resolver.ArtifactPath(MavenDependency{GroupID: groupID, ArtifactID: artifactID, Version: dep.Version}, "")
+		if r, err := zip.OpenReader(jar); err == nil {
+			for _, f := range r.File {
+				if strings.HasSuffix(f.Name, ".class") && !strings.HasPrefix(f.Name, "META-INF/") {
+					if dir := path.Dir(f.Name); dir != "." {
+						packages[strings.ReplaceAll(dir, "/", ".")] = true
+					}
+				}
+			}
+			r.Close()
+		}
+	}
+
+	if len(packages) == 0 {
+		return []string{groupID}, true
+	}
+	var result []string
+	for pkg := range packages {
+		result = append(result, pkg)
+	}
+	sort.Strings(result)
+	return result, false
+}
+
+// collectFileImports 返回项目代码中每个类（或包、模块）导入的包或模块：Java / Kotlin 的 import、JavaScript / TypeScript 的 import 与 require、
+// Go 的 import，以及方法调用中解析出的接收者类型（覆盖通配符导入和全限定名调用）
+func collectFileImports(query *QueryEngine) map[string][]string {
+	fileClasses := make(map[string][]string)
+	filePackages := make(map[string]string)
+	imports := make(map[string][]string)
+	for _, node := range query.index.index {
+		if node.Metadata["layer"] == "library" || node.File == "" {
+			continue
+		}
+		if _, ok := fileClasses[node.File]; !ok {
+			fileClasses[node.File] = nil
+		}
+		if node.Package != "" {
+			filePackages[node.File] = node.Package
+		}
+		switch node.Type {
+		case "Class":
+			fileClasses[node.File] = append(fileClasses[node.File], node.FullClassName)
+		case "MethodCall":
+			if receiverType := node.Metadata["receiverType"]; receiverType != "" && node.Metadata["callerClass"] != "" {
+				imports[node.Metadata["callerClass"]] = append(imports[node.Metadata["callerClass"]], receiverType)
+			}
+		}
+	}
+
+	for file, classes := range fileClasses {
+		fileImports := scanImports(file)
+		if len(fileImports) == 0 {
+			continue
+		}
+		// 没有类的文件（Go 包、JavaScript 模块）以包名或模块路径代替
+		if len(classes) == 0 {
+			if pkg := filePackages[file]; pkg != "" {
+				classes = []string{pkg}
+			} else {
+				classes = []string{file}
+			}
+		}
+		for _, class := range classes {
+			imports[class] = append(imports[class], fileImports...)
+		}
+	}
+	return imports
+}
+
+// scanImports 读取源码文件中的导入语句
+func scanImports(file string) []string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var imports []string
+	switch filepath.Ext(file) {
+	case ".java", ".kt", ".kts", ".groovy", ".scala":
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if rest, ok := strings.CutPrefix(line, "import "); ok {
+				rest = strings.TrimPrefix(strings.TrimSpace(rest), "static ")
+				rest = strings.TrimRight(strings.Fields(rest + " ")[0], ";")
+				imports = append(imports, rest)
+			}
+		}
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		for _, m := range esImportSourcePattern.FindAllStringSubmatch(string(data), -1) {
+			imports = append(imports, m[1])
+		}
+	case ".go":
+		if f, err := parser.ParseFile(token.NewFileSet(), file, data, parser.ImportsOnly); err == nil {
+			for _, spec := range f.Imports {
+				imports = append(imports, strings.Trim(spec.Path.Value, "`\""))
+			}
+		}
+	}
+	return imports
+}
+
+// FormatVulnerableDependencies 格式化漏洞依赖报告，importedOnly 为 true 时只输出被项目代码导入的组件
+func FormatVulnerableDependencies(results []VulnerableDependency, importedOnly bool) string {
+	var builder strings.Builder
+	count := 0
+	for _, result := range results {
+		if importedOnly && len(result.Importers) == 0 {
+			continue
+		}
+		count++
+		dep := result.Dependency
+		version := dep.Version
+		if result.UnknownVersion {
+			version = "版本未知"
+			if dep.Version != "" {
+				version += " " + dep.Version
+			}
+		}
+		builder.WriteString(fmt.Sprintf("%d. [%s] %s@%s", count, dep.Ecosystem, dep.Name, version))
+		if dep.Scope != "" {
+			builder.WriteString(fmt.Sprintf(" (%s)", dep.Scope))
+		}
+		builder.WriteString(fmt.Sprintf("\n   声明位置: %s\n", dep.Manifest))
+		if result.UnknownVersion {
+			builder.WriteString("   版本未能解析（父 POM 不在本地仓库、属性未定义或为版本区间），以下为该组件的全部公告，需人工确认实际版本是否受影响\n")
+		}
+		for _, advisory := range result.Advisories {
+			builder.WriteString(fmt.Sprintf("   - %s", advisory.ID))
+			if len(advisory.Aliases) > 0 {
+				builder.WriteString(fmt.Sprintf(" (%s)", strings.Join(advisory.Aliases, ", ")))
+			}
+			if advisory.Severity != "" {
+				builder.WriteString(fmt.Sprintf(" [%s]", advisory.Severity))
+			}
+			builder.WriteString(fmt.Sprintf(" %s\n", advisory.Summary))
+			if len(advisory.Fixed) > 0 {
+				builder.WriteString(fmt.Sprintf("     修复版本: %s\n", strings.Join(advisory.Fixed, ", ")))
+			}
+		}
+		if len(result.Importers) == 0 {
+			builder.WriteString("   导入该组件的代码: 未发现，可能未被项目代码直接使用\n")
+		} else {
+			note := ""
+			if result.Inferred {
+				note = "（按 groupId 推断包名，结果可能不准确）"
+			}
+			builder.WriteString(fmt.Sprintf("   导入该组件的代码 (%d)%s:\n", len(result.Importers), note))
+			for _, importer := range result.Importers {
+				builder.WriteString(fmt.Sprintf("     %s\n", importer))
+			}
+		}
+		builder.WriteString("\n")
+	}
+	if count == 0 {
+		return "未发现存在已知漏洞的依赖"
+	}
+	return builder.String()
+}
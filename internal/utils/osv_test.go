@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0.0", 0},
+		{"2.14.1", "2.9.10", 1},
+		{"v1.9.0", "1.10.0", -1},
+		{"2.0.0-rc1", "2.0.0", -1},
+		{"1.0.1", "1.0-rc1", 1},
+		{"5.3.18.RELEASE", "5.3.18", 0},
+		{"1.0-SNAPSHOT", "1.0", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := compareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// testOSVAdvisories 测试用的公告：log4j-core 的区间公告与 lodash 的版本列表公告
+const testOSVAdvisories = `[
+  {
+    "id": "GHSA-jfh8-c2jp-5v3q",
+    "aliases": ["CVE-2021-44228"],
+    "summary": "Remote code injection in Log4j",
+    "database_specific": {"severity": "CRITICAL"},
+    "affected": [{
+      "package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [
+        {"introduced": "2.0-beta9"}, {"fixed": "2.3.1"},
+        {"introduced": "2.4"}, {"fixed": "2.12.2"},
+        {"introduced": "2.13.0"}, {"fixed": "2.15.0"}
+      ]}]
+    }]
+  },
+  {
+    "id": "GHSA-test-log4j-api",
+    "summary": "Test advisory for log4j-api",
+    "database_specific": {"severity": "CRITICAL"},
+    "affected": [{
+      "package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-api"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0"}, {"fixed": "2.17.0"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-p6mc-m468-83gw",
+    "details": "Prototype pollution in lodash\nmore details",
+    "affected": [{
+      "package": {"ecosystem": "npm", "name": "lodash"},
+      "versions": ["4.17.15", "4.17.19"]
+    }]
+  }
+]`
+
+func TestOSVDatabaseMatch(t *testing.T) {
+	db := &OSVDatabase{advisories: make(map[string][]*osvAdvisory)}
+	db.add([]byte(testOSVAdvisories))
+
+	tests := []struct {
+		name string
+		dep  ManifestDependency
+		want []string
+	}{
+		{"区间内", ManifestDependency{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1"}, []string{"GHSA-jfh8-c2jp-5v3q"}},
+		{"修复版本", ManifestDependency{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core", Version: "2.15.0"}, nil},
+		{"区间之间", ManifestDependency{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core", Version: "2.3.2"}, nil},
+		{"早于引入版本", ManifestDependency{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core", Version: "1.2.17"}, nil},
+		{"版本列表", ManifestDependency{Ecosystem: "npm", Name: "lodash", Version: "4.17.19"}, []string{"GHSA-p6mc-m468-83gw"}},
+		{"不在版本列表", ManifestDependency{Ecosystem: "npm", Name: "lodash", Version: "4.17.21"}, nil},
+		{"其他生态", ManifestDependency{Ecosystem: "npm", Name: "org.apache.logging.log4j:log4j-core", Version: "2.14.1"}, nil},
+		// 版本未能解析时无法核对，返回该组件的全部公告
+		{"未展开的属性", ManifestDependency{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core", Version: "${log4j.version}"}, []string{"GHSA-jfh8-c2jp-5v3q"}},
+		{"版本区间", ManifestDependency{Ecosystem: "Maven", Name: "org.apache.logging.log4j:log4j-core", Version: "[2.0,3.0)"}, []string{"GHSA-jfh8-c2jp-5v3q"}},
+		{"空版本", ManifestDependency{Ecosystem: "npm", Name: "lodash"}, []string{"GHSA-p6mc-m468-83gw"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, advisory := range db.Match(tt.dep) {
+				got = append(got, advisory.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	advisories := db.Match(ManifestDependency{Ecosystem: "npm", Name: "lodash", Version: "4.17.15"})
+	if len(advisories) != 1 || advisories[0].Summary != "Prototype pollution in lodash" {
+		t.Errorf("没有 summary 时应取 details 的第一行: %+v", advisories)
+	}
+}
+
+func TestFindVulnerableDependencies(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	project := filepath.Join(dir, "project")
+	writeFiles(t, cacheDir, map[string]string{"osv/advisories.json": testOSVAdvisories})
+	writeFiles(t, project, map[string]string{
+		"pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0</version>
+  <dependencies>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-core</artifactId>
+      <version>2.14.1</version>
+    </dependency>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-api</artifactId>
+      <version>${log4j.version}</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"src/main/java/app/Audit.java": `package app;
+
+import org.apache.logging.log4j.LogManager;
+
+public class Audit {
+    void log(String msg) {
+        LogManager.getLogger().info(msg);
+    }
+}
+`,
+		"web/package.json":      `{"dependencies": {"lodash": "^4.17.0"}, "devDependencies": {"jest": "^29.0.0"}}`,
+		"web/package-lock.json": `{"packages": {"node_modules/lodash": {"version": "4.17.19"}}}`,
+	})
+
+	db, err := LoadOSVDatabase(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewParserManager()
+	m.RegisterParser(&JavaParser{})
+	if err := m.BuildIndexFromDir(project); err != nil {
+		t.Fatal(err)
+	}
+	repository := filepath.Join(dir, "repo")
+	results := FindVulnerableDependencies(NewQueryEngine(m.GetIndex()), db, CollectDependencies(project, nil, repository), repository)
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Dependency.Name+"@"+result.Dependency.Version+" "+strings.Join(result.Importers, ","))
+	}
+	// 版本未知的组件即使被导入，也排在已确认受影响的组件之后
+	want := []string{
+		"org.apache.logging.log4j:log4j-core@2.14.1 app.Audit",
+		"lodash@4.17.19 ",
+		"org.apache.logging.log4j:log4j-api@${log4j.version} app.Audit",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindVulnerableDependencies = %v, want %v", got, want)
+	}
+	if !results[0].Inferred {
+		t.Errorf("本地仓库中没有 jar 时应按 groupId 推断包名")
+	}
+	if results[0].UnknownVersion || !results[2].UnknownVersion {
+		t.Errorf("只有版本未能解析的依赖标注为版本未知")
+	}
+	if report := FormatVulnerableDependencies(results, true); strings.Contains(report, "lodash") || !strings.Contains(report, "CVE-2021-44228") ||
+		!strings.Contains(report, "log4j-api@版本未知 ${log4j.version}") {
+		t.Errorf("只输出被导入的组件时报告不正确:\n%s", report)
+	}
+}
+
+func TestLoadOSVDatabaseErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadOSVDatabase(dir); err == nil {
+		t.Errorf("没有 osv 目录时应当返回错误")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "osv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOSVDatabase(dir); err == nil {
+		t.Errorf("osv 目录中没有公告时应当返回错误")
+	}
+}
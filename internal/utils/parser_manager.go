@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -28,14 +29,57 @@ func (m *ParserManager) RegisterParser(parser ASTParser) {
 	m.parsers[parser.Language()] = parser
 }
 
-// BuildIndexFromDir 从目录构建索引
-func (m *ParserManager) BuildIndexFromDir(root string) error {
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+// BuildIndexFromDir 从目录构建索引，libraryDirs 为依赖库的源码目录，其中的节点标记为 library 层，
+// 与项目代码一起参与父类、子类和方法调用的解析
+func (m *ParserManager) BuildIndexFromDir(root string, libraryDirs ...string) error {
+	// 记录 Gradle 构建中的子项目、源码集和依赖，节点所属模块按 Gradle 项目路径命名
+	m.index.gradleProjects = FindGradleProjects(root)
+
+	if err := m.indexDir(root, ""); err != nil {
+		return err
+	}
+	for _, dir := range libraryDirs {
+		if err := m.indexDir(dir, "library"); err != nil {
+			return err
+		}
+	}
+
+	// Go 的接口按方法集隐式实现，需要看到所有文件中的方法后才能确定
+	LinkGoImplementations(m)
+
+	// ====== 遍历完所有文件后，再填充子类关系 ======
+	FillSubClasses(m)
+
+	// 子类关系就绪后再解析方法调用，重写方法的查找依赖 SubClasses
+	BuildCallGraph(m)
+
+	// 关联字段节点与字段读写位置
+	BuildFieldReferences(m)
+
+	// 关联 PHP 的 include / require 与被包含的文件
+	BuildIncludeGraph(m)
+	return nil
+}
+
+// indexDir 解析目录下的所有源码文件并加入索引，layer 非空时记录到节点的 layer 元数据
+func (m *ParserManager) indexDir(root, layer string) error {
+	// 反编译归档得到的目录及其来源归档，见 ArchiveIngester
+	artifacts := make(map[string]string)
+	modules := newModuleResolver(root, artifacts, m.index.gradleProjects)
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if info.IsDir() {
+			// 跳过 JavaScript 依赖目录
+			if info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			if data, err := os.ReadFile(filepath.Join(path, ArtifactMarkerFile)); err == nil {
+				artifacts[path] = strings.TrimSpace(string(data))
+			}
 			return nil
 		}
 
@@ -49,6 +93,31 @@ func (m *ParserManager) BuildIndexFromDir(root string) error {
 			language = "java"
 		case ".py":
 			language = "python"
+		case ".kt":
+			language = "kotlin"
+		case ".cs":
+			language = "csharp"
+		case ".c":
+			language = "c"
+		case ".cpp", ".cc", ".cxx", ".h", ".hpp", ".hh", ".hxx":
+			language = "cpp"
+		case ".js", ".jsx", ".mjs", ".cjs":
+			// 跳过压缩后的脚本
+			if !strings.HasSuffix(path, ".min.js") {
+				language = "javascript"
+			}
+		case ".ts", ".tsx":
+			// 跳过类型声明文件
+			if !strings.HasSuffix(path, ".d.ts") {
+				language = "typescript"
+			}
+		case ".php", ".phtml", ".php5", ".php7":
+			language = "php"
+		case ".xml":
+			// 只解析 Servlet 部署描述符
+			if name := filepath.Base(path); name == "web.xml" || name == "web-fragment.xml" {
+				language = "webxml"
+			}
 		default:
 			return nil
 		}
@@ -62,25 +131,53 @@ func (m *ParserManager) BuildIndexFromDir(root string) error {
 		// 解析文件
 		nodes, err := parser.ParseFile(path)
 		if err != nil {
+			// 依赖库中个别文件解析失败不影响项目代码的索引
+			if layer != "" {
+				return nil
+			}
 			return err
 		}
 
+		// 标注节点来自哪个归档
+		artifact := nearestArtifact(artifacts, root, path)
+		module := modules.resolve(path)
+
 		// 添加到索引
 		m.mu.Lock()
 		for _, node := range nodes {
+			if artifact != "" || layer != "" {
+				if node.Metadata == nil {
+					node.Metadata = make(map[string]string)
+				}
+			}
+			if artifact != "" {
+				node.Metadata["artifact"] = artifact
+			}
+			if layer != "" {
+				node.Metadata["layer"] = layer
+			}
+			node.Module = module
 			m.index.AddNode(node)
 		}
 		m.mu.Unlock()
 
 		return nil
 	})
-	if err != nil {
-		return err
-	}
+}
 
-	// ====== 遍历完所有文件后，再填充子类关系 ======
-	FillSubClasses(m)
-	return nil
+// nearestArtifact 返回文件所在的最近一层归档目录对应的归档名
+func nearestArtifact(artifacts map[string]string, root, path string) string {
+	if len(artifacts) == 0 {
+		return ""
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if artifact, ok := artifacts[dir]; ok {
+			return artifact
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
 }
 
 // GetIndex 获取索引
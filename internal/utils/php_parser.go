@@ -0,0 +1,802 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/php"
+)
+
+// PHPParser 实现 PHP 语言的 AST 解析。
+// 命名空间（\ 替换为 .，如 App.Http.Controllers）记录为 Package，全局命名空间的类只有类名；
+// 类、接口、trait 和枚举记录为 Class，类中的方法记录为 Method，函数记录为 Function（className 为命名空间，全局命名空间为 global）。
+// 每个文件额外记录一个 File 节点，include / require 记录为 Include 节点，建立索引后关联到被包含文件的 File 节点
+type PHPParser struct{}
+
+// phpScope 记录遍历到当前节点时所处的命名空间、类和函数上下文，用于推断调用的接收者类型
+type phpScope struct {
+	namespace  string            // 当前命名空间（点分形式）
+	classID    string            // 所在类节点ID
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的父类全限定名（用于 parent::）
+	methodID   string            // 所在函数节点ID
+	fieldTypes map[string]string // 所在类的属性名 -> 类型全限定名
+	varTypes   map[string]string // 参数及局部变量名（不含 $）-> 类型全限定名（未知时为空）
+}
+
+// phpImports use 语句导入的类和函数：别名 -> 全限定名（点分形式）
+type phpImports struct {
+	classes   map[string]string
+	functions map[string]string
+}
+
+// phpBuiltinFunctions 常用的内置函数，在命名空间中不加 \ 调用时仍按全局函数处理
+var phpBuiltinFunctions = map[string]bool{
+	"eval": true, "assert": true, "system": true, "exec": true, "shell_exec": true, "passthru": true,
+	"popen": true, "proc_open": true, "pcntl_exec": true, "call_user_func": true, "call_user_func_array": true,
+	"create_function": true, "preg_replace": true, "unserialize": true, "serialize": true,
+	"file_get_contents": true, "file_put_contents": true, "fopen": true, "fwrite": true,
+	"readfile": true, "unlink": true, "copy": true, "rename": true, "move_uploaded_file": true,
+	"mysql_query": true, "mysqli_query": true, "pg_query": true, "sqlite_query": true, "header": true,
+	"setcookie": true, "extract": true, "parse_str": true, "curl_exec": true, "curl_init": true,
+	"simplexml_load_string": true, "dirname": true, "basename": true, "strlen": true, "sprintf": true,
+	"printf": true, "print_r": true, "var_dump": true, "json_encode": true, "json_decode": true,
+	"htmlspecialchars": true, "addslashes": true, "error_log": true, "is_array": true, "isset": true,
+	"count": true, "in_array": true, "array_map": true, "implode": true, "explode": true, "trim": true,
+}
+
+// phpPrimitiveTypes 标量类型和伪类型，不参与类型推断
+var phpPrimitiveTypes = map[string]bool{
+	"int": true, "float": true, "string": true, "bool": true, "array": true, "mixed": true,
+	"void": true, "null": true, "callable": true, "iterable": true, "object": true, "never": true,
+	"false": true, "true": true, "integer": true, "boolean": true, "double": true, "resource": true,
+}
+
+// phpDocVarPattern 匹配文档注释中的 @var 类型，用于推断未声明类型的属性
+var phpDocVarPattern = regexp.MustCompile(`@var\s+\\?([A-Za-z_][\w\\]*)`)
+
+func (p *PHPParser) Language() string {
+	return "php"
+}
+
+func (p *PHPParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(php.GetLanguage())
+	tree := parser.Parse(nil, code)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	imports := p.collectImports(root, code)
+	scope := &phpScope{varTypes: map[string]string{}}
+
+	var nodes []UniversalASTNode
+	p.traverseNode(root, filePath, code, imports, scope, &nodes)
+
+	// 文件节点作为 include / require 关系的端点
+	nodes = append(nodes, UniversalASTNode{
+		ID:        filePath,
+		Language:  "php",
+		Type:      "File",
+		Name:      filepath.Base(filePath),
+		File:      filePath,
+		Package:   scope.namespace,
+		StartLine: int(root.StartPoint().Row),
+		EndLine:   int(root.EndPoint().Row),
+		Metadata:  map[string]string{},
+	})
+	return nodes, nil
+}
+
+// phpName 将 PHP 的 \ 分隔名称转换为点分形式，去掉开头的 \
+func phpName(name string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(name), `\`), `\`, ".")
+}
+
+// collectImports 收集 use 语句（包括分组导入和 use function），返回别名 -> 全限定名
+func (p *PHPParser) collectImports(root *sitter.Node, code []byte) phpImports {
+	imports := phpImports{classes: make(map[string]string), functions: make(map[string]string)}
+
+	add := func(isFunction bool, fullName string, alias *sitter.Node) {
+		fullName = phpName(fullName)
+		local := fullName[strings.LastIndex(fullName, ".")+1:]
+		if alias != nil {
+			local = alias.Content(code)
+		}
+		if isFunction {
+			imports.functions[local] = fullName
+		} else {
+			imports.classes[local] = fullName
+		}
+	}
+	aliasOf := func(clause *sitter.Node) *sitter.Node {
+		for i := 0; i < int(clause.NamedChildCount()); i++ {
+			if child := clause.NamedChild(i); child.Type() == "namespace_aliasing_clause" && child.NamedChildCount() > 0 {
+				return child.NamedChild(0)
+			}
+		}
+		return nil
+	}
+
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if node.Type() != "namespace_use_declaration" {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				walk(node.NamedChild(i))
+			}
+			return
+		}
+		isFunction, prefix := false, ""
+		for i := 0; i < int(node.ChildCount()); i++ {
+			child := node.Child(i)
+			switch child.Type() {
+			case "function":
+				isFunction = true
+			case "namespace_name":
+				prefix = child.Content(code)
+			case "namespace_use_clause":
+				if child.NamedChildCount() > 0 {
+					add(isFunction, child.NamedChild(0).Content(code), aliasOf(child))
+				}
+			case "namespace_use_group":
+				for j := 0; j < int(child.NamedChildCount()); j++ {
+					clause := child.NamedChild(j)
+					if clause.NamedChildCount() > 0 {
+						add(isFunction, prefix+`\`+clause.NamedChild(0).Content(code), aliasOf(clause))
+					}
+				}
+			}
+		}
+	}
+	walk(root)
+	return imports
+}
+
+// resolveClassName 将源码中的类名解析为全限定名：处理 self / static / parent、完全限定名、use 别名和当前命名空间
+func (p *PHPParser) resolveClassName(name string, imports phpImports, scope *phpScope) string {
+	name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "?"))
+	switch strings.ToLower(name) {
+	case "":
+		return ""
+	case "self", "static", "$this":
+		return scope.className
+	case "parent":
+		return scope.superClass
+	}
+	if phpPrimitiveTypes[strings.ToLower(name)] || strings.ContainsAny(name, "|&$") {
+		return ""
+	}
+	if strings.HasPrefix(name, `\`) {
+		return phpName(name)
+	}
+	dotted := phpName(name)
+	head, rest, _ := strings.Cut(dotted, ".")
+	if full, ok := imports.classes[head]; ok {
+		return joinDotted(full, rest)
+	}
+	return joinDotted(scope.namespace, dotted)
+}
+
+func (p *PHPParser) traverseNode(node *sitter.Node, filePath string, code []byte, imports phpImports, scope *phpScope, nodes *[]UniversalASTNode) {
+	// 子节点默认沿用当前作用域，进入命名空间块、类或函数时替换为新的作用域
+	childScope := scope
+
+	switch node.Type() {
+	case "namespace_definition":
+		namespace := ""
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			namespace = phpName(nameNode.Content(code))
+		}
+		if node.ChildByFieldName("body") == nil {
+			// namespace X; 作用于文件中后续的所有声明
+			scope.namespace = namespace
+		} else {
+			childScope = &phpScope{namespace: namespace, varTypes: make(map[string]string)}
+		}
+	case "class_declaration", "interface_declaration", "trait_declaration", "enum_declaration":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		className := nameNode.Content(code)
+		id := fmt.Sprintf("%s:%s:%d", filePath, className, node.StartByte())
+		fullName := joinDotted(scope.namespace, className)
+
+		classNode := UniversalASTNode{
+			ID:          id,
+			Language:    "php",
+			Type:        "Class",
+			Name:        className,
+			File:        filePath,
+			Package:     scope.namespace,
+			StartLine:   int(node.StartPoint().Row),
+			EndLine:     int(node.EndPoint().Row),
+			Fields:      make([]FieldInfo, 0),
+			Metadata:    map[string]string{"kind": strings.TrimSuffix(node.Type(), "_declaration")},
+			Annotations: p.collectAttributes(node, code, imports, scope),
+			Modifiers:   p.collectModifiers(node, code),
+		}
+
+		// 父类、实现的接口和使用的 trait 都记录为 SuperClasses，trait 中的方法按继承的方法解析
+		var superFullNames, traits []string
+		superClass := ""
+		body := node.ChildByFieldName("body")
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child.Type() != "base_clause" && child.Type() != "class_interface_clause" {
+				continue
+			}
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				fq := p.resolveClassName(child.NamedChild(j).Content(code), imports, scope)
+				if fq == "" {
+					continue
+				}
+				if child.Type() == "base_clause" && node.Type() == "class_declaration" && superClass == "" {
+					superClass = fq
+				}
+				superFullNames = append(superFullNames, fq)
+			}
+		}
+		if body != nil {
+			for i := 0; i < int(body.NamedChildCount()); i++ {
+				if member := body.NamedChild(i); member.Type() == "use_declaration" {
+					for j := 0; j < int(member.NamedChildCount()); j++ {
+						if traitName := member.NamedChild(j); traitName.Type() == "name" || traitName.Type() == "qualified_name" {
+							traits = append(traits, p.resolveClassName(traitName.Content(code), imports, scope))
+						}
+					}
+				}
+			}
+		}
+		superFullNames = append(superFullNames, traits...)
+		for _, fq := range superFullNames {
+			pkg, name := "", fq
+			if idx := strings.LastIndex(fq, "."); idx != -1 {
+				pkg, name = fq[:idx], fq[idx+1:]
+			}
+			classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: name})
+		}
+		classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+		classNode.Metadata["traits"] = strings.Join(traits, ",")
+		if scope.classID != "" {
+			classNode.IsInnerClass = true
+			classNode.OuterClass = scope.className
+		}
+
+		childScope = &phpScope{
+			namespace:  scope.namespace,
+			classID:    id,
+			className:  fullName,
+			superClass: superClass,
+			fieldTypes: make(map[string]string),
+			varTypes:   make(map[string]string),
+		}
+		if body != nil {
+			p.collectClassFields(body, code, &classNode)
+		}
+		*nodes = append(*nodes, classNode)
+
+		for _, field := range classNode.Fields {
+			fieldType := p.resolveClassName(field.Type, imports, childScope)
+			childScope.fieldTypes[field.Name] = fieldType
+			*nodes = append(*nodes, UniversalASTNode{
+				ID:          fmt.Sprintf("%s:%s.%s:%d", filePath, className, field.Name, field.StartLine),
+				Language:    "php",
+				Type:        "Field",
+				Name:        field.Name,
+				File:        filePath,
+				Package:     scope.namespace,
+				StartLine:   field.StartLine,
+				EndLine:     field.EndLine,
+				Annotations: field.Annotations,
+				Modifiers:   field.Modifiers,
+				Metadata: map[string]string{
+					"fieldType": field.Type,
+					"fullType":  fieldType,
+					"classID":   id,
+					"className": fullName,
+				},
+			})
+		}
+	case "method_declaration", "function_definition":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		funcName := nameNode.Content(code)
+		id := fmt.Sprintf("%s:%s:%d", filePath, funcName, node.StartByte())
+		childScope = &phpScope{
+			namespace:  scope.namespace,
+			classID:    scope.classID,
+			className:  scope.className,
+			superClass: scope.superClass,
+			methodID:   id,
+			fieldTypes: scope.fieldTypes,
+			varTypes:   make(map[string]string),
+		}
+		params, methodParams := p.bindParams(node, code, imports, childScope)
+
+		returnType := ""
+		if returnNode := node.ChildByFieldName("return_type"); returnNode != nil {
+			returnType = returnNode.Content(code)
+		}
+
+		nodeType, owner, classID := "Function", scope.namespace, ""
+		if owner == "" {
+			owner = globalNamespace
+		}
+		if node.Type() == "method_declaration" && scope.classID != "" {
+			nodeType, owner, classID = "Method", scope.className, scope.classID
+		}
+		*nodes = append(*nodes, UniversalASTNode{
+			ID:           id,
+			Language:     "php",
+			Type:         nodeType,
+			Name:         funcName,
+			File:         filePath,
+			Package:      scope.namespace,
+			StartLine:    int(node.StartPoint().Row),
+			EndLine:      int(node.EndPoint().Row),
+			MethodParams: methodParams,
+			Params:       params,
+			Annotations:  p.collectAttributes(node, code, imports, scope),
+			Modifiers:    p.collectModifiers(node, code),
+			Metadata: map[string]string{
+				"returnType":     returnType,
+				"returnFullType": p.resolveClassName(returnType, imports, childScope),
+				"classID":        classID,
+				"className":      owner,
+			},
+		})
+	case "anonymous_function_creation_expression", "arrow_function":
+		// 闭包中的调用归属外层函数，参数只在闭包内可见
+		childScope = &phpScope{
+			namespace:  scope.namespace,
+			classID:    scope.classID,
+			className:  scope.className,
+			superClass: scope.superClass,
+			methodID:   scope.methodID,
+			fieldTypes: scope.fieldTypes,
+			varTypes:   make(map[string]string),
+		}
+		for name, varType := range scope.varTypes {
+			childScope.varTypes[name] = varType
+		}
+		p.bindParams(node, code, imports, childScope)
+	case "assignment_expression":
+		// 记录局部变量类型：$x = new Foo()、$x = Foo::create()
+		if left, right := node.ChildByFieldName("left"), node.ChildByFieldName("right"); left != nil && right != nil && left.Type() == "variable_name" {
+			scope.varTypes[strings.TrimPrefix(left.Content(code), "$")] = p.expressionType(right, code, imports, scope)
+		}
+	case "function_call_expression", "member_call_expression", "nullsafe_member_call_expression",
+		"scoped_call_expression", "object_creation_expression":
+		if callNode, ok := p.callNode(node, filePath, code, imports, scope); ok {
+			*nodes = append(*nodes, callNode)
+		}
+	case "include_expression", "include_once_expression", "require_expression", "require_once_expression":
+		*nodes = append(*nodes, p.includeNode(node, filePath, code, scope))
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		// 属性（#[...]）已记录为注解，不再作为调用处理
+		if child == nil || child.Type() == "attribute_list" {
+			continue
+		}
+		p.traverseNode(child, filePath, code, imports, childScope, nodes)
+	}
+}
+
+// bindParams 解析函数的形参并记录到作用域的变量表，返回参数信息和用于签名匹配的类型：
+// 未声明类型的参数类型为 mixed，可变参数追加 ...，默认值不计入签名
+func (p *PHPParser) bindParams(node *sitter.Node, code []byte, imports phpImports, scope *phpScope) ([]ParamInfo, []string) {
+	var params []ParamInfo
+	var methodParams []string
+
+	paramsNode := node.ChildByFieldName("parameters")
+	if paramsNode == nil {
+		return nil, nil
+	}
+	for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+		paramNode := paramsNode.NamedChild(i)
+		nameNode := paramNode.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		param := ParamInfo{Name: nameNode.Content(code)}
+		if typeNode := paramNode.ChildByFieldName("type"); typeNode != nil {
+			param.Type = typeNode.Content(code)
+		}
+		if attributes := paramNode.ChildByFieldName("attributes"); attributes != nil {
+			param.Annotations = p.attributeInfos(attributes, code, imports, scope)
+		}
+		scope.varTypes[strings.TrimPrefix(param.Name, "$")] = p.resolveClassName(param.Type, imports, scope)
+
+		signature := param.Type
+		if signature == "" {
+			signature = "mixed"
+		}
+		if paramNode.Type() == "variadic_parameter" {
+			param.Variadic = true
+			signature += "..."
+		} else if paramNode.ChildByFieldName("default_value") != nil {
+			param.Optional = true
+		}
+		params = append(params, param)
+		methodParams = append(methodParams, signature)
+	}
+	return params, methodParams
+}
+
+// collectClassFields 收集类属性、构造函数中的提升属性以及类常量和枚举成员
+func (p *PHPParser) collectClassFields(body *sitter.Node, code []byte, classNode *UniversalASTNode) {
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		member := body.NamedChild(i)
+		switch member.Type() {
+		case "property_declaration", "const_declaration":
+			fieldType := ""
+			if typeNode := member.ChildByFieldName("type"); typeNode != nil {
+				fieldType = typeNode.Content(code)
+			} else if prev := member.PrevNamedSibling(); prev != nil && prev.Type() == "comment" {
+				// 老代码常用 /** @var Foo */ 注释声明属性类型
+				if match := phpDocVarPattern.FindStringSubmatch(prev.Content(code)); match != nil {
+					fieldType = match[1]
+				}
+			}
+			modifiers := p.collectModifiers(member, code)
+			if member.Type() == "const_declaration" {
+				modifiers = appendUnique(modifiers, "const")
+			}
+			for j := 0; j < int(member.NamedChildCount()); j++ {
+				element := member.NamedChild(j)
+				if element.Type() != "property_element" && element.Type() != "const_element" {
+					continue
+				}
+				nameNode := element.NamedChild(0)
+				if nameNode == nil {
+					continue
+				}
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:      strings.TrimPrefix(nameNode.Content(code), "$"),
+					Type:      fieldType,
+					StartLine: int(element.StartPoint().Row),
+					EndLine:   int(element.EndPoint().Row),
+					Modifiers: modifiers,
+					Metadata:  map[string]string{},
+				})
+			}
+		case "enum_case":
+			if nameNode := member.ChildByFieldName("name"); nameNode != nil {
+				classNode.Fields = append(classNode.Fields, FieldInfo{
+					Name:      nameNode.Content(code),
+					Type:      classNode.Name,
+					StartLine: int(member.StartPoint().Row),
+					EndLine:   int(member.EndPoint().Row),
+					Modifiers: []string{"case"},
+					Metadata:  map[string]string{},
+				})
+			}
+		case "method_declaration":
+			if name := member.ChildByFieldName("name"); name == nil || !strings.EqualFold(name.Content(code), "__construct") {
+				continue
+			}
+			params := member.ChildByFieldName("parameters")
+			if params == nil {
+				continue
+			}
+			for j := 0; j < int(params.NamedChildCount()); j++ {
+				param := params.NamedChild(j)
+				nameNode := param.ChildByFieldName("name")
+				if param.Type() != "property_promotion_parameter" || nameNode == nil {
+					continue
+				}
+				field := FieldInfo{
+					Name:      strings.TrimPrefix(nameNode.Content(code), "$"),
+					StartLine: int(param.StartPoint().Row),
+					EndLine:   int(param.EndPoint().Row),
+					Modifiers: p.collectModifiers(param, code),
+					Metadata:  map[string]string{},
+				}
+				if typeNode := param.ChildByFieldName("type"); typeNode != nil {
+					field.Type = typeNode.Content(code)
+				}
+				classNode.Fields = append(classNode.Fields, field)
+			}
+		}
+	}
+}
+
+// collectModifiers 收集访问控制、static、abstract、final、readonly 等修饰符
+func (p *PHPParser) collectModifiers(node *sitter.Node, code []byte) []string {
+	var modifiers []string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		switch child := node.NamedChild(i); child.Type() {
+		case "visibility_modifier", "static_modifier", "abstract_modifier", "final_modifier", "readonly_modifier", "var_modifier":
+			modifiers = append(modifiers, strings.ToLower(child.Content(code)))
+		}
+	}
+	return modifiers
+}
+
+// collectAttributes 收集声明上的 PHP 8 属性（#[Route('/x')]）作为注解
+func (p *PHPParser) collectAttributes(node *sitter.Node, code []byte, imports phpImports, scope *phpScope) []AnnotationInfo {
+	if attributes := node.ChildByFieldName("attributes"); attributes != nil {
+		return p.attributeInfos(attributes, code, imports, scope)
+	}
+	return nil
+}
+
+// attributeInfos 将 attribute_list 转换为注解：第一个位置参数记为 value，命名参数按名称记录
+func (p *PHPParser) attributeInfos(list *sitter.Node, code []byte, imports phpImports, scope *phpScope) []AnnotationInfo {
+	var annotations []AnnotationInfo
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		group := list.NamedChild(i)
+		for j := 0; j < int(group.NamedChildCount()); j++ {
+			attribute := group.NamedChild(j)
+			if attribute.Type() != "attribute" || attribute.NamedChildCount() == 0 {
+				continue
+			}
+			fullName := p.resolveClassName(attribute.NamedChild(0).Content(code), imports, scope)
+			annotation := AnnotationInfo{
+				Name:      ShortClassName(fullName),
+				FullName:  fullName,
+				Arguments: map[string]string{},
+			}
+			if args := attribute.ChildByFieldName("parameters"); args != nil {
+				for k := 0; k < int(args.NamedChildCount()); k++ {
+					arg := args.NamedChild(k)
+					if arg.Type() != "argument" || arg.NamedChildCount() == 0 {
+						continue
+					}
+					value := arg.NamedChild(int(arg.NamedChildCount()) - 1)
+					if argName := arg.ChildByFieldName("name"); argName != nil {
+						annotation.Arguments[argName.Content(code)] = p.literalValue(value, code)
+					} else if _, exists := annotation.Arguments["value"]; !exists {
+						annotation.Arguments["value"] = p.literalValue(value, code)
+					}
+				}
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// literalValue 提取字面量的值：字符串去掉引号，数组以逗号连接各元素
+func (p *PHPParser) literalValue(node *sitter.Node, code []byte) string {
+	switch node.Type() {
+	case "string", "encapsed_string":
+		var content strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			content.WriteString(node.NamedChild(i).Content(code))
+		}
+		return content.String()
+	case "array_creation_expression":
+		var values []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			element := node.NamedChild(i)
+			if element.NamedChildCount() > 0 {
+				values = append(values, p.literalValue(element.NamedChild(int(element.NamedChildCount())-1), code))
+			}
+		}
+		return strings.Join(values, ",")
+	}
+	return node.Content(code)
+}
+
+// callNode 为函数调用、方法调用、静态调用和 new 表达式创建 MethodCall 节点
+func (p *PHPParser) callNode(node *sitter.Node, filePath string, code []byte, imports phpImports, scope *phpScope) (UniversalASTNode, bool) {
+	name, receiver, receiverType, receiverCallID := "", "", "", ""
+	switch node.Type() {
+	case "object_creation_expression":
+		if node.NamedChildCount() == 0 {
+			return UniversalASTNode{}, false
+		}
+		class := node.NamedChild(0)
+		if class.Type() == "anonymous_class" || class.Type() == "declaration_list" {
+			return UniversalASTNode{}, false
+		}
+		name = "<init>"
+		if class.Type() != "variable_name" {
+			receiverType = p.resolveClassName(class.Content(code), imports, scope)
+		}
+	case "function_call_expression":
+		function := node.ChildByFieldName("function")
+		if function == nil {
+			return UniversalASTNode{}, false
+		}
+		switch function.Type() {
+		case "name":
+			// 命名空间中的函数找不到时回退到全局函数，常用的内置函数直接按全局函数处理
+			name = function.Content(code)
+			if full, ok := imports.functions[name]; ok {
+				receiverType, name = full[:max(strings.LastIndex(full, "."), 0)], ShortClassName(full)
+			} else if scope.namespace == "" || phpBuiltinFunctions[strings.ToLower(name)] {
+				receiverType = globalNamespace
+			} else {
+				receiverType = scope.namespace
+			}
+		case "qualified_name":
+			full := phpName(function.Content(code))
+			if !strings.HasPrefix(function.Content(code), `\`) {
+				full = p.resolveClassName(function.Content(code), phpImports{classes: imports.classes}, scope)
+			}
+			name, receiverType = ShortClassName(full), globalNamespace
+			if idx := strings.LastIndex(full, "."); idx != -1 {
+				receiverType = full[:idx]
+			}
+		default:
+			// $callback() 等动态调用无法确定目标
+			name = function.Content(code)
+		}
+	case "member_call_expression", "nullsafe_member_call_expression":
+		object, nameNode := node.ChildByFieldName("object"), node.ChildByFieldName("name")
+		if object == nil || nameNode == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiver = nameNode.Content(code), object.Content(code)
+		receiverType = p.expressionType(object, code, imports, scope)
+		// 链式调用的接收者类型在建立索引后按前一个调用的返回类型推断
+		if receiverType == "" && strings.HasSuffix(object.Type(), "call_expression") {
+			if inner := object.ChildByFieldName("name"); inner != nil {
+				receiverCallID = callNodeID(filePath, inner.Content(code), int(object.StartByte()), int(object.EndByte()))
+			}
+		}
+	case "scoped_call_expression":
+		class, nameNode := node.ChildByFieldName("scope"), node.ChildByFieldName("name")
+		if class == nil || nameNode == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiver = nameNode.Content(code), class.Content(code)
+		if class.Type() != "variable_name" {
+			receiverType = p.resolveClassName(receiver, imports, scope)
+		}
+	}
+
+	var arguments []ExprInfo
+	if argsNode := node.ChildByFieldName("arguments"); argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			arguments = append(arguments, p.exprInfo(argsNode.NamedChild(i), code))
+		}
+	} else {
+		// object_creation_expression 的参数列表不是具名字段
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if argsNode := node.NamedChild(i); argsNode.Type() == "arguments" {
+				for j := 0; j < int(argsNode.NamedChildCount()); j++ {
+					arguments = append(arguments, p.exprInfo(argsNode.NamedChild(j), code))
+				}
+			}
+		}
+	}
+
+	callNode := UniversalASTNode{
+		ID:        callNodeID(filePath, name, int(node.StartByte()), int(node.EndByte())),
+		Language:  "php",
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      filePath,
+		Package:   scope.namespace,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	return callNode, true
+}
+
+// expressionType 推断表达式的类型：$this、已知类型的变量和属性、new 表达式，无法推断时返回空
+func (p *PHPParser) expressionType(expr *sitter.Node, code []byte, imports phpImports, scope *phpScope) string {
+	switch expr.Type() {
+	case "variable_name":
+		name := strings.TrimPrefix(expr.Content(code), "$")
+		if name == "this" {
+			return scope.className
+		}
+		return scope.varTypes[name]
+	case "member_access_expression", "nullsafe_member_access_expression":
+		object, nameNode := expr.ChildByFieldName("object"), expr.ChildByFieldName("name")
+		if object != nil && nameNode != nil && object.Content(code) == "$this" {
+			return scope.fieldTypes[nameNode.Content(code)]
+		}
+	case "object_creation_expression":
+		if expr.NamedChildCount() > 0 && expr.NamedChild(0).Type() != "variable_name" {
+			return p.resolveClassName(expr.NamedChild(0).Content(code), imports, scope)
+		}
+	case "parenthesized_expression":
+		if expr.NamedChildCount() > 0 {
+			return p.expressionType(expr.NamedChild(0), code, imports, scope)
+		}
+	}
+	return ""
+}
+
+// includeNode 为 include / require 创建 Include 节点。
+// 路径为字符串字面量或 __DIR__ . '/x.php'、dirname(__FILE__) . '/x.php' 时在 target 中记录推断出的文件路径，
+// 由变量拼接的动态路径 target 为空
+func (p *PHPParser) includeNode(node *sitter.Node, filePath string, code []byte, scope *phpScope) UniversalASTNode {
+	kind := strings.TrimSuffix(node.Type(), "_expression")
+	var arguments []ExprInfo
+	pathText, target := "", ""
+	if node.NamedChildCount() > 0 {
+		expr := node.NamedChild(0)
+		for expr.Type() == "parenthesized_expression" && expr.NamedChildCount() > 0 {
+			expr = expr.NamedChild(0)
+		}
+		pathText = expr.Content(code)
+		arguments = append(arguments, p.exprInfo(expr, code))
+		switch expr.Type() {
+		case "string", "encapsed_string":
+			target = p.literalValue(expr, code)
+		case "binary_expression":
+			left, right := expr.ChildByFieldName("left"), expr.ChildByFieldName("right")
+			if left != nil && right != nil && (right.Type() == "string" || right.Type() == "encapsed_string") {
+				switch strings.ReplaceAll(left.Content(code), " ", "") {
+				case "__DIR__", "dirname(__FILE__)":
+					target = filepath.Join(filepath.Dir(filePath), p.literalValue(right, code))
+				}
+			}
+		}
+	}
+
+	return UniversalASTNode{
+		ID:        callNodeID(filePath, kind, int(node.StartByte()), int(node.EndByte())),
+		Language:  "php",
+		Type:      "Include",
+		Name:      kind,
+		Arguments: arguments,
+		File:      filePath,
+		Package:   scope.namespace,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"path":        pathText,
+			"target":      target,
+			"callerID":    scope.methodID,
+			"callerClass": scope.className,
+		},
+	}
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名（不含 $），$this->x 形式的属性记为 this.x
+func (p *PHPParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "variable_name":
+			if name := strings.TrimPrefix(node.Content(code), "$"); name != "this" {
+				info.Uses = appendUnique(info.Uses, name)
+			}
+			return
+		case "member_access_expression":
+			object, nameNode := node.ChildByFieldName("object"), node.ChildByFieldName("name")
+			if object != nil && nameNode != nil && object.Content(code) == "$this" {
+				info.Uses = appendUnique(info.Uses, "this."+nameNode.Content(code))
+				return
+			}
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(exprNode)
+	return info
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPHPParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantParams []string // 签名中的参数类型
+		wantInfo   []ParamInfo
+	}{
+		{
+			name:       "类型与默认值",
+			source:     "<?php\nfunction f(string $a, $b = 1, ?int $c = null) {}\n",
+			wantParams: []string{"string", "mixed", "?int"},
+			wantInfo:   []ParamInfo{{Name: "$a", Type: "string"}, {Name: "$b", Optional: true}, {Name: "$c", Type: "?int", Optional: true}},
+		},
+		{
+			name:       "可变参数",
+			source:     "<?php\nfunction f($fmt, string ...$args) {}\n",
+			wantParams: []string{"mixed", "string..."},
+			wantInfo:   []ParamInfo{{Name: "$fmt"}, {Name: "$args", Type: "string", Variadic: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, map[string]string{"mod.php": tt.source}, &PHPParser{})
+			var found bool
+			for _, node := range index.index {
+				if node.Name != "f" || node.Type != "Function" {
+					continue
+				}
+				found = true
+				if !reflect.DeepEqual(node.MethodParams, tt.wantParams) {
+					t.Errorf("MethodParams = %q, want %q", node.MethodParams, tt.wantParams)
+				}
+				if !reflect.DeepEqual(node.Params, tt.wantInfo) {
+					t.Errorf("Params = %+v, want %+v", node.Params, tt.wantInfo)
+				}
+			}
+			if !found {
+				t.Fatalf("未找到函数 f")
+			}
+		})
+	}
+}
+
+func TestPHPCallGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "类或命名空间.方法名"
+		want   []string
+	}{
+		{
+			name: "命名空间中的类与默认参数",
+			files: map[string]string{
+				"src/Repo.php": `<?php
+namespace App;
+
+class Repo {
+    public function find($key, $limit = 10) { return $key; }
+}
+`,
+				"src/Ctl.php": `<?php
+namespace App\Http;
+
+use App\Repo;
+
+class Ctl {
+    private Repo $repo;
+
+    public function show($key) {
+        return $this->repo->find($key);
+    }
+}
+`,
+			},
+			caller: "App.Http.Ctl.show",
+			want:   []string{"App.Repo.find/2"},
+		},
+		{
+			name: "全局命名空间中子类的重写",
+			files: map[string]string{
+				"Repo.php": `<?php
+class Repo {
+    public function find($key) { return $key; }
+}
+
+class CachedRepo extends Repo {
+    public function find($key) { return $key; }
+}
+`,
+				"Ctl.php": `<?php
+class Ctl {
+    public function show(Repo $repo, $key) {
+        return $repo->find($key);
+    }
+}
+`,
+			},
+			caller: "Ctl.show",
+			want:   []string{"CachedRepo.find/1", "Repo.find/1"},
+		},
+		{
+			name: "命名空间中回退到全局函数",
+			files: map[string]string{
+				"helpers.php": `<?php
+function render($view, ...$args) {}
+`,
+				"Ctl.php": `<?php
+namespace App;
+
+class Ctl {
+    public function show($key) {
+        render('user', $key, 1);
+    }
+}
+`,
+			},
+			caller: "App.Ctl.show",
+			want:   []string{"global.render/2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &PHPParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, method := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, method); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildIncludeGraph(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"index.php":         "<?php\nrequire_once __DIR__ . '/lib/db.php';\ninclude 'config.php';\n",
+		"lib/db.php":        "<?php\nfunction query($sql) {}\n",
+		"config/config.php": "<?php\n$debug = true;\n",
+	}, &PHPParser{})
+
+	var includer UniversalASTNode
+	for id, node := range index.index {
+		if node.Type == "File" && strings.HasSuffix(id, "index.php") {
+			includer = node
+		}
+	}
+	var included []string
+	for _, rel := range includer.Relations {
+		if rel.Type == "includes" {
+			included = append(included, filepath.Base(rel.TargetID))
+		}
+	}
+	sort.Strings(included)
+	if want := []string{"config.php", "db.php"}; !reflect.DeepEqual(included, want) {
+		t.Fatalf("index.php 包含的文件 = %v, want %v", included, want)
+	}
+	for id, node := range index.index {
+		if node.Type == "File" && strings.HasSuffix(id, "db.php") && !hasRelation(node, includer.ID, "included_by") {
+			t.Errorf("db.php 缺少 included_by 关系: %v", node.Relations)
+		}
+	}
+}
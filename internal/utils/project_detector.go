@@ -82,6 +82,7 @@ func (pd *ProjectDetector) isValidProject(projectPath string) bool {
 		"setup.py",         // Python
 		"go.mod",           // Go
 		"requirements.txt", // Python
+		"composer.json",    // PHP
 		"src/",             // 源码目录
 		"main/",            // 主目录
 		"java/",            // Java代码
@@ -97,7 +98,7 @@ func (pd *ProjectDetector) isValidProject(projectPath string) bool {
 	}
 
 	// 检查是否包含源代码文件
-	codeExtensions := []string{".java", ".go", ".py", ".js", ".ts", ".c", ".cpp", ".h"}
+	codeExtensions := []string{".java", ".go", ".py", ".js", ".ts", ".php", ".kt", ".cs", ".c", ".cpp", ".cc", ".h", ".hpp"}
 	if pd.containsCodeFiles(projectPath, codeExtensions) {
 		return true
 	}
@@ -0,0 +1,775 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/python"
+)
+
+// PythonParser 实现 Python 语言的 AST 解析。
+// 模块的点分路径（如 app.views）记录为 Package，类的全限定名为 模块路径.类名；
+// 类中的函数记录为 Method，模块级和嵌套函数记录为 Function，装饰器记录为 Annotations
+type PythonParser struct{}
+
+// pythonScope 记录遍历到当前节点时所处的类和函数上下文，用于推断调用的接收者类型
+type pythonScope struct {
+	classID    string            // 所在类节点ID
+	className  string            // 所在类全限定名
+	superClass string            // 所在类的第一个基类全限定名（用于 super() 调用）
+	methodID   string            // 所在函数节点ID
+	fieldTypes map[string]string // 所在类的属性名 -> 类型全限定名
+	varTypes   map[string]string // 函数参数及局部变量名 -> 类型全限定名（未知时为空）
+}
+
+// pythonBuiltins 常用的内置函数和类型，调用时接收者类型记为 builtins
+var pythonBuiltins = map[string]bool{
+	"print": true, "eval": true, "exec": true, "compile": true, "open": true, "input": true,
+	"__import__": true, "getattr": true, "setattr": true, "hasattr": true, "delattr": true,
+	"len": true, "str": true, "int": true, "float": true, "bool": true, "bytes": true,
+	"list": true, "dict": true, "set": true, "tuple": true, "type": true, "object": true,
+	"isinstance": true, "issubclass": true, "super": true, "range": true, "enumerate": true,
+	"zip": true, "map": true, "filter": true, "sorted": true, "format": true, "repr": true,
+	"globals": true, "locals": true, "vars": true, "Exception": true, "ValueError": true,
+	"TypeError": true, "KeyError": true, "RuntimeError": true, "BaseException": true,
+}
+
+func (p *PythonParser) Language() string {
+	return "python"
+}
+
+func (p *PythonParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	code, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(python.GetLanguage())
+	tree := parser.Parse(nil, code)
+	defer tree.Close()
+
+	root := tree.RootNode()
+	moduleName := pythonModuleName(filePath)
+	imports := p.collectImports(root, code, filePath, moduleName)
+
+	var nodes []UniversalASTNode
+	p.traverseNode(root, filePath, code, moduleName, imports, &pythonScope{varTypes: map[string]string{}}, &nodes)
+	return nodes, nil
+}
+
+// pythonModuleName 按包含 __init__.py 的目录推断模块的点分路径，如 app/views.py -> app.views
+func pythonModuleName(filePath string) string {
+	var parts []string
+	if stem := strings.TrimSuffix(filepath.Base(filePath), ".py"); stem != "__init__" {
+		parts = append(parts, stem)
+	}
+	for dir := filepath.Dir(filePath); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "__init__.py")); err != nil {
+			break
+		}
+		parts = append([]string{filepath.Base(dir)}, parts...)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// collectImports 收集文件中的 import 语句（包括 try/if 中的条件导入），返回 本地名称 -> 限定名
+func (p *PythonParser) collectImports(root *sitter.Node, code []byte, filePath, moduleName string) map[string]string {
+	imports := make(map[string]string)
+
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "import_statement":
+			// import a.b 绑定的是 a，import a.b as c 绑定的是 a.b
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				name := node.NamedChild(i)
+				if name.Type() == "aliased_import" {
+					if module, alias := name.ChildByFieldName("name"), name.ChildByFieldName("alias"); module != nil && alias != nil {
+						imports[alias.Content(code)] = module.Content(code)
+					}
+				} else if name.Type() == "dotted_name" {
+					head := strings.Split(name.Content(code), ".")[0]
+					imports[head] = head
+				}
+			}
+			return
+		case "import_from_statement":
+			moduleNode := node.ChildByFieldName("module_name")
+			if moduleNode == nil {
+				return
+			}
+			base := moduleNode.Content(code)
+			if moduleNode.Type() == "relative_import" {
+				base = p.resolveRelativeImport(moduleNode, code, filePath, moduleName)
+			}
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				name := node.NamedChild(i)
+				if name == moduleNode {
+					continue
+				}
+				if name.Type() == "aliased_import" {
+					if imported, alias := name.ChildByFieldName("name"), name.ChildByFieldName("alias"); imported != nil && alias != nil {
+						imports[alias.Content(code)] = joinDotted(base, imported.Content(code))
+					}
+				} else if name.Type() == "dotted_name" {
+					imports[name.Content(code)] = joinDotted(base, name.Content(code))
+				}
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(root)
+	return imports
+}
+
+// resolveRelativeImport 将 from . import x / from ..pkg import x 中的相对模块转换为绝对路径
+func (p *PythonParser) resolveRelativeImport(node *sitter.Node, code []byte, filePath, moduleName string) string {
+	// __init__.py 自身就是包，普通模块所在的包为去掉最后一段后的路径
+	pkg := strings.Split(moduleName, ".")
+	if filepath.Base(filePath) != "__init__.py" {
+		pkg = pkg[:len(pkg)-1]
+	}
+	rest := ""
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() == "import_prefix" {
+			// 每多一个点向上一级
+			for up := len(strings.TrimSpace(child.Content(code))) - 1; up > 0 && len(pkg) > 0; up-- {
+				pkg = pkg[:len(pkg)-1]
+			}
+		} else if child.Type() == "dotted_name" {
+			rest = child.Content(code)
+		}
+	}
+	return joinDotted(strings.Join(pkg, "."), rest)
+}
+
+// joinDotted 用点连接两段名称，忽略空的一段
+func joinDotted(base, name string) string {
+	if base == "" {
+		return name
+	}
+	if name == "" {
+		return base
+	}
+	return base + "." + name
+}
+
+// resolveName 将源码中的点分名称解析为限定名：先查 import，其次是内置名称，最后视为当前模块中的定义
+func (p *PythonParser) resolveName(name, moduleName string, imports map[string]string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+	// 去掉泛型参数，如 List[str]
+	if idx := strings.Index(name, "["); idx != -1 {
+		name = name[:idx]
+	}
+	head, rest, _ := strings.Cut(name, ".")
+	if qualified, ok := imports[head]; ok {
+		return joinDotted(qualified, rest)
+	}
+	if pythonBuiltins[head] {
+		return "builtins." + name
+	}
+	return joinDotted(moduleName, name)
+}
+
+func (p *PythonParser) traverseNode(node *sitter.Node, filePath string, code []byte, moduleName string, imports map[string]string, scope *pythonScope, nodes *[]UniversalASTNode) {
+	// 子节点默认沿用当前作用域，进入类或函数时替换为新的作用域
+	childScope := scope
+
+	switch node.Type() {
+	case "class_definition":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		className := nameNode.Content(code)
+		id := fmt.Sprintf("%s:%s:%d", filePath, className, node.StartByte())
+		nodeName, fullName := className, joinDotted(moduleName, className)
+		if scope.classID != "" {
+			nodeName, fullName = nestedClassName(moduleName, scope.className, className), scope.className+"$"+className
+		}
+
+		classNode := UniversalASTNode{
+			ID:          id,
+			Language:    "python",
+			Type:        "Class",
+			Name:        nodeName,
+			File:        filePath,
+			Package:     moduleName,
+			StartLine:   int(node.StartPoint().Row),
+			EndLine:     int(node.EndPoint().Row),
+			Fields:      make([]FieldInfo, 0),
+			Metadata:    map[string]string{},
+			Annotations: p.collectDecorators(node, code, moduleName, imports),
+		}
+
+		// 基类列表，metaclass= 等关键字参数不是基类
+		var superFullNames []string
+		if bases := node.ChildByFieldName("superclasses"); bases != nil {
+			for i := 0; i < int(bases.NamedChildCount()); i++ {
+				base := bases.NamedChild(i)
+				if base.Type() != "identifier" && base.Type() != "attribute" {
+					continue
+				}
+				fq := p.resolveName(base.Content(code), moduleName, imports)
+				if fq == "builtins.object" {
+					continue
+				}
+				superFullNames = append(superFullNames, fq)
+				pkg, name := "", fq
+				if idx := strings.LastIndex(fq, "."); idx != -1 {
+					pkg, name = fq[:idx], fq[idx+1:]
+				}
+				classNode.SuperClasses = append(classNode.SuperClasses, ClassRef{Package: pkg, Name: name})
+			}
+		}
+		classNode.Metadata["superClasses"] = strings.Join(superFullNames, ",")
+
+		if body := node.ChildByFieldName("body"); body != nil {
+			p.collectClassFields(body, code, &classNode)
+		}
+		*nodes = append(*nodes, classNode)
+
+		childScope = &pythonScope{
+			classID:    id,
+			className:  fullName,
+			fieldTypes: make(map[string]string),
+			varTypes:   make(map[string]string),
+		}
+		if len(superFullNames) > 0 {
+			childScope.superClass = superFullNames[0]
+		}
+		for _, field := range classNode.Fields {
+			fieldType := p.resolveName(field.Type, moduleName, imports)
+			if field.Type == "" {
+				fieldType = ""
+			}
+			childScope.fieldTypes[field.Name] = fieldType
+			*nodes = append(*nodes, UniversalASTNode{
+				ID:        fmt.Sprintf("%s:%s.%s:%d", filePath, className, field.Name, field.StartLine),
+				Language:  "python",
+				Type:      "Field",
+				Name:      field.Name,
+				File:      filePath,
+				Package:   moduleName,
+				StartLine: field.StartLine,
+				EndLine:   field.EndLine,
+				Metadata: map[string]string{
+					"fieldType": field.Type,
+					"fullType":  fieldType,
+					"classID":   id,
+					"className": fullName,
+				},
+			})
+		}
+	case "function_definition":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		funcName := nameNode.Content(code)
+		id := fmt.Sprintf("%s:%s:%d", filePath, funcName, node.StartByte())
+		decorators := p.collectDecorators(node, code, moduleName, imports)
+		// 直接定义在类体中的函数是方法，其余为函数
+		isMethod := scope.classID != "" && scope.methodID == ""
+
+		childScope = &pythonScope{
+			classID:    scope.classID,
+			className:  scope.className,
+			superClass: scope.superClass,
+			methodID:   id,
+			fieldTypes: scope.fieldTypes,
+			varTypes:   make(map[string]string),
+		}
+		// 闭包可以访问外层函数的变量
+		for name, varType := range scope.varTypes {
+			childScope.varTypes[name] = varType
+		}
+
+		var modifiers []string
+		if node.Child(0) != nil && node.Child(0).Type() == "async" {
+			modifiers = append(modifiers, "async")
+		}
+		_, isStatic := FindAnnotation(decorators, "staticmethod")
+		if isStatic {
+			modifiers = append(modifiers, "static")
+		}
+
+		var params []ParamInfo
+		var methodParams []string
+		if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+			for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+				param, signature, ok := p.paramInfo(paramsNode.NamedChild(i), code)
+				if !ok {
+					continue
+				}
+				// 实例方法和类方法的第一个参数是 self / cls，调用时不传入
+				if isMethod && !isStatic && i == 0 {
+					childScope.varTypes[param.Name] = scope.className
+					continue
+				}
+				params = append(params, param)
+				methodParams = append(methodParams, signature)
+				childScope.varTypes[param.Name] = ""
+				if param.Type != "" {
+					childScope.varTypes[param.Name] = p.resolveName(param.Type, moduleName, imports)
+				}
+			}
+		}
+
+		returnType := ""
+		if returnNode := node.ChildByFieldName("return_type"); returnNode != nil {
+			returnType = returnNode.Content(code)
+		}
+		returnFullType := ""
+		if returnType != "" && returnType != "None" {
+			returnFullType = p.resolveName(returnType, moduleName, imports)
+		}
+
+		nodeType, owner := "Function", moduleName
+		if isMethod {
+			nodeType, owner = "Method", scope.className
+		}
+		*nodes = append(*nodes, UniversalASTNode{
+			ID:           id,
+			Language:     "python",
+			Type:         nodeType,
+			Name:         funcName,
+			File:         filePath,
+			Package:      moduleName,
+			StartLine:    int(node.StartPoint().Row),
+			EndLine:      int(node.EndPoint().Row),
+			MethodParams: methodParams,
+			Params:       params,
+			Annotations:  decorators,
+			Modifiers:    modifiers,
+			Metadata: map[string]string{
+				"returnType":     returnType,
+				"returnFullType": returnFullType,
+				"classID":        scope.classID,
+				"className":      owner,
+			},
+		})
+	case "assignment":
+		// 记录局部变量的类型：x: Foo = ...、x = Foo(...)、x = y
+		left := node.ChildByFieldName("left")
+		if left == nil || left.Type() != "identifier" || scope.methodID == "" {
+			break
+		}
+		varType := ""
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			varType = p.resolveName(typeNode.Content(code), moduleName, imports)
+		} else if right := node.ChildByFieldName("right"); right != nil {
+			varType = p.expressionType(right, code, moduleName, imports, scope)
+		}
+		scope.varTypes[left.Content(code)] = varType
+	case "call":
+		if callNode, ok := p.callNode(node, filePath, code, moduleName, imports, scope); ok {
+			*nodes = append(*nodes, callNode)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		// 装饰器已记录为注解，不再作为调用处理
+		if child == nil || child.Type() == "decorator" {
+			continue
+		}
+		p.traverseNode(child, filePath, code, moduleName, imports, childScope, nodes)
+	}
+}
+
+// callNode 为函数或方法调用创建 MethodCall 节点：
+// 首字母大写的名称按构造调用处理（<init>），obj.m() 按接收者推断类型，模块函数的接收者类型为模块路径
+func (p *PythonParser) callNode(node *sitter.Node, filePath string, code []byte, moduleName string, imports map[string]string, scope *pythonScope) (UniversalASTNode, bool) {
+	function := node.ChildByFieldName("function")
+	if function == nil {
+		return UniversalASTNode{}, false
+	}
+
+	name, receiver, receiverType, receiverCallID := "", "", "", ""
+	switch function.Type() {
+	case "identifier":
+		name = function.Content(code)
+		if name == "super" {
+			// super() 只作为接收者使用，super().m() 的接收者类型为父类
+			return UniversalASTNode{}, false
+		}
+		if _, isVar := scope.varTypes[name]; isVar {
+			// 调用保存在变量中的函数，无法确定目标
+			break
+		}
+		qualified := p.resolveName(name, moduleName, imports)
+		if isCapitalizedName(qualified) {
+			name, receiverType = "<init>", qualified
+		} else if idx := strings.LastIndex(qualified, "."); idx != -1 {
+			receiverType = qualified[:idx]
+		}
+	case "attribute":
+		attr, object := function.ChildByFieldName("attribute"), function.ChildByFieldName("object")
+		if attr == nil || object == nil {
+			return UniversalASTNode{}, false
+		}
+		name, receiver = attr.Content(code), object.Content(code)
+		receiverType = p.expressionType(object, code, moduleName, imports, scope)
+		// models.CharField(...) 是对导入模块中类的构造调用
+		if receiverType != "" && isDottedName(function) && isCapitalizedName(name) {
+			name, receiverType = "<init>", receiverType+"."+name
+		}
+		// 链式调用的接收者类型在建立索引后按前一个调用的返回类型推断
+		if receiverType == "" && object.Type() == "call" {
+			if inner := object.ChildByFieldName("function"); inner != nil {
+				innerName := inner.Content(code)
+				if inner.Type() == "attribute" {
+					if innerAttr := inner.ChildByFieldName("attribute"); innerAttr != nil {
+						innerName = innerAttr.Content(code)
+					}
+				}
+				receiverCallID = callNodeID(filePath, innerName, int(object.StartByte()), int(object.EndByte()))
+			}
+		}
+	default:
+		return UniversalASTNode{}, false
+	}
+
+	var arguments []ExprInfo
+	if argsNode := node.ChildByFieldName("arguments"); argsNode != nil {
+		for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+			if arg := argsNode.NamedChild(i); arg.Type() != "comment" {
+				arguments = append(arguments, p.exprInfo(arg, code))
+			}
+		}
+	}
+
+	callNode := UniversalASTNode{
+		ID:        callNodeID(filePath, name, int(node.StartByte()), int(node.EndByte())),
+		Language:  "python",
+		Type:      "MethodCall",
+		Name:      name,
+		Arguments: arguments,
+		File:      filePath,
+		Package:   moduleName,
+		StartLine: int(node.StartPoint().Row),
+		EndLine:   int(node.EndPoint().Row),
+		Metadata: map[string]string{
+			"receiver":     receiver,
+			"receiverType": receiverType,
+			"argCount":     strconv.Itoa(len(arguments)),
+			"callerID":     scope.methodID,
+			"callerClass":  scope.className,
+		},
+	}
+	if receiverCallID != "" {
+		callNode.Metadata["receiverCallID"] = receiverCallID
+	}
+	return callNode, true
+}
+
+// expressionType 推断表达式的类型：self/cls、已知类型的变量和属性、构造调用、super()，
+// 以及导入的模块（如 os.path 的类型为 os.path）。无法推断时返回空
+func (p *PythonParser) expressionType(expr *sitter.Node, code []byte, moduleName string, imports map[string]string, scope *pythonScope) string {
+	switch expr.Type() {
+	case "identifier":
+		name := expr.Content(code)
+		if varType, ok := scope.varTypes[name]; ok {
+			return varType
+		}
+		if qualified, ok := imports[name]; ok {
+			return qualified
+		}
+		if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+			return p.resolveName(name, moduleName, imports)
+		}
+	case "attribute":
+		object, attr := expr.ChildByFieldName("object"), expr.ChildByFieldName("attribute")
+		if object == nil || attr == nil {
+			return ""
+		}
+		// self.x 取类属性的类型
+		if object.Type() == "identifier" && scope.varTypes[object.Content(code)] == scope.className && scope.className != "" {
+			return scope.fieldTypes[attr.Content(code)]
+		}
+		// 导入的模块中的名称，如 os.path
+		head := strings.Split(expr.Content(code), ".")[0]
+		if _, isVar := scope.varTypes[head]; !isVar {
+			if _, ok := imports[head]; ok && isDottedName(expr) {
+				return p.resolveName(expr.Content(code), moduleName, imports)
+			}
+		}
+	case "call":
+		function := expr.ChildByFieldName("function")
+		if function == nil {
+			return ""
+		}
+		if function.Type() == "identifier" && function.Content(code) == "super" {
+			return scope.superClass
+		}
+		if function.Type() == "identifier" || function.Type() == "attribute" {
+			if _, isVar := scope.varTypes[function.Content(code)]; isVar || !isDottedName(function) {
+				return ""
+			}
+			if qualified := p.resolveName(function.Content(code), moduleName, imports); isCapitalizedName(qualified) {
+				return qualified
+			}
+		}
+	case "string", "concatenated_string":
+		return "builtins.str"
+	case "parenthesized_expression":
+		if expr.NamedChildCount() > 0 {
+			return p.expressionType(expr.NamedChild(0), code, moduleName, imports, scope)
+		}
+	}
+	return ""
+}
+
+// isDottedName 判断表达式是否只由标识符和属性访问组成，如 a.b.c
+func isDottedName(expr *sitter.Node) bool {
+	switch expr.Type() {
+	case "identifier":
+		return true
+	case "attribute":
+		object := expr.ChildByFieldName("object")
+		return object != nil && isDottedName(object)
+	}
+	return false
+}
+
+// paramInfo 解析一个形参，返回参数信息和用于签名匹配的类型：
+// 未标注类型的参数类型为 Any，*args / **kwargs 追加 ...，默认值不计入签名
+func (p *PythonParser) paramInfo(node *sitter.Node, code []byte) (ParamInfo, string, bool) {
+	var param ParamInfo
+	switch node.Type() {
+	case "identifier":
+		param.Name = node.Content(code)
+	case "typed_parameter":
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			switch child.Type() {
+			case "identifier":
+				param.Name = child.Content(code)
+			case "list_splat_pattern", "dictionary_splat_pattern":
+				param.Name = strings.TrimLeft(child.Content(code), "*")
+				param.Variadic = true
+			}
+		}
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			param.Type = typeNode.Content(code)
+		}
+	case "default_parameter", "typed_default_parameter":
+		if name := node.ChildByFieldName("name"); name != nil {
+			param.Name = name.Content(code)
+		}
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			param.Type = typeNode.Content(code)
+		}
+		param.Optional = true
+	case "list_splat_pattern", "dictionary_splat_pattern":
+		param.Name = strings.TrimLeft(node.Content(code), "*")
+		param.Variadic = true
+	default:
+		// 仅限关键字 / 仅限位置参数的分隔符 * 和 /
+		return param, "", false
+	}
+	if param.Name == "" {
+		return param, "", false
+	}
+	signature := param.Type
+	if signature == "" {
+		signature = "Any"
+	}
+	if param.Variadic {
+		signature += "..."
+	}
+	return param, signature, true
+}
+
+// collectClassFields 收集类属性（类体中的赋值）和实例属性（方法中的 self.x = ...）
+func (p *PythonParser) collectClassFields(body *sitter.Node, code []byte, classNode *UniversalASTNode) {
+	seen := make(map[string]bool)
+	addField := func(nameNode, typeNode, valueNode *sitter.Node, modifiers []string) {
+		name := nameNode.Content(code)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		field := FieldInfo{
+			Name:      name,
+			StartLine: int(nameNode.StartPoint().Row),
+			EndLine:   int(nameNode.EndPoint().Row),
+			Modifiers: modifiers,
+			Metadata:  map[string]string{},
+		}
+		// 未标注类型时，Django / SQLAlchemy 的 models.CharField(...) 这类初始化调用可以说明字段含义
+		if typeNode != nil {
+			field.Type = typeNode.Content(code)
+		} else if valueNode != nil && valueNode.Type() == "call" {
+			if function := valueNode.ChildByFieldName("function"); function != nil && isDottedName(function) {
+				field.Type = function.Content(code)
+			}
+		}
+		classNode.Fields = append(classNode.Fields, field)
+	}
+
+	// 实例属性只在方法体中查找，不进入嵌套的类和函数
+	var collectSelfAttrs func(node *sitter.Node, self string)
+	collectSelfAttrs = func(node *sitter.Node, self string) {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			switch child.Type() {
+			case "class_definition", "function_definition", "decorated_definition", "lambda":
+				continue
+			case "assignment":
+				if left := child.ChildByFieldName("left"); left != nil && left.Type() == "attribute" {
+					object, attr := left.ChildByFieldName("object"), left.ChildByFieldName("attribute")
+					if object != nil && attr != nil && object.Content(code) == self {
+						addField(attr, child.ChildByFieldName("type"), child.ChildByFieldName("right"), nil)
+					}
+				}
+			}
+			collectSelfAttrs(child, self)
+		}
+	}
+
+	var methods []*sitter.Node
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		stmt := body.NamedChild(i)
+		switch stmt.Type() {
+		case "expression_statement":
+			if stmt.NamedChildCount() == 0 || stmt.NamedChild(0).Type() != "assignment" {
+				continue
+			}
+			assignment := stmt.NamedChild(0)
+			if left := assignment.ChildByFieldName("left"); left != nil && left.Type() == "identifier" {
+				addField(left, assignment.ChildByFieldName("type"), assignment.ChildByFieldName("right"), []string{"static"})
+			}
+		case "function_definition":
+			methods = append(methods, stmt)
+		case "decorated_definition":
+			if definition := stmt.ChildByFieldName("definition"); definition != nil && definition.Type() == "function_definition" {
+				methods = append(methods, definition)
+			}
+		}
+	}
+	for _, method := range methods {
+		params := method.ChildByFieldName("parameters")
+		body := method.ChildByFieldName("body")
+		if params == nil || body == nil || params.NamedChildCount() == 0 || params.NamedChild(0).Type() != "identifier" {
+			continue
+		}
+		collectSelfAttrs(body, params.NamedChild(0).Content(code))
+	}
+}
+
+// collectDecorators 将定义上的装饰器记录为注解：Name 为最后一段名称（如 route），FullName 为解析后的限定名，
+// 调用参数中第一个位置参数记为 value，关键字参数按参数名记录
+func (p *PythonParser) collectDecorators(definition *sitter.Node, code []byte, moduleName string, imports map[string]string) []AnnotationInfo {
+	parent := definition.Parent()
+	if parent == nil || parent.Type() != "decorated_definition" {
+		return nil
+	}
+	var annotations []AnnotationInfo
+	for i := 0; i < int(parent.NamedChildCount()); i++ {
+		decorator := parent.NamedChild(i)
+		if decorator.Type() != "decorator" || decorator.NamedChildCount() == 0 {
+			continue
+		}
+		expr := decorator.NamedChild(0)
+		var argsNode *sitter.Node
+		if expr.Type() == "call" {
+			argsNode = expr.ChildByFieldName("arguments")
+			expr = expr.ChildByFieldName("function")
+		}
+		if expr == nil {
+			continue
+		}
+		dotted := expr.Content(code)
+		annotation := AnnotationInfo{
+			Name:      dotted[strings.LastIndex(dotted, ".")+1:],
+			FullName:  p.resolveName(dotted, moduleName, imports),
+			Arguments: map[string]string{},
+		}
+		if argsNode != nil {
+			for j := 0; j < int(argsNode.NamedChildCount()); j++ {
+				arg := argsNode.NamedChild(j)
+				if arg.Type() == "keyword_argument" {
+					if key, value := arg.ChildByFieldName("name"), arg.ChildByFieldName("value"); key != nil && value != nil {
+						annotation.Arguments[key.Content(code)] = p.literalValue(value, code)
+					}
+				} else if _, exists := annotation.Arguments["value"]; !exists && arg.Type() != "comment" {
+					annotation.Arguments["value"] = p.literalValue(arg, code)
+				}
+			}
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
+
+// literalValue 提取字面量的值：字符串去掉引号，列表和元组以逗号连接各元素
+func (p *PythonParser) literalValue(node *sitter.Node, code []byte) string {
+	switch node.Type() {
+	case "string":
+		var content strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); child.Type() == "string_content" {
+				content.WriteString(child.Content(code))
+			}
+		}
+		return content.String()
+	case "list", "tuple", "set":
+		var values []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); child.Type() != "comment" {
+				values = append(values, p.literalValue(child, code))
+			}
+		}
+		return strings.Join(values, ",")
+	}
+	return node.Content(code)
+}
+
+// exprInfo 记录表达式原文及其中引用的变量名，self.x 形式的属性保留 self. 前缀
+func (p *PythonParser) exprInfo(exprNode *sitter.Node, code []byte) ExprInfo {
+	info := ExprInfo{Text: exprNode.Content(code)}
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		switch node.Type() {
+		case "identifier":
+			info.Uses = appendUnique(info.Uses, node.Content(code))
+			return
+		case "attribute":
+			if object := node.ChildByFieldName("object"); object != nil {
+				if object.Type() == "identifier" && object.Content(code) == "self" {
+					info.Uses = appendUnique(info.Uses, node.Content(code))
+					return
+				}
+				walk(object)
+			}
+			return
+		case "keyword_argument":
+			if value := node.ChildByFieldName("value"); value != nil {
+				walk(value)
+			}
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(exprNode)
+	return info
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPythonParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantParams []string // 签名中的参数类型
+		wantInfo   []ParamInfo
+	}{
+		{
+			name:       "无类型标注",
+			source:     "def f(a, b):\n    pass\n",
+			wantParams: []string{"Any", "Any"},
+			wantInfo:   []ParamInfo{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			name:       "默认参数不计入签名",
+			source:     "def f(a, b=1, c: int = 2):\n    pass\n",
+			wantParams: []string{"Any", "Any", "int"},
+			wantInfo:   []ParamInfo{{Name: "a"}, {Name: "b", Optional: true}, {Name: "c", Type: "int", Optional: true}},
+		},
+		{
+			name:       "可变参数",
+			source:     "def f(a, *args: str, **kwargs):\n    pass\n",
+			wantParams: []string{"Any", "str...", "Any..."},
+			wantInfo:   []ParamInfo{{Name: "a"}, {Name: "args", Type: "str", Variadic: true}, {Name: "kwargs", Variadic: true}},
+		},
+		{
+			name:       "仅限关键字参数",
+			source:     "def f(a, *, key=None):\n    pass\n",
+			wantParams: []string{"Any", "Any"},
+			wantInfo:   []ParamInfo{{Name: "a"}, {Name: "key", Optional: true}},
+		},
+		{
+			name:       "方法忽略 self",
+			source:     "class A:\n    def f(self, a: str):\n        pass\n",
+			wantParams: []string{"str"},
+			wantInfo:   []ParamInfo{{Name: "a", Type: "str"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, map[string]string{"mod.py": tt.source}, &PythonParser{})
+			var found bool
+			for _, node := range index.index {
+				if node.Name != "f" || (node.Type != "Function" && node.Type != "Method") {
+					continue
+				}
+				found = true
+				if !reflect.DeepEqual(node.MethodParams, tt.wantParams) {
+					t.Errorf("MethodParams = %q, want %q", node.MethodParams, tt.wantParams)
+				}
+				for i := range node.Params {
+					node.Params[i].Annotations = nil
+				}
+				if !reflect.DeepEqual(node.Params, tt.wantInfo) {
+					t.Errorf("Params = %+v, want %+v", node.Params, tt.wantInfo)
+				}
+			}
+			if !found {
+				t.Fatalf("未找到函数 f")
+			}
+		})
+	}
+}
+
+func TestPythonCallGraph(t *testing.T) {
+	repoSource := `class Repo:
+    def __init__(self, url, timeout=10):
+        self.url = url
+
+    def find(self, key, limit=None):
+        return key
+`
+	tests := []struct {
+		name   string
+		files  map[string]string
+		caller string // 调用方 "模块或类.函数名"
+		want   []string
+	}{
+		{
+			name: "省略默认参数的构造与方法调用",
+			files: map[string]string{
+				"app/__init__.py": "",
+				"app/repo.py":     repoSource,
+				"app/views.py": `from app.repo import Repo
+
+def show(key):
+    repo = Repo("db")
+    return repo.find(key)
+`,
+			},
+			caller: "app.views.show",
+			want:   []string{"app.repo.Repo.__init__/2", "app.repo.Repo.find/2"},
+		},
+		{
+			name: "实参超过形参个数",
+			files: map[string]string{
+				"app/__init__.py": "",
+				"app/repo.py":     repoSource,
+				"app/views.py": `from app.repo import Repo
+
+def show(key):
+    repo = Repo("db")
+    return repo.find(key, 1, 2)
+`,
+			},
+			caller: "app.views.show",
+			want:   []string{"app.repo.Repo.__init__/2"},
+		},
+		{
+			name: "模块函数与可变参数",
+			files: map[string]string{
+				"app/__init__.py": "",
+				"app/util.py": `def log(fmt, *args, **kwargs):
+    pass
+`,
+				"app/views.py": `from app import util
+
+def show(key):
+    util.log("%s %s", key, 1, level="info")
+`,
+			},
+			caller: "app.views.show",
+			want:   []string{"app.util.log/3"},
+		},
+		{
+			name: "self 方法调用",
+			files: map[string]string{
+				"app/__init__.py": "",
+				"app/ctl.py": `class Ctl:
+    def show(self, key):
+        return self.check(key)
+
+    def check(self, value, strict=False):
+        return value
+`,
+			},
+			caller: "app.ctl.Ctl.show",
+			want:   []string{"app.ctl.Ctl.check/2"},
+		},
+		{
+			name: "嵌套类",
+			files: map[string]string{
+				"app/__init__.py": "",
+				"app/ctl.py": `class Outer:
+    class Inner:
+        def run(self, cmd):
+            pass
+
+
+def show(cmd):
+    runner = Outer.Inner()
+    runner.run(cmd)
+`,
+			},
+			caller: "app.ctl.show",
+			want:   []string{"app.ctl.Outer$Inner.run/1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexSources(t, tt.files, &PythonParser{})
+			dot := strings.LastIndex(tt.caller, ".")
+			owner, function := tt.caller[:dot], tt.caller[dot+1:]
+			if got := callTargets(index, owner, function); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s 调用 = %v, want %v", tt.caller, got, tt.want)
+			}
+		})
+	}
+}
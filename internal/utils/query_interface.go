@@ -55,6 +55,26 @@ func (e *QueryEngine) FindByLanguage(language string) []UniversalASTNode {
 	})
 }
 
+// FindByModule 按所属模块查找节点，匹配规则见 MatchModule
+func (e *QueryEngine) FindByModule(module string) []UniversalASTNode {
+	return e.index.FindNodes(func(node UniversalASTNode) bool {
+		return MatchModule(node, module)
+	})
+}
+
+// InModule 返回只包含指定模块节点的查询引擎，module 为空时返回自身
+func (e *QueryEngine) InModule(module string) *QueryEngine {
+	if module == "" {
+		return e
+	}
+	index := NewASTIndex()
+	index.gradleProjects = e.index.gradleProjects
+	for _, node := range e.FindByModule(module) {
+		index.index[node.ID] = node
+	}
+	return NewQueryEngine(index)
+}
+
 // GetCodeSnippet 获取代码片段
 func (e *QueryEngine) GetCodeSnippet(node UniversalASTNode, contextLines int) (string, error) {
 	// 打印调试信息
@@ -105,12 +125,48 @@ func (e *QueryEngine) GetCodeSnippet(node UniversalASTNode, contextLines int) (s
 	return strings.Join(lines, "\n"), nil
 }
 
-// VisualizeCallGraph 可视化调用关系
-func (e *QueryEngine) VisualizeCallGraph(startNode UniversalASTNode, depth int) string {
-	var builder strings.Builder
-	visited := make(map[string]bool)
-	e.traverseCallGraph(&builder, startNode, 0, depth, visited)
-	return builder.String()
+// VisualizeCallGraph 可视化调用关系：从起始方法出发沿 calls 关系展开 depth 层，
+// format 支持 text（缩进文本）、mermaid 与 dot（Graphviz）
+func (e *QueryEngine) VisualizeCallGraph(startNode UniversalASTNode, depth int, format string) string {
+	var order []UniversalASTNode // 按访问顺序记录节点，保证输出稳定
+	var edges [][2]string
+	visited := map[string]bool{startNode.ID: true}
+	order = append(order, startNode)
+
+	// 按层广度优先展开
+	frontier := []UniversalASTNode{startNode}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []UniversalASTNode
+		for _, node := range frontier {
+			for _, rel := range node.Relations {
+				if rel.Type != "calls" {
+					continue
+				}
+				callee, ok := e.index.GetNode(rel.TargetID)
+				if !ok {
+					continue
+				}
+				edges = append(edges, [2]string{node.ID, callee.ID})
+				if !visited[callee.ID] {
+					visited[callee.ID] = true
+					order = append(order, callee)
+					next = append(next, callee)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	switch format {
+	case "mermaid":
+		return renderMermaid(order, edges)
+	case "dot":
+		return renderDot(order, edges)
+	default:
+		var builder strings.Builder
+		e.traverseCallGraph(&builder, startNode, 0, depth, make(map[string]bool))
+		return builder.String()
+	}
 }
 
 func (e *QueryEngine) traverseCallGraph(builder *strings.Builder, node UniversalASTNode, level, maxDepth int, visited map[string]bool) {
@@ -120,17 +176,58 @@ func (e *QueryEngine) traverseCallGraph(builder *strings.Builder, node Universal
 	visited[node.ID] = true
 
 	indent := strings.Repeat("  ", level)
-	builder.WriteString(fmt.Sprintf("%s[%s] %s.%s (%s:%d)\n",
-		indent, node.Language, node.Package, node.Name,
-		filepath.Base(node.File), node.StartLine))
-
-	// 查找调用关系 (简化实现)
-	called := e.index.FindNodes(func(n UniversalASTNode) bool {
-		// 实际项目中需要更精确的关系匹配
-		return n.Type == "FunctionCall" && n.Name == node.Name && n.ID != node.ID
-	})
+	builder.WriteString(fmt.Sprintf("%s[%s] %s (%s:%d)\n",
+		indent, node.Language, callGraphLabel(node),
+		filepath.Base(node.File), node.StartLine+1))
+
+	for _, rel := range node.Relations {
+		if rel.Type != "calls" {
+			continue
+		}
+		if callee, ok := e.index.GetNode(rel.TargetID); ok {
+			e.traverseCallGraph(builder, callee, level+1, maxDepth, visited)
+		}
+	}
+}
+
+// callGraphLabel 调用图中方法节点的显示名称：简单类名.方法名(参数类型)
+func callGraphLabel(node UniversalASTNode) string {
+	className := ShortClassName(node.Metadata["className"])
+	if className == "" {
+		className = node.Package
+	}
+	return fmt.Sprintf("%s.%s(%s)", className, node.Name, strings.Join(node.MethodParams, ", "))
+}
+
+// renderMermaid 将调用图输出为 Mermaid flowchart
+func renderMermaid(nodes []UniversalASTNode, edges [][2]string) string {
+	ids := make(map[string]string)
+	var builder strings.Builder
+	builder.WriteString("flowchart TD\n")
+	for i, node := range nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		label := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(callGraphLabel(node))
+		builder.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", ids[node.ID], label))
+	}
+	for _, edge := range edges {
+		builder.WriteString(fmt.Sprintf("    %s --> %s\n", ids[edge[0]], ids[edge[1]]))
+	}
+	return builder.String()
+}
 
-	for _, call := range called {
-		e.traverseCallGraph(builder, call, level+1, maxDepth, visited)
+// renderDot 将调用图输出为 Graphviz DOT
+func renderDot(nodes []UniversalASTNode, edges [][2]string) string {
+	ids := make(map[string]string)
+	var builder strings.Builder
+	builder.WriteString("digraph CallGraph {\n    rankdir=LR;\n    node [shape=box];\n")
+	for i, node := range nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		label := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(callGraphLabel(node))
+		builder.WriteString(fmt.Sprintf("    %s [label=\"%s\\n%s:%d\"];\n", ids[node.ID], label, filepath.Base(node.File), node.StartLine+1))
 	}
+	for _, edge := range edges {
+		builder.WriteString(fmt.Sprintf("    %s -> %s;\n", ids[edge[0]], ids[edge[1]]))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
 }
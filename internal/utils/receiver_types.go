@@ -0,0 +1,356 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxReceiverChain 链式调用接收者类型推断的最大轮数，a().b().c() 每一轮向后推进一层
+const maxReceiverChain = 10
+
+// knownSuperTypes 项目外常用类型的直接父类型，用于按父类型查询调用（如 HttpServletRequest 的调用按 ServletRequest 查询）
+var knownSuperTypes = map[string][]string{
+	"javax.servlet.http.HttpServletRequest":         {"javax.servlet.ServletRequest"},
+	"javax.servlet.http.HttpServletResponse":        {"javax.servlet.ServletResponse"},
+	"javax.servlet.ServletRequestWrapper":           {"javax.servlet.ServletRequest"},
+	"javax.servlet.ServletResponseWrapper":          {"javax.servlet.ServletResponse"},
+	"javax.servlet.http.HttpServletRequestWrapper":  {"javax.servlet.ServletRequestWrapper", "javax.servlet.http.HttpServletRequest"},
+	"javax.servlet.http.HttpServletResponseWrapper": {"javax.servlet.ServletResponseWrapper", "javax.servlet.http.HttpServletResponse"},
+	"javax.servlet.http.HttpServlet":                {"javax.servlet.GenericServlet"},
+	"javax.servlet.GenericServlet":                  {"javax.servlet.Servlet"},
+	"javax.servlet.ServletInputStream":              {"java.io.InputStream"},
+	"javax.servlet.ServletOutputStream":             {"java.io.OutputStream"},
+	"org.apache.sling.api.SlingHttpServletRequest":  {"javax.servlet.http.HttpServletRequest"},
+	"org.apache.sling.api.SlingHttpServletResponse": {"javax.servlet.http.HttpServletResponse"},
+	"java.sql.PreparedStatement":                    {"java.sql.Statement"},
+	"java.sql.CallableStatement":                    {"java.sql.PreparedStatement"},
+	"java.io.FileInputStream":                       {"java.io.InputStream"},
+	"java.io.ObjectInputStream":                     {"java.io.InputStream"},
+	"java.io.FileOutputStream":                      {"java.io.OutputStream"},
+	"java.io.BufferedReader":                        {"java.io.Reader"},
+	"java.io.InputStreamReader":                     {"java.io.Reader"},
+	"java.io.FileReader":                            {"java.io.InputStreamReader"},
+	"java.io.PrintWriter":                           {"java.io.Writer"},
+	"java.io.FileWriter":                            {"java.io.Writer"},
+	"java.net.HttpURLConnection":                    {"java.net.URLConnection"},
+	"java.net.URLClassLoader":                       {"java.lang.ClassLoader"},
+	"javax.naming.InitialContext":                   {"javax.naming.Context"},
+	"javax.naming.directory.InitialDirContext":      {"javax.naming.InitialContext", "javax.naming.directory.DirContext"},
+	"javax.naming.directory.DirContext":             {"javax.naming.Context"},
+	"java.util.ArrayList":                           {"java.util.List"},
+	"java.util.LinkedList":                          {"java.util.List"},
+	"java.util.HashMap":                             {"java.util.Map"},
+	"java.util.LinkedHashMap":                       {"java.util.HashMap"},
+	"java.util.HashSet":                             {"java.util.Set"},
+	"java.util.Properties":                          {"java.util.Map"},
+}
+
+// knownReturnTypes 项目外常用方法的返回类型（类名#方法名），用于推断 Runtime.getRuntime().exec() 这类链式调用的接收者
+var knownReturnTypes = map[string]string{
+	"java.lang.Object#getClass":                                        "java.lang.Class",
+	"java.lang.Object#toString":                                        "java.lang.String",
+	"java.lang.Runtime#getRuntime":                                     "java.lang.Runtime",
+	"java.lang.Runtime#exec":                                           "java.lang.Process",
+	"java.lang.ProcessBuilder#start":                                   "java.lang.Process",
+	"java.lang.ProcessBuilder#command":                                 "java.lang.ProcessBuilder",
+	"java.lang.Process#getInputStream":                                 "java.io.InputStream",
+	"java.lang.Process#getErrorStream":                                 "java.io.InputStream",
+	"java.lang.Class#forName":                                          "java.lang.Class",
+	"java.lang.Class#getMethod":                                        "java.lang.reflect.Method",
+	"java.lang.Class#getDeclaredMethod":                                "java.lang.reflect.Method",
+	"java.lang.Class#getConstructor":                                   "java.lang.reflect.Constructor",
+	"java.lang.Class#getDeclaredConstructor":                           "java.lang.reflect.Constructor",
+	"java.lang.Class#getField":                                         "java.lang.reflect.Field",
+	"java.lang.Class#getDeclaredField":                                 "java.lang.reflect.Field",
+	"java.lang.Class#getClassLoader":                                   "java.lang.ClassLoader",
+	"java.lang.ClassLoader#loadClass":                                  "java.lang.Class",
+	"java.lang.Thread#currentThread":                                   "java.lang.Thread",
+	"java.lang.Thread#getContextClassLoader":                           "java.lang.ClassLoader",
+	"java.lang.String#trim":                                            "java.lang.String",
+	"java.lang.String#strip":                                           "java.lang.String",
+	"java.lang.String#toLowerCase":                                     "java.lang.String",
+	"java.lang.String#toUpperCase":                                     "java.lang.String",
+	"java.lang.String#substring":                                       "java.lang.String",
+	"java.lang.String#replace":                                         "java.lang.String",
+	"java.lang.String#replaceAll":                                      "java.lang.String",
+	"java.lang.String#concat":                                          "java.lang.String",
+	"java.lang.String#format":                                          "java.lang.String",
+	"java.lang.String#valueOf":                                         "java.lang.String",
+	"java.lang.StringBuilder#append":                                   "java.lang.StringBuilder",
+	"java.lang.StringBuffer#append":                                    "java.lang.StringBuffer",
+	"javax.servlet.ServletRequest#getParameter":                        "java.lang.String",
+	"javax.servlet.ServletRequest#getInputStream":                      "javax.servlet.ServletInputStream",
+	"javax.servlet.ServletRequest#getReader":                           "java.io.BufferedReader",
+	"javax.servlet.ServletRequest#getRequestDispatcher":                "javax.servlet.RequestDispatcher",
+	"javax.servlet.ServletRequest#getServletContext":                   "javax.servlet.ServletContext",
+	"javax.servlet.http.HttpServletRequest#getHeader":                  "java.lang.String",
+	"javax.servlet.http.HttpServletRequest#getQueryString":             "java.lang.String",
+	"javax.servlet.http.HttpServletRequest#getRequestURI":              "java.lang.String",
+	"javax.servlet.http.HttpServletRequest#getSession":                 "javax.servlet.http.HttpSession",
+	"javax.servlet.http.HttpSession#getServletContext":                 "javax.servlet.ServletContext",
+	"javax.servlet.ServletContext#getRealPath":                         "java.lang.String",
+	"javax.servlet.ServletContext#getRequestDispatcher":                "javax.servlet.RequestDispatcher",
+	"javax.servlet.ServletResponse#getWriter":                          "java.io.PrintWriter",
+	"javax.servlet.ServletResponse#getOutputStream":                    "javax.servlet.ServletOutputStream",
+	"javax.servlet.http.Cookie#getValue":                               "java.lang.String",
+	"java.sql.DriverManager#getConnection":                             "java.sql.Connection",
+	"javax.sql.DataSource#getConnection":                               "java.sql.Connection",
+	"java.sql.Connection#createStatement":                              "java.sql.Statement",
+	"java.sql.Connection#prepareStatement":                             "java.sql.PreparedStatement",
+	"java.sql.Connection#prepareCall":                                  "java.sql.CallableStatement",
+	"java.sql.Statement#executeQuery":                                  "java.sql.ResultSet",
+	"javax.xml.parsers.DocumentBuilderFactory#newInstance":             "javax.xml.parsers.DocumentBuilderFactory",
+	"javax.xml.parsers.DocumentBuilderFactory#newDocumentBuilder":      "javax.xml.parsers.DocumentBuilder",
+	"javax.xml.parsers.SAXParserFactory#newInstance":                   "javax.xml.parsers.SAXParserFactory",
+	"javax.xml.parsers.SAXParserFactory#newSAXParser":                  "javax.xml.parsers.SAXParser",
+	"javax.xml.parsers.SAXParser#getXMLReader":                         "org.xml.sax.XMLReader",
+	"javax.xml.transform.TransformerFactory#newInstance":               "javax.xml.transform.TransformerFactory",
+	"javax.xml.transform.TransformerFactory#newTransformer":            "javax.xml.transform.Transformer",
+	"javax.xml.stream.XMLInputFactory#newInstance":                     "javax.xml.stream.XMLInputFactory",
+	"javax.xml.stream.XMLInputFactory#newFactory":                      "javax.xml.stream.XMLInputFactory",
+	"javax.xml.validation.SchemaFactory#newInstance":                   "javax.xml.validation.SchemaFactory",
+	"javax.script.ScriptEngineManager#getEngineByName":                 "javax.script.ScriptEngine",
+	"javax.script.ScriptEngineManager#getEngineByExtension":            "javax.script.ScriptEngine",
+	"javax.script.ScriptEngineManager#getEngineByMimeType":             "javax.script.ScriptEngine",
+	"java.nio.file.Paths#get":                                          "java.nio.file.Path",
+	"java.nio.file.Path#resolve":                                       "java.nio.file.Path",
+	"java.nio.file.Path#toFile":                                        "java.io.File",
+	"java.io.File#toPath":                                              "java.nio.file.Path",
+	"java.io.File#getCanonicalPath":                                    "java.lang.String",
+	"java.io.File#getAbsolutePath":                                     "java.lang.String",
+	"java.net.URI#toURL":                                               "java.net.URL",
+	"java.net.URL#openConnection":                                      "java.net.URLConnection",
+	"java.net.URL#openStream":                                          "java.io.InputStream",
+	"java.util.Base64#getDecoder":                                      "java.util.Base64.Decoder",
+	"java.util.Base64#getEncoder":                                      "java.util.Base64.Encoder",
+	"org.apache.sling.api.SlingHttpServletRequest#getResourceResolver": "org.apache.sling.api.resource.ResourceResolver",
+	"org.apache.sling.api.SlingHttpServletRequest#getResource":         "org.apache.sling.api.resource.Resource",
+	"org.apache.sling.api.SlingHttpServletRequest#getRequestParameter": "org.apache.sling.api.request.RequestParameter",
+	"org.apache.sling.api.request.RequestParameter#getString":          "java.lang.String",
+	"org.apache.sling.api.resource.ResourceResolver#getResource":       "org.apache.sling.api.resource.Resource",
+	"org.apache.sling.api.resource.ResourceResolver#resolve":           "org.apache.sling.api.resource.Resource",
+	"os/exec#Command":                                                  "os/exec.Cmd",
+	"os/exec#CommandContext":                                           "os/exec.Cmd",
+	"database/sql#Open":                                                "database/sql.DB",
+}
+
+func init() {
+	// Jakarta EE 9 之后 javax.servlet 改名为 jakarta.servlet，类型关系不变
+	for name, supers := range knownSuperTypes {
+		if strings.HasPrefix(name, "javax.servlet.") {
+			var renamed []string
+			for _, super := range supers {
+				renamed = append(renamed, strings.Replace(super, "javax.servlet.", "jakarta.servlet.", 1))
+			}
+			knownSuperTypes[strings.Replace(name, "javax.servlet.", "jakarta.servlet.", 1)] = renamed
+		}
+	}
+	for name, returnType := range knownReturnTypes {
+		if strings.HasPrefix(name, "javax.servlet.") {
+			knownReturnTypes[strings.Replace(name, "javax.servlet.", "jakarta.servlet.", 1)] = strings.Replace(returnType, "javax.servlet.", "jakarta.servlet.", 1)
+		}
+	}
+}
+
+// resolveDeferredReceivers 补全解析单个文件时无法确定的接收者类型：
+// 链式调用 a.b().c() 中 c 的接收者为 b 的返回类型，裸字段名可能是继承自父类的字段
+func (r *callResolver) resolveDeferredReceivers() {
+	for round := 0; round < maxReceiverChain; round++ {
+		changed := false
+		for id, call := range r.index.index {
+			if call.Type != "MethodCall" || call.Metadata == nil || call.Metadata["receiverType"] != "" {
+				continue
+			}
+			receiverType := ""
+			if innerID := call.Metadata["receiverCallID"]; innerID != "" {
+				if inner, ok := r.index.index[innerID]; ok {
+					receiverType = r.returnType(inner)
+				}
+			} else if name := call.Metadata["receiverField"]; name != "" {
+				receiverType = r.inheritedFieldType(call.Metadata["callerClass"], call.Package, name)
+			}
+			if receiverType == "" {
+				continue
+			}
+			call.Metadata["receiverType"] = receiverType
+			r.index.index[id] = call
+			changed = true
+		}
+		if !changed {
+			return
+		}
+	}
+}
+
+// returnType 返回方法调用的返回类型：优先使用索引中方法声明的返回类型，其次查已知的项目外方法
+func (r *callResolver) returnType(call UniversalASTNode) string {
+	receiverType := call.Metadata["receiverType"]
+	if receiverType == "" {
+		return ""
+	}
+	argCount, _ := strconv.Atoi(call.Metadata["argCount"])
+	for _, classID := range r.receiverClasses(call, receiverType) {
+		for _, methodID := range r.findInHierarchy(classID, call.Name, argCount, make(map[string]bool)) {
+			if returnType := r.index.index[methodID].Metadata["returnFullType"]; returnType != "" {
+				return returnType
+			}
+		}
+	}
+	// 接收者为模块时调用的是模块级函数，如 Go 的 srv := NewServer()
+	for _, funcID := range r.matchFunctions(receiverType, call.Name, argCount) {
+		if returnType := r.index.index[funcID].Metadata["returnFullType"]; returnType != "" {
+			return returnType
+		}
+	}
+	for _, typeName := range r.supertypes(receiverType) {
+		if returnType, ok := knownReturnTypes[typeName+"#"+call.Name]; ok {
+			return returnType
+		}
+	}
+	return ""
+}
+
+// inheritedFieldType 在类的父类链中查找字段，返回字段类型的全限定名
+func (r *callResolver) inheritedFieldType(className, pkg, name string) string {
+	for _, typeName := range r.supertypes(className) {
+		for _, classID := range r.lookupClass(typeName, pkg) {
+			if fieldType := r.fieldTypes[classID][name]; fieldType != "" {
+				return fieldType
+			}
+		}
+	}
+	return ""
+}
+
+// lookupClass 按全限定名查找类节点，找不到时依次退回到相对当前包的嵌套类（如 C++ 命名空间中的 Outer::Inner）
+// 和同包同名的类（import * 推断的包名可能不准确）
+func (r *callResolver) lookupClass(typeName, pkg string) []string {
+	if classIDs := r.classesByName[typeName]; len(classIDs) > 0 {
+		return classIDs
+	}
+	if classIDs := r.classesByName[pkg+"."+typeName]; len(classIDs) > 0 && pkg != "" {
+		return classIDs
+	}
+	return r.classesByName[pkg+"."+ShortClassName(typeName)]
+}
+
+// receiverClasses 查找调用的接收者类型对应的类节点，找不到时在调用方所在类及其外层类的嵌套类中查找，
+// 如在 Outer 中直接以 Inner 引用 Outer$Inner
+func (r *callResolver) receiverClasses(call UniversalASTNode, receiverType string) []string {
+	if classIDs := r.lookupClass(receiverType, call.Package); len(classIDs) > 0 {
+		return classIDs
+	}
+	name := ShortClassName(receiverType)
+	for outer := call.Metadata["callerClass"]; outer != ""; {
+		if classIDs := r.classesByName[outer+"$"+name]; len(classIDs) > 0 {
+			return classIDs
+		}
+		idx := strings.LastIndex(outer, "$")
+		if idx == -1 {
+			break
+		}
+		outer = outer[:idx]
+	}
+	return nil
+}
+
+// supertypes 返回类型自身及其全部父类型（索引中声明的继承关系与已知的项目外类型关系），最后为 java.lang.Object
+func (r *callResolver) supertypes(typeName string) []string {
+	var result []string
+	visited := make(map[string]bool)
+	var walk func(name string)
+	walk = func(name string) {
+		if name == "" || visited[name] {
+			return
+		}
+		visited[name] = true
+		result = append(result, name)
+		for _, classID := range r.classesByName[name] {
+			for _, super := range r.index.index[classID].SuperClasses {
+				walk(super.FullName())
+			}
+		}
+		for _, super := range knownSuperTypes[name] {
+			walk(super)
+		}
+	}
+	walk(typeName)
+	if !visited["java.lang.Object"] {
+		result = append(result, "java.lang.Object")
+	}
+	return result
+}
+
+// FindCalls 查找对 类名#方法名 的调用，接收者类型为该类或其子类型的调用均会列出，
+// 例如 javax.servlet.ServletRequest#getParameter 同时匹配 HttpServletRequest 上的调用。
+// 类名可以是简单类名，方法名以 * 结尾时按前缀匹配；接收者类型无法推断的同名调用单独列出
+func FindCalls(query *QueryEngine, target string) (string, error) {
+	className, methodName, found := strings.Cut(target, "#")
+	if !found || className == "" || methodName == "" {
+		return "", fmt.Errorf("target must be in the form Class#method, got: %s", target)
+	}
+	// 允许带参数列表的写法，如 Statement#executeQuery(String)
+	if idx := strings.Index(methodName, "("); idx != -1 {
+		methodName = methodName[:idx]
+	}
+
+	resolver := newCallResolver(query.index)
+	var matched, unresolved []UniversalASTNode
+	for _, call := range query.index.index {
+		if call.Type != "MethodCall" || !matchName(call.Name, methodName) {
+			continue
+		}
+		receiverType := call.Metadata["receiverType"]
+		if receiverType == "" {
+			unresolved = append(unresolved, call)
+			continue
+		}
+		for _, typeName := range resolver.supertypes(receiverType) {
+			if classNameMatches(typeName, className) {
+				matched = append(matched, call)
+				break
+			}
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("==== %s 的调用 ====\n", target))
+	writeCallSites(&builder, query, matched)
+	if len(unresolved) > 0 {
+		builder.WriteString("\n==== 接收者类型未知的同名调用 ====\n")
+		writeCallSites(&builder, query, unresolved)
+	}
+	return builder.String(), nil
+}
+
+// writeCallSites 按文件和行号输出调用位置、接收者类型和代码行
+func writeCallSites(builder *strings.Builder, query *QueryEngine, calls []UniversalASTNode) {
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].File != calls[j].File {
+			return calls[i].File < calls[j].File
+		}
+		return calls[i].StartLine < calls[j].StartLine
+	})
+	builder.WriteString(fmt.Sprintf("共 %d 处:\n", len(calls)))
+	for _, call := range calls {
+		location := call.Metadata["callerClass"]
+		if caller, ok := query.index.GetNode(call.Metadata["callerID"]); ok {
+			location = describeMethod(caller)
+		}
+		receiver := call.Metadata["receiver"]
+		if receiverType := call.Metadata["receiverType"]; receiverType != "" {
+			receiver = fmt.Sprintf("%s (%s)", receiver, receiverType)
+		}
+		builder.WriteString(fmt.Sprintf("  - %s (%s:%d)\n    接收者: %s\n", location, filepath.Base(call.File), call.StartLine+1, receiver))
+		// 节点行号从 0 开始，GetCodeSnippet 按从 1 开始的行号读取
+		line := call
+		line.StartLine++
+		line.EndLine = line.StartLine
+		if code, err := query.GetCodeSnippet(line, 0); err == nil {
+			builder.WriteString(fmt.Sprintf("    %s\n", strings.TrimSpace(code)))
+		}
+	}
+}
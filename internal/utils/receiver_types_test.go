@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindCalls(t *testing.T) {
+	index := indexSources(t, map[string]string{
+		"app/Ctl.java": `package app;
+
+import javax.servlet.http.HttpServletRequest;
+
+public class Ctl {
+    void handle(HttpServletRequest req, Object other) throws Exception {
+        String cmd = req.getParameter("cmd");
+        Runtime.getRuntime().exec(cmd);
+        other.getParameter("x");
+    }
+}
+`,
+	}, &JavaParser{})
+	query := NewQueryEngine(index)
+
+	tests := []struct {
+		name    string
+		target  string
+		want    []string
+		notWant []string
+	}{
+		{"按父类型匹配", "javax.servlet.ServletRequest#getParameter", []string{"共 1 处", "req (javax.servlet.http.HttpServletRequest)"}, []string{"other"}},
+		{"链式调用的接收者", "Runtime#exec", []string{"共 1 处", "Runtime.getRuntime().exec(cmd);"}, []string{"接收者类型未知"}},
+		{"方法名前缀与参数列表", "java.lang.Runtime#ex*(String)", []string{"共 1 处"}, nil},
+		{"不相关的类型", "java.sql.Statement#execute", []string{"共 0 处"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := FindCalls(query, tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result, want) {
+					t.Errorf("FindCalls(%s) 缺少 %q:\n%s", tt.target, want, result)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(result, notWant) {
+					t.Errorf("FindCalls(%s) 不应包含 %q:\n%s", tt.target, notWant, result)
+				}
+			}
+		})
+	}
+
+	if _, err := FindCalls(query, "getParameter"); err == nil {
+		t.Errorf("缺少 # 时应当返回错误")
+	}
+}
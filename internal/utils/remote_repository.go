@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -28,16 +29,54 @@ func NewRemoteRepositoryManager(config *configs.Config) *RemoteRepositoryManager
 func (m *RemoteRepositoryManager) DownloadAndPrepare() (string, error) {
 	repoConfig := m.config.RemoteRepository
 
+	var repoPath string
+	var err error
 	switch repoConfig.Type {
 	case "zip":
-		return m.downloadAndExtractZip(repoConfig.URL, repoConfig.TargetPath)
+		// JAR/WAR/EAR 不直接解压，保留原文件交给反编译流程处理
+		if IsJavaArchive(repoConfig.URL) {
+			repoPath, err = m.downloadArchive(repoConfig.URL, repoConfig.TargetPath)
+		} else {
+			repoPath, err = m.downloadAndExtractZip(repoConfig.URL, repoConfig.TargetPath)
+		}
 	case "git":
-		return m.cloneGitRepository(repoConfig.URL, repoConfig.Branch, repoConfig.TargetPath)
+		repoPath, err = m.cloneGitRepository(repoConfig.URL, repoConfig.Branch, repoConfig.TargetPath)
 	case "local":
-		return repoConfig.TargetPath, nil
+		// 通过 local:path 指定时路径在 URL 中
+		repoPath = repoConfig.TargetPath
+		if repoConfig.URL != "" {
+			repoPath = repoConfig.URL
+		}
 	default:
 		return "", fmt.Errorf("不支持的仓库类型: %s", repoConfig.Type)
 	}
+	if err != nil {
+		return "", err
+	}
+
+	// Java 归档或归档目录先解包并反编译为源码
+	if IsArchiveInput(repoPath) {
+		return NewArchiveIngester(m.config).Prepare(repoPath)
+	}
+	return repoPath, nil
+}
+
+// downloadArchive 下载 Java 归档，按原文件名保存
+func (m *RemoteRepositoryManager) downloadArchive(url, targetPath string) (string, error) {
+	// 清理目标目录
+	if err := os.RemoveAll(targetPath); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("清理目录失败: %v", err)
+	}
+	if err := os.MkdirAll(targetPath, 0755); err != nil {
+		return "", fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	name, _, _ := strings.Cut(url, "?")
+	archivePath := filepath.Join(targetPath, path.Base(name))
+	if err := m.downloadFile(url, archivePath); err != nil {
+		return "", err
+	}
+	return archivePath, nil
 }
 
 // downloadAndExtractZip 下载并解压ZIP文件
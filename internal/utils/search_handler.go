@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -10,7 +12,7 @@ func SearchClassOnly(query *QueryEngine, className string) ([]string, error) {
 	var results []string
 	for _, node := range query.index.index {
 		if node.Type == "Class" && IsMatchingClass(node, className) {
-			snippet, err := query.GetCodeSnippet(node, 100) // 0 表示不扩展上下文行
+			snippet, err := searchSnippet(query, node, 100) // 0 表示不扩展上下文行
 			if err == nil {
 				results = append(results, snippet)
 			}
@@ -32,11 +34,11 @@ func SearchClassMethod(query *QueryEngine, className, methodName string) ([]stri
 	var results []string
 	for _, class := range targetClasses {
 		for _, node := range query.index.index {
-			// 检查方法是否在目标类范围内
-			if node.Type == "Method" &&
+			// 检查方法是否在目标类范围内，或声明在类外（Go 的方法、C++ 类外定义的成员函数）
+			if node.Type == "Method" && (node.Metadata["classID"] == class.ID ||
 				node.File == class.File &&
-				node.StartLine >= class.StartLine &&
-				node.EndLine <= class.EndLine {
+					node.StartLine >= class.StartLine &&
+					node.EndLine <= class.EndLine) {
 
 				// 打印调试信息
 				fmt.Printf("Checking method: %s.%s (Return: %s, Params: %v)\n",
@@ -45,7 +47,7 @@ func SearchClassMethod(query *QueryEngine, className, methodName string) ([]stri
 				// 检查方法签名匹配
 				if IsMatchingMethod(node, methodName) {
 					fmt.Printf("Found matching method: %s.%s\n", node.Package, node.Name)
-					snippet, err := query.GetCodeSnippet(node, 1) // 添加1行上下文
+					snippet, err := searchSnippet(query, node, 1) // 添加1行上下文
 					if err != nil {
 						fmt.Printf("Error getting code snippet: %v\n", err)
 						continue
@@ -94,13 +96,14 @@ func SearchClassField(query *QueryEngine, className, fieldName string) ([]string
 					Package:   class.Package,
 					StartLine: field.StartLine,
 					EndLine:   field.EndLine,
+					Module:    class.Module,
 					Metadata: map[string]string{
 						"fieldType": field.Type,
 					},
 				}
 
 				// 获取字段的代码片段
-				snippet, err := query.GetCodeSnippet(fieldNode, 1)
+				snippet, err := searchSnippet(query, fieldNode, 1)
 				if err == nil {
 					results = append(results, snippet)
 				}
@@ -138,9 +141,10 @@ func searchInClassScope(query *QueryEngine, className, targetName, targetType st
 	findInClass = func(class UniversalASTNode) {
 		// 查找目标元素（方法或字段）
 		for _, node := range query.index.index {
-			if node.File != class.File ||
+			outOfClass := node.File != class.File ||
 				node.StartLine < class.StartLine ||
-				node.EndLine > class.EndLine {
+				node.EndLine > class.EndLine
+			if outOfClass && (node.Metadata["classID"] != class.ID || class.ID == "") {
 				continue
 			}
 
@@ -148,7 +152,7 @@ func searchInClassScope(query *QueryEngine, className, targetName, targetType st
 				// 特殊处理方法签名
 				if targetType == "Method" {
 					if IsMatchingMethod(node, targetName) {
-						snippet, err := query.GetCodeSnippet(node, 1)
+						snippet, err := searchSnippet(query, node, 1)
 						if err == nil {
 							results = append(results, snippet)
 						}
@@ -156,7 +160,7 @@ func searchInClassScope(query *QueryEngine, className, targetName, targetType st
 				} else {
 					// 字段直接匹配
 					if node.Name == targetName {
-						snippet, err := query.GetCodeSnippet(node, 1)
+						snippet, err := searchSnippet(query, node, 1)
 						if err == nil {
 							results = append(results, snippet)
 						}
@@ -191,49 +195,31 @@ func EnhancedSearchClassField(query *QueryEngine, className, fieldName string) (
 	var results []string
 	seen := make(map[string]struct{}) // 用于去重
 
-	// 遍历所有节点
+	// 遍历所有字段节点，按所在类过滤
 	for _, node := range query.GetAllNodes() {
-		// 检查是否是目标类
-		if node.Type == "Class" && IsMatchingClass(node, className) {
-			fmt.Printf("Found matching class: %s.%s\n", node.Package, node.Name)
-
-			// 在类的字段列表中查找目标字段
-			for _, field := range node.Fields {
-				if field.Name == fieldName {
-					fmt.Printf("Found matching field: %s (Type: %s, Lines: %d-%d)\n",
-						field.Name, field.Type, field.StartLine, field.EndLine)
-
-					// 创建临时节点用于获取代码片段
-					fieldNode := UniversalASTNode{
-						ID:        fmt.Sprintf("%s:%s:%d", node.File, field.Name, field.StartLine),
-						Language:  node.Language,
-						Type:      "Field",
-						Name:      field.Name,
-						File:      node.File,
-						Package:   node.Package,
-						StartLine: field.StartLine,
-						EndLine:   field.EndLine,
-						Metadata: map[string]string{
-							"fieldType": field.Type,
-						},
-					}
-
-					// 获取字段的代码片段
-					snippet, err := query.GetCodeSnippet(fieldNode, 1) // 添加1行上下文
-					if err != nil {
-						fmt.Printf("Error getting code snippet: %v\n", err)
-						continue
-					}
+		if node.Type != "Field" || node.Name != fieldName {
+			continue
+		}
+		class, ok := query.index.GetNode(node.Metadata["classID"])
+		if !ok || !IsMatchingClass(class, className) {
+			continue
+		}
+		fmt.Printf("Found matching field: %s.%s (Type: %s, Lines: %d-%d)\n",
+			class.Name, node.Name, node.Metadata["fieldType"], node.StartLine, node.EndLine)
+
+		// 获取字段的代码片段
+		snippet, err := searchSnippet(query, node, 1) // 添加1行上下文
+		if err != nil {
+			fmt.Printf("Error getting code snippet: %v\n", err)
+			continue
+		}
 
-					// 以代码片段内容为唯一性依据去重
-					if _, ok := seen[snippet]; !ok {
-						result := fmt.Sprintf("Field: %s (Type: %s)\n%s",
-							field.Name, field.Type, snippet)
-						results = append(results, result)
-						seen[snippet] = struct{}{}
-					}
-				}
-			}
+		// 以代码片段内容为唯一性依据去重
+		if _, ok := seen[snippet]; !ok {
+			result := fmt.Sprintf("Field: %s (Type: %s)\n%s",
+				node.Name, node.Metadata["fieldType"], snippet)
+			results = append(results, result)
+			seen[snippet] = struct{}{}
 		}
 	}
 
@@ -251,6 +237,24 @@ func SmartSearchClassMethod(query *QueryEngine, className, methodName string) ([
 	return SearchClassMethod(query, className, methodName)
 }
 
+// searchSnippet 获取搜索结果的代码片段，节点有所属模块时在片段前标注模块，
+// 同一全限定类名出现在多个模块（或同时有源码与反编译副本）时据此区分
+func searchSnippet(query *QueryEngine, node UniversalASTNode, contextLines int) (string, error) {
+	snippet, err := query.GetCodeSnippet(node, contextLines)
+	if err != nil || node.Module == "" {
+		return snippet, err
+	}
+	return fmt.Sprintf("模块: %s\n%s", node.Module, snippet), nil
+}
+
+// moduleSuffix 返回位置信息后的模块标注，没有所属模块时为空
+func moduleSuffix(module string) string {
+	if module == "" {
+		return ""
+	}
+	return ", 模块: " + module
+}
+
 // 格式化搜索结果函数
 func FormatSearchResults(results []string) string {
 	if len(results) == 0 {
@@ -276,7 +280,12 @@ func UnifiedSearch(query *QueryEngine, className, methodName, fieldName string)
 
 	switch {
 	case methodName != "":
-		return SmartSearchClassMethod(query, className, methodName)
+		results, err := SmartSearchClassMethod(query, className, methodName)
+		if err != nil || len(results) > 0 {
+			return results, err
+		}
+		// 没有匹配的类时按模块名查找模块级函数
+		return searchModuleFunctions(query, className, methodName)
 	case fieldName != "":
 		return EnhancedSearchClassField(query, className, fieldName)
 	default:
@@ -284,21 +293,21 @@ func UnifiedSearch(query *QueryEngine, className, methodName, fieldName string)
 	}
 }
 
-// 查询接口：查找所有父类（递归获取所有父类）
-func GetAllSuperClasses(query *QueryEngine, className string) [][]ClassRef {
-	var results [][]ClassRef
+// 查询接口：查找所有父类（递归获取所有父类），结果按匹配到的类所属模块分组，module 非空时只查找该模块中的类
+func GetAllSuperClasses(query *QueryEngine, className, module string) map[string][]ClassRef {
+	results := make(map[string][]ClassRef)
 	for _, node := range query.index.index {
-		if node.Type == "Class" && (node.FullClassName == className || node.Name == className) {
-			// 递归收集所有父类
-			allSuperClasses := collectAllSuperClasses(query, node.FullClassName, make(map[string]bool))
-			results = append(results, allSuperClasses)
+		if node.Type == "Class" && (node.FullClassName == className || node.Name == className) && MatchModule(node, module) {
+			// 递归收集所有父类，同名父类优先取同一模块中的定义
+			allSuperClasses := collectAllSuperClasses(query, node.FullClassName, node.Module, make(map[string]bool))
+			results[node.Module] = append(results[node.Module], allSuperClasses...)
 		}
 	}
 	return results
 }
 
-// 递归收集所有父类（包括间接父类）
-func collectAllSuperClasses(query *QueryEngine, className string, visited map[string]bool) []ClassRef {
+// 递归收集所有父类（包括间接父类），同一全限定类名存在于多个模块时优先使用 module 中的类
+func collectAllSuperClasses(query *QueryEngine, className, module string, visited map[string]bool) []ClassRef {
 	var allSuperClasses []ClassRef
 
 	// 查找当前类
@@ -308,7 +317,9 @@ func collectAllSuperClasses(query *QueryEngine, className string, visited map[st
 		if node.Type == "Class" && node.FullClassName == className {
 			currentNode = node
 			found = true
-			break
+			if node.Module == module {
+				break
+			}
 		}
 	}
 
@@ -318,12 +329,12 @@ func collectAllSuperClasses(query *QueryEngine, className string, visited map[st
 
 	// 添加直接父类
 	for _, superClass := range currentNode.SuperClasses {
-		superClassName := superClass.Package + "." + superClass.Name
+		superClassName := superClass.FullName()
 		if !visited[superClassName] {
 			visited[superClassName] = true
 			allSuperClasses = append(allSuperClasses, superClass)
 			// 递归获取父类的父类
-			parentSupers := collectAllSuperClasses(query, superClassName, visited)
+			parentSupers := collectAllSuperClasses(query, superClassName, module, visited)
 			allSuperClasses = append(allSuperClasses, parentSupers...)
 		}
 	}
@@ -331,13 +342,186 @@ func collectAllSuperClasses(query *QueryEngine, className string, visited map[st
 	return allSuperClasses
 }
 
-// 查询接口：查找所有子类
-func GetAllSubClasses(query *QueryEngine, className string) [][]ClassRef {
-	var results [][]ClassRef
+// 查询接口：查找所有子类，结果按匹配到的类所属模块分组，module 非空时只查找该模块中的类
+func GetAllSubClasses(query *QueryEngine, className, module string) map[string][]ClassRef {
+	results := make(map[string][]ClassRef)
 	for _, node := range query.index.index {
-		if node.Type == "Class" && (node.FullClassName == className || node.Name == className) {
-			results = append(results, node.SubClasses)
+		if node.Type == "Class" && (node.FullClassName == className || node.Name == className) && MatchModule(node, module) {
+			results[node.Module] = append(results[node.Module], node.SubClasses...)
 		}
 	}
 	return results
 }
+
+// SearchFilter code_search 的筛选条件，为空的条件不参与筛选
+type SearchFilter struct {
+	// Annotation 注解简单名或全限定名，以 ! 开头表示没有该注解（方法还要求所在类上也没有该注解）
+	Annotation string
+	// Modifiers 逗号分隔的修饰符，以 ! 开头表示不含该修饰符，如 public,!final
+	Modifiers string
+}
+
+// matchAnnotation 判断注解列表是否满足注解条件，取反时任一列表中出现该注解即不满足
+func (f SearchFilter) matchAnnotation(annotations ...[]AnnotationInfo) bool {
+	if f.Annotation == "" {
+		return true
+	}
+	negate := strings.HasPrefix(f.Annotation, "!")
+	name := strings.TrimPrefix(strings.TrimPrefix(f.Annotation, "!"), "@")
+	for _, list := range annotations {
+		if _, ok := FindAnnotation(list, name); ok {
+			return !negate
+		}
+	}
+	return negate
+}
+
+// matchModifiers 判断修饰符列表是否满足修饰符条件
+func (f SearchFilter) matchModifiers(modifiers []string) bool {
+	for _, want := range splitNonEmpty(f.Modifiers) {
+		negate := strings.HasPrefix(want, "!")
+		want = strings.TrimPrefix(want, "!")
+		found := false
+		for _, modifier := range modifiers {
+			if modifier == want {
+				found = true
+				break
+			}
+		}
+		if found == negate {
+			return false
+		}
+	}
+	return true
+}
+
+// matchName 按名称筛选，* 表示全部，以 * 结尾表示前缀匹配
+func matchName(name, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") && !strings.Contains(pattern, "(") {
+		return strings.HasPrefix(name, strings.TrimSuffix(pattern, "*"))
+	}
+	return name == pattern
+}
+
+// SearchWithFilters 按注解和修饰符筛选类、方法或字段，返回每个元素的位置、修饰符与注解。
+// className 为空表示全部类；methodName 或 fieldName 为 * 表示类中全部方法或字段，以 * 结尾表示名称前缀，如 set*
+func SearchWithFilters(query *QueryEngine, className, methodName, fieldName string, filter SearchFilter) ([]string, error) {
+	if methodName != "" && fieldName != "" {
+		return nil, fmt.Errorf("cannot specify both methodName and fieldName at the same time")
+	}
+	if strings.Trim(filter.Annotation, "!@") == "" && strings.Trim(filter.Modifiers, "!, ") == "" {
+		return nil, fmt.Errorf("annotation or modifiers is required")
+	}
+	describe := func(modifiers []string, annotations []AnnotationInfo) string {
+		var parts []string
+		for _, a := range annotations {
+			parts = append(parts, a.String())
+		}
+		parts = append(parts, modifiers...)
+		if len(parts) == 0 {
+			return "无"
+		}
+		return strings.Join(parts, " ")
+	}
+
+	classes := make(map[string]UniversalASTNode)
+	for id, node := range query.index.index {
+		if node.Type == "Class" && (className == "" || IsMatchingClass(node, className)) {
+			classes[id] = node
+		}
+	}
+
+	var results []string
+	switch {
+	case methodName != "":
+		for _, node := range query.index.index {
+			class, ok := classes[node.Metadata["classID"]]
+			// 模块级函数（如 Python 的 Flask 视图函数）按所在模块匹配 className
+			isFunction := node.Type == "Function" && node.Metadata["classID"] == "" &&
+				(className == "" || isMatchingModule(node, className))
+			if !isFunction && (node.Type != "Method" || !ok) {
+				continue
+			}
+			// 带括号时按方法签名匹配，否则按名称匹配
+			if strings.Contains(methodName, "(") {
+				if !IsMatchingMethod(node, methodName) {
+					continue
+				}
+			} else if !matchName(node.Name, methodName) {
+				continue
+			}
+			// 取反筛选时类上的注解同样生效，如类上的 @PreAuthorize 作用于全部方法
+			annotations := [][]AnnotationInfo{node.Annotations}
+			if strings.HasPrefix(filter.Annotation, "!") {
+				annotations = append(annotations, class.Annotations)
+			}
+			if !filter.matchAnnotation(annotations...) || !filter.matchModifiers(node.Modifiers) {
+				continue
+			}
+			results = append(results, fmt.Sprintf("方法: %s (%s:%d%s)\n修饰: %s",
+				describeMethod(node), filepath.Base(node.File), node.StartLine+1, moduleSuffix(node.Module),
+				describe(node.Modifiers, node.Annotations)))
+		}
+	case fieldName != "":
+		for _, class := range classes {
+			for _, field := range class.Fields {
+				if !matchName(field.Name, fieldName) || !filter.matchAnnotation(field.Annotations) ||
+					!filter.matchModifiers(field.Modifiers) {
+					continue
+				}
+				results = append(results, fmt.Sprintf("字段: %s.%s#%s (Type: %s, %s:%d%s)\n修饰: %s",
+					class.Package, class.Name, field.Name, field.Type, filepath.Base(class.File), field.StartLine+1,
+					moduleSuffix(class.Module), describe(fieldKeywords(field.Modifiers), field.Annotations)))
+			}
+		}
+	default:
+		for _, class := range classes {
+			if !filter.matchAnnotation(class.Annotations) || !filter.matchModifiers(class.Modifiers) {
+				continue
+			}
+			results = append(results, fmt.Sprintf("类: %s.%s (%s:%d%s)\n修饰: %s",
+				class.Package, class.Name, filepath.Base(class.File), class.StartLine+1, moduleSuffix(class.Module), describe(class.Modifiers, class.Annotations)))
+		}
+	}
+	sort.Strings(results)
+	return results, nil
+}
+
+// isMatchingModule 判断函数节点所在模块是否为 moduleName，支持只写最后几段，如 views 匹配 app.views
+func isMatchingModule(node UniversalASTNode, moduleName string) bool {
+	module := node.Metadata["className"]
+	// JavaScript / TypeScript 的模块路径以 / 分隔
+	return module == moduleName || strings.HasSuffix(module, "."+moduleName) || strings.HasSuffix(module, "/"+moduleName)
+}
+
+// searchModuleFunctions 查找模块中与方法签名匹配的模块级函数并返回源代码片段
+func searchModuleFunctions(query *QueryEngine, moduleName, methodName string) ([]string, error) {
+	var results []string
+	for _, node := range query.index.index {
+		if node.Type != "Function" || node.Metadata["classID"] != "" || !isMatchingModule(node, moduleName) ||
+			!IsMatchingMethod(node, methodName) {
+			continue
+		}
+		snippet, err := searchSnippet(query, node, 1)
+		if err != nil {
+			fmt.Printf("Error getting code snippet: %v\n", err)
+			continue
+		}
+		results = append(results, snippet)
+	}
+	return results, nil
+}
+
+// fieldKeywords 字段的 Modifiers 中包含注解原文，这里只保留修饰符关键字
+func fieldKeywords(modifiers []string) []string {
+	var keywords []string
+	for _, modifier := range modifiers {
+		if !strings.HasPrefix(modifier, "@") {
+			keywords = append(keywords, modifier)
+		}
+	}
+	return keywords
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchWithFilters(t *testing.T) {
+	query := NewQueryEngine(indexSources(t, map[string]string{
+		"app/AdminController.java": `package app;
+
+import org.springframework.security.access.prepost.PreAuthorize;
+import org.springframework.web.bind.annotation.*;
+
+@RestController
+public class AdminController {
+    @Autowired
+    private UserService users;
+
+    private String name;
+
+    @PreAuthorize("hasRole('ADMIN')")
+    @GetMapping("/admin/users")
+    public void list() {}
+
+    @PostMapping("/admin/reset")
+    public void reset() {}
+}
+`,
+		"app/OpenController.java": `package app;
+
+@RestController
+@PreAuthorize("isAuthenticated()")
+public class OpenController {
+    @GetMapping("/me")
+    public void me() {}
+}
+`,
+	}, &JavaParser{}))
+
+	tests := []struct {
+		name       string
+		className  string
+		methodName string
+		fieldName  string
+		filter     SearchFilter
+		want       []string // 每条结果的第一行
+		wantErr    bool
+	}{
+		{name: "类注解", filter: SearchFilter{Annotation: "RestController"}, want: []string{"类: app.AdminController (AdminController.java:6)", "类: app.OpenController (OpenController.java:3)"}},
+		{name: "全限定名", methodName: "*", filter: SearchFilter{Annotation: "@org.springframework.security.access.prepost.PreAuthorize"}, want: []string{"方法: app.AdminController#list() (AdminController.java:13)"}},
+		{name: "取反时考虑类上的注解", methodName: "*", filter: SearchFilter{Annotation: "!PreAuthorize"}, want: []string{"方法: app.AdminController#reset() (AdminController.java:17)"}},
+		{name: "字段", className: "AdminController", fieldName: "*", filter: SearchFilter{Annotation: "Autowired"}, want: []string{"字段: app.AdminController#users (Type: UserService, AdminController.java:9)"}},
+		{name: "修饰符", className: "AdminController", methodName: "*", filter: SearchFilter{Modifiers: "public,!static"}, want: []string{"方法: app.AdminController#list() (AdminController.java:13)", "方法: app.AdminController#reset() (AdminController.java:17)"}},
+		{name: "名称前缀与注解组合", methodName: "re*", filter: SearchFilter{Annotation: "PostMapping", Modifiers: "public"}, want: []string{"方法: app.AdminController#reset() (AdminController.java:17)"}},
+		{name: "私有字段", fieldName: "*", filter: SearchFilter{Modifiers: "private,!static"}, want: []string{"字段: app.AdminController#name (Type: String, AdminController.java:11)", "字段: app.AdminController#users (Type: UserService, AdminController.java:9)"}},
+		{name: "方法与字段不能同时指定", methodName: "*", fieldName: "*", filter: SearchFilter{Annotation: "Autowired"}, wantErr: true},
+		{name: "缺少筛选条件", filter: SearchFilter{Annotation: "!", Modifiers: " , "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := SearchWithFilters(query, tt.className, tt.methodName, tt.fieldName, tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchWithFilters err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := []string{}
+			for _, result := range results {
+				got = append(got, strings.SplitN(result, "\n", 2)[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchWithFilters =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
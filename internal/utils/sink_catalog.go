@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// javaSinkCatalog 内置的 Java 高危 API 目录，按漏洞类型分组，格式为 类名#方法名
+var javaSinkCatalog = map[string][]string{
+	"command-injection": {
+		"java.lang.Runtime#exec", "java.lang.ProcessBuilder#<init>", "java.lang.ProcessBuilder#command",
+	},
+	"sql-injection": {
+		"java.sql.Statement#executeQuery", "java.sql.Statement#executeUpdate",
+		"java.sql.Statement#execute", "java.sql.Statement#addBatch",
+		"java.sql.Connection#prepareStatement", "java.sql.Connection#prepareCall",
+	},
+	"deserialization": {
+		"java.io.ObjectInputStream#readObject", "java.io.ObjectInputStream#readUnshared",
+		"java.beans.XMLDecoder#readObject", "com.thoughtworks.xstream.XStream#fromXML",
+		"org.yaml.snakeyaml.Yaml#load", "org.yaml.snakeyaml.Yaml#loadAll",
+		"com.alibaba.fastjson.JSON#parse", "com.alibaba.fastjson.JSON#parseObject",
+		"com.fasterxml.jackson.databind.ObjectMapper#enableDefaultTyping",
+		"com.caucho.hessian.io.HessianInput#readObject", "com.caucho.hessian.io.Hessian2Input#readObject",
+		"com.esotericsoftware.kryo.Kryo#readClassAndObject",
+	},
+	"jndi-injection": {
+		"javax.naming.Context#lookup", "javax.naming.InitialContext#lookup",
+		"javax.naming.InitialContext#doLookup", "javax.naming.directory.DirContext#lookup",
+		"org.springframework.jndi.JndiTemplate#lookup",
+	},
+	"xxe": {
+		"javax.xml.parsers.DocumentBuilder#parse", "javax.xml.parsers.SAXParser#parse",
+		"org.xml.sax.XMLReader#parse", "javax.xml.stream.XMLInputFactory#createXMLStreamReader",
+		"javax.xml.stream.XMLInputFactory#createXMLEventReader", "javax.xml.transform.Transformer#transform",
+		"javax.xml.bind.Unmarshaller#unmarshal", "org.dom4j.io.SAXReader#read",
+		"org.jdom2.input.SAXBuilder#build", "javax.xml.validation.Validator#validate",
+	},
+	"expression-injection": {
+		"org.springframework.expression.ExpressionParser#parseExpression",
+		"org.springframework.expression.spel.standard.SpelExpressionParser#parseExpression",
+		"org.springframework.expression.Expression#getValue",
+		"ognl.Ognl#getValue", "ognl.Ognl#parseExpression", "ognl.Ognl#setValue",
+		"org.mvel2.MVEL#eval", "org.mvel2.MVEL#executeExpression",
+		"javax.script.ScriptEngine#eval", "groovy.lang.GroovyShell#evaluate",
+		"org.apache.commons.jexl3.JexlEngine#createExpression",
+	},
+	"reflection": {
+		"java.lang.Class#forName", "java.lang.Class#newInstance", "java.lang.ClassLoader#loadClass",
+		"java.lang.reflect.Method#invoke", "java.lang.reflect.Constructor#newInstance",
+		"java.net.URLClassLoader#<init>",
+	},
+	"path-traversal": {
+		"java.io.File#<init>", "java.io.FileInputStream#<init>", "java.io.FileOutputStream#<init>",
+		"java.io.FileReader#<init>", "java.io.FileWriter#<init>", "java.io.RandomAccessFile#<init>",
+		"java.nio.file.Paths#get", "java.nio.file.Path#of", "java.nio.file.Files#readAllBytes",
+		"java.nio.file.Files#readAllLines", "java.nio.file.Files#newInputStream",
+		"java.nio.file.Files#newOutputStream", "java.nio.file.Files#write", "java.nio.file.Files#copy",
+		"java.nio.file.Files#delete", "org.apache.commons.io.FileUtils#readFileToString",
+		"org.apache.commons.io.FileUtils#writeStringToFile", "org.springframework.web.multipart.MultipartFile#transferTo",
+	},
+}
+
+// nativeSinkCatalog 内置的 C/C++ 高危函数目录，标准库函数调用的接收者类型为 libc
+var nativeSinkCatalog = map[string][]string{
+	"command-injection": {
+		"libc#system", "libc#popen", "libc#execl", "libc#execlp", "libc#execle",
+		"libc#execv", "libc#execvp", "libc#execve",
+	},
+	"buffer-overflow": {
+		"libc#strcpy", "libc#strcat", "libc#sprintf", "libc#vsprintf", "libc#gets",
+		"libc#memcpy", "libc#memmove", "libc#scanf", "libc#sscanf",
+	},
+}
+
+// sinkCatalogs 参与 DefaultSinkRules 的各语言高危 API 目录，新增语言的目录在此登记
+var sinkCatalogs = []map[string][]string{javaSinkCatalog, nativeSinkCatalog}
+
+// xmlHardeningMethods 出现在同一方法中即认为 XML 解析器做过安全配置的方法
+var xmlHardeningMethods = map[string]bool{
+	"setFeature": true, "setExpandEntityReferences": true, "setXIncludeAware": true,
+	"setAttribute": true, "setProperty": true,
+}
+
+// DefaultSinkRules 返回内置高危 API 目录对应的规则列表
+func DefaultSinkRules() []TaintRule {
+	var rules []TaintRule
+	for _, catalog := range sinkCatalogs {
+		for category, specs := range catalog {
+			for _, spec := range specs {
+				rule, _ := ParseTaintRule(category, spec)
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// SinkFinding 一处高危 API 调用
+type SinkFinding struct {
+	Category string `json:"category"` // 漏洞类型
+	API      string `json:"api"`      // 命中的规则
+	Method   string `json:"method"`   // 调用所在方法
+	File     string `json:"file"`     // 文件路径
+	Line     int    `json:"line"`     // 行号（从 1 开始）
+	Code     string `json:"code"`     // 调用语句
+	Note     string `json:"note"`     // 补充说明
+}
+
+// FindSinks 列出索引中所有匹配规则的方法调用，rules 为空时使用内置目录
+func FindSinks(query *QueryEngine, rules []TaintRule) []SinkFinding {
+	if len(rules) == 0 {
+		rules = DefaultSinkRules()
+	}
+
+	// 记录每个方法中调用过的方法名，用于判断 XML 解析器是否做过加固
+	calledNames := make(map[string]map[string]bool)
+	for _, node := range query.index.index {
+		if node.Type != "MethodCall" {
+			continue
+		}
+		callerID := node.Metadata["callerID"]
+		if calledNames[callerID] == nil {
+			calledNames[callerID] = make(map[string]bool)
+		}
+		calledNames[callerID][node.Name] = true
+	}
+
+	matcher := newRuleMatcher(query)
+	ruleIndex := newRuleIndex(rules)
+	var findings []SinkFinding
+	for _, node := range query.index.index {
+		// 只报告项目代码中的调用，依赖库内部的调用只参与调用链分析
+		if node.Type != "MethodCall" || node.Metadata["layer"] == "library" {
+			continue
+		}
+		for _, rule := range ruleIndex.forCall(node.Name) {
+			if !matcher.callMatchesRule(node, rule) {
+				continue
+			}
+
+			finding := SinkFinding{
+				Category: rule.Category,
+				API:      rule.String(),
+				Method:   node.Metadata["callerClass"],
+				File:     node.File,
+				Line:     node.StartLine + 1,
+			}
+			if caller, ok := query.index.GetNode(node.Metadata["callerID"]); ok {
+				finding.Method = describeMethod(caller)
+			}
+			// 节点行号从 0 开始，GetCodeSnippet 按从 1 开始的行号读取
+			callLine := node
+			callLine.StartLine++
+			callLine.EndLine++
+			if code, err := query.GetCodeSnippet(callLine, 0); err == nil {
+				finding.Code = strings.TrimSpace(code)
+			}
+			if rule.Category == "xxe" {
+				for name := range calledNames[node.Metadata["callerID"]] {
+					if xmlHardeningMethods[name] {
+						finding.Note = "同一方法中调用了 " + name + "，可能已做安全配置"
+						break
+					}
+				}
+			}
+			findings = append(findings, finding)
+			break
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return findings
+}
+
+// FormatSinkFindings 按漏洞类型分组格式化高危 API 调用
+func FormatSinkFindings(findings []SinkFinding) string {
+	if len(findings) == 0 {
+		return "未找到高危 API 调用"
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(findings); {
+		category := findings[i].Category
+		j := i
+		for j < len(findings) && findings[j].Category == category {
+			j++
+		}
+		builder.WriteString(fmt.Sprintf("==== %s (%d) ====\n", category, j-i))
+		for k, finding := range findings[i:j] {
+			builder.WriteString(fmt.Sprintf("  %d. %s\n     位置: %s (%s:%d)\n",
+				k+1, finding.API, finding.Method, filepath.Base(finding.File), finding.Line))
+			if finding.Code != "" {
+				builder.WriteString(fmt.Sprintf("     代码: %s\n", finding.Code))
+			}
+			if finding.Note != "" {
+				builder.WriteString(fmt.Sprintf("     说明: %s\n", finding.Note))
+			}
+		}
+		builder.WriteString("\n")
+		i = j
+	}
+	return builder.String()
+}
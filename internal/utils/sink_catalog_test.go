@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindSinks(t *testing.T) {
+	query := NewQueryEngine(indexSources(t, map[string]string{
+		"app/Base.java": `package app;
+
+public class Base {
+    public void run(String cmd) {}
+}
+`,
+		"app/Sub.java": `package app;
+
+public class Sub extends Base {
+}
+`,
+		"app/Other.java": `package app;
+
+public class Other {
+    public void run(String cmd) {}
+}
+`,
+		"app/Ctl.java": `package app;
+
+import javax.xml.parsers.DocumentBuilder;
+
+public class Ctl {
+    void handle(Sub sub, Other other, String cmd) throws Exception {
+        sub.run(cmd);
+        other.run("ls");
+        Runtime.getRuntime().exec(cmd);
+    }
+
+    void parse(DocumentBuilder builder, String xml) throws Exception {
+        builder.setFeature("x", true);
+        builder.parse(xml);
+    }
+}
+`,
+	}, &JavaParser{}))
+
+	// sinkSummary 以 "分类 规则 所在方法 说明" 概括每处调用
+	sinkSummary := func(findings []SinkFinding) []string {
+		summary := []string{}
+		for _, f := range findings {
+			summary = append(summary, f.Category+" "+f.API+" "+f.Method+" "+f.Note)
+		}
+		return summary
+	}
+
+	tests := []struct {
+		name  string
+		rules []TaintRule
+		want  []string
+	}{
+		{
+			name:  "自定义规则匹配子类接收者",
+			rules: []TaintRule{{Category: "custom", Class: "app.Base", Method: "run"}},
+			want:  []string{"custom app.Base#run app.Ctl#handle(Sub, Other, String) "},
+		},
+		{
+			name:  "规则只写简单类名",
+			rules: []TaintRule{{Category: "custom", Class: "Other", Method: "run"}},
+			want:  []string{"custom Other#run app.Ctl#handle(Sub, Other, String) "},
+		},
+		{
+			name: "内置目录与 XML 加固说明",
+			want: []string{
+				"command-injection java.lang.Runtime#exec app.Ctl#handle(Sub, Other, String) ",
+				"xxe javax.xml.parsers.DocumentBuilder#parse app.Ctl#parse(DocumentBuilder, String) 同一方法中调用了 setFeature，可能已做安全配置",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sinkSummary(FindSinks(query, tt.rules)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindSinks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindNativeSinks(t *testing.T) {
+	query := NewQueryEngine(indexSources(t, map[string]string{
+		"main.c": `#include <stdlib.h>
+#include <string.h>
+
+void run(const char *cmd, char *buf) {
+    strcpy(buf, cmd);
+    system(cmd);
+    strlen(cmd);
+}
+`,
+	}, &CParser{}))
+
+	var got []string
+	for _, f := range FindSinks(query, nil) {
+		got = append(got, f.Category+" "+f.API)
+	}
+	sort.Strings(got)
+	want := []string{"buffer-overflow libc#strcpy", "command-injection libc#system"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindSinks = %v, want %v", got, want)
+	}
+}
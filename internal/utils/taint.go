@@ -0,0 +1,706 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TaintRule 描述一个污点源、危险汇聚点或净化方法。
+// 默认匹配对 Class#Method 的方法调用；Target 为 method 时匹配方法声明（包括子类中的重写）；
+// 设置了 Annotation 时按方法或参数上的注解匹配
+type TaintRule struct {
+	Category   string `yaml:"category" json:"category"`     // 分类，如 command-injection
+	Class      string `yaml:"class" json:"class"`           // 全限定类名，也可以只写简单类名
+	Method     string `yaml:"method" json:"method"`         // 方法名，构造器为 <init>
+	Annotation string `yaml:"annotation" json:"annotation"` // 注解简单名，如 RequestParam
+	Target     string `yaml:"target" json:"target"`         // call（默认）或 method
+}
+
+// String 以 类名#方法名 或 @注解 的形式输出规则
+func (r TaintRule) String() string {
+	if r.Annotation != "" {
+		return "@" + r.Annotation
+	}
+	return r.Class + "#" + r.Method
+}
+
+// matchesDeclaration 规则是否匹配方法声明而不是方法调用
+func (r TaintRule) matchesDeclaration() bool {
+	return r.Annotation != "" || r.Target == "method"
+}
+
+// ParseTaintRule 解析 类名#方法名 形式的规则
+func ParseTaintRule(category, spec string) (TaintRule, error) {
+	parts := strings.SplitN(spec, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return TaintRule{}, fmt.Errorf("规则格式错误，应为 类名#方法名: %s", spec)
+	}
+	return TaintRule{Category: category, Class: parts[0], Method: parts[1]}, nil
+}
+
+// TaintConfig 污点分析配置
+type TaintConfig struct {
+	Sources           []TaintRule // 返回外部输入的方法调用
+	SourceAnnotations []string    // 带有这些注解的方法参数视为外部输入
+	Sinks             []TaintRule // 危险方法调用
+	Sanitizers        []TaintRule // 净化方法，经过它们的路径不再报告
+	MaxDepth          int         // 源方法到汇聚点方法之间最多经过的调用层数
+}
+
+// DefaultTaintConfig 返回内置的 Java Web 污点源与汇聚点
+func DefaultTaintConfig() TaintConfig {
+	config := TaintConfig{
+		SourceAnnotations: []string{
+			// Spring MVC
+			"RequestParam", "PathVariable", "RequestBody", "RequestHeader",
+			"CookieValue", "ModelAttribute", "MatrixVariable", "RequestPart",
+			// JAX-RS
+			"QueryParam", "PathParam", "FormParam", "HeaderParam", "CookieParam", "MatrixParam",
+		},
+		MaxDepth: 8,
+	}
+
+	requestMethods := []string{
+		"getParameter", "getParameterValues", "getParameterMap", "getParameterNames",
+		"getHeader", "getHeaders", "getQueryString", "getCookies", "getInputStream",
+		"getReader", "getRequestURI", "getRequestURL", "getPathInfo", "getPart", "getParts",
+	}
+	for _, pkg := range []string{"javax.servlet", "jakarta.servlet"} {
+		for _, class := range []string{pkg + ".ServletRequest", pkg + ".http.HttpServletRequest"} {
+			for _, method := range requestMethods {
+				config.Sources = append(config.Sources, TaintRule{Category: "web-input", Class: class, Method: method})
+			}
+		}
+	}
+
+	// 汇聚点与 find_sinks 共用内置高危 API 目录
+	config.Sinks = DefaultSinkRules()
+	return config
+}
+
+// TaintHop 污点路径中的一跳
+type TaintHop struct {
+	Class  string `json:"class"`  // 所在类全限定名
+	Method string `json:"method"` // 所在方法
+	File   string `json:"file"`   // 文件路径
+	Line   int    `json:"line"`   // 行号（从 1 开始）
+	Detail string `json:"detail"` // 该跳的说明
+}
+
+// TaintPath 一条从污点源到危险汇聚点的调用路径
+type TaintPath struct {
+	Category string     `json:"category"` // 汇聚点分类
+	Source   TaintHop   `json:"source"`   // 污点源位置
+	Sink     TaintHop   `json:"sink"`     // 汇聚点调用位置
+	Hops     []TaintHop `json:"hops"`     // 从源方法到汇聚点方法依次经过的方法
+}
+
+// taintSink 方法中匹配到汇聚点规则的一次调用
+type taintSink struct {
+	call UniversalASTNode
+	rule TaintRule
+}
+
+// taintSource 方法中的污点源：被污染的参数与返回外部输入的调用
+type taintSource struct {
+	hop    TaintHop
+	params map[int]bool       // 被污染的参数下标
+	calls  []UniversalASTNode // 返回外部输入的调用，其返回值被污染
+}
+
+// methodFacts 方法内参与污点传播的节点，遍历索引时一次收集
+type methodFacts struct {
+	defs       []UniversalASTNode // LocalVariable / Assignment 节点
+	calls      []UniversalASTNode // 已关联到被调用方法的调用
+	sanitizers []UniversalASTNode // 匹配净化规则的调用
+}
+
+// taintState 搜索中的一个状态：某个方法及其被污染的参数，parent 和 call 记录污点从哪个方法的哪次调用传入
+type taintState struct {
+	methodID string
+	params   map[int]bool
+	calls    []UniversalASTNode // 只有源方法有：返回外部输入的调用
+	parent   *taintState
+	call     UniversalASTNode
+	detail   string // 传入污点的实参说明
+}
+
+// key 以方法ID和被污染的参数下标区分状态，同一方法的参数被污染的情况不同时需要分别分析
+func (s *taintState) key() string {
+	indexes := make([]int, 0, len(s.params))
+	for i := range s.params {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return fmt.Sprintf("%s%v", s.methodID, indexes)
+}
+
+// FindTaintPaths 沿调用图和方法内的数据流查找从污点源到危险汇聚点的路径：
+// 污点只经由实参传入被调用方法的对应参数，汇聚点调用的实参或接收者被污染时才报告，
+// 经过净化方法的值不再被污染。每个源方法对每个汇聚点调用只报告最短的一条路径
+func FindTaintPaths(query *QueryEngine, config TaintConfig) []TaintPath {
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = DefaultTaintConfig().MaxDepth
+	}
+
+	sources := make(map[string]*taintSource)  // 方法ID -> 该方法中的污点源
+	sinks := make(map[string][]taintSink)     // 方法ID -> 该方法中的汇聚点调用
+	facts := make(map[string]*methodFacts)    // 方法ID -> 方法内的定义与调用
+	sanitizerMethods := make(map[string]bool) // 本身是净化方法的方法ID
+	sourceOf := func(methodID string) *taintSource {
+		if sources[methodID] == nil {
+			sources[methodID] = &taintSource{params: make(map[int]bool)}
+		}
+		return sources[methodID]
+	}
+	factsOf := func(methodID string) *methodFacts {
+		if facts[methodID] == nil {
+			facts[methodID] = &methodFacts{}
+		}
+		return facts[methodID]
+	}
+
+	matcher := newRuleMatcher(query)
+	sourceRules, sinkRules, sanitizerRules := newRuleIndex(config.Sources), newRuleIndex(config.Sinks), newRuleIndex(config.Sanitizers)
+	for _, node := range query.index.index {
+		switch node.Type {
+		case "Method":
+			if hop, params := annotatedParamSource(node, config.SourceAnnotations); len(params) > 0 {
+				source := sourceOf(node.ID)
+				if source.hop.Detail == "" {
+					source.hop = hop
+				}
+				for _, i := range params {
+					source.params[i] = true
+				}
+			}
+			for _, rule := range sourceRules.forDeclaration(node.Name) {
+				if matcher.methodMatchesRule(node, rule) {
+					// 入口方法的全部参数都来自外部
+					source := sourceOf(node.ID)
+					source.hop = TaintHop{
+						Class:  node.Metadata["className"],
+						File:   node.File,
+						Line:   node.StartLine + 1,
+						Detail: "污点源 入口方法 " + rule.String(),
+					}
+					for i := range node.Params {
+						source.params[i] = true
+					}
+					break
+				}
+			}
+			for _, rule := range sanitizerRules.forDeclaration(node.Name) {
+				if matcher.methodMatchesRule(node, rule) {
+					sanitizerMethods[node.ID] = true
+					break
+				}
+			}
+		case "LocalVariable", "Assignment":
+			if methodID := node.Metadata["methodID"]; methodID != "" {
+				factsOf(methodID).defs = append(factsOf(methodID).defs, node)
+			}
+		case "MethodCall":
+			callerID := node.Metadata["callerID"]
+			if callerID == "" {
+				continue
+			}
+			for _, rel := range node.Relations {
+				if rel.Type == "calls" {
+					factsOf(callerID).calls = append(factsOf(callerID).calls, node)
+					break
+				}
+			}
+			for _, rule := range sanitizerRules.forCall(node.Name) {
+				if matcher.callMatchesRule(node, rule) {
+					factsOf(callerID).sanitizers = append(factsOf(callerID).sanitizers, node)
+					break
+				}
+			}
+			for _, rule := range sourceRules.callRules(node.Name) {
+				// 按注解或方法声明匹配的污点源已在 Method 节点处理
+				if matcher.callMatchesRule(node, rule) {
+					source := sourceOf(callerID)
+					if source.hop.Detail == "" || node.StartLine+1 < source.hop.Line {
+						source.hop = TaintHop{
+							Class:  node.Metadata["callerClass"],
+							File:   node.File,
+							Line:   node.StartLine + 1,
+							Detail: "污点源 " + rule.String(),
+						}
+					}
+					source.calls = append(source.calls, node)
+					break
+				}
+			}
+			for _, rule := range sinkRules.forCall(node.Name) {
+				if matcher.callMatchesRule(node, rule) {
+					sinks[callerID] = append(sinks[callerID], taintSink{call: node, rule: rule})
+					break
+				}
+			}
+		}
+	}
+
+	flows := make(map[string]*methodDataFlow)
+	flowOf := func(method UniversalASTNode) *methodDataFlow {
+		if flows[method.ID] == nil {
+			var defs []UniversalASTNode
+			if f := facts[method.ID]; f != nil {
+				defs = f.defs
+			}
+			flows[method.ID] = buildMethodDataFlow(query, method, defs)
+		}
+		return flows[method.ID]
+	}
+
+	var paths []TaintPath
+	for sourceID, source := range sources {
+		sourceMethod, ok := query.index.GetNode(sourceID)
+		if !ok || sanitizerMethods[sourceID] {
+			continue
+		}
+		source.hop.Method = describeMethod(sourceMethod)
+
+		// 广度优先搜索，状态的 parent 记录最短路径上的前一个方法
+		start := &taintState{methodID: sourceID, params: source.params, calls: source.calls}
+		visited := map[string]bool{start.key(): true}
+		reported := make(map[string]bool)
+		frontier := []*taintState{start}
+		for depth := 0; depth <= config.MaxDepth && len(frontier) > 0; depth++ {
+			var next []*taintState
+			for _, state := range frontier {
+				method, _ := query.index.GetNode(state.methodID)
+				flow := &taintFlow{flow: flowOf(method), state: state}
+				if f := facts[state.methodID]; f != nil {
+					flow.sanitizers = f.sanitizers
+				}
+				for _, sink := range sinks[state.methodID] {
+					if reported[sink.call.ID] {
+						continue
+					}
+					if detail, ok := flow.taintedInput(sink.call); ok {
+						reported[sink.call.ID] = true
+						paths = append(paths, buildTaintPath(query, state, source.hop, sink, detail))
+					}
+				}
+				if depth == config.MaxDepth || facts[state.methodID] == nil {
+					continue
+				}
+				for _, call := range facts[state.methodID].calls {
+					args := flow.taintedArgs(call)
+					if len(args) == 0 {
+						continue
+					}
+					for _, rel := range call.Relations {
+						// 不进入净化方法
+						if rel.Type != "calls" || sanitizerMethods[rel.TargetID] {
+							continue
+						}
+						callee, ok := query.index.GetNode(rel.TargetID)
+						if !ok {
+							continue
+						}
+						params := calleeParams(callee, args)
+						if len(params) == 0 {
+							continue
+						}
+						nextState := &taintState{methodID: callee.ID, params: params, parent: state, call: call, detail: describeTaintedArgs(call, args)}
+						if visited[nextState.key()] {
+							continue
+						}
+						visited[nextState.key()] = true
+						next = append(next, nextState)
+					}
+				}
+			}
+			frontier = next
+		}
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := paths[i], paths[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Source.File != b.Source.File || a.Source.Line != b.Source.Line {
+			return a.Source.File < b.Source.File || (a.Source.File == b.Source.File && a.Source.Line < b.Source.Line)
+		}
+		return a.Sink.File < b.Sink.File || (a.Sink.File == b.Sink.File && a.Sink.Line < b.Sink.Line)
+	})
+	return paths
+}
+
+// buildTaintPath 根据搜索状态的前驱记录还原从源方法到汇聚点方法的路径
+func buildTaintPath(query *QueryEngine, state *taintState, source TaintHop, sink taintSink, detail string) TaintPath {
+	var chain []*taintState
+	for s := state; s != nil; s = s.parent {
+		chain = append([]*taintState{s}, chain...)
+	}
+
+	path := TaintPath{
+		Category: sink.rule.Category,
+		Source:   source,
+		Sink: TaintHop{
+			Class:  sink.call.Metadata["callerClass"],
+			File:   sink.call.File,
+			Line:   sink.call.StartLine + 1,
+			Detail: "汇聚点 " + sink.rule.String() + "，" + detail,
+		},
+	}
+	for i, s := range chain {
+		method, _ := query.index.GetNode(s.methodID)
+		hop := TaintHop{
+			Class:  method.Metadata["className"],
+			Method: describeMethod(method),
+			File:   method.File,
+			Line:   method.StartLine + 1,
+		}
+		if i+1 < len(chain) {
+			call := chain[i+1].call
+			hop.Line = call.StartLine + 1
+			hop.Detail = "调用 " + query.index.index[chain[i+1].methodID].Name + "，" + chain[i+1].detail
+		} else {
+			hop.Line = path.Sink.Line
+			hop.Detail = path.Sink.Detail
+		}
+		path.Hops = append(path.Hops, hop)
+	}
+	path.Sink.Method = path.Hops[len(path.Hops)-1].Method
+	return path
+}
+
+// annotatedParamSource 返回方法中带有污点源注解的参数下标，以及以第一个这样的参数描述的污点源
+func annotatedParamSource(method UniversalASTNode, annotations []string) (TaintHop, []int) {
+	var hop TaintHop
+	var params []int
+	for i, param := range method.Params {
+		if annotation, ok := FindAnnotation(param.Annotations, annotations...); ok {
+			if len(params) == 0 {
+				hop = TaintHop{
+					Class:  method.Metadata["className"],
+					File:   method.File,
+					Line:   method.StartLine + 1,
+					Detail: fmt.Sprintf("污点源 第 %d 个参数 @%s %s", i+1, annotation.Name, param.Name),
+				}
+			}
+			params = append(params, i)
+		}
+	}
+	return hop, params
+}
+
+// taintFlow 在一个搜索状态下判断方法内的表达式是否被污染：
+// 被污染的参数、源方法中返回外部输入的调用，以及由它们赋值得到的局部变量。
+// 字段与常量不视为被污染，作为净化方法实参出现的变量视为已净化
+type taintFlow struct {
+	flow       *methodDataFlow
+	state      *taintState
+	sanitizers []UniversalASTNode
+}
+
+// taintedInput 判断汇聚点调用的实参或接收者是否被污染，返回被污染的输入说明
+func (f *taintFlow) taintedInput(call UniversalASTNode) (string, bool) {
+	if args := f.taintedArgs(call); len(args) > 0 {
+		return describeTaintedArgs(call, args), true
+	}
+	if receiver := call.Metadata["receiver"]; receiver != "" {
+		if f.exprTainted(ExprInfo{Text: receiver, Uses: expressionIdentifiers(receiver)}, call.StartLine, call.EndLine, make(map[string]bool)) {
+			return "接收者 " + receiver + " 被污染", true
+		}
+	}
+	return "", false
+}
+
+// taintedArgs 返回调用中被污染的实参下标
+func (f *taintFlow) taintedArgs(call UniversalASTNode) []int {
+	var args []int
+	for i, arg := range call.Arguments {
+		if f.exprTainted(arg, call.StartLine, call.EndLine, make(map[string]bool)) {
+			args = append(args, i)
+		}
+	}
+	return args
+}
+
+// exprTainted 判断位于 start 到 end 行的表达式是否被污染
+func (f *taintFlow) exprTainted(expr ExprInfo, start, end int, visited map[string]bool) bool {
+	for _, call := range f.state.calls {
+		text := callText(call)
+		if call.StartLine >= start && call.StartLine <= end && strings.Contains(expr.Text, text) &&
+			!f.sanitized(expr.Text, start, end, func(arg ExprInfo) bool { return strings.Contains(arg.Text, text) }) {
+			return true
+		}
+	}
+	for _, use := range expr.Uses {
+		if f.sanitized(expr.Text, start, end, func(arg ExprInfo) bool { return slices.Contains(arg.Uses, use) }) {
+			continue
+		}
+		if f.varTainted(use, start, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// varTainted 判断 line 行处的变量是否被污染：变量是被污染的参数，或任一可能到达该行的定义被污染
+func (f *taintFlow) varTainted(name string, line int, visited map[string]bool) bool {
+	if visited[name] {
+		return false
+	}
+	visited[name] = true
+	if i, ok := f.flow.params[name]; ok && f.state.params[i] {
+		return true
+	}
+	for _, def := range f.flow.reachingDefs(name, line) {
+		value := ExprInfo{Text: def.Metadata["value"], Uses: splitNonEmpty(def.Metadata["uses"])}
+		if f.exprTainted(value, def.StartLine, def.EndLine, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitized 判断表达式中是否有净化方法调用，且其实参或接收者满足 matches
+func (f *taintFlow) sanitized(text string, start, end int, matches func(arg ExprInfo) bool) bool {
+	for _, call := range f.sanitizers {
+		if call.StartLine < start || call.StartLine > end || !strings.Contains(text, callText(call)) {
+			continue
+		}
+		if receiver := call.Metadata["receiver"]; receiver != "" && matches(ExprInfo{Text: receiver, Uses: expressionIdentifiers(receiver)}) {
+			return true
+		}
+		for _, arg := range call.Arguments {
+			if matches(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// calleeParams 将被污染的实参下标对应到被调用方法的参数下标，多出的实参对应到可变参数
+func calleeParams(callee UniversalASTNode, args []int) map[int]bool {
+	params := make(map[int]bool)
+	for _, i := range args {
+		switch {
+		case i < len(callee.Params):
+			params[i] = true
+		case len(callee.Params) > 0 && callee.Params[len(callee.Params)-1].Variadic:
+			params[len(callee.Params)-1] = true
+		}
+	}
+	return params
+}
+
+// describeTaintedArgs 描述调用中被污染的实参
+func describeTaintedArgs(call UniversalASTNode, args []int) string {
+	parts := make([]string, 0, len(args))
+	for _, i := range args {
+		parts = append(parts, fmt.Sprintf("第 %d 个实参 %s", i+1, call.Arguments[i].Text))
+	}
+	return strings.Join(parts, "、") + " 被污染"
+}
+
+// callText 返回调用在表达式原文中的开头部分，如 getParameter( 或构造器调用的 File(
+func callText(call UniversalASTNode) string {
+	if call.Name == "<init>" {
+		name := ShortClassName(call.Metadata["receiverType"])
+		if idx := strings.LastIndex(name, "$"); idx != -1 {
+			name = name[idx+1:]
+		}
+		return name + "("
+	}
+	return call.Name + "("
+}
+
+// expressionIdentifiers 提取表达式中作为变量引用的标识符：跳过字符串字面量以及 . 之后的成员名
+func expressionIdentifiers(text string) []string {
+	var names []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"' || c == '\'':
+			// 跳过字符串和字符字面量
+			j := i + 1
+			for j < len(text) && text[j] != c {
+				if text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j + 1
+		case c == '_' || c == '$' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(text) && (text[j] == '_' || text[j] == '$' || unicode.IsLetter(rune(text[j])) || unicode.IsDigit(rune(text[j]))) {
+				j++
+			}
+			if i == 0 || text[i-1] != '.' {
+				names = appendUnique(names, text[i:j])
+			}
+			i = j
+		case unicode.IsDigit(rune(c)):
+			// 跳过数字字面量，如 0x1F
+			for i < len(text) && (text[i] == '_' || unicode.IsLetter(rune(text[i])) || unicode.IsDigit(rune(text[i]))) {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return names
+}
+
+// ruleMatcher 在一次查询中按规则匹配方法调用与方法声明：类型的全部父类型只计算一次，
+// 避免对每个调用都遍历整个索引查找父类
+type ruleMatcher struct {
+	query      *QueryEngine
+	resolver   *callResolver
+	supertypes map[string][]string // 类型 -> 自身及全部父类型
+}
+
+func newRuleMatcher(query *QueryEngine) *ruleMatcher {
+	return &ruleMatcher{
+		query:      query,
+		resolver:   newCallResolver(query.index),
+		supertypes: make(map[string][]string),
+	}
+}
+
+// isSubtypeOf 判断类型是否为 ruleClass 本身或其子类型（包括索引中声明的继承关系与已知的项目外类型关系）
+func (m *ruleMatcher) isSubtypeOf(typeName, ruleClass string) bool {
+	supers, ok := m.supertypes[typeName]
+	if !ok {
+		supers = m.resolver.supertypes(typeName)
+		m.supertypes[typeName] = supers
+	}
+	for _, super := range supers {
+		if classNameMatches(super, ruleClass) {
+			return true
+		}
+	}
+	return false
+}
+
+// callMatchesRule 判断方法调用是否匹配规则：方法名相同，且接收者类型为规则中的类或其子类。
+// 接收者类型无法推断时，退化为检查接收者表达式中是否出现规则类的简单类名。
+// 匹配方法声明的规则通过调用图检查被调用的方法
+func (m *ruleMatcher) callMatchesRule(call UniversalASTNode, rule TaintRule) bool {
+	if rule.matchesDeclaration() {
+		for _, rel := range call.Relations {
+			if callee, ok := m.query.index.GetNode(rel.TargetID); ok && rel.Type == "calls" && m.methodMatchesRule(callee, rule) {
+				return true
+			}
+		}
+		return false
+	}
+	if call.Name != rule.Method {
+		return false
+	}
+	receiverType := call.Metadata["receiverType"]
+	if receiverType == "" {
+		return strings.Contains(call.Metadata["receiver"], ShortClassName(rule.Class))
+	}
+	return m.isSubtypeOf(receiverType, rule.Class)
+}
+
+// methodMatchesRule 判断方法声明是否匹配规则：按方法上的注解，或按方法名及所在类（含父类）匹配
+func (m *ruleMatcher) methodMatchesRule(method UniversalASTNode, rule TaintRule) bool {
+	if rule.Annotation != "" {
+		_, ok := FindAnnotation(method.Annotations, rule.Annotation)
+		return ok
+	}
+	if method.Name != rule.Method {
+		return false
+	}
+	return m.isSubtypeOf(method.Metadata["className"], rule.Class)
+}
+
+// ruleIndex 按方法名索引规则，只有同名的调用规则和匹配方法声明的规则需要逐条检查，候选规则保持原有顺序
+type ruleIndex struct {
+	rules        []TaintRule
+	byMethod     map[string][]int // 方法名 -> 匹配调用的规则下标
+	declarations []int            // 匹配方法声明（按注解或 target: method）的规则下标
+}
+
+func newRuleIndex(rules []TaintRule) ruleIndex {
+	index := ruleIndex{rules: rules, byMethod: make(map[string][]int)}
+	for i, rule := range rules {
+		if rule.matchesDeclaration() {
+			index.declarations = append(index.declarations, i)
+		} else {
+			index.byMethod[rule.Method] = append(index.byMethod[rule.Method], i)
+		}
+	}
+	return index
+}
+
+// forCall 返回可能匹配该方法名调用的规则
+func (idx ruleIndex) forCall(name string) []TaintRule {
+	calls, declarations := idx.byMethod[name], idx.declarations
+	candidates := make([]TaintRule, 0, len(calls)+len(declarations))
+	for len(calls) > 0 || len(declarations) > 0 {
+		if len(declarations) == 0 || len(calls) > 0 && calls[0] < declarations[0] {
+			candidates = append(candidates, idx.rules[calls[0]])
+			calls = calls[1:]
+		} else {
+			candidates = append(candidates, idx.rules[declarations[0]])
+			declarations = declarations[1:]
+		}
+	}
+	return candidates
+}
+
+// callRules 返回按该方法名匹配调用的规则，不含匹配方法声明的规则
+func (idx ruleIndex) callRules(name string) []TaintRule {
+	var candidates []TaintRule
+	for _, i := range idx.byMethod[name] {
+		candidates = append(candidates, idx.rules[i])
+	}
+	return candidates
+}
+
+// forDeclaration 返回可能匹配该方法名声明的规则
+func (idx ruleIndex) forDeclaration(name string) []TaintRule {
+	var candidates []TaintRule
+	for _, i := range idx.declarations {
+		if rule := idx.rules[i]; rule.Annotation != "" || rule.Method == name {
+			candidates = append(candidates, rule)
+		}
+	}
+	return candidates
+}
+
+// classNameMatches 比较全限定类名，规则只写简单类名时按简单类名比较
+func classNameMatches(fullName, ruleClass string) bool {
+	if !strings.Contains(ruleClass, ".") {
+		return ShortClassName(fullName) == ruleClass
+	}
+	return fullName == ruleClass
+}
+
+// FormatTaintPaths 格式化污点路径，limit 大于 0 时最多输出 limit 条
+func FormatTaintPaths(paths []TaintPath, limit int) string {
+	if len(paths) == 0 {
+		return "未找到从污点源到危险方法的调用路径"
+	}
+
+	var builder strings.Builder
+	for i, path := range paths {
+		if limit > 0 && i >= limit {
+			builder.WriteString(fmt.Sprintf("... 共 %d 条路径，仅显示前 %d 条，可通过 category 参数缩小范围\n", len(paths), limit))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("==== 路径 %d [%s] ====\n", i+1, path.Category))
+		builder.WriteString(fmt.Sprintf("%s (%s:%d)\n", path.Source.Detail, filepath.Base(path.Source.File), path.Source.Line))
+		for j, hop := range path.Hops {
+			builder.WriteString(fmt.Sprintf("  %d. %s (%s:%d) %s\n", j+1, hop.Method, filepath.Base(hop.File), hop.Line, hop.Detail))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
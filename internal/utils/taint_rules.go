@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"Fenrir-CodeAuditTool/configs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// taintRuleFile 规则文件结构
+type taintRuleFile struct {
+	Sources    []TaintRule `yaml:"sources"`
+	Sinks      []TaintRule `yaml:"sinks"`
+	Sanitizers []TaintRule `yaml:"sanitizers"`
+}
+
+// LoadTaintConfig 加载内置规则与配置文件 rules 段指定的规则文件，每次调用都会重新读取规则文件
+func LoadTaintConfig(config *configs.Config) (TaintConfig, error) {
+	taintConfig := DefaultTaintConfig()
+	if config.Rules.DisableBuiltin {
+		taintConfig = TaintConfig{MaxDepth: taintConfig.MaxDepth}
+	}
+
+	for _, pattern := range config.Rules.Files {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return taintConfig, fmt.Errorf("规则文件路径错误 %s: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			return taintConfig, fmt.Errorf("未找到规则文件: %s", pattern)
+		}
+		for _, file := range matches {
+			ruleFile, err := loadTaintRuleFile(file)
+			if err != nil {
+				return taintConfig, err
+			}
+			for _, rule := range ruleFile.Sources {
+				if rule.Category == "" {
+					rule.Category = "custom-input"
+				}
+				// 注解规则同时匹配方法参数上的注解
+				if rule.Annotation != "" {
+					taintConfig.SourceAnnotations = append(taintConfig.SourceAnnotations, rule.Annotation)
+				}
+				taintConfig.Sources = append(taintConfig.Sources, rule)
+			}
+			for _, rule := range ruleFile.Sinks {
+				if rule.Category == "" {
+					rule.Category = "custom"
+				}
+				taintConfig.Sinks = append(taintConfig.Sinks, rule)
+			}
+			taintConfig.Sanitizers = append(taintConfig.Sanitizers, ruleFile.Sanitizers...)
+		}
+	}
+	return taintConfig, nil
+}
+
+// loadTaintRuleFile 读取并校验单个规则文件
+func loadTaintRuleFile(file string) (*taintRuleFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则文件失败 %s: %v", file, err)
+	}
+
+	var ruleFile taintRuleFile
+	if err := yaml.Unmarshal(data, &ruleFile); err != nil {
+		return nil, fmt.Errorf("解析规则文件失败 %s: %v", file, err)
+	}
+
+	groups := map[string][]TaintRule{
+		"sources":    ruleFile.Sources,
+		"sinks":      ruleFile.Sinks,
+		"sanitizers": ruleFile.Sanitizers,
+	}
+	for group, rules := range groups {
+		for i, rule := range rules {
+			if rule.Annotation == "" && (rule.Class == "" || rule.Method == "") {
+				return nil, fmt.Errorf("规则文件 %s 中 %s 第 %d 条规则需要指定 annotation，或同时指定 class 和 method", file, group, i+1)
+			}
+			if rule.Target != "" && rule.Target != "call" && rule.Target != "method" {
+				return nil, fmt.Errorf("规则文件 %s 中 %s 第 %d 条规则的 target 只能为 call 或 method", file, group, i+1)
+			}
+		}
+	}
+	return &ruleFile, nil
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"Fenrir-CodeAuditTool/configs"
+)
+
+func TestLoadTaintConfig(t *testing.T) {
+	builtin := DefaultTaintConfig()
+	tests := []struct {
+		name           string
+		rules          string
+		disableBuiltin bool
+		wantErr        bool
+		wantSources    int
+		wantSinks      int
+		wantSanitizers int
+	}{
+		{
+			name: "追加到内置规则",
+			rules: `sources:
+  - class: app.Ctx
+    method: param
+  - annotation: Input
+sinks:
+  - class: app.Shell
+    method: run
+sanitizers:
+  - class: app.Clean
+    method: escape
+`,
+			wantSources:    len(builtin.Sources) + 2,
+			wantSinks:      len(builtin.Sinks) + 1,
+			wantSanitizers: 1,
+		},
+		{
+			name: "禁用内置规则",
+			rules: `sinks:
+  - class: app.Shell
+    method: run
+`,
+			disableBuiltin: true,
+			wantSinks:      1,
+		},
+		{
+			name: "缺少 method",
+			rules: `sinks:
+  - class: app.Shell
+`,
+			wantErr: true,
+		},
+		{
+			name: "target 取值错误",
+			rules: `sinks:
+  - class: app.Shell
+    method: run
+    target: field
+`,
+			wantErr: true,
+		},
+		{
+			name:    "YAML 格式错误",
+			rules:   "sinks: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "rules.yaml")
+			if err := os.WriteFile(file, []byte(tt.rules), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config := &configs.Config{}
+			config.Rules.Files = []string{file}
+			config.Rules.DisableBuiltin = tt.disableBuiltin
+
+			got, err := LoadTaintConfig(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTaintConfig err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Sources) != tt.wantSources || len(got.Sinks) != tt.wantSinks || len(got.Sanitizers) != tt.wantSanitizers {
+				t.Errorf("规则数 sources=%d sinks=%d sanitizers=%d, want %d %d %d",
+					len(got.Sources), len(got.Sinks), len(got.Sanitizers), tt.wantSources, tt.wantSinks, tt.wantSanitizers)
+			}
+		})
+	}
+
+	config := &configs.Config{}
+	config.Rules.Files = []string{filepath.Join(t.TempDir(), "*.yaml")}
+	if _, err := LoadTaintConfig(config); err == nil {
+		t.Errorf("规则文件不存在时应当返回错误")
+	}
+}
+
+func TestFindTaintPathsWithCustomRules(t *testing.T) {
+	files := map[string]string{
+		"app/Shell.java": `package app;
+
+public class Shell {
+    void run(String cmd) {}
+}
+`,
+		"app/Clean.java": `package app;
+
+public class Clean {
+    static String escape(String s) { return s; }
+}
+`,
+		"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Shell shell;
+
+    @Endpoint
+    void exec(String cmd) { shell.run(cmd); }
+
+    @Endpoint
+    void safeExec(String cmd) { shell.run(Clean.escape(cmd)); }
+
+    void input(@Input String cmd) { shell.run(cmd); }
+}
+`,
+	}
+	query := NewQueryEngine(indexSources(t, files, &JavaParser{}))
+
+	tests := []struct {
+		name   string
+		config TaintConfig
+		want   []string
+	}{
+		{
+			name: "方法注解污点源与自定义汇聚点",
+			config: TaintConfig{
+				Sources: []TaintRule{{Category: "input", Annotation: "Endpoint"}},
+				Sinks:   []TaintRule{{Category: "shell", Class: "app.Shell", Method: "run"}},
+			},
+			want: []string{"shell: app.Ctl#exec(String)", "shell: app.Ctl#safeExec(String)"},
+		},
+		{
+			name: "净化方法",
+			config: TaintConfig{
+				Sources:    []TaintRule{{Category: "input", Annotation: "Endpoint"}},
+				Sinks:      []TaintRule{{Category: "shell", Class: "app.Shell", Method: "run"}},
+				Sanitizers: []TaintRule{{Class: "Clean", Method: "escape"}},
+			},
+			want: []string{"shell: app.Ctl#exec(String)"},
+		},
+		{
+			name: "参数注解与方法声明汇聚点",
+			config: TaintConfig{
+				SourceAnnotations: []string{"Input"},
+				Sinks:             []TaintRule{{Category: "shell", Class: "Shell", Method: "run", Target: "method"}},
+			},
+			want: []string{"shell: app.Ctl#input(String)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taintPathSummary(FindTaintPaths(query, tt.config)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindTaintPaths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTaintRule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    TaintRule
+		wantErr bool
+	}{
+		{"java.lang.Runtime#exec", TaintRule{Category: "c", Class: "java.lang.Runtime", Method: "exec"}, false},
+		{"File#<init>", TaintRule{Category: "c", Class: "File", Method: "<init>"}, false},
+		{"java.lang.Runtime", TaintRule{}, true},
+		{"#exec", TaintRule{}, true},
+		{"Runtime#", TaintRule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseTaintRule("c", tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTaintRule(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTaintRule(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+// taintPathSummary 以 "分类: 源方法 -> ... -> 汇聚点方法" 的形式概括污点路径
+func taintPathSummary(paths []TaintPath) []string {
+	summary := []string{}
+	for _, path := range paths {
+		line := path.Category + ":"
+		for _, hop := range path.Hops {
+			line += " " + hop.Method
+		}
+		summary = append(summary, line)
+	}
+	return summary
+}
+
+func TestFindTaintPaths(t *testing.T) {
+	shell := `package app;
+
+public class Shell {
+    void run(String cmd) throws Exception {
+        Runtime.getRuntime().exec(cmd);
+    }
+}
+`
+	svc := func(body string) string {
+		return `package app;
+
+public class Svc {
+    private Shell shell;
+
+    void handle(String cmd, String mode) throws Exception {
+` + body + `
+    }
+}
+`
+	}
+	ctl := `package app;
+
+public class Ctl {
+    private Svc svc;
+
+    void run(@RequestParam String cmd) throws Exception { svc.handle(cmd, "fast"); }
+}
+`
+	tests := []struct {
+		name       string
+		files      map[string]string
+		maxDepth   int
+		sinks      []TaintRule
+		sanitizers []TaintRule
+		want       []string
+	}{
+		{
+			name: "注解参数经调用到达汇聚点",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Shell shell;
+
+    String run(@RequestParam String cmd) throws Exception {
+        shell.run(cmd);
+        return "ok";
+    }
+}
+`,
+			},
+			want: []string{"command-injection: app.Ctl#run(String) app.Shell#run(String)"},
+		},
+		{
+			name: "同一方法中的 Servlet 输入与构造器汇聚点",
+			files: map[string]string{
+				"app/Download.java": `package app;
+
+import java.io.File;
+import javax.servlet.http.HttpServletRequest;
+
+public class Download {
+    File open(HttpServletRequest request) {
+        String name = request.getParameter("name");
+        return new File(name);
+    }
+}
+`,
+			},
+			want: []string{"path-traversal: app.Download#open(HttpServletRequest)"},
+		},
+		{
+			name: "超过最大深度",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Svc.java": `package app;
+
+public class Svc {
+    private Shell shell;
+
+    void handle(String cmd) throws Exception { shell.run(cmd); }
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    private Svc svc;
+
+    void run(@PathVariable String cmd) throws Exception { svc.handle(cmd); }
+}
+`,
+			},
+			maxDepth: 1,
+			want:     []string{},
+		},
+		{
+			name: "局部变量经多层调用传递",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Ctl.java":   ctl,
+				"app/Svc.java": svc(`        String full = "sh -c " + cmd;
+        shell.run(full);`),
+			},
+			want: []string{"command-injection: app.Ctl#run(String) app.Svc#handle(String, String) app.Shell#run(String)"},
+		},
+		{
+			name: "只有被污染的参数继续传递",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Ctl.java":   ctl,
+				"app/Svc.java":   svc(`        shell.run(mode);`),
+			},
+			want: []string{},
+		},
+		{
+			name: "常量实参",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Ctl.java":   ctl,
+				"app/Svc.java": svc(`        String fixed = "ls";
+        shell.run(fixed);
+        Runtime.getRuntime().exec("id");`),
+			},
+			want: []string{},
+		},
+		{
+			name: "经过净化方法的值",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Ctl.java":   ctl,
+				"app/Svc.java": svc(`        String safe = Escaper.quote(cmd);
+        shell.run(safe);`),
+			},
+			sanitizers: []TaintRule{{Class: "app.Escaper", Method: "quote"}},
+			want:       []string{},
+		},
+		{
+			name: "净化方法不影响未经过它的值",
+			files: map[string]string{
+				"app/Shell.java": shell,
+				"app/Ctl.java":   ctl,
+				"app/Svc.java": svc(`        String safe = Escaper.quote(cmd);
+        shell.run(cmd);`),
+			},
+			sanitizers: []TaintRule{{Class: "app.Escaper", Method: "quote"}},
+			want:       []string{"command-injection: app.Ctl#run(String) app.Svc#handle(String, String) app.Shell#run(String)"},
+		},
+		{
+			name: "接收者被污染",
+			files: map[string]string{
+				"app/Template.java": `package app;
+
+public class Template {
+    Template(String source) {}
+
+    String render() { return ""; }
+}
+`,
+				"app/Ctl.java": `package app;
+
+public class Ctl {
+    String run(@RequestParam String source) {
+        Template fixed = new Template("hello");
+        fixed.render();
+        Template template = new Template(source);
+        return template.render();
+    }
+}
+`,
+			},
+			sinks: []TaintRule{{Category: "template-injection", Class: "app.Template", Method: "render"}},
+			want:  []string{"template-injection: app.Ctl#run(String)"},
+		},
+		{
+			name: "没有污点源",
+			files: map[string]string{
+				"app/Shell.java": shell,
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := NewQueryEngine(indexSources(t, tt.files, &JavaParser{}))
+			config := DefaultTaintConfig()
+			config.MaxDepth = tt.maxDepth
+			config.Sanitizers = tt.sanitizers
+			if tt.sinks != nil {
+				config.Sinks = tt.sinks
+			}
+			if got := taintPathSummary(FindTaintPaths(query, config)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindTaintPaths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
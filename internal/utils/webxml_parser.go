@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WebXMLParser 解析 WEB-INF/web.xml 和 web-fragment.xml 中声明的 Servlet、Filter 和 Listener
+type WebXMLParser struct{}
+
+// webXMLDescriptor web.xml 中与入口相关的元素，不区分命名空间
+type webXMLDescriptor struct {
+	Servlets []struct {
+		Name    string `xml:"servlet-name"`
+		Class   string `xml:"servlet-class"`
+		JSPFile string `xml:"jsp-file"`
+	} `xml:"servlet"`
+	ServletMappings []struct {
+		Name        string   `xml:"servlet-name"`
+		URLPatterns []string `xml:"url-pattern"`
+	} `xml:"servlet-mapping"`
+	Filters []struct {
+		Name  string `xml:"filter-name"`
+		Class string `xml:"filter-class"`
+	} `xml:"filter"`
+	FilterMappings []struct {
+		Name         string   `xml:"filter-name"`
+		URLPatterns  []string `xml:"url-pattern"`
+		ServletNames []string `xml:"servlet-name"`
+	} `xml:"filter-mapping"`
+	Listeners []struct {
+		Class string `xml:"listener-class"`
+	} `xml:"listener"`
+}
+
+func (p *WebXMLParser) Language() string {
+	return "webxml"
+}
+
+func (p *WebXMLParser) ParseFile(filePath string) ([]UniversalASTNode, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var descriptor webXMLDescriptor
+	if err := xml.Unmarshal(content, &descriptor); err != nil {
+		// 格式错误的部署描述符不影响其他文件的索引
+		fmt.Printf("解析 %s 失败: %v\n", filePath, err)
+		return nil, nil
+	}
+
+	servletPatterns := make(map[string][]string)
+	for _, mapping := range descriptor.ServletMappings {
+		servletPatterns[mapping.Name] = append(servletPatterns[mapping.Name], trimAll(mapping.URLPatterns)...)
+	}
+	filterPatterns := make(map[string][]string)
+	for _, mapping := range descriptor.FilterMappings {
+		filterPatterns[mapping.Name] = append(filterPatterns[mapping.Name], trimAll(mapping.URLPatterns)...)
+		// 按 servlet-name 映射的过滤器记录为 servlet:名称
+		for _, servletName := range trimAll(mapping.ServletNames) {
+			filterPatterns[mapping.Name] = append(filterPatterns[mapping.Name], "servlet:"+servletName)
+		}
+	}
+
+	var nodes []UniversalASTNode
+	for _, servlet := range descriptor.Servlets {
+		name := strings.TrimSpace(servlet.Name)
+		className := strings.TrimSpace(servlet.Class)
+		if className == "" {
+			className = strings.TrimSpace(servlet.JSPFile)
+		}
+		nodes = append(nodes, webXMLEntryPoint(filePath, "servlet", name, className, servletPatterns[name], len(nodes)))
+	}
+	for _, filter := range descriptor.Filters {
+		name := strings.TrimSpace(filter.Name)
+		nodes = append(nodes, webXMLEntryPoint(filePath, "filter", name, strings.TrimSpace(filter.Class), filterPatterns[name], len(nodes)))
+	}
+	for _, listener := range descriptor.Listeners {
+		className := strings.TrimSpace(listener.Class)
+		nodes = append(nodes, webXMLEntryPoint(filePath, "listener", ShortClassName(className), className, nil, len(nodes)))
+	}
+	return nodes, nil
+}
+
+// webXMLEntryPoint 创建 web.xml 中声明的入口节点
+func webXMLEntryPoint(filePath, kind, name, className string, urlPatterns []string, seq int) UniversalASTNode {
+	pkg := ""
+	if i := strings.LastIndex(className, "."); i > 0 && !strings.HasSuffix(className, ".jsp") {
+		pkg = className[:i]
+	}
+	return UniversalASTNode{
+		ID:       fmt.Sprintf("%s:%s:%s:%d", filePath, kind, name, seq),
+		Language: "xml",
+		Type:     "EntryPoint",
+		Name:     name,
+		File:     filePath,
+		Package:  pkg,
+		Metadata: map[string]string{
+			"kind":        kind,
+			"source":      "web.xml",
+			"className":   className,
+			"urlPatterns": strings.Join(urlPatterns, ","),
+		},
+	}
+}
+
+// trimAll 去掉每个元素首尾空白并丢弃空元素
+func trimAll(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}